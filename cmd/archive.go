@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/encryption"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/blob"
+	"github.com/shubhamgptln/sarama-ai/usecase/archive"
+	"github.com/shubhamgptln/sarama-ai/usecase/snapshot"
+)
+
+// ArchiveConfig sets up the blob store that keeps raw webhook payloads,
+// downloaded attachments, purged dead letters and index snapshots, and how
+// long each archived kind is kept.
+type ArchiveConfig struct {
+	Blob       blob.Config
+	Encryption EncryptionConfig
+	archive.Config
+}
+
+// EncryptionConfig sets up envelope encryption of the blobs, with a master
+// key held by Provider: "file" or "aws-kms"; blobs are stored as is when
+// empty.
+type EncryptionConfig struct {
+	Provider string
+	// KeyFile holds the master keys for the file provider.
+	KeyFile string
+	// KeyID is the active key in KeyFile, the last one when empty, or the
+	// AWS KMS key.
+	KeyID string
+	KMS   encryption.AWSKMSConfig
+}
+
+// newBlobStore returns nil when no blob store is configured, and an
+// *encryption.BlobStore when encryption is.
+func newBlobStore(config *Config) (domain.BlobStore, error) {
+	if config.Archive.Blob.Backend == "" {
+		return nil, nil
+	}
+	store, err := blob.Open(config.Archive.Blob)
+	if err != nil {
+		return nil, err
+	}
+	keys, err := newKeyProvider(config.Archive.Encryption)
+	if err != nil || keys == nil {
+		return store, err
+	}
+	return encryption.NewBlobStore(store, encryption.NewEnvelope(keys)), nil
+}
+
+// newKeyProvider returns nil when encryption is off.
+func newKeyProvider(c EncryptionConfig) (encryption.KeyProvider, error) {
+	switch c.Provider {
+	case "":
+		return nil, nil
+	case "file":
+		return encryption.NewFileKeys(c.KeyFile, c.KeyID)
+	case "aws-kms":
+		kms := c.KMS
+		kms.KeyID = c.KeyID
+		return encryption.NewAWSKMS(kms)
+	}
+	return nil, fmt.Errorf("unknown encryption key provider %q", c.Provider)
+}
+
+// encryptedBlobs returns blobs when it encrypts, and nil otherwise.
+func encryptedBlobs(blobs domain.BlobStore) *encryption.BlobStore {
+	encrypted, _ := blobs.(*encryption.BlobStore)
+	return encrypted
+}
+
+// newArchive returns nil without a blob store.
+func newArchive(config *Config, blobs domain.BlobStore) *archive.Service {
+	if blobs == nil {
+		return nil
+	}
+	return archive.NewService(blobs, config.Archive.Config)
+}
+
+// newSnapshots returns nil without a blob store to keep snapshots in.
+func newSnapshots(config *Config, store domain.VectorStore, blobs domain.BlobStore) *snapshot.Service {
+	if blobs == nil {
+		return nil
+	}
+	return snapshot.NewService(store, blobs, config.Embeddings.EmbeddingModel)
+}
@@ -1 +1,111 @@
 package cmd
+
+import (
+	"fmt"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/confluence"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/llm"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/postgres"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/sqlite"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/vectorstore"
+	"github.com/shubhamgptln/sarama-ai/usecase/experiment"
+	"github.com/shubhamgptln/sarama-ai/usecase/moderation"
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+	"github.com/shubhamgptln/sarama-ai/usecase/research"
+	"github.com/shubhamgptln/sarama-ai/usecase/writeback"
+)
+
+// newVectorStore opens the VECTOR_STORE_BACKEND; the postgres and sqlite
+// backends keep documents and chunks in the database of repos.
+func newVectorStore(config *Config, repos *repositories) (domain.VectorStore, error) {
+	switch config.VectorStore.Backend {
+	case "postgres":
+		if repos.db == nil {
+			return nil, fmt.Errorf("vector store: postgres needs STORAGE_BACKEND=postgres")
+		}
+		return postgres.NewVectorStore(repos.db), nil
+	case "sqlite":
+		if repos.sqlite == nil {
+			return nil, fmt.Errorf("vector store: sqlite needs STORAGE_BACKEND=sqlite")
+		}
+		return sqlite.NewVectorStore(repos.sqlite), nil
+	}
+	store, err := vectorstore.New(config.VectorStore)
+	if err != nil {
+		return nil, fmt.Errorf("vector store: %w", err)
+	}
+	return store, nil
+}
+
+func newQueryService(config *Config, store domain.VectorStore, embedder domain.Embedder, cache domain.Cache, stats domain.RetrievalStats, opts ...query.Option) (*query.Service, error) {
+	opts = append(opts, query.WithRetrievalStats(stats))
+	if cache != nil && config.Cache.AnswerTTL > 0 {
+		opts = append(opts, query.WithAnswerCache(meteredCache{cache, "answers"}, config.Cache.AnswerTTL))
+	}
+	moderator, err := newModerator(config)
+	if err != nil {
+		return nil, fmt.Errorf("moderation: %w", err)
+	}
+	if moderator != nil {
+		opts = append(opts, query.WithModerator(moderator))
+	}
+
+	return query.NewService(embedder, store, llm.NewClient(config.LLM), config.Query, opts...), nil
+}
+
+func newResearchService(config *Config, queries *query.Service) *research.Service {
+	return research.NewService(queries, llm.NewClient(config.LLM), config.Research)
+}
+
+// newWritebackService returns nil unless WRITEBACK_ENABLED is set.
+func newWritebackService(config *Config, store domain.VectorStore, queries *query.Service) (*writeback.Service, error) {
+	if !config.Writeback.Enabled {
+		return nil, nil
+	}
+	return writeback.NewService(store, queries, llm.NewClient(config.LLM), confluence.NewClient(config.Confluence), config.Writeback.Config)
+}
+
+// newModerator builds the moderation stage for MODERATION_MODE: off, rules, provider or both.
+func newModerator(config *Config) (domain.Moderator, error) {
+	var chain moderation.Chain
+
+	switch config.Moderation.Mode {
+	case "", "off":
+		return nil, nil
+	case "rules", "provider", "both":
+	default:
+		return nil, fmt.Errorf("unknown moderation mode %q", config.Moderation.Mode)
+	}
+
+	if config.Moderation.Mode == "rules" || config.Moderation.Mode == "both" {
+		rules := moderation.DefaultRules
+		if config.Moderation.RulesFile != "" {
+			custom, err := moderation.LoadRules(config.Moderation.RulesFile)
+			if err != nil {
+				return nil, err
+			}
+			rules = append(append([]moderation.Rule{}, rules...), custom...)
+		}
+		rm, err := moderation.NewRuleModerator(rules)
+		if err != nil {
+			return nil, err
+		}
+		chain = append(chain, rm)
+	}
+	if config.Moderation.Mode == "provider" || config.Moderation.Mode == "both" {
+		chain = append(chain, llm.NewClient(config.LLM))
+	}
+	return chain, nil
+}
+
+func newExperimentManager(config *Config) (*experiment.Manager, error) {
+	if config.App.ExperimentsFile == "" {
+		return experiment.NewManager(nil)
+	}
+	experiments, err := experiment.LoadFile(config.App.ExperimentsFile)
+	if err != nil {
+		return nil, fmt.Errorf("experiments: %w", err)
+	}
+	return experiment.NewManager(experiments)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/confluence"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/oidc"
+	"github.com/shubhamgptln/sarama-ai/usecase/access"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+	"github.com/shubhamgptln/sarama-ai/usecase/rbac"
+	"github.com/shubhamgptln/sarama-ai/usecase/tenant"
+)
+
+// newAuthService returns nil when authentication is disabled.
+func newAuthService(config *Config, keys domain.APIKeyRepository) (*auth.Service, error) {
+	if !config.Auth.Enabled {
+		return nil, nil
+	}
+	var tokens domain.TokenVerifier
+	if config.OIDC.Issuer != "" {
+		verifier, err := oidc.NewVerifier(config.OIDC)
+		if err != nil {
+			return nil, err
+		}
+		tokens = verifier
+	}
+	return auth.NewService(keys, tokens, config.Auth)
+}
+
+// newRBACService returns nil when authentication is disabled.
+func newRBACService(config *Config, bindings domain.RoleBindingRepository) *rbac.Service {
+	if !config.Auth.Enabled {
+		return nil
+	}
+	return rbac.NewService(bindings)
+}
+
+// newTenantService returns nil unless a tenants file is set.
+func newTenantService(config *Config, shared *sharedState) (*tenant.Service, error) {
+	if config.App.TenantsFile == "" {
+		return nil, nil
+	}
+	tenants, err := tenant.LoadFile(config.App.TenantsFile)
+	if err != nil {
+		return nil, fmt.Errorf("tenants: %w", err)
+	}
+	return tenant.NewService(tenants, shared.tenantOptions()...)
+}
+
+// newAccessService returns nil unless document-level authorization is enabled.
+func newAccessService(config *Config) *access.Service {
+	if !config.Auth.Enabled || !config.Access.Enabled {
+		return nil
+	}
+	return access.NewService(confluence.NewClient(config.Confluence), config.Access)
+}
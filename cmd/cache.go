@@ -0,0 +1,115 @@
+package cmd
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/memory"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/redis"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+	"github.com/shubhamgptln/sarama-ai/usecase/tenant"
+)
+
+// CacheConfig sets up the embedding and answer caches, and Redis to share
+// them, rate-limit and quota counters and reindex locks between instances.
+type CacheConfig struct {
+	Redis redis.Config
+	// EmbeddingTTL and AnswerTTL are how long embeddings and answers are
+	// reused; zero turns that cache off.
+	EmbeddingTTL time.Duration
+	AnswerTTL    time.Duration
+	// MaxEntries bounds the in-process cache used without Redis.
+	MaxEntries int
+}
+
+// sharedState is what instances share through Redis when REDIS_URL is set;
+// without it each instance keeps its own.
+type sharedState struct {
+	cache  domain.Cache
+	locker domain.Locker
+	// counters is nil without Redis; the rate limiter then keeps its own.
+	counters middleware.RateLimitCounters
+	// quotas is nil without Redis; tenant quotas are then counted per instance.
+	quotas tenant.Counters
+	redis  *redis.Client
+}
+
+func newSharedState(ctx context.Context, config *Config) (*sharedState, error) {
+	if config.Cache.Redis.URL == "" {
+		return &sharedState{cache: memory.NewCache(config.Cache.MaxEntries), locker: memory.NewLocker()}, nil
+	}
+	client, err := redis.Open(ctx, config.Cache.Redis)
+	if err != nil {
+		return nil, err
+	}
+	return &sharedState{
+		cache:    redis.NewCache(client),
+		locker:   redis.NewLocker(client),
+		counters: redis.NewRateLimitCounters(client),
+		quotas:   redis.NewQuotaCounters(client),
+		redis:    client,
+	}, nil
+}
+
+func (s *sharedState) rateLimiterOptions() []middleware.RateLimiterOption {
+	if s.counters == nil {
+		return nil
+	}
+	return []middleware.RateLimiterOption{middleware.WithCounters(s.counters)}
+}
+
+func (s *sharedState) tenantOptions() []tenant.Option {
+	if s.quotas == nil {
+		return nil
+	}
+	return []tenant.Option{tenant.WithCounters(s.quotas)}
+}
+
+// flush empties the in-process cache; a Redis cache is left to expire.
+func (s *sharedState) flush() {
+	if c, ok := s.cache.(*memory.Cache); ok {
+		c.Flush()
+	}
+}
+
+func (s *sharedState) Close() {
+	if s.redis != nil {
+		s.redis.Close()
+	}
+}
+
+func (s *sharedState) checks() map[string]domain.HealthChecker {
+	if s.redis == nil {
+		return nil
+	}
+	return map[string]domain.HealthChecker{"redis": s.redis}
+}
+
+var cacheLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+	Name: "cache_lookups_total",
+	Help: "Cache lookups by cache (embeddings, answers) and result (hit, miss, error).",
+}, []string{"cache", "result"})
+
+// meteredCache counts the lookups of one use of the shared cache, whose hit
+// ratio is hits over all lookups.
+type meteredCache struct {
+	domain.Cache
+	name string
+}
+
+func (c meteredCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok, err := c.Cache.Get(ctx, key)
+	result := "miss"
+	switch {
+	case err != nil:
+		result = "error"
+	case ok:
+		result = "hit"
+	}
+	cacheLookups.WithLabelValues(c.name, result).Inc()
+	return value, ok, err
+}
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v2"
+)
+
+// Config values are looked up by their environment variable names in five
+// layers, highest precedence first: -set flags, the environment, the remote
+// config store, the -config file, then the defaults of the ENVIRONMENT's
+// profile. Whatever none of them sets keeps its default. A value that
+// references a secret is replaced with the secret once resolveSecrets ran.
+var (
+	flagValues    map[string]string
+	remoteValues  map[string]string
+	fileValues    map[string]string
+	profileValues map[string]string
+	// configFile is the -config file fileValues came from, re-read on reload.
+	configFile string
+)
+
+// profileDefaults harden an environment beyond the built-in defaults; the
+// config file or environment can still override each of them.
+var profileDefaults = map[string]map[string]string{
+	"production": {
+		"LOG_FORMAT":   "json",
+		"AUTH_ENABLED": "true",
+	},
+}
+
+func lookupEnv(key string) (string, bool) {
+	value, ok := flagValues[key]
+	if !ok {
+		value, ok = os.LookupEnv(key)
+	}
+	if !ok {
+		value, ok = remoteValues[key]
+	}
+	if !ok {
+		value, ok = fileValues[key]
+	}
+	if !ok {
+		value, ok = profileValues[key]
+	}
+	if secretResolver != nil {
+		if secret, resolved := secretResolver.Lookup(value); resolved {
+			return secret, true
+		}
+	}
+	return value, ok
+}
+
+// environmentOf is the ENVIRONMENT that file, as the config file, results in.
+func environmentOf(file map[string]string) string {
+	if value, ok := flagValues["ENVIRONMENT"]; ok {
+		return value
+	}
+	if value, ok := os.LookupEnv("ENVIRONMENT"); ok {
+		return value
+	}
+	if value, ok := remoteValues["ENVIRONMENT"]; ok {
+		return value
+	}
+	if value, ok := file["ENVIRONMENT"]; ok {
+		return value
+	}
+	return "development"
+}
+
+// profileFile is the overlay of the config file path for an environment,
+// e.g. config.production.yaml for config.yaml.
+func profileFile(path, environment string) string {
+	ext := filepath.Ext(path)
+	return strings.TrimSuffix(path, ext) + "." + environment + ext
+}
+
+// loadConfigFiles reads the config file at path and, when there is one, its
+// overlay for the environment, whose values win. It returns the files read.
+func loadConfigFiles(path string) (map[string]string, []string, error) {
+	values, err := loadConfigFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := []string{path}
+	overlay := profileFile(path, environmentOf(values))
+	if _, err := os.Stat(overlay); err == nil {
+		overrides, err := loadConfigFile(overlay)
+		if err != nil {
+			return nil, nil, err
+		}
+		maps.Copy(values, overrides)
+		files = append(files, overlay)
+	}
+	return values, files, nil
+}
+
+// setProfile applies the profile defaults of the configured environment.
+func setProfile() string {
+	environment := environmentOf(fileValues)
+	profileValues = profileDefaults[environment]
+	return environment
+}
+
+// loadConfigFile reads a YAML (.yaml, .yml) or TOML (.toml) config file into
+// values keyed like the environment variables they stand in for. Nested keys
+// are joined with underscores and upper-cased, so log: {level: debug} is
+// LOG_LEVEL, and so is a top-level LOG_LEVEL key. Lists become comma-separated.
+func loadConfigFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]any
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(b, &doc)
+	case ".toml":
+		err = toml.Unmarshal(b, &doc)
+	default:
+		return nil, fmt.Errorf("%s: unsupported config file format, use .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	values := make(map[string]string)
+	if err := flattenConfig("", doc, values); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flattenConfig(prefix string, v any, values map[string]string) error {
+	var m map[string]any
+	switch v := v.(type) {
+	case map[string]any:
+		m = v
+	case map[any]any:
+		m = make(map[string]any, len(v))
+		for k, item := range v {
+			m[fmt.Sprint(k)] = item
+		}
+	default:
+		value, err := configValue(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", prefix, err)
+		}
+		values[prefix] = value
+		return nil
+	}
+	for k, item := range m {
+		key := strings.NewReplacer("-", "_", ".", "_").Replace(strings.ToUpper(k))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+		if err := flattenConfig(key, item, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// configValue renders a leaf the way its environment variable would be
+// written.
+func configValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	case time.Time:
+		return v.Format(time.RFC3339), nil
+	case []any:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := configValue(item)
+			if err != nil {
+				return "", err
+			}
+			if _, ok := item.([]any); ok {
+				return "", fmt.Errorf("nested lists aren't supported")
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]any, map[any]any:
+		return "", fmt.Errorf("tables inside lists aren't supported")
+	}
+	return fmt.Sprint(v), nil
+}
+
+// setFlag collects -set KEY=VALUE flags.
+func setFlag(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok || strings.TrimSpace(key) == "" {
+		return fmt.Errorf("want KEY=VALUE, got %q", s)
+	}
+	if flagValues == nil {
+		flagValues = make(map[string]string)
+	}
+	flagValues[strings.TrimSpace(key)] = value
+	return nil
+}
@@ -0,0 +1,245 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// settingKind is how a setting's value is written.
+type settingKind int
+
+const (
+	kindString settingKind = iota
+	kindDuration
+	kindInt
+	kindFloat
+	kindBool
+	kindList
+	kindFields
+	kindDurationList
+	kindMap
+)
+
+type setting struct {
+	kind         settingKind
+	defaultValue any
+}
+
+// knownSettings collects the settings LoadConfig reads while it runs.
+var knownSettings map[string]setting
+
+// describe records that key is a setting; the first description of a key
+// wins, so getters built on getListEnv keep their own kind.
+func describe(key string, kind settingKind, defaultValue any) {
+	if knownSettings == nil {
+		return
+	}
+	if _, ok := knownSettings[key]; !ok {
+		knownSettings[key] = setting{kind: kind, defaultValue: defaultValue}
+	}
+}
+
+// unknownSettings lists the keys set outside the environment that LoadConfig
+// didn't read, which are most likely misspelled.
+func unknownSettings() []string {
+	var unknown []string
+	for _, layer := range []struct {
+		source string
+		values map[string]string
+	}{
+		{"-set", flagValues},
+		{"remote config", remoteValues},
+		{"config file", fileValues},
+	} {
+		for _, key := range slices.Sorted(maps.Keys(layer.values)) {
+			if _, ok := knownSettings[key]; ok {
+				continue
+			}
+			msg := fmt.Sprintf("%s: unknown setting %s", layer.source, key)
+			if parent := mapSettingOf(key); parent != "" {
+				msg += fmt.Sprintf(" (%s takes comma-separated key=value pairs)", parent)
+			} else if suggestion := closestSetting(key); suggestion != "" {
+				msg += fmt.Sprintf(" (did you mean %s?)", suggestion)
+			}
+			unknown = append(unknown, msg)
+		}
+	}
+	return unknown
+}
+
+// mapSettingOf is the key=value setting that key was probably meant as an
+// entry of, as when a file nests levels: {ingestion: debug} under log.
+func mapSettingOf(key string) string {
+	for name, s := range knownSettings {
+		if s.kind == kindMap && strings.HasPrefix(key, name+"_") {
+			return name
+		}
+	}
+	return ""
+}
+
+// closestSetting is the known setting nearest to key by edit distance, if one
+// is close enough to be a typo of it.
+func closestSetting(key string) string {
+	best, bestDistance := "", max(2, len(key)/5)+1
+	for _, name := range slices.Sorted(maps.Keys(knownSettings)) {
+		if d := editDistance(key, name); d < bestDistance {
+			best, bestDistance = name, d
+		}
+	}
+	return best
+}
+
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+// warnUnknownSettings logs the unknown settings that Validate lets through
+// when CONFIG_STRICT is off.
+func (c *Config) warnUnknownSettings() {
+	if c.App.ConfigStrict {
+		return
+	}
+	for _, msg := range c.unknownSettings {
+		log.Printf("Ignoring %s; CONFIG_STRICT=true makes this an error\n", msg)
+	}
+}
+
+// warnDeprecations logs the deprecated values the config was loaded with.
+func (c *Config) warnDeprecations() {
+	for _, msg := range c.deprecations {
+		log.Printf("Warning: %s\n", msg)
+	}
+}
+
+// runPrintConfigSchema writes a JSON Schema of the config file. Every setting
+// appears under its lowercased variable name, e.g. read_timeout, and under the
+// section its first word names, e.g. log: {level: ...}.
+func runPrintConfigSchema(w io.Writer) error {
+	LoadConfig()
+	properties := make(map[string]any)
+	sections := make(map[string]map[string]any)
+	for key, s := range knownSettings {
+		name := strings.ToLower(key)
+		properties[name] = s.schema()
+		if section, rest, ok := strings.Cut(name, "_"); ok {
+			if sections[section] == nil {
+				sections[section] = make(map[string]any)
+			}
+			sections[section][rest] = s.schema()
+		}
+	}
+	for section, fields := range sections {
+		object := map[string]any{"type": "object", "properties": fields, "additionalProperties": false}
+		if flat, ok := properties[section]; ok {
+			// A setting and a section share the name, e.g. port and port_*.
+			properties[section] = map[string]any{"anyOf": []any{flat, object}}
+			continue
+		}
+		properties[section] = object
+	}
+	schema := map[string]any{
+		"$schema":              "https://json-schema.org/draft/2020-12/schema",
+		"title":                "Sarama config file",
+		"description":          "Keys mirror the environment variables in .env.example. Lists may also be written comma-separated.",
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(schema)
+}
+
+const durationPattern = `^-?([0-9]+(\.[0-9]+)?(ns|us|µs|ms|s|m|h))+$|^0$`
+
+func (s setting) schema() map[string]any {
+	var schema map[string]any
+	switch s.kind {
+	case kindDuration:
+		schema = map[string]any{"type": "string", "pattern": durationPattern}
+	case kindInt:
+		schema = map[string]any{"type": "integer"}
+	case kindFloat:
+		schema = map[string]any{"type": "number"}
+	case kindBool:
+		schema = map[string]any{"type": "boolean"}
+	case kindList, kindFields:
+		schema = map[string]any{"type": []string{"array", "string"}, "items": map[string]any{"type": "string"}}
+	case kindDurationList:
+		schema = map[string]any{"type": []string{"array", "string"}, "items": map[string]any{"type": "string", "pattern": durationPattern}}
+	case kindMap:
+		schema = map[string]any{"type": "string", "description": "Comma-separated key=value pairs"}
+	default:
+		schema = map[string]any{"type": "string"}
+	}
+	if d := schemaDefault(s.defaultValue); d != nil {
+		schema["default"] = d
+	}
+	return schema
+}
+
+// schemaDefault renders a default the way it would be written in the file,
+// leaving out empty ones.
+func schemaDefault(v any) any {
+	switch v := v.(type) {
+	case string:
+		if v != "" {
+			return v
+		}
+	case time.Duration:
+		return v.String()
+	case int, float64, bool:
+		return v
+	case []string:
+		if len(v) > 0 {
+			return v
+		}
+	case []time.Duration:
+		if len(v) > 0 {
+			items := make([]string, len(v))
+			for i, d := range v {
+				items[i] = d.String()
+			}
+			return items
+		}
+	case map[string]float64:
+		return mapDefault(v)
+	case map[string]time.Duration:
+		return mapDefault(v)
+	}
+	return nil
+}
+
+func mapDefault[V any](m map[string]V) any {
+	if len(m) == 0 {
+		return nil
+	}
+	pairs := make([]string, 0, len(m))
+	for _, k := range slices.Sorted(maps.Keys(m)) {
+		pairs = append(pairs, fmt.Sprintf("%s=%v", k, m[k]))
+	}
+	return strings.Join(pairs, ",")
+}
@@ -3,70 +3,581 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"maps"
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
+	"strconv"
 	"syscall"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/auditlog"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/breaker"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/certs"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/confluence"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/kafka"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/remoteconfig"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/slack"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/smtp"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/memory"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/teams"
+	"github.com/shubhamgptln/sarama-ai/interface/api"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+	"github.com/shubhamgptln/sarama-ai/pkg/flags"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+	"github.com/shubhamgptln/sarama-ai/usecase/archive"
+	"github.com/shubhamgptln/sarama-ai/usecase/audit"
+	"github.com/shubhamgptln/sarama-ai/usecase/catalog"
+	"github.com/shubhamgptln/sarama-ai/usecase/content"
+	"github.com/shubhamgptln/sarama-ai/usecase/digest"
+	"github.com/shubhamgptln/sarama-ai/usecase/erasure"
+	"github.com/shubhamgptln/sarama-ai/usecase/gaps"
+	"github.com/shubhamgptln/sarama-ai/usecase/indexversion"
+	"github.com/shubhamgptln/sarama-ai/usecase/ingest"
+	"github.com/shubhamgptln/sarama-ai/usecase/notify"
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+	"github.com/shubhamgptln/sarama-ai/usecase/reindex"
+	"github.com/shubhamgptln/sarama-ai/usecase/related"
+	"github.com/shubhamgptln/sarama-ai/usecase/retention"
 )
 
 type ConfluenceWebhook struct {
 	Event string `json:"event"`
 	Page  struct {
-		ID    int    `json:"id"`
-		Title string `json:"title"`
+		ID       int    `json:"id"`
+		Title    string `json:"title"`
+		SpaceKey string `json:"spaceKey"`
 	} `json:"page"`
 }
 
-func handleConfluenceWebhook(w http.ResponseWriter, r *http.Request) {
+var confluenceActions = map[string]domain.IngestAction{
+	"page_created":  domain.IngestUpsert,
+	"page_updated":  domain.IngestUpsert,
+	"page_restored": domain.IngestUpsert,
+	"page_moved":    domain.IngestUpsert,
+	"page_removed":  domain.IngestDelete,
+	"page_trashed":  domain.IngestDelete,
+}
+
+// toIngestEvent normalizes a Confluence webhook; events we don't index are dropped.
+func (w ConfluenceWebhook) toIngestEvent() (domain.IngestEvent, bool) {
+	action, ok := confluenceActions[w.Event]
+	if !ok || w.Page.ID == 0 {
+		return domain.IngestEvent{}, false
+	}
+	return domain.IngestEvent{
+		ID:         id.New(),
+		Source:     "confluence",
+		Action:     action,
+		DocumentID: strconv.Itoa(w.Page.ID),
+		Title:      w.Page.Title,
+		SpaceKey:   w.Page.SpaceKey,
+		RawType:    w.Event,
+		ReceivedAt: time.Now().UTC(),
+	}, true
+}
+
+var (
+	webhookEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "webhook_events_total",
+		Help: "Change events received by source and result (invalid, ignored, processed, failed).",
+	}, []string{"source", "result"})
+
+	ingestInProgress = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "ingest_events_in_progress",
+		Help: "Ingest events being published or indexed.",
+	})
+)
+
+// webhookHandler publishes normalized events when a publisher is set and
+// indexes them inline when an ingester is set.
+type webhookHandler struct {
+	ingester  indexer
+	publisher domain.IngestPublisher
+	timeout   time.Duration
+	drainer   *middleware.Drainer
+	// archive, when set, keeps every raw payload received.
+	archive *archive.Service
+}
+
+// webhookMaxBody bounds a Confluence webhook payload, which only describes the
+// page that changed.
+const webhookMaxBody = 1 << 20
+
+func (h *webhookHandler) handleConfluenceWebhook(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
 		return
 	}
 
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, webhookMaxBody))
+	if err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
 	var webhook ConfluenceWebhook
-	if err := json.NewDecoder(r.Body).Decode(&webhook); err != nil {
-		http.Error(w, "Invalid payload", http.StatusBadRequest)
+	if err := json.Unmarshal(payload, &webhook); err != nil {
+		webhookEvents.WithLabelValues("confluence", "invalid").Inc()
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
 		return
 	}
+	h.save(payload)
 
 	log.Printf("Confluence event: %s, Page: %s\n", webhook.Event, webhook.Page.Title)
+	if event, ok := webhook.toIngestEvent(); ok {
+		h.dispatch(r.Context(), event)
+	} else {
+		webhookEvents.WithLabelValues("confluence", "ignored").Inc()
+	}
+
 	w.WriteHeader(http.StatusOK)
 	if _, err := fmt.Fprintf(w, "Webhook processed"); err != nil {
 		log.Printf("Error writing response: %v\n", err)
 	}
 }
 
+// save archives a raw payload in the background so it can be inspected or
+// replayed. Only payloads that parse are kept: the endpoint is open to anyone
+// who can reach it.
+func (h *webhookHandler) save(payload []byte) {
+	if !h.archive.Enabled(archive.Webhooks) {
+		return
+	}
+	h.drainer.Go(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), h.timeout)
+		defer cancel()
+		if _, err := h.archive.Save(ctx, archive.Webhooks, "confluence-"+id.New()+".json", payload, "application/json"); err != nil {
+			log.Printf("Archiving webhook payload failed: %v\n", err)
+		}
+	})
+}
+
+// dispatch processes event in the background; shutdown waits for it to
+// finish. The request ending doesn't cancel it, but its trace carries on.
+func (h *webhookHandler) dispatch(ctx context.Context, event domain.IngestEvent) {
+	ctx = context.WithoutCancel(ctx)
+	h.drainer.Go(func() { h.process(ctx, event) })
+}
+
+func (h *webhookHandler) process(ctx context.Context, event domain.IngestEvent) {
+	ingestInProgress.Inc()
+	defer ingestInProgress.Dec()
+	if err := h.handle(ctx, event); err != nil {
+		webhookEvents.WithLabelValues(event.Source, "failed").Inc()
+		log.Printf("Handling ingest event %s failed: %v\n", event.ID, err)
+		return
+	}
+	webhookEvents.WithLabelValues(event.Source, "processed").Inc()
+}
+
+// handle publishes and indexes event within the ingest timeout.
+func (h *webhookHandler) handle(ctx context.Context, event domain.IngestEvent) error {
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+	var errs []error
+	if h.publisher != nil {
+		if err := h.publisher.PublishIngestEvent(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("publish: %w", err))
+		}
+	}
+	if h.ingester != nil {
+		if err := h.ingester.Handle(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("ingest %s %s: %w", event.Action, event.DocumentID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
 func StartServer(port string) {
 	config := LoadConfig()
+	if err := config.Validate(); err != nil {
+		log.Fatalf("%v\n", err)
+	}
+	config.warnUnknownSettings()
+	config.warnDeprecations()
+	appLogger, closeLog, err := newLogger(config)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v\n", err)
+	}
+	defer closeLog()
+	// Flush logs last when exiting on a fatal error.
+	logger.RegisterExitHandler(closeLog)
+	closeTracing := startTracing(config)
+	defer closeTracing()
+	breaker.Configure(config.Breaker)
+
+	flags.Configure(config.App.Environment, config.Flags, flagSubject)
+
+	repos, err := newRepositories(context.Background(), config)
+	if err != nil {
+		fatalf("Failed to initialize storage: %v\n", err)
+	}
+	defer repos.Close()
+	logger.RegisterExitHandler(repos.Close)
+	store, err := newVectorStore(config, repos)
+	if err != nil {
+		fatalf("Failed to initialize vector store: %v\n", err)
+	}
+	shared, err := newSharedState(context.Background(), config)
+	if err != nil {
+		fatalf("Failed to initialize caches: %v\n", err)
+	}
+	defer shared.Close()
+	logger.RegisterExitHandler(shared.Close)
+	blobs, err := newBlobStore(config)
+	if err != nil {
+		fatalf("Failed to initialize blob storage: %v\n", err)
+	}
+	archiver := newArchive(config, blobs)
+	glossaryService, err := newGlossaryService(config)
+	if err != nil {
+		fatalf("Failed to initialize glossary: %v\n", err)
+	}
+	notifier := notify.NewService(memory.NewWebhookRepository(), config.Notify)
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "webhook_delivery_queue_depth",
+		Help: "Outbound webhook deliveries waiting for a worker.",
+	}, func() float64 { return float64(notifier.QueueDepth()) })
+	promauto.NewGaugeFunc(prometheus.GaugeOpts{
+		Name: "webhook_delivery_worker_utilization",
+		Help: "Share of outbound webhook workers sending a delivery.",
+	}, func() float64 {
+		total, busy := notifier.Workers()
+		return float64(busy) / float64(total)
+	})
+	// Every ingest pipeline gets ingestOpts; the one writing the index being
+	// served also records, enriches and notifies with primaryOpts.
+	ingestOpts := []ingest.Option{ingest.WithLogger(appLogger.Named("ingestion"))}
+	primaryOpts := []ingest.Option{
+		ingest.WithEnricher(glossaryService),
+		ingest.WithLedger(repos.ledger),
+		ingest.WithNotifier(notifier),
+	}
+	caches := map[string]func(){}
+	if describer := newDiagramDescriber(config, archiver); describer != nil {
+		ingestOpts = append(ingestOpts, ingest.WithPreprocessor(describer))
+		caches["diagram_descriptions"] = describer.Flush
+	}
+	// With index versions, everything else reads and embeds through the
+	// version being served.
+	versionedStore := store
+	embedder := newEmbedder(config, shared.cache)
+	router := newIndexRouter(config, store, shared.cache)
+	if router != nil {
+		store, embedder = router, router
+	}
+	queryOpts := []query.Option{query.WithGlossary(glossaryService)}
+	graphService := newGraphService(config, store)
+	if graphService != nil {
+		primaryOpts = append(primaryOpts, ingest.WithEnricher(graphService))
+		queryOpts = append(queryOpts, query.WithGraph(graphService))
+	}
+	var ingester indexer
+	var versions *indexversion.Service
+	if router != nil {
+		versions = newIndexVersions(config, router, versionedStore, repos, shared.cache, ingestOpts, primaryOpts)
+		ingester = versions
+	} else {
+		opts := slices.Concat(ingestOpts, primaryOpts, []ingest.Option{ingest.WithUnitOfWork(repos.unitOfWork(store))})
+		ingester = newIngestService(config, store, shared.cache, opts...)
+	}
+	retrievals := memory.NewRetrievalStats()
+	gapTracker := gaps.NewTracker(memory.NewGapRepository(), config.Gaps)
+	queryOpts = append(queryOpts, query.WithGapTracker(gapTracker))
+	queryService, err := newQueryService(config, store, embedder, shared.cache, retrievals, queryOpts...)
+	if err != nil {
+		fatalf("Failed to initialize query service: %v\n", err)
+	}
+	tenants, err := newTenantService(config, shared)
+	if err != nil {
+		fatalf("Failed to load tenants: %v\n", err)
+	}
+	experiments, err := newExperimentManager(config)
+	if err != nil {
+		fatalf("Failed to load experiments: %v\n", err)
+	}
+
+	// Background jobs stop when the server shuts down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	bus, err := newEventBus(jobsCtx, config, versionedStore)
+	if err != nil {
+		fatalf("Failed to initialize event bus: %v\n", err)
+	}
+	defer bus.Close()
+	logger.RegisterExitHandler(func() { bus.Close() })
+	drainer := middleware.NewDrainer("/health", "/live", "/ready", "/metrics")
+	webhooks := &webhookHandler{publisher: bus.publisher, timeout: config.Ingest.Timeout, drainer: drainer, archive: archiver}
+
+	var auditLog domain.AuditLog
+	var auditOpts []audit.Option
+	var erasureOpts []erasure.Option
+	if config.AuditLog.Enabled() {
+		securityLog, err := auditlog.Open(config.AuditLog)
+		if err != nil {
+			fatalf("Failed to open audit log: %v\n", err)
+		}
+		defer securityLog.Close()
+		logger.RegisterExitHandler(func() { securityLog.Close() })
+		auditLog = securityLog
+		auditOpts = append(auditOpts, audit.WithAuditLog(securityLog))
+		erasureOpts = append(erasureOpts, erasure.WithAuditLog(securityLog))
+	}
+	var auditPublisher domain.AuditPublisher
+	if config.Kafka.AuditTopic != "" && len(config.Kafka.Brokers) > 0 {
+		auditProducer, err := kafka.NewAuditProducer(config.Kafka)
+		if err != nil {
+			fatalf("Failed to initialize Kafka audit producer: %v\n", err)
+		}
+		defer auditProducer.Close()
+		logger.RegisterExitHandler(func() { auditProducer.Close() })
+		auditPublisher = auditProducer
+		erasureOpts = append(erasureOpts, erasure.WithRetained(
+			"Kafka topic "+config.Kafka.AuditTopic+": query audit events expire with the topic's retention"))
+	}
+	var auditRecorder *audit.Recorder
+	if auditPublisher != nil || auditLog != nil {
+		auditRecorder, err = audit.NewRecorder(auditPublisher, config.Audit, auditOpts...)
+		if err != nil {
+			fatalf("Failed to initialize audit recorder: %v\n", err)
+		}
+	}
+
+	authService, err := newAuthService(config, repos.apiKeys)
+	if err != nil {
+		fatalf("Failed to initialize authentication: %v\n", err)
+	}
+	limiter := middleware.NewRateLimiter(config.RateLimit, shared.rateLimiterOptions()...)
+	middlewares, err := newMiddleware(config, appLogger.Named("http"), authService, limiter)
+	if err != nil {
+		fatalf("Failed to build HTTP middleware: %v\n", err)
+	}
+	reloader := newConfigReloader(config, appLogger, limiter, queryService)
+
+	auditor := content.NewAuditor(store, retrievals, config.Content)
+	auditor.Start(jobsCtx)
+	if archiver != nil {
+		archiver.Start(jobsCtx)
+	}
+	retainer := retention.NewService(repos.conversations, repos.feedback, config.Retention)
+	retainer.Start(jobsCtx)
+	notifier.Start(jobsCtx)
+
+	switch config.Ingest.Mode {
+	case "inline":
+		webhooks.ingester = ingester
+	case "queue":
+		go bus.consumer.ConsumeIngestEvents(jobsCtx, func(ctx context.Context, event domain.IngestEvent) error {
+			ingestInProgress.Inc()
+			defer ingestInProgress.Dec()
+			ctx, cancel := context.WithTimeout(ctx, config.Ingest.Timeout)
+			defer cancel()
+			return ingester.Handle(ctx, event)
+		})
+	default:
+		fatalf("Unknown ingest mode %q\n", config.Ingest.Mode)
+	}
+
+	ready := newReadiness(config, versionedStore, bus, repos, shared, drainer)
+	caches["readiness"] = ready.flush
+	if shared.redis == nil {
+		caches["embeddings_and_answers"] = shared.flush
+	}
+	accessService := newAccessService(config)
+	if accessService != nil {
+		caches["space_permissions"] = accessService.Flush
+	}
+	connectors := ingestDependencies(config, versionedStore)
+	maps.Copy(connectors, bus.checks)
+	connectors["confluence"] = confluence.NewClient(config.Confluence)
+	var slackClient *slack.Client
+	if config.Chat.Slack.Enabled() {
+		slackClient = slack.NewClient(config.Chat.Slack)
+		connectors["slack"] = slackClient
+	}
+	var teamsClient *teams.Client
+	if config.Chat.Teams.Enabled() {
+		if teamsClient, err = teams.NewClient(config.Chat.Teams); err != nil {
+			fatalf("Failed to initialize Teams bot: %v\n", err)
+		}
+		connectors["teams"] = teamsClient
+	}
+	var digester *digest.Service
+	if config.Digest.Enabled() {
+		mailer := smtp.NewMailer(config.Digest.SMTP)
+		if digester, err = digest.NewService(store, gapTracker, mailer, config.Digest.Config); err != nil {
+			fatalf("Failed to initialize email digests: %v\n", err)
+		}
+		digester.Start(jobsCtx)
+		connectors["smtp"] = mailer
+	}
+	writer, err := newWritebackService(config, store, queryService)
+	if err != nil {
+		fatalf("Failed to initialize Confluence write-back: %v\n", err)
+	}
 
 	mux := http.NewServeMux()
-	mux.HandleFunc("/webhook/confluence", handleConfluenceWebhook)
-	mux.HandleFunc("/health", healthCheck)
+	mux.HandleFunc("/webhook/confluence", webhooks.handleConfluenceWebhook)
+	mux.HandleFunc("/live", liveness)
+	mux.HandleFunc("/health", liveness) // kept for existing probes; prefer /live
+	mux.HandleFunc("/ready", ready.handle)
+	if config.Telemetry.Metrics {
+		mux.Handle("/metrics", promhttp.Handler())
+	}
+	var slo *middleware.SLOTracker
+	if config.Telemetry.SLO.Enabled() {
+		slo = middleware.NewSLOTracker(config.Telemetry.SLO)
+		go slo.Run(jobsCtx, 30*time.Second)
+		prometheus.MustRegister(slo)
+	}
+	handlers := api.NewHandler(api.Services{
+		Query:       queryService,
+		Experiments: experiments,
+		Feedback:    repos.feedback,
+		Related:     related.NewService(store),
+		Auditor:     auditor,
+		Gaps:        gapTracker,
+		Glossary:    glossaryService,
+		Graph:       graphService,
+		Research:    newResearchService(config, queryService),
+		Audit:       auditRecorder,
+		AuditLog:    auditLog,
+		Auth:        authService,
+		RBAC:        newRBACService(config, repos.roleBindings),
+		Access:      accessService,
+		Catalog:     catalog.NewService(store),
+		Reindex:     reindex.NewService(store, webhooks.handle, reindex.WithNotifier(notifier), reindex.WithLocker(shared.locker)),
+		Notify:      notifier,
+		Digest:      digester,
+		Writeback:   writer,
+		Connectors:  connectors,
+		DeadLetters: archiver.DeadLetters(bus.deadLetters),
+		Slack:       slackClient,
+		Teams:       teamsClient,
+		Ingest:      webhooks.dispatch,
+		Logger:      appLogger,
+		Caches:      caches,
+		Config:      func() map[string]any { return maskedConfig(reloader.Current()) },
+
+		ChatSpaceKeys:       config.Chat.SpaceKeys,
+		Background:          drainer.Go,
+		Conversations:       repos.conversations,
+		ConversationHistory: config.Storage.ConversationHistory,
+		AddDocument:         ingester.AddManual,
+		DeleteDocument:      ingester.Delete,
+		Snapshots:           newSnapshots(config, store, blobs),
+		IndexVersions:       versions,
+		Retention:           retainer,
+		Erasure:             erasure.NewService(repos.conversations, repos.feedback, erasureOpts...),
+		Encryption:          encryptedBlobs(blobs),
+		Tenants:             tenants,
+		SLO:                 slo,
+	})
+	handlers.Register(mux)
+
+	// The admin API gets its own listener when configured, so it can be kept
+	// off the public network
+	adminMux := mux
+	if config.Server.AdminPort != "" {
+		adminMux = http.NewServeMux()
+	}
+	handlers.RegisterAdmin(adminMux)
+	if config.Server.DebugEndpoints {
+		handlers.RegisterDebug(adminMux)
+	}
 
-	server := &http.Server{
-		Addr:           ":" + port,
-		Handler:        mux,
-		ReadTimeout:    config.Server.ReadTimeout,
-		WriteTimeout:   config.Server.WriteTimeout,
-		IdleTimeout:    config.Server.IdleTimeout,
-		MaxHeaderBytes: config.Server.MaxHeaderBytes,
+	server := newHTTPServer(config, "main", port)
+	var adminServer *http.Server
+	if adminMux != mux {
+		adminServer = newHTTPServer(config, "admin", config.Server.AdminPort)
+		if config.Server.DebugEndpoints {
+			// net/http/pprof refuses profiles longer than WriteTimeout; the
+			// timeout middleware bounds each request instead.
+			adminServer.WriteTimeout = 0
+		}
+	}
+	wrap := func(h http.Handler) http.Handler { return h }
+
+	// With a config file, SIGHUP and changes to the file reload it; otherwise
+	// SIGHUP only switches the log level
+	reloads := []func(){reloadLogLevel(config, appLogger)}
+	if configFile != "" {
+		reloads = []func(){reloader.Reload}
+		if config.App.ConfigWatchInterval > 0 {
+			go reloader.Watch(jobsCtx, config.App.ConfigWatchInterval)
+		}
+	}
+	if remoteSource != nil && config.Remote.PollInterval > 0 {
+		go remoteconfig.Watch(jobsCtx, remoteSource, config.Remote.PollInterval, remoteRevision, reloader.ReloadRemote, func(err error) {
+			log.Printf("Checking remote config failed: %v\n", err)
+		})
+	}
+	if secretResolver != nil && config.Secrets.RefreshInterval > 0 {
+		go refreshSecrets(jobsCtx, config.Secrets.RefreshInterval, reloader.Reload)
+	}
+	var tlsManager *certs.Manager
+	if config.Server.TLS.ClientCAFile != "" && !config.Server.TLS.Enabled() {
+		fatalf("MTLS_CLIENT_CA_FILE requires TLS to be enabled\n")
+	}
+	if config.Server.TLS.Enabled() {
+		tlsManager, err = certs.NewManager(config.Server.TLS)
+		if err != nil {
+			fatalf("Failed to initialize TLS: %v\n", err)
+		}
+		server.TLSConfig = tlsManager.TLSConfig()
+		if adminServer != nil {
+			adminServer.TLSConfig = tlsManager.TLSConfig()
+		}
+		wrap = middleware.RequireClientCert(tlsManager.VerifyClient)
+		go tlsManager.Watch(jobsCtx)
+		reloads = append(reloads, reloadTLS(tlsManager))
+		if acmeHandler := tlsManager.HTTPHandler(); acmeHandler != nil && config.Server.ACMEHTTPPort != "" {
+			go func() {
+				if err := http.ListenAndServe(":"+config.Server.ACMEHTTPPort, acmeHandler); err != nil {
+					log.Printf("ACME HTTP listener error: %v\n", err)
+				}
+			}()
+		}
+	}
+	go reloadOnHangup(jobsCtx, reloads...)
+	handle := func(mux *http.ServeMux) http.Handler {
+		handler := middleware.Chain(wrap(mux), middlewares...)
+		if config.Telemetry.Metrics {
+			handler = middleware.Metrics(mux)(handler)
+		}
+		if slo != nil {
+			handler = slo.Middleware(mux)(handler)
+		}
+		return drainer.Track(middleware.Tracing(mux)(handler))
+	}
+	server.Handler = handle(mux)
+	if adminServer != nil {
+		adminServer.Handler = handle(adminMux)
 	}
 
 	// Channel to listen for interrupt signals
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-	// Start server in a goroutine
-	go func() {
-		log.Printf("Server listening on port %s\n", port)
-		log.Printf("Environment: %s\n", config.App.Environment)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("Server error: %v\n", err)
-		}
-	}()
+	// Start servers in goroutines
+	log.Printf("Environment: %s\n", config.App.Environment)
+	go serve(server, "Server", tlsManager != nil, config.Server.KeepAlivePeriod)
+	if adminServer != nil {
+		go serve(adminServer, "Admin server", tlsManager != nil, config.Server.KeepAlivePeriod)
+	}
 
 	// Wait for interrupt signal
 	sig := <-sigChan
@@ -77,17 +588,50 @@ func StartServer(port string) {
 	ctx, cancel := context.WithTimeout(context.Background(), config.Server.ShutdownTimeout)
 	defer cancel()
 
-	// Gracefully shutdown the server
-	if err := server.Shutdown(ctx); err != nil {
-		log.Printf("Server shutdown error: %v\n", err)
-		server.Close()
+	// Fail readiness, then let in-flight requests, streams and webhook
+	// processing finish before the listener closes
+	if err := drainer.Drain(ctx, config.Server.DrainDelay); err != nil {
+		log.Printf("Draining stopped with %d tasks in flight: %v\n", drainer.Active(), err)
+	}
+
+	// Gracefully shutdown the servers
+	for _, srv := range []*http.Server{server, adminServer} {
+		if srv == nil {
+			continue
+		}
+		if err := srv.Shutdown(ctx); err != nil {
+			log.Printf("Server shutdown error: %v\n", err)
+			srv.Close()
+		}
 	}
+	stopJobs()
 
 	log.Println("Server shutdown completed")
 }
 
-func healthCheck(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusOK)
-	fmt.Fprintf(w, `{"status":"healthy","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
+// reloadOnHangup runs each reload on SIGHUP.
+func reloadOnHangup(ctx context.Context, reloads ...func()) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			for _, reload := range reloads {
+				reload()
+			}
+		}
+	}
+}
+
+func reloadTLS(tlsManager *certs.Manager) func() {
+	return func() {
+		if err := tlsManager.Reload(); err != nil {
+			log.Printf("Reloading TLS certificate failed: %v\n", err)
+			return
+		}
+		log.Println("TLS certificate reloaded")
+	}
 }
@@ -1 +1,91 @@
 package cmd
+
+import (
+	"fmt"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/confluence"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/llm"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/memory"
+	"github.com/shubhamgptln/sarama-ai/usecase/archive"
+	"github.com/shubhamgptln/sarama-ai/usecase/diagrams"
+	"github.com/shubhamgptln/sarama-ai/usecase/glossary"
+	"github.com/shubhamgptln/sarama-ai/usecase/graph"
+	"github.com/shubhamgptln/sarama-ai/usecase/ingest"
+)
+
+// newEmbedder reuses embeddings from cache for EMBEDDING_CACHE_TTL; cache may
+// be nil.
+func newEmbedder(config *Config, cache domain.Cache) domain.Embedder {
+	return newModelEmbedder(config, cache, config.Embeddings.EmbeddingModel)
+}
+
+// newModelEmbedder embeds with model rather than EMBEDDING_MODEL, e.g. for an
+// index version built with another model.
+func newModelEmbedder(config *Config, cache domain.Cache, model string) domain.Embedder {
+	embeddings := config.Embeddings
+	embeddings.EmbeddingModel = model
+	client := llm.NewClient(embeddings)
+	if cache == nil || config.Cache.EmbeddingTTL <= 0 {
+		return client
+	}
+	return llm.NewCachedEmbedder(client, meteredCache{cache, "embeddings"}, model, config.Cache.EmbeddingTTL)
+}
+
+func newIngestService(config *Config, store domain.VectorStore, cache domain.Cache, opts ...ingest.Option) *ingest.Service {
+	return ingest.NewService(confluence.NewClient(config.Confluence), newEmbedder(config, cache), store, ingestConfig(config, config.Ingest.Config), opts...)
+}
+
+// ingestConfig hands the embedder EMBED_PARALLELISM batches of
+// EMBED_BATCH_SIZE chunks at a time, so the client can send them in parallel.
+func ingestConfig(config *Config, cfg ingest.Config) ingest.Config {
+	cfg.EmbedBatchSize = config.Embeddings.EmbedBatchSize * config.Embeddings.EmbedParallelism
+	return cfg
+}
+
+// newGlossaryService builds glossary extraction for GLOSSARY_MODE: off, rules, llm or both.
+func newGlossaryService(config *Config) (*glossary.Service, error) {
+	var extractors []glossary.Extractor
+	switch config.Ingest.GlossaryMode {
+	case "off":
+	case "rules":
+		extractors = append(extractors, glossary.RuleExtractor{})
+	case "llm":
+		extractors = append(extractors, glossary.NewLLMExtractor(llm.NewClient(config.LLM), ""))
+	case "both":
+		extractors = append(extractors, glossary.RuleExtractor{}, glossary.NewLLMExtractor(llm.NewClient(config.LLM), ""))
+	default:
+		return nil, fmt.Errorf("unknown glossary mode %q", config.Ingest.GlossaryMode)
+	}
+	return glossary.NewService(memory.NewGlossaryRepository(), extractors...), nil
+}
+
+func newGraphService(config *Config, store domain.VectorStore) *graph.Service {
+	if !config.Graph.Enabled {
+		return nil
+	}
+	extractor := graph.NewLLMExtractor(llm.NewClient(config.LLM), "")
+	return graph.NewService(memory.NewGraphRepository(), store, extractor, config.Graph.Config)
+}
+
+// newDiagramDescriber archives the images it downloads when archiver keeps
+// attachments.
+func newDiagramDescriber(config *Config, archiver *archive.Service) *diagrams.Describer {
+	if !config.Diagrams.Enabled {
+		return nil
+	}
+	source := archiver.Images(confluence.NewClient(config.Confluence))
+	return diagrams.NewDescriber(source, llm.NewClient(config.LLM), config.Diagrams.Config)
+}
+
+// ingestDependencies are the backends indexing can't progress without.
+func ingestDependencies(config *Config, store domain.VectorStore) map[string]domain.HealthChecker {
+	checks := map[string]domain.HealthChecker{"llm": llm.NewClient(config.LLM)}
+	if config.Embeddings.BaseURL != config.LLM.BaseURL {
+		checks["embeddings"] = llm.NewClient(config.Embeddings)
+	}
+	if hc, ok := store.(domain.HealthChecker); ok {
+		checks["vector_store"] = hc
+	}
+	return checks
+}
@@ -0,0 +1,182 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/confluence"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/llm"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/memory"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/vectorstore"
+	"github.com/shubhamgptln/sarama-ai/usecase/eval"
+	"github.com/shubhamgptln/sarama-ai/usecase/ingest"
+)
+
+func runEval(args []string) error {
+	fs := flag.NewFlagSet("eval", flag.ExitOnError)
+	dataset := fs.String("dataset", "", "Path to a JSONL file of evaluation cases")
+	out := fs.String("out", "", "Write the full JSON report to this file")
+	topK := fs.Int("top-k", 0, "Chunks to retrieve per question (defaults to RETRIEVAL_TOP_K)")
+	judgeModel := fs.String("judge-model", "", "Model used as the answer judge (defaults to LLM_CHAT_MODEL)")
+	noJudge := fs.Bool("no-judge", false, "Skip LLM-as-judge answer scoring")
+	chunkSizes := fs.String("chunk-sizes", "", "Compare chunk sizes, e.g. 512,1024: re-fetches and re-embeds the store's Confluence pages at each size into memory and evaluates each")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *dataset == "" {
+		return errors.New("--dataset is required")
+	}
+	sizes, err := parseChunkSizes(*chunkSizes)
+	if err != nil {
+		return err
+	}
+
+	config := LoadConfig()
+	cases, err := eval.LoadDataset(*dataset)
+	if err != nil {
+		return fmt.Errorf("load dataset: %w", err)
+	}
+
+	// A memory store starts empty in this process, which would score every
+	// case against no context at all.
+	if backend := config.VectorStore.Backend; backend == "" || backend == "memory" {
+		return errors.New("eval needs a persistent vector store (VECTOR_STORE_BACKEND=qdrant, postgres or sqlite)")
+	}
+
+	repos := &repositories{}
+	if config.VectorStore.Backend == "postgres" || config.VectorStore.Backend == "sqlite" {
+		if repos, err = newRepositories(context.Background(), config); err != nil {
+			return err
+		}
+		defer repos.Close()
+	}
+	store, err := newVectorStore(config, repos)
+	if err != nil {
+		return err
+	}
+
+	var judge *eval.Judge
+	if !*noJudge {
+		judge = eval.NewJudge(llm.NewClient(config.LLM), *judgeModel)
+	}
+	evaluate := func(ctx context.Context, store domain.VectorStore) (*eval.Report, error) {
+		service, err := newQueryService(config, store, newEmbedder(config, nil), nil, memory.NewRetrievalStats())
+		if err != nil {
+			return nil, err
+		}
+		return eval.NewRunner(service, judge, *topK).Run(ctx, cases), nil
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var result interface{ WriteJSON(io.Writer) error }
+	if len(sizes) == 0 {
+		report, err := evaluate(ctx, store)
+		if err != nil {
+			return err
+		}
+		if err := report.WriteText(os.Stdout); err != nil {
+			return err
+		}
+		result = report
+	} else {
+		sweep, err := sweepChunkSizes(ctx, config, store, sizes, evaluate)
+		if err != nil {
+			return err
+		}
+		result = sweep
+	}
+
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := result.WriteJSON(f); err != nil {
+			return fmt.Errorf("write report: %w", err)
+		}
+	}
+	return nil
+}
+
+func parseChunkSizes(s string) ([]int, error) {
+	var sizes []int
+	for _, field := range strings.Split(s, ",") {
+		if field = strings.TrimSpace(field); field == "" {
+			continue
+		}
+		size, err := strconv.Atoi(field)
+		if err != nil || size <= 0 {
+			return nil, fmt.Errorf("--chunk-sizes: %q is not a positive number", field)
+		}
+		sizes = append(sizes, size)
+	}
+	return sizes, nil
+}
+
+// sweepChunkSizes indexes the Confluence pages in store into a memory store
+// per chunk size and evaluates each. The overlap keeps its ratio to
+// CHUNK_SIZE. Documents added through the API can't be fetched again and are
+// left out of every variant.
+func sweepChunkSizes(ctx context.Context, config *Config, store domain.VectorStore, sizes []int, evaluate func(context.Context, domain.VectorStore) (*eval.Report, error)) (*eval.Sweep, error) {
+	var documents []string
+	seen := make(map[string]bool)
+	err := store.ScanChunks(ctx, func(c domain.Chunk) error {
+		if !domain.IsManualDocument(c.DocumentID) && !seen[c.DocumentID] {
+			seen[c.DocumentID] = true
+			documents = append(documents, c.DocumentID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("list documents: %w", err)
+	}
+	if len(documents) == 0 {
+		return nil, errors.New("--chunk-sizes: the vector store has no Confluence pages to re-index")
+	}
+
+	sweep := &eval.Sweep{}
+	for _, size := range sizes {
+		cfg := config.Ingest.Config
+		cfg.ChunkSize = size
+		cfg.ChunkOverlap = config.Ingest.ChunkOverlap * size / max(config.Ingest.ChunkSize, 1)
+		variant := vectorstore.NewMemory()
+		ingester := ingest.NewService(confluence.NewClient(config.Confluence), newEmbedder(config, nil), variant, ingestConfig(config, cfg))
+		failed := 0
+		for _, id := range documents {
+			event := domain.IngestEvent{ID: fmt.Sprintf("eval-%d-%s", size, id), Source: "eval", Action: domain.IngestUpsert, DocumentID: id, ReceivedAt: time.Now()}
+			if err := ingester.Handle(ctx, event); err != nil {
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
+				log.Printf("Indexing document %s at chunk size %d failed: %v\n", id, size, err)
+				failed++
+			}
+		}
+
+		report, err := evaluate(ctx, variant)
+		if err != nil {
+			return nil, err
+		}
+		fmt.Printf("\n== chunk size %d, overlap %d: %d of %d documents indexed ==\n", size, cfg.ChunkOverlap, len(documents)-failed, len(documents))
+		if err := report.WriteText(os.Stdout); err != nil {
+			return nil, err
+		}
+		sweep.Add(fmt.Sprintf("chunk size %d", size), report)
+	}
+	fmt.Println()
+	return sweep, sweep.WriteText(os.Stdout)
+}
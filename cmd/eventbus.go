@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/kafka"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/nats"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/rabbitmq"
+)
+
+// eventBus is the configured ingest event transport. consumer is only set when
+// this instance indexes from the queue (INGEST_MODE=queue).
+type eventBus struct {
+	publisher domain.IngestPublisher
+	consumer  domain.IngestConsumer
+	closers   []io.Closer
+	// checks report whether the bus is connected and keeping up.
+	checks map[string]domain.HealthChecker
+	// deadLetters is nil unless this instance can manage the bus's dead letters;
+	// for Kafka that needs the consumer's admin client.
+	deadLetters domain.DeadLetterQueue
+}
+
+func (b *eventBus) Close() error {
+	var errs []error
+	// Close in reverse creation order: consumers before the producers they forward through.
+	for i := len(b.closers) - 1; i >= 0; i-- {
+		errs = append(errs, b.closers[i].Close())
+	}
+	return errors.Join(errs...)
+}
+
+func eventBusBackend(config *Config) string {
+	if config.EventBus.Backend != "" {
+		return config.EventBus.Backend
+	}
+	if len(config.Kafka.Brokers) > 0 {
+		return "kafka"
+	}
+	return "none"
+}
+
+// newEventBus connects the configured backend. Kafka background monitors run until ctx ends.
+func newEventBus(ctx context.Context, config *Config, store domain.VectorStore) (*eventBus, error) {
+	consume := config.Ingest.Mode == "queue"
+	bus := &eventBus{checks: map[string]domain.HealthChecker{}}
+
+	switch backend := eventBusBackend(config); backend {
+	case "none":
+		if consume {
+			return nil, fmt.Errorf("INGEST_MODE=queue requires an event bus")
+		}
+	case "kafka":
+		if err := kafka.EnsureTopics(config.Kafka, config.Kafka.RequiredTopics(consume)); err != nil {
+			return nil, err
+		}
+		producer, err := kafka.NewProducer(config.Kafka)
+		if err != nil {
+			return nil, err
+		}
+		bus.publisher = producer
+		bus.closers = append(bus.closers, producer)
+		bus.checks["kafka"] = producer
+		if consume {
+			consumer, err := kafka.NewConsumer(config.Kafka, producer)
+			if err != nil {
+				bus.Close()
+				return nil, err
+			}
+			bus.consumer = consumer
+			bus.deadLetters = consumer
+			bus.closers = append(bus.closers, consumer)
+			if config.Readiness.MaxConsumerLag > 0 {
+				bus.checks["consumer_lag"] = consumerLagCheck(consumer, config.Readiness.MaxConsumerLag)
+			}
+			go consumer.MonitorLag(ctx, config.Kafka.LagInterval)
+			go consumer.PauseWhileUnhealthy(ctx, config.Kafka.HealthInterval, ingestDependencies(config, store))
+		}
+	case "nats":
+		natsBus, err := nats.NewBus(ctx, config.EventBus.NATS)
+		if err != nil {
+			return nil, err
+		}
+		bus.publisher = natsBus
+		bus.closers = append(bus.closers, natsBus)
+		bus.checks["nats"] = natsBus
+		bus.deadLetters = natsBus
+		if consume {
+			bus.consumer = natsBus
+		}
+	case "rabbitmq":
+		rabbitBus, err := rabbitmq.NewBus(config.EventBus.RabbitMQ)
+		if err != nil {
+			return nil, err
+		}
+		bus.publisher = rabbitBus
+		bus.closers = append(bus.closers, rabbitBus)
+		bus.checks["rabbitmq"] = rabbitBus
+		bus.deadLetters = rabbitBus
+		if consume {
+			bus.consumer = rabbitBus
+		}
+	default:
+		return nil, fmt.Errorf("unknown event bus %q", backend)
+	}
+	return bus, nil
+}
@@ -0,0 +1,17 @@
+package cmd
+
+import (
+	"context"
+
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+)
+
+// flagSubject is who a percentage rollout is decided for: the authenticated
+// caller, so they see the same behavior on every request, or else the request.
+func flagSubject(ctx context.Context) string {
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		return p.ID
+	}
+	return middleware.RequestIDFromContext(ctx)
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/kafka"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+)
+
+// ReadinessConfig bounds the dependency checks behind /ready. Results are
+// cached for CacheTTL so frequent probes don't hammer the LLM provider.
+type ReadinessConfig struct {
+	CheckTimeout   time.Duration
+	CacheTTL       time.Duration
+	MaxConsumerLag int64
+	MaxInFlight    int
+}
+
+// healthFunc adapts a function to domain.HealthChecker.
+type healthFunc func(ctx context.Context) error
+
+func (f healthFunc) Ping(ctx context.Context) error { return f(ctx) }
+
+// consumerLagCheck fails while the consumer is more than max messages behind.
+func consumerLagCheck(consumer *kafka.Consumer, max int64) domain.HealthChecker {
+	return healthFunc(func(context.Context) error {
+		if lag, ok := consumer.Lag(); ok && lag > max {
+			return fmt.Errorf("consumer lag %d exceeds %d", lag, max)
+		}
+		return nil
+	})
+}
+
+// inFlightCheck fails while more than max requests and background ingest tasks
+// are in flight.
+func inFlightCheck(drainer *middleware.Drainer, max int) domain.HealthChecker {
+	return healthFunc(func(context.Context) error {
+		if active := drainer.Active(); active > max {
+			return fmt.Errorf("%d tasks in flight exceeds %d", active, max)
+		}
+		return nil
+	})
+}
+
+type dependencyStatus struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type readinessResponse struct {
+	Status    string                      `json:"status"`
+	Timestamp string                      `json:"timestamp"`
+	Checks    map[string]dependencyStatus `json:"checks,omitempty"`
+}
+
+// readiness reports whether this instance should receive traffic: it is not
+// draining and every dependency check passes.
+type readiness struct {
+	drainer *middleware.Drainer
+	checks  map[string]domain.HealthChecker
+	cfg     ReadinessConfig
+
+	mu      sync.Mutex
+	checked time.Time
+	results map[string]dependencyStatus
+}
+
+// newReadiness checks the vector store, LLM provider, event bus, database and
+// Redis, plus the in-flight limit when one is configured.
+func newReadiness(config *Config, store domain.VectorStore, bus *eventBus, repos *repositories, shared *sharedState, drainer *middleware.Drainer) *readiness {
+	checks := ingestDependencies(config, store)
+	for name, check := range bus.checks {
+		checks[name] = check
+	}
+	for name, check := range repos.checks() {
+		checks[name] = check
+	}
+	for name, check := range shared.checks() {
+		checks[name] = check
+	}
+	if config.Readiness.MaxInFlight > 0 {
+		checks["in_flight"] = inFlightCheck(drainer, config.Readiness.MaxInFlight)
+	}
+	return &readiness{drainer: drainer, checks: checks, cfg: config.Readiness}
+}
+
+func (rd *readiness) handle(w http.ResponseWriter, r *http.Request) {
+	resp := readinessResponse{Status: "ready", Timestamp: time.Now().Format(time.RFC3339)}
+	status := http.StatusOK
+	if rd.drainer.Draining() {
+		resp.Status = "draining"
+		status = http.StatusServiceUnavailable
+	} else {
+		resp.Checks = rd.run(r.Context())
+		for _, check := range resp.Checks {
+			if check.Status != "ok" {
+				resp.Status = "not_ready"
+				status = http.StatusServiceUnavailable
+			}
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		log.Printf("Error writing response: %v\n", err)
+	}
+}
+
+// run checks every dependency concurrently, reusing results younger than the
+// cache TTL.
+func (rd *readiness) run(ctx context.Context) map[string]dependencyStatus {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	if rd.results != nil && time.Since(rd.checked) < rd.cfg.CacheTTL {
+		return rd.results
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, rd.cfg.CheckTimeout)
+	defer cancel()
+	results := make(map[string]dependencyStatus, len(rd.checks))
+	var (
+		wg  sync.WaitGroup
+		rmu sync.Mutex
+	)
+	for name, check := range rd.checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := check.Ping(ctx)
+			result := dependencyStatus{Status: "ok", LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				result.Status = "fail"
+				result.Error = err.Error()
+			}
+			rmu.Lock()
+			results[name] = result
+			rmu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if failing := failingChecks(results); len(failing) > 0 {
+		log.Printf("Readiness checks failing: %v\n", failing)
+	}
+	rd.results, rd.checked = results, time.Now()
+	return results
+}
+
+// flush drops cached results so the next probe checks again.
+func (rd *readiness) flush() {
+	rd.mu.Lock()
+	defer rd.mu.Unlock()
+	rd.results = nil
+}
+
+func failingChecks(results map[string]dependencyStatus) []string {
+	var failing []string
+	for name, result := range results {
+		if result.Status != "ok" {
+			failing = append(failing, name+": "+result.Error)
+		}
+	}
+	sort.Strings(failing)
+	return failing
+}
+
+// liveness only reports that the process is up and serving; it never checks
+// dependencies, so an outage elsewhere doesn't get the pod restarted.
+func liveness(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintf(w, `{"status":"alive","timestamp":"%s"}`, time.Now().Format(time.RFC3339))
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"slices"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/confluence"
+	"github.com/shubhamgptln/sarama-ai/usecase/indexversion"
+	"github.com/shubhamgptln/sarama-ai/usecase/ingest"
+)
+
+// indexer is what changes to the index go through: the ingest service, or the
+// index versions passing them on to each version kept up to date.
+type indexer interface {
+	Handle(ctx context.Context, event domain.IngestEvent) error
+	AddManual(ctx context.Context, m ingest.Manual) (string, error)
+	Delete(ctx context.Context, documentID string) error
+}
+
+// newIndexRouter returns nil unless store can hold further versions of the
+// index; the database-backed stores can't, as they share tables and
+// transactions with the other repositories.
+func newIndexRouter(config *Config, store domain.VectorStore, cache domain.Cache) *indexversion.Router {
+	if _, ok := store.(domain.IndexVersioner); !ok {
+		return nil
+	}
+	return indexversion.NewRouter(store, newEmbedder(config, cache))
+}
+
+// newIndexVersions builds each version's pipeline like the ingest service's:
+// every version gets the options of opts, and the serving one primaryOpts too.
+func newIndexVersions(config *Config, router *indexversion.Router, store domain.VectorStore, repos *repositories, cache domain.Cache, opts, primaryOpts []ingest.Option) *indexversion.Service {
+	settings := indexversion.Settings{
+		ChunkSize:      config.Ingest.ChunkSize,
+		ChunkOverlap:   config.Ingest.ChunkOverlap,
+		EmbeddingModel: config.Embeddings.EmbeddingModel,
+	}
+	factory := func(store domain.VectorStore, settings indexversion.Settings, primary bool) indexversion.Pipeline {
+		embedder := newModelEmbedder(config, cache, settings.EmbeddingModel)
+		versionOpts := slices.Clone(opts)
+		if primary {
+			versionOpts = append(versionOpts, primaryOpts...)
+			versionOpts = append(versionOpts, ingest.WithUnitOfWork(repos.unitOfWork(store)))
+		}
+		versionConfig := ingestConfig(config, ingest.Config{ChunkSize: settings.ChunkSize, ChunkOverlap: settings.ChunkOverlap})
+		return indexversion.Pipeline{
+			Embedder: embedder,
+			Ingester: ingest.NewService(confluence.NewClient(config.Confluence), embedder, store, versionConfig, versionOpts...),
+		}
+	}
+	return indexversion.NewService(router, store.(domain.IndexVersioner), settings, factory)
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+)
+
+// newLogger builds the application logger from the LOG_* settings and installs
+// it as the process default. The standard library logger is redirected to the
+// same outputs, redacted alike. The returned func flushes and closes them.
+func newLogger(config *Config) (*logger.Logger, func(), error) {
+	level, err := logger.ParseLevel(config.App.LogLevel)
+	if err != nil {
+		return nil, nil, err
+	}
+	componentLevels := make(map[string]logger.Level)
+	for component, name := range config.App.LogLevels {
+		if componentLevels[component], err = logger.ParseLevel(name); err != nil {
+			return nil, nil, fmt.Errorf("LOG_LEVELS %s: %w", component, err)
+		}
+	}
+	overflow, err := logger.ParseOverflow(config.App.LogOverflow)
+	if err != nil {
+		return nil, nil, err
+	}
+	redactor, err := logger.NewRedactor(config.App.LogRedactKeys, config.App.LogRedactPatterns)
+	if err != nil {
+		return nil, nil, err
+	}
+	outputs := config.App.LogOutputs
+	if len(outputs) == 0 {
+		target := "stderr"
+		if config.App.LogFile.Path != "" {
+			target = "file"
+		}
+		outputs = []string{target}
+	}
+
+	var sinks []logger.Sink
+	var writers []io.Writer
+	var asyncs []*logger.AsyncWriter
+	var closers []io.Closer
+	closeLog := func() {
+		log.SetOutput(os.Stderr)
+		for _, c := range closers {
+			c.Close()
+		}
+	}
+	for _, spec := range outputs {
+		sink, closer, err := openLogOutput(config, spec)
+		if err != nil {
+			closeLog()
+			return nil, nil, err
+		}
+		if closer != nil {
+			closers = append(closers, closer)
+		}
+		if config.App.LogAsync {
+			async := logger.NewAsyncWriter(sink.Out, config.App.LogBufferSize, overflow)
+			sink.Out = async
+			asyncs = append(asyncs, async)
+			// Closed first, so buffered lines reach files before they close.
+			closers = append([]io.Closer{async}, closers...)
+		}
+		sinks = append(sinks, sink)
+		writers = append(writers, sink.Out)
+	}
+	if len(asyncs) > 0 {
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Name: "log_lines_dropped_total",
+			Help: "Log lines discarded because the async log buffer was full.",
+		}, func() float64 {
+			var n uint64
+			for _, async := range asyncs {
+				n += async.Dropped()
+			}
+			return float64(n)
+		})
+	}
+	log.SetOutput(redactor.Writer(io.MultiWriter(writers...)))
+
+	opts := []logger.Option{
+		logger.WithSinks(sinks...),
+		logger.WithComponentLevels(componentLevels),
+		logger.WithCaller(config.App.LogCaller),
+		logger.WithDevelopment(config.App.Environment == "development"),
+		logger.WithSampling(config.App.LogSampling),
+		logger.WithRedaction(redactor),
+	}
+	if name := config.App.LogStacktraceLevel; name != "" && name != "off" {
+		stackLevel, err := logger.ParseLevel(name)
+		if err != nil {
+			closeLog()
+			return nil, nil, err
+		}
+		opts = append(opts, logger.WithStacktrace(stackLevel))
+	}
+	if config.App.LogAlert.URL != "" {
+		alertLevel, err := logger.ParseLevel(config.App.LogAlertLevel)
+		if err != nil {
+			closeLog()
+			return nil, nil, err
+		}
+		alerts := logger.NewWebhookHook(config.App.LogAlert)
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Name: "log_alerts_dropped_total",
+			Help: "Log alerts not sent because the alert queue was full.",
+		}, func() float64 { return float64(alerts.Dropped()) })
+		opts = append(opts, logger.WithHook(alertLevel, alerts))
+		// Closed first, so failures sending the last alerts are still logged.
+		closers = append([]io.Closer{alerts}, closers...)
+	}
+	if config.Telemetry.Logs.Enabled() {
+		exporter := logger.NewOTLPExporter(config.Telemetry.Logs)
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Name: "log_records_export_dropped_total",
+			Help: "Log records not exported over OTLP because the export queue was full.",
+		}, func() float64 { return float64(exporter.Dropped()) })
+		opts = append(opts, logger.WithHook(logger.LevelDebug, exporter))
+		closers = append([]io.Closer{exporter}, closers...)
+	}
+	l := logger.New(nil, level, opts...)
+	if config.App.LogSampling.Enabled() {
+		promauto.NewCounterFunc(prometheus.CounterOpts{
+			Name: "log_lines_sampled_out_total",
+			Help: "Log lines dropped by sampling repeated messages.",
+		}, func() float64 { return float64(l.SampledOut()) })
+	}
+	logger.SetDefault(l)
+	return l, closeLog, nil
+}
+
+// openLogOutput opens a LOG_OUTPUTS entry, target[:format[:level]], where
+// target is stdout, stderr, file (LOG_FILE), syslog or journald. The format defaults to
+// LOG_FORMAT, or when that is empty to console for a terminal in development
+// and text otherwise. The level defaults to everything LOG_LEVEL lets through.
+func openLogOutput(config *Config, spec string) (logger.Sink, io.Closer, error) {
+	target, rest, _ := strings.Cut(strings.TrimSpace(spec), ":")
+	formatName, levelName, _ := strings.Cut(rest, ":")
+	sink := logger.Sink{Level: logger.LevelDebug}
+	if levelName != "" {
+		var err error
+		if sink.Level, err = logger.ParseLevel(levelName); err != nil {
+			return logger.Sink{}, nil, err
+		}
+	}
+	var closer io.Closer
+	terminal := false
+	switch target {
+	case "stdout", "stderr":
+		f := os.Stdout
+		if target == "stderr" {
+			f = os.Stderr
+		}
+		sink.Out, terminal = f, logger.IsTerminal(f)
+	case "file":
+		file, err := logger.OpenRotatingFile(config.App.LogFile)
+		if err != nil {
+			return logger.Sink{}, nil, err
+		}
+		sink.Out, closer = file, file
+	case "syslog":
+		w, err := logger.DialSyslog(config.App.LogSyslog)
+		if err != nil {
+			return logger.Sink{}, nil, err
+		}
+		sink.Out, closer = w, w
+	case "journald":
+		w, err := logger.DialJournald("", config.App.LogSyslog.Tag)
+		if err != nil {
+			return logger.Sink{}, nil, err
+		}
+		sink.Out, closer = w, w
+	default:
+		return logger.Sink{}, nil, fmt.Errorf("unknown log output %q", target)
+	}
+
+	if formatName == "" {
+		formatName = config.App.LogFormat
+	}
+	if formatName == "" {
+		formatName = string(logger.FormatText)
+		if terminal && config.App.Environment == "development" {
+			formatName = string(logger.FormatConsole)
+		}
+	}
+	format, err := logger.ParseFormat(formatName)
+	if err != nil {
+		if closer != nil {
+			closer.Close()
+		}
+		return logger.Sink{}, nil, err
+	}
+	sink.Encoder = logger.NewEncoder(format)
+	if format == logger.FormatConsole {
+		// Colors only help on a terminal; NO_COLOR turns them off there too.
+		sink.Encoder = logger.ConsoleEncoder{NoColor: !terminal || config.App.LogNoColor}
+	}
+	return sink, closer, nil
+}
+
+// fatalf logs like log.Fatalf but exits through logger.Exit, so the exit
+// handlers flush logs and close connections first.
+func fatalf(format string, args ...any) {
+	log.Printf(format, args...)
+	logger.Exit(1)
+}
+
+// reloadLogLevel returns the SIGHUP action for the log level: it reads the
+// level from LOG_LEVEL_FILE when set, e.g. a mounted ConfigMap, and otherwise
+// toggles between debug and LOG_LEVEL.
+func reloadLogLevel(config *Config, l *logger.Logger) func() {
+	return func() {
+		configured, _ := logger.ParseLevel(config.App.LogLevel)
+		level := logger.LevelDebug
+		if l.Level() == logger.LevelDebug {
+			level = configured
+		}
+		if path := config.App.LogLevelFile; path != "" {
+			b, err := os.ReadFile(path)
+			if err != nil {
+				log.Printf("Reading log level failed: %v\n", err)
+				return
+			}
+			if level, err = logger.ParseLevel(string(b)); err != nil {
+				log.Printf("Reading log level from %s failed: %v\n", path, err)
+				return
+			}
+		}
+		log.Printf("Log level changed from %s to %s\n", l.Level(), level)
+		l.SetLevel(level)
+	}
+}
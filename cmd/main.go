@@ -3,12 +3,61 @@ package cmd
 import (
 	"flag"
 	"log"
+	"os"
+	"strings"
 )
 
 func Main() {
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		if err := runEval(os.Args[2:]); err != nil {
+			log.Fatalf("Evaluation failed: %v\n", err)
+		}
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "audit-verify" {
+		if err := runAuditVerify(os.Args[2:]); err != nil {
+			log.Fatalf("Audit log verification failed: %v\n", err)
+		}
+		return
+	}
+
 	port := flag.String("port", "8080", "Server port")
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "YAML or TOML config file; environment variables override it (defaults to CONFIG_FILE)")
+	printConfig := flag.Bool("print-config", false, "Print the effective configuration, secrets masked, and exit")
+	printSchema := flag.Bool("print-config-schema", false, "Print a JSON Schema of the config file and exit")
+	flag.Func("set", "Set a config value, KEY=VALUE, overriding the environment and config file; repeatable", setFlag)
 	flag.Parse()
 
+	if *configPath != "" {
+		values, files, err := loadConfigFiles(*configPath)
+		if err != nil {
+			log.Fatalf("Failed to read config file: %v\n", err)
+		}
+		fileValues = values
+		configFile = *configPath
+		log.Printf("Loaded %d settings from %s\n", len(values), strings.Join(files, ", "))
+	}
+	if err := loadRemoteConfig(); err != nil {
+		log.Fatalf("Failed to load remote config: %v\n", err)
+	}
+	if environment := setProfile(); profileValues != nil {
+		log.Printf("Using the %s profile defaults\n", environment)
+	}
+	if err := resolveSecrets(); err != nil {
+		log.Fatalf("Failed to resolve secrets: %v\n", err)
+	}
+	if *printSchema {
+		if err := runPrintConfigSchema(os.Stdout); err != nil {
+			log.Fatalf("%v\n", err)
+		}
+		return
+	}
+	if *printConfig {
+		if err := runPrintConfig(os.Stdout); err != nil {
+			log.Fatalf("%v\n", err)
+		}
+		return
+	}
 	log.Println("Sarama AI Server starting...")
 	StartServer(*port)
 }
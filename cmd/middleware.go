@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+)
+
+// newMiddleware builds the HTTP middleware chain in the order configured by
+// HTTP_MIDDLEWARE. Middleware that is disabled by its own config is skipped,
+// except the rate limit, which a config reload can turn on.
+func newMiddleware(config *Config, l *logger.Logger, authService *auth.Service, limiter *middleware.RateLimiter) ([]middleware.Middleware, error) {
+	return middleware.Build(config.Server.Middleware, map[string]middleware.Middleware{
+		"recovery":    middleware.Recover(l),
+		"request_id":  middleware.RequestID(),
+		"logging":     middleware.Logging(l, config.Server.Logging),
+		"cors":        middleware.CORS(config.Server.CORS),
+		"compression": middleware.Compress(config.Server.Compress),
+		"auth":        middleware.Authenticate(authService),
+		"rate_limit":  limiter.Middleware(),
+		"timeout":     middleware.Timeout(config.Server.Timeouts),
+	})
+}
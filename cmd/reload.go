@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+	"github.com/shubhamgptln/sarama-ai/pkg/flags"
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+)
+
+// configReloader loads the config again, picking up edits to the -config file,
+// remote config changes and refreshed secrets, and applies the settings that
+// can change while serving: log levels, the rate limit, the retrieval top-k,
+// the answer model and feature flags. Other changes are logged as needing a
+// restart and left alone.
+type configReloader struct {
+	mu      sync.Mutex
+	current *Config
+	logger  *logger.Logger
+	limiter *middleware.RateLimiter
+	queries *query.Service
+}
+
+func newConfigReloader(config *Config, l *logger.Logger, limiter *middleware.RateLimiter, queries *query.Service) *configReloader {
+	return &configReloader{current: config, logger: l, limiter: limiter, queries: queries}
+}
+
+// Current is the config in effect, including what reloads applied.
+func (r *configReloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// ReloadRemote takes new settings from the remote config store and reloads.
+func (r *configReloader) ReloadRemote(values map[string]string) {
+	r.mu.Lock()
+	remoteValues = values
+	r.mu.Unlock()
+	r.Reload()
+}
+
+func (r *configReloader) Reload() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if configFile != "" {
+		values, _, err := loadConfigFiles(configFile)
+		if err != nil {
+			log.Printf("Config reload failed: %v\n", err)
+			return
+		}
+		fileValues = values
+	}
+	setProfile()
+	next := LoadConfig()
+	if err := next.Validate(); err != nil {
+		log.Printf("Config reload rejected: %v\n", err)
+		return
+	}
+	next.warnUnknownSettings()
+
+	cur := r.current
+	if restart := restartRequired(cur, next); len(restart) > 0 {
+		log.Printf("Config reload: changes to %s need a restart and were not applied\n", strings.Join(restart, ", "))
+	}
+	applied := *cur
+	if next.App.LogLevel != cur.App.LogLevel {
+		level, _ := logger.ParseLevel(next.App.LogLevel)
+		r.logger.SetLevel(level)
+		log.Printf("Config reload: LOG_LEVEL changed from %s to %s\n", cur.App.LogLevel, next.App.LogLevel)
+	}
+	if !reflect.DeepEqual(next.App.LogLevels, cur.App.LogLevels) {
+		for component := range cur.App.LogLevels {
+			if _, ok := next.App.LogLevels[component]; !ok {
+				r.logger.ResetComponentLevel(component)
+			}
+		}
+		for component, name := range next.App.LogLevels {
+			level, _ := logger.ParseLevel(name)
+			r.logger.SetComponentLevel(component, level)
+		}
+		log.Printf("Config reload: LOG_LEVELS changed to %v\n", next.App.LogLevels)
+	}
+	if next.RateLimit != cur.RateLimit {
+		r.limiter.SetConfig(next.RateLimit)
+		log.Printf("Config reload: rate limit changed to %g requests/s, burst %d\n", next.RateLimit.Rate, next.RateLimit.Burst)
+	}
+	if next.Query.TopK != cur.Query.TopK {
+		r.queries.SetTopK(next.Query.TopK)
+		log.Printf("Config reload: RETRIEVAL_TOP_K changed from %d to %d\n", cur.Query.TopK, next.Query.TopK)
+	}
+	if next.LLM.ChatModel != cur.LLM.ChatModel {
+		r.queries.SetModel(next.LLM.ChatModel)
+		log.Printf("Config reload: LLM_CHAT_MODEL changed from %s to %s for answers; research, write-back and extraction keep their startup model until restart\n", cur.LLM.ChatModel, next.LLM.ChatModel)
+	}
+	if !reflect.DeepEqual(next.Flags, cur.Flags) {
+		flags.Configure(cur.App.Environment, next.Flags, flagSubject)
+		log.Printf("Config reload: FEATURE_FLAGS changed to %v\n", next.Flags)
+	}
+	applied.App.LogLevel = next.App.LogLevel
+	applied.App.LogLevels = next.App.LogLevels
+	applied.RateLimit = next.RateLimit
+	applied.Query.TopK = next.Query.TopK
+	applied.LLM.ChatModel = next.LLM.ChatModel
+	applied.Flags = next.Flags
+	r.current = &applied
+}
+
+// Watch reloads whenever the config file or its environment overlay is
+// created, modified or removed, checking every interval.
+func (r *configReloader) Watch(ctx context.Context, interval time.Duration) {
+	last := r.filesState()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if state := r.filesState(); state != last {
+				last = state
+				r.Reload()
+			}
+		}
+	}
+}
+
+// filesState sums up the modification times and sizes of the config files.
+func (r *configReloader) filesState() string {
+	var state strings.Builder
+	for _, path := range []string{configFile, profileFile(configFile, r.Current().App.Environment)} {
+		if info, err := os.Stat(path); err == nil {
+			fmt.Fprintf(&state, "%s %d %d;", path, info.ModTime().UnixNano(), info.Size())
+		}
+	}
+	return state.String()
+}
+
+// restartRequired lists the settings that differ between cur and next other
+// than the ones a reload applies.
+func restartRequired(cur, next *Config) []string {
+	a, b := *cur, *next
+	b.App.LogLevel, b.App.LogLevels = a.App.LogLevel, a.App.LogLevels
+	b.RateLimit = a.RateLimit
+	b.Query.TopK = a.Query.TopK
+	b.LLM.ChatModel = a.LLM.ChatModel
+	b.Flags = a.Flags
+	var changed []string
+	diffConfig("", reflect.ValueOf(a), reflect.ValueOf(b), &changed)
+	slices.Sort(changed)
+	return changed
+}
+
+// diffConfig appends the paths of the fields that differ, descending into
+// nested config structs so the report names e.g. Server.ReadTimeout.
+func diffConfig(path string, a, b reflect.Value, changed *[]string) {
+	if reflect.DeepEqual(a.Interface(), b.Interface()) {
+		return
+	}
+	if a.Kind() != reflect.Struct {
+		*changed = append(*changed, path)
+		return
+	}
+	n := len(*changed)
+	for i := 0; i < a.NumField(); i++ {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := path
+		if !field.Anonymous {
+			name = strings.TrimPrefix(path+"."+field.Name, ".")
+		}
+		diffConfig(name, a.Field(i), b.Field(i), changed)
+	}
+	if len(*changed) == n {
+		// Only unexported state differs, e.g. inside a time.Time.
+		*changed = append(*changed, path)
+	}
+}
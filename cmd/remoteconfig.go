@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/infrastructure/remoteconfig"
+)
+
+// remoteSource is the store remoteValues were loaded from at remoteRevision;
+// StartServer keeps watching it.
+var (
+	remoteSource   remoteconfig.Source
+	remoteRevision uint64
+)
+
+func loadRemoteConfigSettings() remoteconfig.Config {
+	return remoteconfig.Config{
+		Backend:      getEnv("REMOTE_CONFIG_BACKEND", ""),
+		Address:      getEnv("REMOTE_CONFIG_ADDRESS", ""),
+		Prefix:       getEnv("REMOTE_CONFIG_PREFIX", "sarama/"),
+		Token:        getEnv("REMOTE_CONFIG_TOKEN", ""),
+		Username:     getEnv("REMOTE_CONFIG_USERNAME", ""),
+		Password:     getEnv("REMOTE_CONFIG_PASSWORD", ""),
+		PollInterval: getDurationEnv("REMOTE_CONFIG_POLL_INTERVAL", 30*time.Second),
+		Timeout:      getDurationEnv("REMOTE_CONFIG_TIMEOUT", 10*time.Second),
+	}
+}
+
+// loadRemoteConfig reads the settings from etcd or Consul when
+// REMOTE_CONFIG_BACKEND is set.
+func loadRemoteConfig() error {
+	cfg := loadRemoteConfigSettings()
+	if cfg.Backend == "" {
+		return nil
+	}
+	src, err := remoteconfig.New(cfg)
+	if err != nil {
+		return err
+	}
+	values, revision, err := src.Load(context.Background())
+	if err != nil {
+		return err
+	}
+	remoteValues, remoteSource, remoteRevision = values, src, revision
+	log.Printf("Loaded %d settings from %s under %s\n", len(values), cfg.Backend, cfg.Prefix)
+	return nil
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/infrastructure/secrets"
+)
+
+// secretResolver holds the secrets config values reference, e.g.
+// LLM_API_KEY=vault://secret/sarama#llm_api_key; lookupEnv returns the secret
+// in place of the reference.
+var secretResolver *secrets.Resolver
+
+func loadSecretsConfig() secrets.Config {
+	return secrets.Config{
+		Vault: secrets.VaultConfig{
+			Address:   getEnv("VAULT_ADDR", ""),
+			Token:     getEnv("VAULT_TOKEN", ""),
+			Namespace: getEnv("VAULT_NAMESPACE", ""),
+		},
+		AWS: secrets.AWSConfig{
+			Region:          getEnv("AWS_REGION", ""),
+			AccessKeyID:     getEnv("AWS_ACCESS_KEY_ID", ""),
+			SecretAccessKey: getEnv("AWS_SECRET_ACCESS_KEY", ""),
+			SessionToken:    getEnv("AWS_SESSION_TOKEN", ""),
+			Endpoint:        getEnv("AWS_SECRETS_MANAGER_ENDPOINT", ""),
+		},
+		GCP: secrets.GCPConfig{
+			AccessToken: getEnv("GCP_ACCESS_TOKEN", ""),
+			Endpoint:    getEnv("GCP_SECRET_MANAGER_ENDPOINT", ""),
+		},
+		RefreshInterval: getDurationEnv("SECRETS_REFRESH_INTERVAL", 5*time.Minute),
+		Timeout:         getDurationEnv("SECRETS_TIMEOUT", 10*time.Second),
+	}
+}
+
+// resolveSecrets fetches every secret reference among the config values that
+// take effect, so LoadConfig sees the secrets themselves.
+func resolveSecrets() error {
+	keys := make(map[string]bool)
+	for key := range flagValues {
+		keys[key] = true
+	}
+	for _, kv := range os.Environ() {
+		key, _, _ := strings.Cut(kv, "=")
+		keys[key] = true
+	}
+	for key := range fileValues {
+		keys[key] = true
+	}
+	var refs []string
+	for key := range keys {
+		if value, _ := lookupEnv(key); secrets.IsReference(value) {
+			refs = append(refs, value)
+		}
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+
+	resolver := secrets.NewResolver(loadSecretsConfig())
+	if err := resolver.Resolve(context.Background(), refs); err != nil {
+		return err
+	}
+	secretResolver = resolver
+	log.Printf("Resolved %d secrets\n", resolver.Len())
+	return nil
+}
+
+// refreshSecrets fetches the secrets again every interval and runs reload
+// when one of them changed.
+func refreshSecrets(ctx context.Context, interval time.Duration, reload func()) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			changed, err := secretResolver.Refresh(ctx)
+			if err != nil {
+				log.Printf("Refreshing secrets failed: %v\n", err)
+			}
+			if changed {
+				log.Println("Secrets changed, reloading config")
+				reload()
+			}
+		}
+	}
+}
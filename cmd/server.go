@@ -0,0 +1,105 @@
+package cmd
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	connections = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "http_connections",
+		Help: "Open client connections by state (new, active, idle).",
+	}, []string{"server", "state"})
+
+	connectionsAccepted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_connections_accepted_total",
+		Help: "Client connections accepted.",
+	}, []string{"server"})
+)
+
+// HTTP2Config enables HTTP/2 over TLS and, with Cleartext, unencrypted HTTP/2
+// (h2c) for deployments behind a proxy that speaks HTTP/2 to the backend.
+// HTTP/2 multiplexes SSE streams over one connection instead of holding one
+// connection per stream.
+type HTTP2Config struct {
+	Enabled   bool
+	Cleartext bool
+	// MaxConcurrentStreams caps streams per connection; zero uses Go's default of 250.
+	MaxConcurrentStreams int
+	// PingInterval pings idle connections to detect dead peers; zero disables pings.
+	PingInterval time.Duration
+	PingTimeout  time.Duration
+}
+
+func newHTTPServer(config *Config, name, port string) *http.Server {
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetHTTP2(config.Server.HTTP2.Enabled)
+	protocols.SetUnencryptedHTTP2(config.Server.HTTP2.Enabled && config.Server.HTTP2.Cleartext)
+
+	server := &http.Server{
+		Addr:           ":" + port,
+		ReadTimeout:    config.Server.ReadTimeout,
+		WriteTimeout:   config.Server.WriteTimeout,
+		IdleTimeout:    config.Server.IdleTimeout,
+		MaxHeaderBytes: config.Server.MaxHeaderBytes,
+		Protocols:      protocols,
+		HTTP2: &http.HTTP2Config{
+			MaxConcurrentStreams: config.Server.HTTP2.MaxConcurrentStreams,
+			SendPingTimeout:      config.Server.HTTP2.PingInterval,
+			PingTimeout:          config.Server.HTTP2.PingTimeout,
+		},
+		ConnState: trackConnections(name),
+	}
+	server.SetKeepAlivesEnabled(config.Server.KeepAlive)
+	return server
+}
+
+// trackConnections keeps the connection gauges current.
+func trackConnections(name string) func(net.Conn, http.ConnState) {
+	var (
+		mu     sync.Mutex
+		states = map[net.Conn]http.ConnState{}
+	)
+	return func(conn net.Conn, state http.ConnState) {
+		mu.Lock()
+		defer mu.Unlock()
+		if prev, ok := states[conn]; ok {
+			connections.WithLabelValues(name, prev.String()).Dec()
+		}
+		switch state {
+		case http.StateHijacked, http.StateClosed:
+			delete(states, conn)
+			return
+		case http.StateNew:
+			connectionsAccepted.WithLabelValues(name).Inc()
+		}
+		states[conn] = state
+		connections.WithLabelValues(name, state.String()).Inc()
+	}
+}
+
+// serve runs server until it is shut down; any other failure is fatal.
+// keepAlivePeriod sets the TCP keep-alive probe interval of accepted connections.
+func serve(server *http.Server, name string, tls bool, keepAlivePeriod time.Duration) {
+	ln, err := (&net.ListenConfig{KeepAlive: keepAlivePeriod}).Listen(context.Background(), "tcp", server.Addr)
+	if err != nil {
+		fatalf("%s error: %v\n", name, err)
+	}
+	log.Printf("%s listening on %s (TLS: %t, protocols: %v)\n", name, server.Addr, tls, server.Protocols)
+	if tls {
+		err = server.ServeTLS(ln, "", "")
+	} else {
+		err = server.Serve(ln)
+	}
+	if err != nil && err != http.ErrServerClosed {
+		fatalf("%s error: %v\n", name, err)
+	}
+}
@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"log"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/memory"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/postgres"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/sqlite"
+)
+
+// StorageConfig picks where state that should outlive a restart is kept.
+type StorageConfig struct {
+	// Backend is memory, postgres or sqlite.
+	Backend  string
+	Postgres postgres.Config
+	// Migrate applies pending database migrations at startup.
+	Migrate bool
+	// ConversationHistory is how many earlier messages of a session a question
+	// sent without history is answered with; zero doesn't keep conversations.
+	ConversationHistory int
+	SQLite              sqlite.Config
+}
+
+// repositories hold API keys, role bindings, feedback, ingest sync state and
+// conversations in the STORAGE_BACKEND.
+type repositories struct {
+	apiKeys       domain.APIKeyRepository
+	roleBindings  domain.RoleBindingRepository
+	feedback      domain.FeedbackRepository
+	ledger        domain.IngestLedger
+	conversations domain.ConversationRepository
+	// db is the PostgreSQL database and sqlite the SQLite one, each nil
+	// unless it is the backend.
+	db     *postgres.DB
+	sqlite *sqlite.DB
+}
+
+func newRepositories(ctx context.Context, config *Config) (*repositories, error) {
+	switch config.Storage.Backend {
+	case "postgres":
+		db, err := openDatabase(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return &repositories{
+			apiKeys:       postgres.NewAPIKeyRepository(db),
+			roleBindings:  postgres.NewRoleBindingRepository(db),
+			feedback:      postgres.NewFeedbackRepository(db),
+			ledger:        postgres.NewIngestLedger(db),
+			conversations: postgres.NewConversationRepository(db),
+			db:            db,
+		}, nil
+	case "sqlite":
+		db, err := openSQLite(ctx, config)
+		if err != nil {
+			return nil, err
+		}
+		return &repositories{
+			apiKeys:       sqlite.NewAPIKeyRepository(db),
+			roleBindings:  sqlite.NewRoleBindingRepository(db),
+			feedback:      sqlite.NewFeedbackRepository(db),
+			ledger:        sqlite.NewIngestLedger(db),
+			conversations: sqlite.NewConversationRepository(db),
+			sqlite:        db,
+		}, nil
+	}
+	return &repositories{
+		apiKeys:       memory.NewAPIKeyRepository(),
+		roleBindings:  memory.NewRoleBindingRepository(),
+		feedback:      memory.NewFeedbackRepository(),
+		ledger:        memory.NewIngestLedger(),
+		conversations: memory.NewConversationRepository(),
+	}, nil
+}
+
+// openDatabase connects to PostgreSQL, applying pending migrations unless
+// DATABASE_MIGRATE is off.
+func openDatabase(ctx context.Context, config *Config) (*postgres.DB, error) {
+	db, err := postgres.Open(ctx, config.Storage.Postgres)
+	if err != nil {
+		return nil, err
+	}
+	if config.Storage.Migrate {
+		applied, err := db.Migrate(ctx)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if len(applied) > 0 {
+			log.Printf("Applied database migrations %v\n", applied)
+		}
+	}
+	return db, nil
+}
+
+// openSQLite opens the SQLite file, applying pending migrations unless
+// DATABASE_MIGRATE is off.
+func openSQLite(ctx context.Context, config *Config) (*sqlite.DB, error) {
+	db, err := sqlite.Open(ctx, config.Storage.SQLite)
+	if err != nil {
+		return nil, err
+	}
+	if config.Storage.Migrate {
+		applied, err := db.Migrate(ctx)
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if len(applied) > 0 {
+			log.Printf("Applied database migrations %v\n", applied)
+		}
+	}
+	return db, nil
+}
+
+// unitOfWork groups writes in one database transaction when the vector store
+// is kept in the STORAGE_BACKEND database, and otherwise writes through store
+// and the repositories directly.
+func (r *repositories) unitOfWork(store domain.VectorStore) domain.UnitOfWork {
+	switch store.(type) {
+	case *postgres.VectorStore:
+		return r.db
+	case *sqlite.VectorStore:
+		return r.sqlite
+	}
+	return memory.NewUnitOfWork(domain.Stores{
+		Chunks:        store,
+		Ledger:        r.ledger,
+		APIKeys:       r.apiKeys,
+		Feedback:      r.feedback,
+		Conversations: r.conversations,
+	})
+}
+
+func (r *repositories) Close() {
+	if r.db != nil {
+		r.db.Close()
+	}
+	if r.sqlite != nil {
+		r.sqlite.Close()
+	}
+}
+
+// checks are the readiness checks of the storage backend.
+func (r *repositories) checks() map[string]domain.HealthChecker {
+	switch {
+	case r.db != nil:
+		return map[string]domain.HealthChecker{"database": r.db}
+	case r.sqlite != nil:
+		return map[string]domain.HealthChecker{"database": r.sqlite}
+	}
+	return nil
+}
@@ -0,0 +1,26 @@
+package cmd
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+)
+
+// startTracing records spans and exports them over OTLP when a traces
+// endpoint is configured. The returned func exports the spans still queued.
+func startTracing(config *Config) func() {
+	if !config.Telemetry.Traces.Enabled() {
+		return func() {}
+	}
+	exporter := tracing.NewOTLPExporter(config.Telemetry.Traces)
+	promauto.NewCounterFunc(prometheus.CounterOpts{
+		Name: "trace_spans_export_dropped_total",
+		Help: "Spans not exported over OTLP because the export queue was full.",
+	}, func() float64 { return float64(exporter.Dropped()) })
+	tracing.SetDefault(tracing.NewTracer(exporter, config.Telemetry.Traces.SampleRatio))
+	return func() {
+		tracing.SetDefault(nil)
+		exporter.Close()
+	}
+}
@@ -0,0 +1,469 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/infrastructure/breaker"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/kafka"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/llm"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/remoteconfig"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+	"github.com/shubhamgptln/sarama-ai/usecase/audit"
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+	"github.com/shubhamgptln/sarama-ai/usecase/retention"
+	"github.com/shubhamgptln/sarama-ai/usecase/writeback"
+)
+
+// configProblems collects what's wrong with a config, each problem naming the
+// variable to fix.
+type configProblems []string
+
+func (p *configProblems) addf(format string, args ...any) {
+	*p = append(*p, fmt.Sprintf(format, args...))
+}
+
+func (p *configProblems) oneOf(key, value string, allowed ...string) {
+	if !slices.Contains(allowed, value) {
+		p.addf("%s: %q is not one of %s", key, value, strings.Join(allowed, ", "))
+	}
+}
+
+func (p *configProblems) positive(key string, d time.Duration) {
+	if d <= 0 {
+		p.addf("%s: must be positive, got %s", key, d)
+	}
+}
+
+func (p *configProblems) level(key, name string) {
+	if _, err := logger.ParseLevel(name); err != nil {
+		p.addf("%s: %v", key, err)
+	}
+}
+
+func (p *configProblems) between(key string, v, min, max float64) {
+	if v < min || v > max {
+		p.addf("%s: must be between %g and %g, got %g", key, min, max, v)
+	}
+}
+
+// Validate checks the whole config up front and reports every problem at
+// once: values that didn't parse, unknown settings under CONFIG_STRICT,
+// values out of range, settings a feature needs but lacks, and options that
+// can't be combined. Each section is checked on its own, then the settings
+// that span sections.
+func (c *Config) Validate() error {
+	p := configProblems(slices.Clone(c.parseProblems))
+	if c.App.ConfigStrict {
+		p = append(p, c.unknownSettings...)
+	}
+
+	validateServer(&p, c.Server)
+	validateRateLimit(&p, c.RateLimit)
+	validateBreaker(&p, c.Breaker)
+	validateLogging(&p, c.App)
+	validateTelemetry(&p, c.Telemetry)
+	validateLLM(&p, c.LLM)
+	validateEmbeddings(&p, c.Embeddings)
+	p.positive("CONFLUENCE_TIMEOUT", c.Confluence.Timeout)
+	validateIngest(&p, c.Ingest)
+	if eventBusBackend(c) == "kafka" {
+		validateKafka(&p, c.Kafka)
+	}
+	p.oneOf("VECTOR_STORE_BACKEND", c.VectorStore.Backend, "memory", "qdrant", "postgres", "sqlite")
+	p.positive("VECTOR_STORE_TIMEOUT", c.VectorStore.Timeout)
+	validateStorage(&p, c.Storage)
+	validateQuery(&p, c.Query)
+	p.between("CONTENT_DUPLICATE_THRESHOLD", c.Content.DuplicateThreshold, 0, 1)
+	p.between("GAP_SCORE_THRESHOLD", c.Gaps.ScoreThreshold, 0, 1)
+	p.between("GAP_CLUSTER_SIMILARITY", c.Gaps.ClusterSimilarity, 0, 1)
+	p.oneOf("MODERATION_MODE", c.Moderation.Mode, "", "off", "rules", "provider", "both")
+	p.oneOf("AUDIT_QUESTION_MODE", c.Audit.QuestionMode, "", audit.QuestionRedact, audit.QuestionFull, audit.QuestionHash, audit.QuestionOmit)
+	validateChat(&p, c.Chat)
+	validateDigest(&p, c.Digest)
+	validateRemote(&p, c.Remote)
+	validateCache(&p, c.Cache)
+	validateArchive(&p, c.Archive)
+	validateRetention(&p, c.Retention)
+	if c.Secrets.RefreshInterval < 0 {
+		p.addf("SECRETS_REFRESH_INTERVAL: must not be negative, got %s", c.Secrets.RefreshInterval)
+	}
+
+	backend := eventBusBackend(c)
+	p.oneOf("EVENT_BUS", backend, "none", "kafka", "nats", "rabbitmq")
+	if backend == "none" && c.Ingest.Mode == "queue" {
+		p.addf("INGEST_MODE: queue needs an event bus; set EVENT_BUS or KAFKA_BROKERS")
+	}
+	if c.Auth.Enabled && len(c.Auth.StaticKeys) == 0 && c.OIDC.Issuer == "" {
+		p.addf("AUTH_ENABLED: needs AUTH_API_KEYS or OIDC_ISSUER to accept anyone")
+	}
+	if backend := c.VectorStore.Backend; (backend == "postgres" || backend == "sqlite") && c.Storage.Backend != backend {
+		p.addf("VECTOR_STORE_BACKEND: %s keeps chunks in the database of STORAGE_BACKEND=%s", backend, backend)
+	}
+	if c.Access.Enabled && !c.Auth.Enabled {
+		p.addf("DOCUMENT_ACCESS_ENABLED: needs AUTH_ENABLED to know who is asking")
+	}
+	if c.App.TenantsFile != "" && !c.Auth.Enabled {
+		p.addf("TENANTS_FILE: needs AUTH_ENABLED to know whose tenant is asking")
+	}
+	if c.Server.DebugEndpoints && (c.Server.AdminPort == "" || !c.Auth.Enabled) {
+		p.addf("DEBUG_ENDPOINTS_ENABLED: needs ADMIN_PORT and AUTH_ENABLED, so only admins on the admin network reach them")
+	}
+	if c.Writeback.Enabled {
+		p.oneOf("WRITEBACK_TARGET", c.Writeback.Target, writeback.TargetComment, writeback.TargetProperty)
+		if c.Confluence.BaseURL == "" || c.Confluence.APIToken == "" {
+			p.addf("WRITEBACK_ENABLED: needs CONFLUENCE_BASE_URL and CONFLUENCE_API_TOKEN")
+		}
+	}
+
+	if len(p) == 0 {
+		return nil
+	}
+	return errors.New("invalid configuration:\n  - " + strings.Join(p, "\n  - "))
+}
+
+func validateServer(p *configProblems, s ServerConfig) {
+	p.positive("READ_TIMEOUT", s.ReadTimeout)
+	p.positive("WRITE_TIMEOUT", s.WriteTimeout)
+	p.positive("IDLE_TIMEOUT", s.IdleTimeout)
+	p.positive("SHUTDOWN_TIMEOUT", s.ShutdownTimeout)
+	if s.DrainDelay < 0 || s.DrainDelay >= s.ShutdownTimeout {
+		p.addf("SHUTDOWN_DRAIN_DELAY: must be at least 0 and below SHUTDOWN_TIMEOUT (%s), got %s", s.ShutdownTimeout, s.DrainDelay)
+	}
+	tls := s.TLS
+	if (tls.CertFile == "") != (tls.KeyFile == "") {
+		p.addf("TLS_CERT_FILE and TLS_KEY_FILE: set both or neither")
+	}
+	if tls.CertFile != "" && len(tls.ACMEDomains) > 0 {
+		p.addf("TLS_CERT_FILE and ACME_DOMAINS: use a certificate file or ACME, not both")
+	}
+	if s.CORS.AllowCredentials && slices.Contains(s.CORS.AllowedOrigins, "*") {
+		p.addf("CORS_ALLOW_CREDENTIALS: can't be used with CORS_ALLOWED_ORIGINS=*, which would let any site call the API as the user; list the origins instead")
+	}
+}
+
+func validateRateLimit(p *configProblems, r middleware.RateLimitConfig) {
+	if r.Rate < 0 {
+		p.addf("RATE_LIMIT_RPS: must not be negative, got %g", r.Rate)
+	}
+	if r.Rate > 0 && r.Burst < 1 {
+		p.addf("RATE_LIMIT_BURST: must be at least 1 when RATE_LIMIT_RPS is set, got %d", r.Burst)
+	}
+}
+
+func validateBreaker(p *configProblems, b breaker.Config) {
+	p.between("CIRCUIT_BREAKER_FAILURE_RATIO", b.FailureRatio, 0, 1)
+	if !b.Enabled() {
+		return
+	}
+	if b.MinRequests < 1 {
+		p.addf("CIRCUIT_BREAKER_MIN_REQUESTS: must be at least 1, got %d", b.MinRequests)
+	}
+	if b.HalfOpenProbes < 1 {
+		p.addf("CIRCUIT_BREAKER_HALF_OPEN_PROBES: must be at least 1, got %d", b.HalfOpenProbes)
+	}
+	p.positive("CIRCUIT_BREAKER_WINDOW", b.Window)
+	p.positive("CIRCUIT_BREAKER_OPEN_TIMEOUT", b.OpenTimeout)
+}
+
+func validateLogging(p *configProblems, a AppConfig) {
+	if a.ConfigWatchInterval < 0 {
+		p.addf("CONFIG_WATCH_INTERVAL: must not be negative, got %s", a.ConfigWatchInterval)
+	}
+	p.level("LOG_LEVEL", a.LogLevel)
+	for _, component := range slices.Sorted(maps.Keys(a.LogLevels)) {
+		p.level("LOG_LEVELS "+component, a.LogLevels[component])
+	}
+	if a.LogFormat != "" {
+		if _, err := logger.ParseFormat(a.LogFormat); err != nil {
+			p.addf("LOG_FORMAT: %v", err)
+		}
+	}
+	if _, err := logger.ParseOverflow(a.LogOverflow); err != nil {
+		p.addf("LOG_OVERFLOW: %v", err)
+	}
+	if name := a.LogStacktraceLevel; name != "" && name != "off" {
+		p.level("LOG_STACKTRACE_LEVEL", name)
+	}
+	if a.LogAlert.URL != "" {
+		p.level("LOG_ALERT_LEVEL", a.LogAlertLevel)
+	}
+	for _, spec := range a.LogOutputs {
+		target, _, _ := strings.Cut(spec, ":")
+		p.oneOf("LOG_OUTPUTS", target, "stdout", "stderr", "file", "syslog", "journald")
+		if target == "file" && a.LogFile.Path == "" {
+			p.addf("LOG_OUTPUTS: the file output needs LOG_FILE")
+		}
+	}
+}
+
+func validateTelemetry(p *configProblems, t TelemetryConfig) {
+	p.between("SLO_AVAILABILITY_TARGET", t.SLO.Availability, 0, 1)
+	p.between("SLO_LATENCY_TARGET", t.SLO.Latency, 0, 1)
+	if t.SLO.Enabled() && t.SLO.Latency > 0 {
+		p.positive("SLO_LATENCY_THRESHOLD", t.SLO.LatencyThreshold)
+	}
+	// Both exporters take their timeout from OTEL_EXPORTER_OTLP_TIMEOUT.
+	if t.Logs.Enabled() || t.Traces.Enabled() {
+		p.positive("OTEL_EXPORTER_OTLP_TIMEOUT", t.Traces.Timeout)
+	}
+	if t.Logs.Enabled() {
+		p.positive("OTEL_BLRP_SCHEDULE_DELAY", t.Logs.FlushInterval)
+		if t.Logs.BatchSize < 1 || t.Logs.BatchSize > t.Logs.QueueSize {
+			p.addf("OTEL_BLRP_MAX_EXPORT_BATCH_SIZE: must be at least 1 and at most OTEL_BLRP_MAX_QUEUE_SIZE (%d), got %d", t.Logs.QueueSize, t.Logs.BatchSize)
+		}
+	}
+	if t.Traces.Enabled() {
+		p.positive("OTEL_BSP_SCHEDULE_DELAY", t.Traces.FlushInterval)
+		if t.Traces.BatchSize < 1 || t.Traces.BatchSize > t.Traces.QueueSize {
+			p.addf("OTEL_BSP_MAX_EXPORT_BATCH_SIZE: must be at least 1 and at most OTEL_BSP_MAX_QUEUE_SIZE (%d), got %d", t.Traces.QueueSize, t.Traces.BatchSize)
+		}
+		if t.Traces.SampleRatio < 0 || t.Traces.SampleRatio > 1 {
+			p.addf("OTEL_TRACES_SAMPLER_ARG: must be between 0 and 1, got %g", t.Traces.SampleRatio)
+		}
+	}
+}
+
+func validateLLM(p *configProblems, l llm.Config) {
+	if l.BaseURL == "" {
+		p.addf("LLM_BASE_URL: required")
+	}
+	p.positive("LLM_TIMEOUT", l.Timeout)
+	validateConcurrency(p, "LLM", l)
+}
+
+func validateEmbeddings(p *configProblems, e llm.Config) {
+	if e.BaseURL == "" {
+		p.addf("EMBEDDING_BASE_URL: required")
+	}
+	if e.EmbeddingModel == "" {
+		p.addf("EMBEDDING_MODEL: required")
+	}
+	p.positive("EMBEDDING_TIMEOUT", e.Timeout)
+	validateConcurrency(p, "EMBEDDING", e)
+	if e.EmbedParallelism < 1 {
+		p.addf("EMBED_PARALLELISM: must be at least 1, got %d", e.EmbedParallelism)
+	}
+}
+
+func validateConcurrency(p *configProblems, prefix string, c llm.Config) {
+	if c.MaxConcurrency < 0 {
+		p.addf("%s_MAX_CONCURRENCY: must not be negative, got %d", prefix, c.MaxConcurrency)
+	}
+	if c.QueueTimeout < 0 {
+		p.addf("%s_QUEUE_TIMEOUT: must not be negative, got %s", prefix, c.QueueTimeout)
+	}
+}
+
+func validateIngest(p *configProblems, i IngestConfig) {
+	p.positive("INGEST_TIMEOUT", i.Timeout)
+	p.oneOf("INGEST_MODE", i.Mode, "inline", "queue")
+	p.oneOf("GLOSSARY_MODE", i.GlossaryMode, "off", "rules", "llm", "both")
+	if i.ChunkSize < 1 {
+		p.addf("CHUNK_SIZE: must be at least 1, got %d", i.ChunkSize)
+	} else if i.ChunkOverlap < 0 || i.ChunkOverlap >= i.ChunkSize {
+		p.addf("CHUNK_OVERLAP: must be at least 0 and below CHUNK_SIZE (%d), got %d", i.ChunkSize, i.ChunkOverlap)
+	}
+	if i.EmbedBatchSize < 1 {
+		p.addf("EMBED_BATCH_SIZE: must be at least 1, got %d", i.EmbedBatchSize)
+	}
+}
+
+func validateKafka(p *configProblems, k kafka.Config) {
+	if len(k.Brokers) == 0 {
+		p.addf("KAFKA_BROKERS: required when EVENT_BUS is kafka")
+	}
+	p.positive("KAFKA_TIMEOUT", k.Timeout)
+	p.oneOf("KAFKA_MESSAGE_FORMAT", k.Format, "json", "avro", "protobuf")
+	if k.Format != "json" && k.SchemaRegistry.URL == "" {
+		p.addf("SCHEMA_REGISTRY_URL: required for KAFKA_MESSAGE_FORMAT %s", k.Format)
+	}
+	if (k.TLS.CertFile == "") != (k.TLS.KeyFile == "") {
+		p.addf("KAFKA_TLS_CERT_FILE and KAFKA_TLS_KEY_FILE: set both or neither")
+	}
+	switch sasl := k.SASL; strings.ToUpper(sasl.Mechanism) {
+	case "", "NONE":
+	case "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512":
+		if sasl.Username == "" || sasl.Password == "" {
+			p.addf("KAFKA_SASL_USERNAME and KAFKA_SASL_PASSWORD: required for KAFKA_SASL_MECHANISM %s", sasl.Mechanism)
+		}
+	case "OAUTHBEARER":
+		if sasl.TokenURL == "" {
+			p.addf("KAFKA_SASL_OAUTH_TOKEN_URL: required for KAFKA_SASL_MECHANISM OAUTHBEARER")
+		}
+	default:
+		p.addf("KAFKA_SASL_MECHANISM: %q is not one of PLAIN, SCRAM-SHA-256, SCRAM-SHA-512, OAUTHBEARER", sasl.Mechanism)
+	}
+}
+
+func validateQuery(p *configProblems, q query.Config) {
+	if q.TopK < 1 {
+		p.addf("RETRIEVAL_TOP_K: must be at least 1, got %d", q.TopK)
+	}
+	p.between("LLM_TEMPERATURE", q.Temperature, 0, 2)
+	if q.MaxTokens < 1 {
+		p.addf("LLM_MAX_TOKENS: must be at least 1, got %d", q.MaxTokens)
+	}
+	if q.HistoryTokens >= q.ContextWindow {
+		p.addf("LLM_HISTORY_TOKENS: must be below LLM_CONTEXT_WINDOW (%d), got %d", q.ContextWindow, q.HistoryTokens)
+	}
+	p.oneOf("ANSWER_LANGUAGE_STRATEGY", q.LanguageStrategy, query.StrategyInstruct, query.StrategyTranslate)
+}
+
+func validateStorage(p *configProblems, s StorageConfig) {
+	p.oneOf("STORAGE_BACKEND", s.Backend, "memory", "postgres", "sqlite")
+	if s.ConversationHistory < 0 {
+		p.addf("CONVERSATION_HISTORY: must not be negative, got %d", s.ConversationHistory)
+	}
+	if s.Backend == "sqlite" {
+		if s.SQLite.Path == "" {
+			p.addf("SQLITE_PATH: required for STORAGE_BACKEND sqlite")
+		}
+		p.positive("DATABASE_TIMEOUT", s.SQLite.Timeout)
+	}
+	if s.Backend != "postgres" {
+		return
+	}
+	if s.Postgres.URL == "" {
+		p.addf("DATABASE_URL: required for STORAGE_BACKEND postgres")
+	}
+	if s.Postgres.MaxConns < 1 {
+		p.addf("DATABASE_MAX_CONNS: must be at least 1, got %d", s.Postgres.MaxConns)
+	}
+	p.positive("DATABASE_TIMEOUT", s.Postgres.Timeout)
+}
+
+func validateRemote(p *configProblems, r remoteconfig.Config) {
+	p.oneOf("REMOTE_CONFIG_BACKEND", r.Backend, "", "etcd", "consul")
+	if r.Backend == "" {
+		return
+	}
+	if r.Address == "" {
+		p.addf("REMOTE_CONFIG_ADDRESS: required for REMOTE_CONFIG_BACKEND %s", r.Backend)
+	}
+	if r.PollInterval < 0 {
+		p.addf("REMOTE_CONFIG_POLL_INTERVAL: must not be negative, got %s", r.PollInterval)
+	}
+}
+
+func validateCache(p *configProblems, c CacheConfig) {
+	if c.EmbeddingTTL < 0 {
+		p.addf("EMBEDDING_CACHE_TTL: must not be negative, got %s", c.EmbeddingTTL)
+	}
+	if c.AnswerTTL < 0 {
+		p.addf("ANSWER_CACHE_TTL: must not be negative, got %s", c.AnswerTTL)
+	}
+	if c.Redis.URL == "" {
+		if c.MaxEntries < 1 {
+			p.addf("CACHE_MAX_ENTRIES: must be at least 1, got %d", c.MaxEntries)
+		}
+		return
+	}
+	if u, err := url.Parse(c.Redis.URL); err != nil || (u.Scheme != "redis" && u.Scheme != "rediss" && u.Scheme != "unix") {
+		p.addf("REDIS_URL: must be a redis://, rediss:// or unix:// URL")
+	}
+	p.positive("REDIS_TIMEOUT", c.Redis.Timeout)
+}
+
+func validateArchive(p *configProblems, c ArchiveConfig) {
+	p.oneOf("BLOB_BACKEND", c.Blob.Backend, "", "s3", "gcs", "local")
+	p.oneOf("ENCRYPTION_KEY_PROVIDER", c.Encryption.Provider, "", "file", "aws-kms")
+	switch c.Encryption.Provider {
+	case "file":
+		if c.Encryption.KeyFile == "" {
+			p.addf("ENCRYPTION_KEY_FILE: required for ENCRYPTION_KEY_PROVIDER=file")
+		}
+	case "aws-kms":
+		if c.Encryption.KeyID == "" {
+			p.addf("ENCRYPTION_KEY_ID: required for ENCRYPTION_KEY_PROVIDER=aws-kms")
+		}
+		if c.Encryption.KMS.Region == "" || c.Encryption.KMS.AccessKeyID == "" || c.Encryption.KMS.SecretAccessKey == "" {
+			p.addf("ENCRYPTION_KEY_PROVIDER: aws-kms needs AWS_REGION, AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+		p.positive("ENCRYPTION_KMS_TIMEOUT", c.Encryption.KMS.Timeout)
+	}
+	switch c.Blob.Backend {
+	case "":
+		if c.Encryption.Provider != "" {
+			p.addf("ENCRYPTION_KEY_PROVIDER: encrypts blobs, so needs BLOB_BACKEND")
+		}
+		return
+	case "s3":
+		if c.Blob.Bucket == "" {
+			p.addf("BLOB_BUCKET: required for BLOB_BACKEND=s3")
+		}
+		if c.Blob.S3.AccessKeyID == "" || c.Blob.S3.SecretAccessKey == "" {
+			p.addf("BLOB_BACKEND: s3 needs AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+		}
+	case "gcs":
+		if c.Blob.Bucket == "" {
+			p.addf("BLOB_BUCKET: required for BLOB_BACKEND=gcs")
+		}
+	case "local":
+		if c.Blob.Dir == "" {
+			p.addf("BLOB_DIR: required for BLOB_BACKEND=local")
+		}
+	}
+	p.positive("BLOB_TIMEOUT", c.Blob.Timeout)
+	for _, setting := range []struct {
+		key   string
+		value time.Duration
+	}{
+		{"ARCHIVE_WEBHOOKS_RETENTION", c.Webhooks.Retention},
+		{"ARCHIVE_ATTACHMENTS_RETENTION", c.Attachments.Retention},
+		{"ARCHIVE_DEAD_LETTERS_RETENTION", c.DeadLetters.Retention},
+		{"ARCHIVE_SWEEP_INTERVAL", c.SweepInterval},
+	} {
+		if setting.value < 0 {
+			p.addf("%s: must not be negative, got %s", setting.key, setting.value)
+		}
+	}
+}
+
+func validateRetention(p *configProblems, c retention.Config) {
+	for _, setting := range []struct {
+		key    string
+		policy retention.Policy
+	}{
+		{"RETENTION_CONVERSATIONS", c.Conversations},
+		{"RETENTION_FEEDBACK", c.Feedback},
+	} {
+		if setting.policy.Default < 0 {
+			p.addf("%s: must not be negative, got %s", setting.key, setting.policy.Default)
+		}
+		for tenant, d := range setting.policy.Tenants {
+			if d < 0 {
+				p.addf("%s_TENANTS: %s must not be negative, got %s", setting.key, tenant, d)
+			}
+		}
+	}
+	if c.Interval < 0 {
+		p.addf("RETENTION_INTERVAL: must not be negative, got %s", c.Interval)
+	}
+}
+
+func validateChat(p *configProblems, c ChatConfig) {
+	if (c.Slack.BotToken == "") != (c.Slack.SigningSecret == "") {
+		p.addf("SLACK_BOT_TOKEN and SLACK_SIGNING_SECRET: set both or neither")
+	}
+	if (c.Teams.AppID == "") != (c.Teams.AppPassword == "") {
+		p.addf("TEAMS_APP_ID and TEAMS_APP_PASSWORD: set both or neither")
+	}
+}
+
+func validateDigest(p *configProblems, d DigestConfig) {
+	if len(d.Recipients) == 0 {
+		return
+	}
+	if d.SMTP.Host == "" {
+		p.addf("SMTP_HOST: required to send digests to DIGEST_RECIPIENTS")
+	} else if d.SMTP.From == "" {
+		p.addf("SMTP_FROM: required to send digests")
+	}
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"strings"
+)
+
+// Readers identify who may read a page or space. Documents with no readers
+// have no page restrictions.
+const (
+	// ReaderAnyone marks a space readable by every user, including anonymous ones.
+	ReaderAnyone = "anyone"
+	// ReaderNobody marks a page whose restrictions no single reader is known to satisfy.
+	ReaderNobody = "nobody"
+)
+
+func UserReader(id string) string {
+	return "user:" + strings.ToLower(id)
+}
+
+func GroupReader(name string) string {
+	return "group:" + strings.ToLower(name)
+}
+
+// SpacePermissions reports, per space key, the readers granted read access to the space.
+type SpacePermissions interface {
+	SpaceReaders(ctx context.Context) (map[string][]string, error)
+}
@@ -0,0 +1,69 @@
+package domain
+
+import "time"
+
+type QueryMode string
+
+const (
+	ModeStandard     QueryMode = "standard"
+	ModeDeepResearch QueryMode = "deep_research"
+)
+
+type Question struct {
+	Text      string    `json:"question"`
+	Mode      QueryMode `json:"mode,omitempty"`
+	SessionID string    `json:"session_id,omitempty"`
+	TopK      int       `json:"top_k,omitempty"`
+	SpaceKeys []string  `json:"space_keys,omitempty"`
+	Language  string    `json:"language,omitempty"`
+	// History holds earlier turns of the conversation, oldest first.
+	History []Message `json:"history,omitempty"`
+	// Readers are the caller's identities for page restrictions; set by the server.
+	Readers []string `json:"-"`
+	// Tenant is the caller's tenant, if any; set by the server.
+	Tenant string `json:"-"`
+}
+
+type Citation struct {
+	DocumentID string  `json:"document_id"`
+	Title      string  `json:"title"`
+	URL        string  `json:"url"`
+	Score      float64 `json:"score"`
+}
+
+// AnswerSection is one researched sub-question of a deep-research answer;
+// Citations index into the answer's Citations, starting at 1.
+type AnswerSection struct {
+	Question  string `json:"question"`
+	Answer    string `json:"answer"`
+	Citations []int  `json:"citations"`
+}
+
+type Answer struct {
+	Text       string           `json:"answer"`
+	Citations  []Citation       `json:"citations"`
+	Sections   []AnswerSection  `json:"sections,omitempty"`
+	Chunks     []ScoredChunk    `json:"-"`
+	Model      string           `json:"model"`
+	Usage      Usage            `json:"usage"`
+	Moderation ModerationAction `json:"moderation,omitempty"`
+	Language   string           `json:"language,omitempty"`
+	Latency    time.Duration    `json:"-"`
+}
+
+// DocumentIDs returns the distinct document IDs of the retrieved chunks, in rank order.
+func (a *Answer) DocumentIDs() []string {
+	return DocumentIDs(a.Chunks)
+}
+
+func DocumentIDs(chunks []ScoredChunk) []string {
+	seen := make(map[string]bool, len(chunks))
+	ids := make([]string, 0, len(chunks))
+	for _, c := range chunks {
+		if !seen[c.DocumentID] {
+			seen[c.DocumentID] = true
+			ids = append(ids, c.DocumentID)
+		}
+	}
+	return ids
+}
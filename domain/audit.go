@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// QueryAudit records one answered (or refused) question for downstream analytics.
+// Question is empty or transformed depending on the configured PII policy.
+type QueryAudit struct {
+	ID           string            `json:"id"`
+	At           time.Time         `json:"at"`
+	SessionID    string            `json:"session_id"`
+	Mode         QueryMode         `json:"mode"`
+	Question     string            `json:"question,omitempty"`
+	QuestionHash string            `json:"question_hash,omitempty"`
+	Language     string            `json:"language,omitempty"`
+	DocumentIDs  []string          `json:"document_ids"`
+	Model        string            `json:"model,omitempty"`
+	LatencyMS    int64             `json:"latency_ms"`
+	Usage        Usage             `json:"usage"`
+	Moderation   ModerationAction  `json:"moderation,omitempty"`
+	Variants     map[string]string `json:"variants,omitempty"`
+	Outcome      string            `json:"outcome"`
+}
+
+type AuditPublisher interface {
+	PublishQueryAudit(ctx context.Context, event QueryAudit) error
+}
+
+// Actions recorded in the security audit log.
+const (
+	AuditQuery        = "query"
+	AuditAdminAction  = "admin_action"
+	AuditAuthFailure  = "auth_failure"
+	AuditAccessDenied = "access_denied"
+)
+
+// AuditEvent is an entry in the security audit log, kept apart from
+// operational logs: who did what to which resource, and how it ended.
+type AuditEvent struct {
+	At        time.Time      `json:"at"`
+	Action    string         `json:"action"`
+	Actor     string         `json:"actor,omitempty"`
+	Resource  string         `json:"resource,omitempty"`
+	Outcome   string         `json:"outcome"`
+	RemoteIP  string         `json:"remote_ip,omitempty"`
+	RequestID string         `json:"request_id,omitempty"`
+	Details   map[string]any `json:"details,omitempty"`
+}
+
+type AuditLog interface {
+	Append(ctx context.Context, event AuditEvent) error
+}
+
+// AuditEraser removes an actor's entries from an audit log, for erasure
+// requests.
+type AuditEraser interface {
+	Erase(ctx context.Context, actor string) (int, error)
+}
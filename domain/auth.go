@@ -0,0 +1,74 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+type Scope string
+
+const (
+	ScopeQuery  Scope = "query"
+	ScopeIngest Scope = "ingest"
+	ScopeAdmin  Scope = "admin"
+)
+
+var (
+	ErrAPIKeyNotFound       = errors.New("api key not found")
+	ErrInvalidToken         = errors.New("invalid token")
+	ErrClientCertRequired   = errors.New("client certificate required")
+	ErrClientCertNotAllowed = errors.New("client certificate not allowed")
+)
+
+// APIKey is stored by the SHA-256 hash of the secret; the secret itself is
+// only shown once, when the key is created.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Prefix    string    `json:"prefix,omitempty"`
+	Hash      string    `json:"-"`
+	Scopes    []Scope   `json:"scopes"`
+	Static    bool      `json:"static,omitempty"`
+	CreatedAt time.Time `json:"created_at,omitzero"`
+	// Tenant limits the key to one tenant's data; empty for the deployment's own keys.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+// Principal is the authenticated caller of a request. When Spaces is set,
+// query access is limited to those Confluence spaces; when Tenant is set, to
+// the tenant's data.
+type Principal struct {
+	ID       string `json:"id"`
+	Name     string `json:"name,omitempty"`
+	Username string `json:"username,omitempty"`
+	Email    string `json:"email,omitempty"`
+	// EmailVerified is set when the identity provider vouches for Email;
+	// only a verified email identifies the caller to page permissions.
+	EmailVerified bool         `json:"email_verified,omitempty"`
+	Groups        []string     `json:"groups,omitempty"`
+	Method        string       `json:"method"`
+	Roles         []AccessRole `json:"roles,omitempty"`
+	Scopes        []Scope      `json:"scopes"`
+	Spaces        []string     `json:"spaces,omitempty"`
+	Tenant        string       `json:"tenant,omitempty"`
+}
+
+// HasScope reports whether the principal was granted scope; admin grants every scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	return slices.Contains(p.Scopes, scope) || slices.Contains(p.Scopes, ScopeAdmin)
+}
+
+// TokenVerifier validates a bearer token and returns its claims. Rejected tokens
+// yield ErrInvalidToken; other errors mean the token could not be checked.
+type TokenVerifier interface {
+	VerifyToken(ctx context.Context, token string) (map[string]any, error)
+}
+
+type APIKeyRepository interface {
+	SaveAPIKey(ctx context.Context, key APIKey) error
+	FindAPIKeyByHash(ctx context.Context, hash string) (*APIKey, error)
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+	DeleteAPIKey(ctx context.Context, id string) error
+}
@@ -0,0 +1,24 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+// Blob describes a stored object.
+type Blob struct {
+	Key      string
+	Size     int64
+	Modified time.Time
+}
+
+// BlobStore keeps opaque objects, such as raw webhook payloads and downloaded
+// attachments, under slash-separated keys. Get returns ErrNotFound for a
+// missing key; deleting one is not an error.
+type BlobStore interface {
+	Put(ctx context.Context, key string, data []byte, contentType string) error
+	Get(ctx context.Context, key string) (data []byte, contentType string, err error)
+	// List returns the blobs whose keys start with prefix.
+	List(ctx context.Context, prefix string) ([]Blob, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Cache keeps values for a while, e.g. embeddings and answers. A shared
+// backend lets every instance reuse what one of them computed.
+type Cache interface {
+	// Get reports whether key holds a value that hasn't expired.
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+}
+
+var ErrLocked = errors.New("lock is held elsewhere")
+
+// Locker hands out named locks, so work such as a backfill runs on one
+// instance at a time.
+type Locker interface {
+	// TryLock takes the lock without waiting, or returns ErrLocked. The lock
+	// is held until unlock is called, or for ttl after its holder stops
+	// renewing it, e.g. because the process died.
+	TryLock(ctx context.Context, name string, ttl time.Duration) (unlock func(), err error)
+}
@@ -0,0 +1,28 @@
+package domain
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// ConversationRepository keeps the turns of each session, so a follow-up
+// question sent with only its session ID is answered in context.
+type ConversationRepository interface {
+	AppendMessages(ctx context.Context, sessionID string, messages ...Message) error
+	// Messages returns the last limit messages of the session, oldest first.
+	Messages(ctx context.Context, sessionID string, limit int) ([]Message, error)
+	// PurgeMessages deletes the messages in scope added before before and
+	// returns how many it deleted.
+	PurgeMessages(ctx context.Context, before time.Time, scope RetentionScope) (int, error)
+}
+
+// ConversationTenant returns the tenant of a stored session: the principal ID
+// its key starts with, or "" for a session of an unauthenticated caller.
+func ConversationTenant(sessionKey string) string {
+	tenant, _, ok := strings.Cut(sessionKey, "/")
+	if !ok {
+		return ""
+	}
+	return tenant
+}
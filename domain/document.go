@@ -0,0 +1,60 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// ManualDocumentPrefix starts the IDs of documents added through the API
+// rather than fetched from a source, so they are never re-fetched.
+const ManualDocumentPrefix = "manual:"
+
+func IsManualDocument(id string) bool {
+	return strings.HasPrefix(id, ManualDocumentPrefix)
+}
+
+type Document struct {
+	ID        string          `json:"id"`
+	SpaceKey  string          `json:"space_key"`
+	Title     string          `json:"title"`
+	URL       string          `json:"url"`
+	Body      string          `json:"body,omitempty"`
+	Labels    []string        `json:"labels,omitempty"`
+	Images    []DocumentImage `json:"images,omitempty"`
+	Readers   []string        `json:"readers,omitempty"`
+	Version   int             `json:"version"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type Chunk struct {
+	ID         string    `json:"id"`
+	DocumentID string    `json:"document_id"`
+	SpaceKey   string    `json:"space_key"`
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+	Index      int       `json:"index"`
+	Text       string    `json:"text"`
+	Readers    []string  `json:"readers,omitempty"`
+	Labels     []string  `json:"labels,omitempty"`
+	Embedding  []float32 `json:"-"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type ScoredChunk struct {
+	Chunk
+	Score float64 `json:"score"`
+}
+
+// DocumentImage references an image embedded in a page, either an attachment or an external URL.
+type DocumentImage struct {
+	Filename string `json:"filename,omitempty"`
+	URL      string `json:"url,omitempty"`
+	Alt      string `json:"alt,omitempty"`
+}
+
+func (i DocumentImage) Name() string {
+	if i.Filename != "" {
+		return i.Filename
+	}
+	return i.URL
+}
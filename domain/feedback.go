@@ -0,0 +1,26 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type Feedback struct {
+	ID        string            `json:"id"`
+	SessionID string            `json:"session_id"`
+	Question  string            `json:"question,omitempty"`
+	Rating    int               `json:"rating"`
+	Comment   string            `json:"comment,omitempty"`
+	Variants  map[string]string `json:"variants,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	// Principal is the ID of the caller that gave the feedback, its tenant.
+	Principal string `json:"principal,omitempty"`
+}
+
+type FeedbackRepository interface {
+	Save(ctx context.Context, f Feedback) error
+	List(ctx context.Context) ([]Feedback, error)
+	// Purge deletes the feedback in scope given before before and returns how
+	// many it deleted.
+	Purge(ctx context.Context, before time.Time, scope RetentionScope) (int, error)
+}
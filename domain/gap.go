@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type GapReason string
+
+const (
+	GapLowRetrievalScore GapReason = "low_retrieval_score"
+	GapNoAnswer          GapReason = "no_answer"
+)
+
+// KnowledgeGap is a question the documentation could not answer well.
+type KnowledgeGap struct {
+	Question  string    `json:"question"`
+	Reason    GapReason `json:"reason"`
+	TopScore  float64   `json:"top_score"`
+	Embedding []float32 `json:"-"`
+	At        time.Time `json:"at"`
+}
+
+type GapRepository interface {
+	SaveGap(ctx context.Context, gap KnowledgeGap) error
+	ListGaps(ctx context.Context, since time.Time) ([]KnowledgeGap, error)
+}
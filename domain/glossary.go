@@ -0,0 +1,31 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type GlossaryKind string
+
+const (
+	GlossaryAcronym GlossaryKind = "acronym"
+	GlossaryEntity  GlossaryKind = "entity"
+	GlossaryTerm    GlossaryKind = "term"
+)
+
+type GlossaryEntry struct {
+	Term       string       `json:"term"`
+	Definition string       `json:"definition"`
+	Kind       GlossaryKind `json:"kind"`
+	Sources    []string     `json:"sources"`
+	UpdatedAt  time.Time    `json:"updated_at"`
+}
+
+// GlossaryRepository stores glossary entries per source document, so re-indexing
+// or deleting a page replaces exactly what that page contributed.
+type GlossaryRepository interface {
+	ReplaceDocumentEntries(ctx context.Context, documentID string, entries []GlossaryEntry) error
+	RemoveDocumentEntries(ctx context.Context, documentID string) error
+	LookupTerms(ctx context.Context, terms []string) ([]GlossaryEntry, error)
+	ListGlossary(ctx context.Context) ([]GlossaryEntry, error)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "context"
+
+type Entity struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Type string `json:"type,omitempty"`
+}
+
+// Relation is a subject-predicate-object triple linked back to the chunk it was read from.
+type Relation struct {
+	Subject    Entity `json:"subject"`
+	Predicate  string `json:"predicate"`
+	Object     Entity `json:"object"`
+	DocumentID string `json:"document_id"`
+	ChunkID    string `json:"chunk_id"`
+}
+
+type GraphRepository interface {
+	ReplaceDocumentGraph(ctx context.Context, documentID string, relations []Relation) error
+	RemoveDocumentGraph(ctx context.Context, documentID string) error
+	FindEntities(ctx context.Context, ids []string) ([]Entity, error)
+	// Neighbors returns relations touching any of the entities, up to limit.
+	Neighbors(ctx context.Context, entityIDs []string, limit int) ([]Relation, error)
+}
@@ -0,0 +1,8 @@
+package domain
+
+import "context"
+
+// HealthChecker reports whether a backend is reachable and able to serve requests.
+type HealthChecker interface {
+	Ping(ctx context.Context) error
+}
@@ -0,0 +1,82 @@
+package domain
+
+import (
+	"context"
+	"io"
+	"time"
+)
+
+type IngestAction string
+
+const (
+	IngestUpsert IngestAction = "upsert"
+	IngestDelete IngestAction = "delete"
+)
+
+// IngestEvent is the source-independent form of a change notification.
+type IngestEvent struct {
+	ID         string       `json:"id"`
+	Source     string       `json:"source"`
+	Action     IngestAction `json:"action"`
+	DocumentID string       `json:"document_id"`
+	Title      string       `json:"title,omitempty"`
+	SpaceKey   string       `json:"space_key,omitempty"`
+	RawType    string       `json:"raw_type,omitempty"`
+	ReceivedAt time.Time    `json:"received_at"`
+	// Spaces, when set, limits the event to documents in these spaces, such
+	// as a tenant's; it is skipped for any other document.
+	Spaces []string `json:"spaces,omitempty"`
+}
+
+type DocumentSource interface {
+	GetDocument(ctx context.Context, id string) (*Document, error)
+}
+
+// DocumentStreamer is implemented by sources that can hand a document's text
+// over as it is downloaded, so a large page is never held whole.
+type DocumentStreamer interface {
+	// StreamDocument returns the document without its Body, which is read
+	// from text instead; the caller must close text. Images are set once
+	// text has been read to the end.
+	StreamDocument(ctx context.Context, id string) (doc *Document, text io.ReadCloser, err error)
+}
+
+// IngestPublisher forwards normalized ingest events to other systems.
+type IngestPublisher interface {
+	PublishIngestEvent(ctx context.Context, event IngestEvent) error
+}
+
+// IngestLedger remembers applied ingest events so redelivered events are skipped
+// and events older than the document's latest applied change are ignored.
+type IngestLedger interface {
+	Applied(ctx context.Context, eventID string) (bool, error)
+	Watermark(ctx context.Context, documentID string) (time.Time, error)
+	MarkApplied(ctx context.Context, event IngestEvent) error
+}
+
+type IngestHandler func(ctx context.Context, event IngestEvent) error
+
+// IngestConsumer delivers queued ingest events to handle at least once, until
+// ctx ends. Events whose handler fails are retried and finally dead-lettered.
+type IngestConsumer interface {
+	ConsumeIngestEvents(ctx context.Context, handle IngestHandler) error
+}
+
+// DeadLetter is an event that exhausted its retries. Event is nil when the
+// message could not be decoded.
+type DeadLetter struct {
+	Position string       `json:"position"`
+	Event    *IngestEvent `json:"event,omitempty"`
+	Error    string       `json:"error,omitempty"`
+	Attempts int          `json:"attempts,omitempty"`
+}
+
+// DeadLetterQueue lets operators inspect dead letters and, once the cause is
+// fixed, send them back for processing or drop them. Both work oldest first.
+type DeadLetterQueue interface {
+	PeekDeadLetters(ctx context.Context, limit int) ([]DeadLetter, error)
+	// ReplayDeadLetters requeues up to limit dead letters for processing and
+	// removes them from the dead-letter queue.
+	ReplayDeadLetters(ctx context.Context, limit int) (int, error)
+	PurgeDeadLetters(ctx context.Context) (int, error)
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "context"
+
+// Email is a message with a plain-text body and an optional HTML alternative.
+type Email struct {
+	To      []string
+	Subject string
+	Text    string
+	HTML    string
+}
+
+type Mailer interface {
+	Send(ctx context.Context, email Email) error
+}
@@ -0,0 +1,23 @@
+package domain
+
+import "context"
+
+type ModerationAction string
+
+const (
+	ModerationAllow  ModerationAction = "allow"
+	ModerationRedact ModerationAction = "redact"
+	ModerationBlock  ModerationAction = "block"
+)
+
+type ModerationResult struct {
+	Action     ModerationAction `json:"action"`
+	Text       string           `json:"-"`
+	Categories []string         `json:"categories,omitempty"`
+}
+
+// Moderator inspects text and decides whether it may pass as is, must be
+// redacted (Text holds the redacted version), or must be blocked.
+type Moderator interface {
+	Moderate(ctx context.Context, text string) (ModerationResult, error)
+}
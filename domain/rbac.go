@@ -0,0 +1,56 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AccessRole is an RBAC role; it is unrelated to the chat message Role.
+type AccessRole string
+
+const (
+	AccessReader   AccessRole = "reader"
+	AccessIngester AccessRole = "ingester"
+	AccessAdmin    AccessRole = "admin"
+)
+
+var ErrRoleBindingNotFound = errors.New("role binding not found")
+
+var roleScopes = map[AccessRole][]Scope{
+	AccessReader:   {ScopeQuery},
+	AccessIngester: {ScopeIngest},
+	AccessAdmin:    {ScopeAdmin},
+}
+
+// AccessRoles lists the known roles.
+func AccessRoles() []AccessRole {
+	return []AccessRole{AccessReader, AccessIngester, AccessAdmin}
+}
+
+func (r AccessRole) Valid() bool {
+	_, ok := roleScopes[r]
+	return ok
+}
+
+// Scopes returns the route scopes the role grants.
+func (r AccessRole) Scopes() []Scope {
+	return roleScopes[r]
+}
+
+// RoleBinding grants a role to a principal (an OIDC subject or API key ID).
+// Reader bindings may be limited to Confluence spaces; empty Spaces means all.
+type RoleBinding struct {
+	ID        string     `json:"id"`
+	Subject   string     `json:"subject"`
+	Role      AccessRole `json:"role"`
+	Spaces    []string   `json:"spaces,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type RoleBindingRepository interface {
+	SaveRoleBinding(ctx context.Context, b RoleBinding) error
+	ListRoleBindings(ctx context.Context) ([]RoleBinding, error)
+	RoleBindingsFor(ctx context.Context, subject string) ([]RoleBinding, error)
+	DeleteRoleBinding(ctx context.Context, id string) error
+}
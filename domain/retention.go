@@ -0,0 +1,24 @@
+package domain
+
+// RetentionScope selects whose records a purge deletes. Records belong to the
+// tenant of the caller that created them, i.e. its principal ID; those of
+// unauthenticated callers belong to no tenant.
+type RetentionScope struct {
+	// Tenant, when set, limits the purge to that tenant's records.
+	Tenant string
+	// Except, without Tenant, spares these tenants' records.
+	Except []string
+}
+
+// Covers reports whether a record of tenant falls in the scope.
+func (s RetentionScope) Covers(tenant string) bool {
+	if s.Tenant != "" {
+		return tenant == s.Tenant
+	}
+	for _, t := range s.Except {
+		if t == tenant {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+var ErrNotFound = errors.New("not found")
+
+type SearchFilter struct {
+	SpaceKeys          []string
+	DocumentIDs        []string
+	ExcludeDocumentIDs []string
+	// Readers, when set, hides chunks of restricted pages that list none of them.
+	Readers []string
+}
+
+// Matches reports whether the filter lets c through.
+func (f SearchFilter) Matches(c Chunk) bool {
+	if len(f.SpaceKeys) > 0 && !slices.Contains(f.SpaceKeys, c.SpaceKey) {
+		return false
+	}
+	if len(f.DocumentIDs) > 0 && !slices.Contains(f.DocumentIDs, c.DocumentID) {
+		return false
+	}
+	if len(f.Readers) > 0 && len(c.Readers) > 0 && !slices.ContainsFunc(f.Readers, func(r string) bool { return slices.Contains(c.Readers, r) }) {
+		return false
+	}
+	return !slices.Contains(f.ExcludeDocumentIDs, c.DocumentID)
+}
+
+type VectorStore interface {
+	Upsert(ctx context.Context, chunks []Chunk) error
+	Search(ctx context.Context, vector []float32, topK int, filter SearchFilter) ([]ScoredChunk, error)
+	DeleteDocument(ctx context.Context, documentID string) error
+	// DocumentChunks returns every stored chunk of a document, embeddings included.
+	DocumentChunks(ctx context.Context, documentID string) ([]Chunk, error)
+	// ScanChunks calls fn for every stored chunk, embeddings included, stopping at the first error.
+	ScanChunks(ctx context.Context, fn func(Chunk) error) error
+}
+
+// IndexVersioner is implemented by vector stores that can keep further
+// versions of the index next to their own, e.g. built with another chunk size
+// or embedding model.
+type IndexVersioner interface {
+	// OpenVersion returns the store of the named version, empty until written to.
+	OpenVersion(name string) VectorStore
+	// DropVersion deletes everything stored in the named version.
+	DropVersion(ctx context.Context, name string) error
+}
+
+// RetrievalStats tracks how often documents are served as answer context.
+type RetrievalStats interface {
+	RecordRetrieval(ctx context.Context, documentIDs []string, at time.Time) error
+	RetrievalCounts(ctx context.Context, since time.Time) (map[string]int, error)
+}
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+// Stores are the repositories writes of one unit of work go through.
+type Stores struct {
+	Chunks        VectorStore
+	Ledger        IngestLedger
+	APIKeys       APIKeyRepository
+	Feedback      FeedbackRepository
+	Conversations ConversationRepository
+}
+
+// UnitOfWork makes several writes succeed or fail together, e.g. a document's
+// chunks and the ingest event that produced them.
+type UnitOfWork interface {
+	// Do runs fn with stores whose writes are committed when it returns nil
+	// and discarded when it returns an error.
+	Do(ctx context.Context, fn func(ctx context.Context, stores Stores) error) error
+}
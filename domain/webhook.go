@@ -0,0 +1,72 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"time"
+)
+
+var ErrWebhookNotFound = errors.New("webhook not found")
+
+type EventType string
+
+const (
+	EventDocumentIndexed EventType = "document.indexed"
+	EventDocumentDeleted EventType = "document.deleted"
+	EventSyncCompleted   EventType = "sync.completed"
+)
+
+func EventTypes() []EventType {
+	return []EventType{EventDocumentIndexed, EventDocumentDeleted, EventSyncCompleted}
+}
+
+func (t EventType) Valid() bool {
+	return slices.Contains(EventTypes(), t)
+}
+
+// Webhook is an outbound subscription. Deliveries are signed with Secret;
+// an empty Events list subscribes to every event type.
+type Webhook struct {
+	ID        string      `json:"id"`
+	URL       string      `json:"url"`
+	Secret    string      `json:"-"`
+	Events    []EventType `json:"events,omitempty"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+func (w Webhook) Subscribed(t EventType) bool {
+	return len(w.Events) == 0 || slices.Contains(w.Events, t)
+}
+
+type WebhookRepository interface {
+	SaveWebhook(ctx context.Context, w Webhook) error
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+}
+
+// Notifier tells subscribers that something happened. It must not block the
+// caller on delivery.
+type Notifier interface {
+	Notify(ctx context.Context, event EventType, data any)
+}
+
+// DocumentEvent is the payload of document.indexed and document.deleted.
+type DocumentEvent struct {
+	DocumentID string `json:"document_id"`
+	Title      string `json:"title,omitempty"`
+	SpaceKey   string `json:"space_key,omitempty"`
+	URL        string `json:"url,omitempty"`
+	Version    int    `json:"version,omitempty"`
+	Chunks     int    `json:"chunks,omitempty"`
+}
+
+// SyncEvent is the payload of sync.completed, sent when a reindex job has
+// handed every selected document to ingestion.
+type SyncEvent struct {
+	JobID      string `json:"job_id"`
+	SpaceKey   string `json:"space_key,omitempty"`
+	Documents  int    `json:"documents"`
+	Failed     int    `json:"failed"`
+	DurationMS int64  `json:"duration_ms"`
+}
@@ -0,0 +1,290 @@
+package auditlog
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+)
+
+// ErrTampered means a record no longer matches its hash or doesn't follow on
+// from the record before it.
+var ErrTampered = errors.New("audit log tampered")
+
+type Config struct {
+	// File.Path, when set, enables the audit log. Retention is the rotated
+	// file's MaxAge and MaxBackups.
+	File logger.RotateConfig
+	// HashChain stamps each record with a hash over its content and the
+	// previous record's hash, so edits, deletions and reordering are
+	// detectable with Verify. HashKey, when set, makes it an HMAC, so the
+	// chain can't be recomputed by whoever altered the file.
+	HashChain bool
+	HashKey   string
+}
+
+func (c Config) Enabled() bool {
+	return c.File.Path != ""
+}
+
+// record is one line of the log. Hash is always the last key, and covers the
+// exact bytes of the line before it, so Verify needn't re-encode anything.
+type record struct {
+	Seq uint64 `json:"seq"`
+	domain.AuditEvent
+	PrevHash string `json:"prev_hash,omitempty"`
+}
+
+// Log appends audit events as JSON lines to their own rotated file, separate
+// from the application logs.
+type Log struct {
+	cfg  Config
+	file *logger.RotatingFile
+
+	mu   sync.Mutex
+	seq  uint64
+	prev string
+}
+
+// Open opens the log, continuing the sequence and hash chain of the file's
+// last record when it has one.
+func Open(cfg Config) (*Log, error) {
+	l := &Log{cfg: cfg}
+	if err := l.resume(); err != nil {
+		return nil, err
+	}
+	file, err := logger.OpenRotatingFile(cfg.File)
+	if err != nil {
+		return nil, fmt.Errorf("open audit log: %w", err)
+	}
+	l.file = file
+	return l, nil
+}
+
+func (l *Log) Append(ctx context.Context, event domain.AuditEvent) error {
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+	event.At = event.At.UTC()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	rec := record{Seq: l.seq + 1, AuditEvent: event}
+	if l.cfg.HashChain {
+		rec.PrevHash = l.prev
+	}
+	body, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode audit event: %w", err)
+	}
+	var hash string
+	if l.cfg.HashChain {
+		hash = l.hash(body)
+		body = append(body[:len(body)-1], `,"hash":"`+hash+`"}`...)
+	}
+	if _, err := l.file.Write(append(body, '\n')); err != nil {
+		return fmt.Errorf("write audit event: %w", err)
+	}
+	l.seq, l.prev = rec.Seq, hash
+	return nil
+}
+
+// Erase removes actor's records from the log and its rotated files, e.g. for
+// an erasure request, and returns how many it removed. The records kept are
+// renumbered and their hash chain recomputed, so Verify still passes; appends
+// wait meanwhile.
+func (l *Log) Erase(ctx context.Context, actor string) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	chain := &rechain{key: []byte(l.cfg.HashKey), actor: actor}
+	if err := l.file.Rewrite(chain.rewrite); err != nil {
+		// Continue from whatever the current file now ends with.
+		if rerr := l.resume(); rerr != nil {
+			err = errors.Join(err, rerr)
+		}
+		return chain.removed, fmt.Errorf("erase audit records: %w", err)
+	}
+	if chain.started {
+		l.seq, l.prev = chain.seq, chain.prev
+	}
+	return chain.removed, nil
+}
+
+func (l *Log) Close() error {
+	return l.file.Close()
+}
+
+func (l *Log) hash(body []byte) string {
+	return hashLine([]byte(l.cfg.HashKey), body)
+}
+
+func hashLine(key, body []byte) string {
+	if len(key) == 0 {
+		sum := sha256.Sum256(body)
+		return hex.EncodeToString(sum[:])
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// resume reads the last record of the current file. A file rotated just
+// before a restart starts a new chain; Verify accepts that at a file's start.
+func (l *Log) resume() error {
+	f, err := os.Open(l.cfg.File.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("open audit log: %w", err)
+	}
+	defer f.Close()
+	var last []byte
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		if line := bytes.TrimSpace(scanner.Bytes()); len(line) > 0 {
+			last = append(last[:0], line...)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read audit log: %w", err)
+	}
+	if last == nil {
+		return nil
+	}
+	var tail struct {
+		Seq  uint64 `json:"seq"`
+		Hash string `json:"hash"`
+	}
+	if err := json.Unmarshal(last, &tail); err != nil {
+		return fmt.Errorf("read audit log: last record: %w", err)
+	}
+	l.seq, l.prev = tail.Seq, tail.Hash
+	return nil
+}
+
+// rechain drops an actor's records from the files it is given in order and
+// renumbers and re-hashes the rest. The first record keeps its sequence
+// number and predecessor, which Verify takes on trust, and a record starting a
+// new chain passes that on to the next one kept.
+type rechain struct {
+	key   []byte
+	actor string
+
+	started bool
+	restart bool
+	seq     uint64
+	prev    string
+	removed int
+}
+
+func (c *rechain) rewrite(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	bw := bufio.NewWriter(w)
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var rec struct {
+			record
+			Hash string `json:"hash"`
+		}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return fmt.Errorf("read record: %w", err)
+		}
+		if !c.started {
+			c.started, c.seq, c.prev = true, rec.Seq-1, rec.PrevHash
+		}
+		if rec.Seq == 1 && rec.PrevHash == "" {
+			c.restart = true
+		}
+		if rec.Actor == c.actor {
+			c.removed++
+			continue
+		}
+		if c.restart {
+			c.seq, c.prev, c.restart = 0, "", false
+		}
+		out := rec.record
+		out.Seq, out.PrevHash = c.seq+1, ""
+		// Records written without the hash chain stay without it.
+		if rec.Hash != "" {
+			out.PrevHash = c.prev
+		}
+		body, err := json.Marshal(out)
+		if err != nil {
+			return err
+		}
+		var hash string
+		if rec.Hash != "" {
+			hash = hashLine(c.key, body)
+			body = append(body[:len(body)-1], `,"hash":"`+hash+`"}`...)
+		}
+		if _, err := bw.Write(append(body, '\n')); err != nil {
+			return err
+		}
+		c.seq, c.prev = out.Seq, hash
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Verify checks the hash chain of the records read from r, e.g. rotated files
+// concatenated oldest first, and returns how many it checked. The first
+// record's predecessor is taken on trust.
+func Verify(r io.Reader, key string) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<20)
+	var prev string
+	var seq uint64
+	n := 0
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		n++
+		var rec struct {
+			Seq      uint64 `json:"seq"`
+			PrevHash string `json:"prev_hash"`
+			Hash     string `json:"hash"`
+		}
+		if err := json.Unmarshal(line, &rec); err != nil {
+			return n, fmt.Errorf("record %d: %w", n, err)
+		}
+		suffix := `,"hash":"` + rec.Hash + `"}`
+		if rec.Hash == "" || !strings.HasSuffix(string(line), suffix) {
+			return n, fmt.Errorf("%w: record %d (seq %d) has no trailing hash", ErrTampered, n, rec.Seq)
+		}
+		body := append(line[:len(line)-len(suffix):len(line)-len(suffix)], '}')
+		if !hmac.Equal([]byte(hashLine([]byte(key), body)), []byte(rec.Hash)) {
+			return n, fmt.Errorf("%w: record %d (seq %d) doesn't match its hash", ErrTampered, n, rec.Seq)
+		}
+		// A new chain begins at seq 1 wherever the log restarted on a fresh
+		// file.
+		restart := rec.PrevHash == "" && rec.Seq == 1
+		if n > 1 && !restart && (rec.PrevHash != prev || rec.Seq != seq+1) {
+			return n, fmt.Errorf("%w: record %d (seq %d) doesn't follow seq %d", ErrTampered, n, rec.Seq, seq)
+		}
+		prev, seq = rec.Hash, rec.Seq
+	}
+	return n, scanner.Err()
+}
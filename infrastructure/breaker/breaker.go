@@ -0,0 +1,251 @@
+// Package breaker keeps calls away from a failing dependency. A breaker opens
+// when too many calls in a window fail, rejects calls while open, and after a
+// while lets a few probes through (half-open) to decide whether to close.
+package breaker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var ErrOpen = errors.New("circuit breaker open")
+
+var (
+	stateGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "circuit_breaker_state",
+		Help: "State of each circuit breaker: 0 closed, 1 half-open, 2 open.",
+	}, []string{"name"})
+
+	rejected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "circuit_breaker_rejected_total",
+		Help: "Calls refused by an open circuit breaker.",
+	}, []string{"name"})
+)
+
+type Config struct {
+	// FailureRatio opens a breaker once this share of the calls in Window
+	// failed, counting from MinRequests calls; zero turns breakers off.
+	FailureRatio float64
+	MinRequests  int
+	Window       time.Duration
+	// OpenTimeout is how long an open breaker rejects calls before letting
+	// HalfOpenProbes calls through; it closes once they all succeed and
+	// opens again on the first that fails.
+	OpenTimeout    time.Duration
+	HalfOpenProbes int
+}
+
+func (c Config) Enabled() bool {
+	return c.FailureRatio > 0
+}
+
+var config atomic.Pointer[Config]
+
+// Configure sets how every breaker trips. Until it is called, breakers let
+// all calls through.
+func Configure(cfg Config) {
+	if cfg.HalfOpenProbes <= 0 {
+		cfg.HalfOpenProbes = 1
+	}
+	config.Store(&cfg)
+}
+
+type State int
+
+const (
+	Closed State = iota
+	HalfOpen
+	Open
+)
+
+func (s State) String() string {
+	switch s {
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+var (
+	registryMu sync.Mutex
+	registry   = map[string]*Breaker{}
+)
+
+// For returns the breaker of the named dependency, shared by all its clients.
+func For(name string) *Breaker {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	b, ok := registry[name]
+	if !ok {
+		b = &Breaker{name: name}
+		registry[name] = b
+		stateGauge.WithLabelValues(name).Set(float64(Closed))
+	}
+	return b
+}
+
+type Breaker struct {
+	name string
+
+	mu    sync.Mutex
+	state State
+	// generation changes with the state, so results of calls let through
+	// in an earlier state are ignored.
+	generation  uint64
+	windowStart time.Time
+	requests    int
+	failures    int
+	openedAt    time.Time
+	probes      int
+	successes   int
+}
+
+type benignError struct {
+	err error
+}
+
+func (e *benignError) Error() string { return e.err.Error() }
+
+func (e *benignError) Unwrap() error { return e.err }
+
+// Benign marks an error that says nothing about the dependency's health,
+// such as a 404, so Do counts the call as a success. Do returns err unmarked.
+func Benign(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &benignError{err: err}
+}
+
+// BenignStatus marks err benign when status is a client error other than
+// 429: the request was refused, not the server failing.
+func BenignStatus(status int, err error) error {
+	if status >= 400 && status < 500 && status != http.StatusTooManyRequests {
+		return Benign(err)
+	}
+	return err
+}
+
+// Do calls fn unless the breaker is open, in which case it fails with
+// ErrOpen. Calls cancelled through ctx don't count either way.
+func (b *Breaker) Do(ctx context.Context, fn func() error) error {
+	cfg := config.Load()
+	if cfg == nil || !cfg.Enabled() {
+		return unmark(fn())
+	}
+	generation, ok := b.allow(cfg)
+	if !ok {
+		rejected.WithLabelValues(b.name).Inc()
+		return fmt.Errorf("%s: %w", b.name, ErrOpen)
+	}
+	err := fn()
+	var benign *benignError
+	switch {
+	case errors.As(err, &benign):
+		b.record(cfg, generation, true)
+		return benign.err
+	case err != nil && errors.Is(ctx.Err(), context.Canceled):
+		b.release(generation)
+	default:
+		b.record(cfg, generation, err == nil)
+	}
+	return err
+}
+
+func unmark(err error) error {
+	var benign *benignError
+	if errors.As(err, &benign) {
+		return benign.err
+	}
+	return err
+}
+
+func (b *Breaker) allow(cfg *Config) (uint64, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case Open:
+		if time.Since(b.openedAt) < cfg.OpenTimeout {
+			return 0, false
+		}
+		b.setState(HalfOpen)
+	case HalfOpen:
+		if b.probes >= cfg.HalfOpenProbes {
+			return 0, false
+		}
+	}
+	if b.state == HalfOpen {
+		b.probes++
+	}
+	return b.generation, true
+}
+
+func (b *Breaker) record(cfg *Config, generation uint64, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation != b.generation {
+		return
+	}
+	switch b.state {
+	case HalfOpen:
+		b.probes--
+		if !success {
+			b.setState(Open)
+			return
+		}
+		b.successes++
+		if b.successes >= cfg.HalfOpenProbes {
+			b.setState(Closed)
+		}
+	case Closed:
+		if now := time.Now(); now.Sub(b.windowStart) >= cfg.Window {
+			b.windowStart, b.requests, b.failures = now, 0, 0
+		}
+		b.requests++
+		if !success {
+			b.failures++
+		}
+		if b.requests >= cfg.MinRequests && float64(b.failures) >= cfg.FailureRatio*float64(b.requests) {
+			b.setState(Open)
+		}
+	}
+}
+
+// release frees the probe slot of a call that didn't count.
+func (b *Breaker) release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if generation == b.generation && b.state == HalfOpen {
+		b.probes--
+	}
+}
+
+// setState moves to state and starts it afresh; b.mu must be held.
+func (b *Breaker) setState(state State) {
+	switch {
+	case state == Open && b.state == HalfOpen:
+		log.Printf("Circuit breaker %s opened again: a probe failed\n", b.name)
+	case state == Open:
+		log.Printf("Circuit breaker %s opened after %d of %d calls failed\n", b.name, b.failures, b.requests)
+	default:
+		log.Printf("Circuit breaker %s is %s\n", b.name, state)
+	}
+	b.state = state
+	b.generation++
+	b.windowStart, b.requests, b.failures = time.Now(), 0, 0
+	b.openedAt = time.Now()
+	b.probes, b.successes = 0, 0
+	stateGauge.WithLabelValues(b.name).Set(float64(state))
+}
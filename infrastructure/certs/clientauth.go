@@ -0,0 +1,60 @@
+package certs
+
+import (
+	"crypto/x509"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// VerifyClient checks that a request to one of the configured paths came over
+// TLS with a verified client certificate whose SANs are allowed. Requests to
+// other paths, or any request when mTLS is off, pass.
+func (m *Manager) VerifyClient(r *http.Request) error {
+	if m.cfg.ClientCAFile == "" || !m.protected(r.URL.Path) {
+		return nil
+	}
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 {
+		return domain.ErrClientCertRequired
+	}
+	leaf := r.TLS.VerifiedChains[0][0]
+	if !m.allowedSAN(leaf) {
+		log.Printf("Rejected client certificate %q for %s: SAN not allowed\n", leaf.Subject.CommonName, r.URL.Path)
+		return domain.ErrClientCertNotAllowed
+	}
+	return nil
+}
+
+func (m *Manager) protected(p string) bool {
+	for _, prefix := range m.cfg.ClientCertPaths {
+		if strings.HasPrefix(p, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *Manager) allowedSAN(cert *x509.Certificate) bool {
+	if len(m.cfg.ClientSANs) == 0 {
+		return true
+	}
+	sans := append([]string(nil), cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, u := range cert.URIs {
+		sans = append(sans, u.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, pattern := range m.cfg.ClientSANs {
+		for _, san := range sans {
+			if ok, _ := path.Match(pattern, san); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
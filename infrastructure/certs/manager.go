@@ -0,0 +1,210 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+type Config struct {
+	CertFile string
+	KeyFile  string
+	// ReloadInterval is how often the cert and key files are checked for changes.
+	ReloadInterval time.Duration
+
+	// ACMEDomains enables automatic certificates (e.g. Let's Encrypt) for these hosts.
+	ACMEDomains      []string
+	ACMEEmail        string
+	ACMECacheDir     string
+	ACMEDirectoryURL string
+
+	// ClientCAFile enables client certificates: when set, ClientCertPaths only
+	// accept callers presenting a certificate signed by these CAs whose SANs
+	// match one of ClientSANs (path.Match patterns; empty allows any).
+	ClientCAFile    string
+	ClientCertPaths []string
+	ClientSANs      []string
+}
+
+func (c Config) Enabled() bool {
+	return c.CertFile != "" || len(c.ACMEDomains) > 0
+}
+
+// Manager serves the server certificate from files, reloading them when they
+// change, or from an ACME provider.
+type Manager struct {
+	cfg  Config
+	acme *autocert.Manager
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+	modTime   time.Time
+}
+
+func NewManager(cfg Config) (*Manager, error) {
+	m := &Manager{cfg: cfg}
+	if err := m.loadClientCAs(); err != nil {
+		return nil, err
+	}
+	if len(cfg.ACMEDomains) > 0 {
+		if cfg.CertFile != "" {
+			return nil, fmt.Errorf("tls: configure either certificate files or ACME domains, not both")
+		}
+		m.acme = &autocert.Manager{
+			Prompt:     autocert.AcceptTOS,
+			HostPolicy: autocert.HostWhitelist(cfg.ACMEDomains...),
+			Cache:      autocert.DirCache(cfg.ACMECacheDir),
+			Email:      cfg.ACMEEmail,
+		}
+		if cfg.ACMEDirectoryURL != "" {
+			m.acme.Client = &acme.Client{DirectoryURL: cfg.ACMEDirectoryURL}
+		}
+		return m, nil
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return nil, fmt.Errorf("tls: both certificate and key files are required")
+	}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// TLSConfig returns a server config that always presents the current
+// certificate and, with a client CA configured, verifies client certificates.
+func (m *Manager) TLSConfig() *tls.Config {
+	var cfg *tls.Config
+	if m.acme != nil {
+		cfg = m.acme.TLSConfig()
+	} else {
+		cfg = &tls.Config{GetCertificate: m.getCertificate}
+	}
+	cfg.MinVersion = tls.VersionTLS12
+	if m.cfg.ClientCAFile == "" {
+		return cfg
+	}
+	// Certificates are only required on some paths, which VerifyClient enforces.
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+	cfg.GetConfigForClient = func(*tls.ClientHelloInfo) (*tls.Config, error) {
+		c := cfg.Clone()
+		m.mu.RLock()
+		c.ClientCAs = m.clientCAs
+		m.mu.RUnlock()
+		return c, nil
+	}
+	return cfg
+}
+
+// HTTPHandler answers ACME HTTP-01 challenges and redirects everything else to HTTPS.
+func (m *Manager) HTTPHandler() http.Handler {
+	if m.acme == nil {
+		return nil
+	}
+	return m.acme.HTTPHandler(nil)
+}
+
+func (m *Manager) getCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.cert, nil
+}
+
+// Reload reads the certificate and client CA files again; on error the current
+// ones stay in use.
+func (m *Manager) Reload() error {
+	if err := m.loadClientCAs(); err != nil {
+		return err
+	}
+	if m.acme != nil {
+		return nil
+	}
+	modTime, err := m.filesModTime()
+	if err != nil {
+		return fmt.Errorf("tls: %w", err)
+	}
+	cert, err := tls.LoadX509KeyPair(m.cfg.CertFile, m.cfg.KeyFile)
+	if err != nil {
+		return fmt.Errorf("tls: load key pair: %w", err)
+	}
+	m.mu.Lock()
+	m.cert, m.modTime = &cert, modTime
+	m.mu.Unlock()
+	return nil
+}
+
+func (m *Manager) loadClientCAs() error {
+	if m.cfg.ClientCAFile == "" {
+		return nil
+	}
+	data, err := os.ReadFile(m.cfg.ClientCAFile)
+	if err != nil {
+		return fmt.Errorf("tls: client CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return fmt.Errorf("tls: client CA %s contains no certificates", m.cfg.ClientCAFile)
+	}
+	m.mu.Lock()
+	m.clientCAs = pool
+	m.mu.Unlock()
+	return nil
+}
+
+// Watch reloads the certificate whenever the files change, until ctx ends.
+func (m *Manager) Watch(ctx context.Context) {
+	if m.acme != nil || m.cfg.ReloadInterval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(m.cfg.ReloadInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !m.changed() {
+			continue
+		}
+		if err := m.Reload(); err != nil {
+			log.Printf("Reloading TLS certificate failed: %v\n", err)
+			continue
+		}
+		log.Println("TLS certificate reloaded")
+	}
+}
+
+func (m *Manager) changed() bool {
+	modTime, err := m.filesModTime()
+	if err != nil {
+		return false
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return modTime.After(m.modTime)
+}
+
+// filesModTime is the latest modification time of the cert and key files.
+func (m *Manager) filesModTime() (time.Time, error) {
+	var latest time.Time
+	for _, path := range []string{m.cfg.CertFile, m.cfg.KeyFile} {
+		info, err := os.Stat(path)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
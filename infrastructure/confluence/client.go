@@ -0,0 +1,189 @@
+package confluence
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/breaker"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+	"github.com/shubhamgptln/sarama-ai/pkg/htmltext"
+)
+
+type Config struct {
+	BaseURL  string
+	Email    string
+	APIToken string
+	Timeout  time.Duration
+}
+
+// Client reads pages from the Confluence REST API. With an email configured it
+// uses Cloud basic auth, otherwise the token is sent as a Data Center PAT.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func NewClient(cfg Config) *Client {
+	c := &Client{cfg: cfg}
+	c.httpClient = &http.Client{Timeout: cfg.Timeout, Transport: tracing.Transport(nil), CheckRedirect: c.checkRedirect}
+	return c
+}
+
+type contentResponse struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+	Space struct {
+		Key string `json:"key"`
+	} `json:"space"`
+	Version struct {
+		Number int       `json:"number"`
+		When   time.Time `json:"when"`
+	} `json:"version"`
+	Body struct {
+		Storage struct {
+			Value string `json:"value"`
+		} `json:"storage"`
+	} `json:"body"`
+	Metadata struct {
+		Labels struct {
+			Results []struct {
+				Name string `json:"name"`
+			} `json:"results"`
+		} `json:"labels"`
+	} `json:"metadata"`
+	Restrictions readRestrictions `json:"restrictions"`
+	Ancestors    []struct {
+		Restrictions readRestrictions `json:"restrictions"`
+	} `json:"ancestors"`
+	Links struct {
+		Base  string `json:"base"`
+		WebUI string `json:"webui"`
+	} `json:"_links"`
+}
+
+func (c *Client) GetDocument(ctx context.Context, id string) (*domain.Document, error) {
+	query := url.Values{"expand": {"body.storage,version,space,metadata.labels," + restrictionsExpand}}
+	var resp contentResponse
+	if err := c.get(ctx, "/rest/api/content/"+url.PathEscape(id), query, &resp); err != nil {
+		return nil, err
+	}
+	doc := c.document(resp)
+	doc.Body = htmltext.ExtractString(resp.Body.Storage.Value)
+	doc.Images = c.siteImages(extractImages(strings.NewReader(resp.Body.Storage.Value)))
+	return doc, nil
+}
+
+// document is the page of resp without its body.
+func (c *Client) document(resp contentResponse) *domain.Document {
+	doc := &domain.Document{
+		ID:        resp.ID,
+		SpaceKey:  resp.Space.Key,
+		Title:     resp.Title,
+		URL:       c.pageURL(resp.Links.Base, resp.Links.WebUI),
+		Version:   resp.Version.Number,
+		UpdatedAt: resp.Version.When,
+	}
+	for _, l := range resp.Metadata.Labels.Results {
+		doc.Labels = append(doc.Labels, l.Name)
+	}
+	levels := []readRestrictions{resp.Restrictions}
+	for _, a := range resp.Ancestors {
+		levels = append(levels, a.Restrictions)
+	}
+	doc.Readers = pageReaders(levels)
+	return doc
+}
+
+func (c *Client) pageURL(base, webui string) string {
+	if webui == "" {
+		return ""
+	}
+	if base == "" {
+		base = strings.TrimRight(c.cfg.BaseURL, "/")
+	}
+	return base + webui
+}
+
+func (c *Client) get(ctx context.Context, path string, query url.Values, out any) error {
+	if len(query) > 0 {
+		path += "?" + query.Encode()
+	}
+	return c.do(ctx, http.MethodGet, path, nil, out)
+}
+
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	return breaker.For("confluence").Do(ctx, func() error { return c.send(ctx, method, path, in, out) })
+}
+
+func (c *Client) send(ctx context.Context, method, path string, in, out any) error {
+	resp, err := c.response(ctx, method, path, in)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if out == nil {
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// response sends a request and returns the response when it succeeded; its
+// body is the caller's to close.
+func (c *Client) response(ctx context.Context, method, path string, in any) (*http.Response, error) {
+	var body io.Reader
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return nil, err
+		}
+		body = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(c.cfg.BaseURL, "/")+path, body)
+	if err != nil {
+		return nil, err
+	}
+	c.authorize(req)
+	req.Header.Set("Accept", "application/json")
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("confluence %s %s: %w", method, path, err)
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, breaker.Benign(domain.ErrNotFound)
+	}
+	if resp.StatusCode/100 != 2 {
+		defer resp.Body.Close()
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, breaker.BenignStatus(resp.StatusCode,
+			fmt.Errorf("confluence %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg))))
+	}
+	return resp, nil
+}
+
+// Ping fetches the current user, which checks both reachability and the credentials.
+func (c *Client) Ping(ctx context.Context) error {
+	var user struct{}
+	return c.get(ctx, "/rest/api/user/current", nil, &user)
+}
+
+func (c *Client) authorize(req *http.Request) {
+	switch {
+	case c.cfg.Email != "":
+		req.SetBasicAuth(c.cfg.Email, c.cfg.APIToken)
+	case c.cfg.APIToken != "":
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIToken)
+	}
+}
@@ -0,0 +1,186 @@
+package confluence
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// diagramMacros maps diagram macros to the parameter naming their PNG export attachment.
+var diagramMacros = map[string]string{
+	"drawio": "diagramName",
+	"gliffy": "name",
+}
+
+// extractImages lists the <ac:image> references and diagram macro renderings in
+// Confluence storage format.
+func extractImages(storage io.Reader) []domain.DocumentImage {
+	dec := xml.NewDecoder(storage)
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	var images []domain.DocumentImage
+	var current *domain.DocumentImage
+	var macroParam, param string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "image":
+				current = &domain.DocumentImage{Alt: attr(t, "alt")}
+			case "attachment":
+				if current != nil {
+					current.Filename = attr(t, "filename")
+				}
+			case "url":
+				if current != nil {
+					current.URL = attr(t, "value")
+				}
+			case "structured-macro":
+				macroParam = diagramMacros[attr(t, "name")]
+			case "parameter":
+				param = attr(t, "name")
+			}
+		case xml.CharData:
+			if macroParam != "" && param == macroParam {
+				if name := strings.TrimSpace(string(t)); name != "" {
+					images = append(images, domain.DocumentImage{Filename: name + ".png", Alt: name})
+				}
+				macroParam = ""
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "image":
+				if current != nil && (current.Filename != "" || current.URL != "") {
+					images = append(images, *current)
+				}
+				current = nil
+			case "parameter":
+				param = ""
+			case "structured-macro":
+				macroParam = ""
+			}
+		}
+	}
+	return images
+}
+
+func attr(el xml.StartElement, name string) string {
+	for _, a := range el.Attr {
+		if a.Name.Local == name {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// ErrOffSite is returned for images, or redirects, off the Confluence site:
+// fetching what a page links to would let any editor point the connector, and
+// its credentials, at internal or third-party hosts.
+var ErrOffSite = errors.New("not on the Confluence site")
+
+// FetchImage downloads an attachment, or an image linked on the Confluence
+// site itself, reading at most maxBytes.
+func (c *Client) FetchImage(ctx context.Context, documentID string, img domain.DocumentImage, maxBytes int64) ([]byte, string, error) {
+	u, err := c.imageURL(documentID, img)
+	if err != nil {
+		return nil, "", fmt.Errorf("download image %s: %w", img.Name(), err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("download image %s: %w", img.Name(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("download image %s: status %d", img.Name(), resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
+	if err != nil {
+		return nil, "", err
+	}
+	if int64(len(data)) > maxBytes {
+		return nil, "", fmt.Errorf("image %s exceeds %d bytes", img.Name(), maxBytes)
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = mime.TypeByExtension(path.Ext(img.Name()))
+	}
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = strings.TrimSpace(contentType[:i])
+	}
+	return data, contentType, nil
+}
+
+// siteImages drops the images FetchImage would refuse.
+func (c *Client) siteImages(images []domain.DocumentImage) []domain.DocumentImage {
+	return slices.DeleteFunc(images, func(img domain.DocumentImage) bool {
+		_, err := c.imageURL("", img)
+		return err != nil
+	})
+}
+
+// imageURL returns where img is downloaded from: the attachment of the page,
+// or its URL when that is on the Confluence site.
+func (c *Client) imageURL(documentID string, img domain.DocumentImage) (string, error) {
+	base := strings.TrimRight(c.cfg.BaseURL, "/")
+	if img.URL == "" {
+		return base + "/download/attachments/" + url.PathEscape(documentID) + "/" + url.PathEscape(img.Filename), nil
+	}
+	u, err := url.Parse(img.URL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrOffSite, err)
+	}
+	if site, err := url.Parse(base); err == nil {
+		// Site-relative links, as Confluence writes for its own files.
+		u = site.ResolveReference(u)
+	}
+	if !c.onSite(u) {
+		return "", fmt.Errorf("%w: %s", ErrOffSite, u.Host)
+	}
+	return u.String(), nil
+}
+
+// onSite reports whether u is on the configured Confluence host.
+func (c *Client) onSite(u *url.URL) bool {
+	site, err := url.Parse(c.cfg.BaseURL)
+	if err != nil {
+		return false
+	}
+	return u.Scheme == site.Scheme && strings.EqualFold(u.Host, site.Host)
+}
+
+// checkRedirect keeps requests, and the credentials they carry, on the
+// Confluence host.
+func (c *Client) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= 10 {
+		return errors.New("stopped after 10 redirects")
+	}
+	if !c.onSite(req.URL) {
+		return fmt.Errorf("redirect to %s: %w", req.URL.Host, ErrOffSite)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package confluence
+
+import (
+	"context"
+	"net/url"
+	"slices"
+	"strconv"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// restrictionsExpand fetches read restrictions of the page and its ancestors,
+// which Confluence applies on top of each other.
+const restrictionsExpand = "restrictions.read.restrictions.user,restrictions.read.restrictions.group," +
+	"ancestors.restrictions.read.restrictions.user,ancestors.restrictions.read.restrictions.group"
+
+type user struct {
+	AccountID string `json:"accountId"`
+	Username  string `json:"username"`
+	UserKey   string `json:"userKey"`
+	Email     string `json:"email"`
+}
+
+type subjects struct {
+	User struct {
+		Results []user `json:"results"`
+	} `json:"user"`
+	Group struct {
+		Results []struct {
+			Name string `json:"name"`
+		} `json:"results"`
+	} `json:"group"`
+}
+
+type readRestrictions struct {
+	Read struct {
+		Restrictions subjects `json:"restrictions"`
+	} `json:"read"`
+}
+
+// readers lists every identifier a subject may be matched by.
+func (s subjects) readers() []string {
+	var readers []string
+	for _, u := range s.User.Results {
+		for _, id := range []string{u.AccountID, u.Username, u.UserKey, u.Email} {
+			if id != "" {
+				readers = append(readers, domain.UserReader(id))
+			}
+		}
+	}
+	for _, g := range s.Group.Results {
+		readers = append(readers, domain.GroupReader(g.Name))
+	}
+	return readers
+}
+
+// pageReaders combines the restricted levels of a page conservatively: a reader
+// must be listed at every level, so users who only qualify through different
+// groups at different levels are denied rather than let through.
+func pageReaders(levels []readRestrictions) []string {
+	var readers []string
+	restricted := false
+	for _, level := range levels {
+		listed := level.Read.Restrictions.readers()
+		if len(listed) == 0 {
+			continue
+		}
+		if !restricted {
+			readers, restricted = listed, true
+			continue
+		}
+		readers = slices.DeleteFunc(readers, func(r string) bool { return !slices.Contains(listed, r) })
+	}
+	if restricted && len(readers) == 0 {
+		return []string{domain.ReaderNobody}
+	}
+	return readers
+}
+
+type spacesResponse struct {
+	Results []struct {
+		Key         string `json:"key"`
+		Permissions []struct {
+			Operation struct {
+				Operation  string `json:"operation"`
+				TargetType string `json:"targetType"`
+			} `json:"operation"`
+			Subjects        subjects `json:"subjects"`
+			AnonymousAccess bool     `json:"anonymousAccess"`
+		} `json:"permissions"`
+	} `json:"results"`
+	Size int `json:"size"`
+}
+
+// SpaceReaders lists, for every space, the users and groups with read access.
+func (c *Client) SpaceReaders(ctx context.Context) (map[string][]string, error) {
+	const limit = 50
+	spaces := make(map[string][]string)
+	for start := 0; ; start += limit {
+		query := url.Values{
+			"expand": {"permissions"},
+			"limit":  {strconv.Itoa(limit)},
+			"start":  {strconv.Itoa(start)},
+		}
+		var resp spacesResponse
+		if err := c.get(ctx, "/rest/api/space", query, &resp); err != nil {
+			return nil, err
+		}
+		for _, space := range resp.Results {
+			readers := []string{}
+			for _, p := range space.Permissions {
+				if p.Operation.Operation != "read" || p.Operation.TargetType != "space" {
+					continue
+				}
+				if p.AnonymousAccess {
+					readers = append(readers, domain.ReaderAnyone)
+				}
+				readers = append(readers, p.Subjects.readers()...)
+			}
+			spaces[space.Key] = readers
+		}
+		if resp.Size < limit {
+			return spaces, nil
+		}
+	}
+}
@@ -0,0 +1,245 @@
+package confluence
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/breaker"
+	"github.com/shubhamgptln/sarama-ai/pkg/htmltext"
+)
+
+// storagePath is where a page's storage format sits in a content response.
+var storagePath = []string{"body", "storage", "value"}
+
+// StreamDocument fetches the page and then its body, which is decoded from the
+// response and turned into text as it is read. The page is requested twice,
+// as the body may come before the fields needed to index it.
+func (c *Client) StreamDocument(ctx context.Context, id string) (*domain.Document, io.ReadCloser, error) {
+	path := "/rest/api/content/" + url.PathEscape(id)
+	var resp contentResponse
+	if err := c.get(ctx, path, url.Values{"expand": {"version,space,metadata.labels," + restrictionsExpand}}, &resp); err != nil {
+		return nil, nil, err
+	}
+	doc := c.document(resp)
+
+	body, err := c.open(ctx, path+"?"+url.Values{"expand": {"body.storage"}}.Encode())
+	if err != nil {
+		return nil, nil, err
+	}
+	r := bufio.NewReaderSize(body, 64<<10)
+	if err := seekString(r, storagePath); err != nil {
+		body.Close()
+		return nil, nil, fmt.Errorf("confluence page %s: %w", id, err)
+	}
+
+	text, w := io.Pipe()
+	go func() {
+		defer body.Close()
+		// Images are listed from a copy of the markup as it goes by.
+		images := make(chan []domain.DocumentImage, 1)
+		markup, tee := io.Pipe()
+		go func() {
+			found := extractImages(markup)
+			io.Copy(io.Discard, markup)
+			images <- found
+		}()
+		err := htmltext.ExtractTo(w, io.TeeReader(&jsonString{r: r}, tee))
+		tee.CloseWithError(err)
+		doc.Images = c.siteImages(<-images)
+		w.CloseWithError(err)
+	}()
+	return doc, text, nil
+}
+
+// open sends a GET and returns the body of its successful response.
+func (c *Client) open(ctx context.Context, path string) (io.ReadCloser, error) {
+	var body io.ReadCloser
+	err := breaker.For("confluence").Do(ctx, func() error {
+		resp, err := c.response(ctx, http.MethodGet, path, nil)
+		if err != nil {
+			return err
+		}
+		body = resp.Body
+		return nil
+	})
+	return body, err
+}
+
+var errNoStorage = errors.New("no storage format in response")
+
+// seekString reads r up to the string value at path in a JSON document,
+// leaving r after its opening quote. Other strings are skipped, not kept.
+func seekString(r *bufio.Reader, path []string) error {
+	// keys holds the key of each open object or array and open whether it is
+	// an object, key the last key read.
+	var keys []string
+	var open []bool
+	var key string
+	colon := false
+	for {
+		c, err := r.ReadByte()
+		if err == io.EOF {
+			return errNoStorage
+		}
+		if err != nil {
+			return err
+		}
+		switch c {
+		case '{', '[':
+			keys = append(keys, key)
+			open = append(open, c == '{')
+			key = ""
+		case '}', ']':
+			if len(keys) > 0 {
+				keys, open = keys[:len(keys)-1], open[:len(open)-1]
+			}
+			key = ""
+		case '"':
+			if colon && len(keys) == len(path) && slices.Equal(keys[1:], path[:len(path)-1]) && key == path[len(path)-1] {
+				return nil
+			}
+			s, err := skipString(r)
+			if err != nil {
+				return err
+			}
+			if !colon && len(open) > 0 && open[len(open)-1] {
+				key = s
+			}
+		}
+		switch c {
+		case ':':
+			colon = true
+		case ' ', '\t', '\n', '\r':
+		default:
+			colon = false
+		}
+	}
+}
+
+// skipString reads the rest of a string, returning at most its first bytes.
+func skipString(r *bufio.Reader) (string, error) {
+	var head []byte
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return "", err
+		}
+		switch c {
+		case '"':
+			return string(head), nil
+		case '\\':
+			if c, err = r.ReadByte(); err != nil {
+				return "", err
+			}
+		}
+		if len(head) < 64 {
+			head = append(head, c)
+		}
+	}
+}
+
+// jsonString reads a JSON string from r, the opening quote already read,
+// unescaping it as it goes.
+type jsonString struct {
+	r       *bufio.Reader
+	pending []byte
+	done    bool
+}
+
+func (s *jsonString) Read(p []byte) (int, error) {
+	n := 0
+	for n < len(p) {
+		if len(s.pending) > 0 {
+			k := copy(p[n:], s.pending)
+			s.pending = s.pending[k:]
+			n += k
+			continue
+		}
+		if s.done {
+			break
+		}
+		c, err := s.r.ReadByte()
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		if err != nil {
+			return n, err
+		}
+		switch c {
+		case '"':
+			s.done = true
+		case '\\':
+			r, err := s.escape()
+			if err != nil {
+				return n, err
+			}
+			s.pending = utf8.AppendRune(s.pending[:0], r)
+		default:
+			p[n] = c
+			n++
+		}
+	}
+	if n == 0 && s.done {
+		return 0, io.EOF
+	}
+	return n, nil
+}
+
+func (s *jsonString) escape() (rune, error) {
+	c, err := s.r.ReadByte()
+	if err != nil {
+		return 0, err
+	}
+	switch c {
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		r, err := s.hex()
+		if err != nil || !utf16.IsSurrogate(r) {
+			return r, err
+		}
+		// A surrogate pair is written as two escapes.
+		if next, _ := s.r.Peek(2); string(next) != `\u` {
+			return utf8.RuneError, nil
+		}
+		s.r.Discard(2)
+		low, err := s.hex()
+		if err != nil {
+			return 0, err
+		}
+		return utf16.DecodeRune(r, low), nil
+	default:
+		// \" \\ \/
+		return rune(c), nil
+	}
+}
+
+func (s *jsonString) hex() (rune, error) {
+	var digits [4]byte
+	if _, err := io.ReadFull(s.r, digits[:]); err != nil {
+		return 0, err
+	}
+	v, err := strconv.ParseUint(string(digits[:]), 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("bad \\u escape %q", digits[:])
+	}
+	return rune(v), nil
+}
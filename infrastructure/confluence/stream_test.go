@@ -0,0 +1,119 @@
+package confluence
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// storage exercises the JSON escapes a page body comes with: quotes,
+// backslashes, slashes, control characters and surrogate pairs.
+const storage = `<h1>Runbook "prod"</h1><p>Paths: C:\temp\ and /var/log/ &amp; más 😀 日本</p>` +
+	"<p>tab\there\r\nnext\u2028line</p>" +
+	`<ac:image ac:alt="arch"><ri:attachment ri:filename="arch.png"/></ac:image>` +
+	`<ac:image><ri:url ri:value="https://elsewhere.example/x.png"/></ac:image>` +
+	`<ul><li>one</li><li>two</li></ul><table><tr><td>a</td><td>b</td></tr></table>`
+
+func confluenceServer(t *testing.T, body string) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/rest/api/content/42" {
+			http.NotFound(w, r)
+			return
+		}
+		page := map[string]any{
+			// Fields that look like the body path but aren't.
+			"value":    "not the body",
+			"labels":   []any{"body", "storage", map[string]any{"value": "nested"}},
+			"id":       "42",
+			"title":    `Runbook "prod"`,
+			"space":    map[string]any{"key": "OPS"},
+			"version":  map[string]any{"number": 7, "when": "2026-01-02T03:04:05Z"},
+			"metadata": map[string]any{"labels": map[string]any{"results": []any{map[string]any{"name": "runbook"}}}},
+			"_links":   map[string]any{"base": "https://wiki.example", "webui": "/pages/42"},
+		}
+		if strings.Contains(r.URL.Query().Get("expand"), "body.storage") {
+			page["body"] = map[string]any{"storage": map[string]any{"value": body, "representation": "storage"}}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(page)
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestStreamDocumentMatchesGetDocument(t *testing.T) {
+	for name, body := range map[string]string{
+		"page":      storage,
+		"empty":     "",
+		"malformed": "<p>one</p><p>two < three</p><p>four",
+	} {
+		t.Run(name, func(t *testing.T) {
+			srv := confluenceServer(t, body)
+			c := NewClient(Config{BaseURL: srv.URL})
+			ctx := context.Background()
+
+			want, err := c.GetDocument(ctx, "42")
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc, text, err := c.StreamDocument(ctx, "42")
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := io.ReadAll(text)
+			text.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+			doc.Body = string(b)
+			if !reflect.DeepEqual(doc, want) {
+				t.Errorf("streamed document = %+v\nwant %+v", doc, want)
+			}
+		})
+	}
+}
+
+func TestJSONStringUnescapes(t *testing.T) {
+	for _, want := range []string{"", `a"b\c/d`, "\b\f\n\r\t\x01", "é😀\u2028日本", strings.Repeat("x\"", 70000)} {
+		encoded, err := json.Marshal(want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := &jsonString{r: bufio.NewReader(strings.NewReader(string(encoded[1:]) + `,"next":1}`))}
+		got, err := io.ReadAll(r)
+		if err != nil {
+			t.Fatalf("read %s: %v", encoded, err)
+		}
+		if string(got) != want {
+			t.Errorf("decoded %q, want %q", got, want)
+		}
+	}
+}
+
+func TestJSONStringSurrogatePairs(t *testing.T) {
+	r := &jsonString{r: bufio.NewReader(strings.NewReader(`\ud83d\ude00 \u00e9\ud83d"`))}
+	got, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "😀 é\uFFFD"; string(got) != want {
+		t.Errorf("decoded %q, want %q", got, want)
+	}
+}
+
+func TestStreamDocumentNotFound(t *testing.T) {
+	srv := confluenceServer(t, storage)
+	c := NewClient(Config{BaseURL: srv.URL})
+	if _, _, err := c.StreamDocument(context.Background(), "43"); !errors.Is(err, domain.ErrNotFound) {
+		t.Fatalf("StreamDocument of a missing page: %v, want ErrNotFound", err)
+	}
+}
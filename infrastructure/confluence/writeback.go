@@ -0,0 +1,67 @@
+package confluence
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type storageBody struct {
+	Storage struct {
+		Value          string `json:"value"`
+		Representation string `json:"representation"`
+	} `json:"storage"`
+}
+
+type commentRequest struct {
+	Type      string `json:"type"`
+	Container struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+	} `json:"container"`
+	Body storageBody `json:"body"`
+}
+
+type property struct {
+	Key     string           `json:"key"`
+	Value   any              `json:"value"`
+	Version *propertyVersion `json:"version,omitempty"`
+}
+
+type propertyVersion struct {
+	Number int `json:"number"`
+}
+
+// AddComment posts a footer comment in storage format on a page and returns
+// the comment's URL.
+func (c *Client) AddComment(ctx context.Context, pageID, storage string) (string, error) {
+	req := commentRequest{Type: "comment"}
+	req.Container.ID, req.Container.Type = pageID, "page"
+	req.Body.Storage.Value, req.Body.Storage.Representation = storage, "storage"
+	var resp contentResponse
+	if err := c.do(ctx, http.MethodPost, "/rest/api/content", req, &resp); err != nil {
+		return "", err
+	}
+	return c.pageURL(resp.Links.Base, resp.Links.WebUI), nil
+}
+
+// SetProperty creates or replaces a content property of a page.
+func (c *Client) SetProperty(ctx context.Context, pageID, key string, value any) error {
+	path := "/rest/api/content/" + url.PathEscape(pageID) + "/property"
+	var current property
+	err := c.get(ctx, path+"/"+url.PathEscape(key), nil, &current)
+	if errors.Is(err, domain.ErrNotFound) {
+		return c.do(ctx, http.MethodPost, path, property{Key: key, Value: value}, nil)
+	}
+	if err != nil {
+		return err
+	}
+	version := propertyVersion{Number: 1}
+	if current.Version != nil {
+		version.Number = current.Version.Number + 1
+	}
+	return c.do(ctx, http.MethodPut, path+"/"+url.PathEscape(key), property{Key: key, Value: value, Version: &version}, nil)
+}
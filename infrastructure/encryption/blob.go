@@ -0,0 +1,101 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// BlobStore encrypts the blobs of the store it wraps. Blobs stored before
+// encryption was turned on are still read; Rotate encrypts them.
+type BlobStore struct {
+	store    domain.BlobStore
+	envelope *Envelope
+}
+
+func NewBlobStore(store domain.BlobStore, envelope *Envelope) *BlobStore {
+	return &BlobStore{store: store, envelope: envelope}
+}
+
+// RotateResult counts the blobs one rotation looked at and rewrote.
+type RotateResult struct {
+	Blobs     int `json:"blobs"`
+	Rewrapped int `json:"rewrapped"`
+	Encrypted int `json:"encrypted"`
+}
+
+func (b *BlobStore) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	sealed, err := b.envelope.Seal(ctx, data)
+	if err != nil {
+		return fmt.Errorf("encrypt %s: %w", key, err)
+	}
+	return b.store.Put(ctx, key, sealed, contentType)
+}
+
+func (b *BlobStore) Get(ctx context.Context, key string) ([]byte, string, error) {
+	data, contentType, err := b.store.Get(ctx, key)
+	if err != nil {
+		return nil, "", err
+	}
+	if data, err = b.envelope.Open(ctx, data); err != nil {
+		return nil, "", fmt.Errorf("decrypt %s: %w", key, err)
+	}
+	return data, contentType, nil
+}
+
+// List reports the sizes of the stored, encrypted blobs.
+func (b *BlobStore) List(ctx context.Context, prefix string) ([]domain.Blob, error) {
+	return b.store.List(ctx, prefix)
+}
+
+func (b *BlobStore) Delete(ctx context.Context, key string) error {
+	return b.store.Delete(ctx, key)
+}
+
+// Rotate rewraps the data key of every blob wrapped with another master key
+// than the current one and encrypts the blobs that aren't, carrying on past
+// failures. Once it succeeds, older master keys can be retired.
+func (b *BlobStore) Rotate(ctx context.Context) (RotateResult, error) {
+	var res RotateResult
+	blobs, err := b.store.List(ctx, "")
+	if err != nil {
+		return res, fmt.Errorf("list blobs: %w", err)
+	}
+	rewrapped := map[string][]byte{}
+	var errs []error
+	for _, blob := range blobs {
+		if ctx.Err() != nil {
+			return res, errors.Join(append(errs, ctx.Err())...)
+		}
+		res.Blobs++
+		data, contentType, err := b.store.Get(ctx, blob.Key)
+		if errors.Is(err, domain.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("read %s: %w", blob.Key, err))
+			continue
+		}
+		sealed := Sealed(data)
+		out, changed, err := b.envelope.Rewrap(ctx, data, rewrapped)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("rewrap %s: %w", blob.Key, err))
+			continue
+		}
+		if !changed {
+			continue
+		}
+		if err := b.store.Put(ctx, blob.Key, out, contentType); err != nil {
+			errs = append(errs, fmt.Errorf("write %s: %w", blob.Key, err))
+			continue
+		}
+		if sealed {
+			res.Rewrapped++
+		} else {
+			res.Encrypted++
+		}
+	}
+	return res, errors.Join(errs...)
+}
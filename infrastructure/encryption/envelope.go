@@ -0,0 +1,227 @@
+// Package encryption encrypts data at rest with envelope encryption: each
+// payload is sealed with a data key, and the data key is stored beside it
+// wrapped by a master key that never leaves its KeyProvider. Rotating the
+// master key only rewraps data keys; payloads aren't re-encrypted.
+package encryption
+
+import (
+	"bytes"
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var ErrMalformed = errors.New("malformed encrypted payload")
+
+// magic starts every sealed payload, so data stored before encryption was
+// turned on is told apart and read as is.
+var magic = []byte("SRE\x01")
+
+// dataKeyTTL is how long one data key seals new payloads, so a KMS isn't
+// called for every write.
+const dataKeyTTL = 5 * time.Minute
+
+// maxUnwrapped bounds the cache of unwrapped data keys.
+const maxUnwrapped = 1024
+
+// KeyProvider wraps data keys with a master key. KeyID names the master key
+// new data keys are wrapped with; Unwrap is given the ID a key was wrapped
+// with, which may be an older one.
+type KeyProvider interface {
+	KeyID() string
+	Wrap(ctx context.Context, dataKey []byte) ([]byte, error)
+	Unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// Envelope seals and opens payloads with data keys wrapped by keys.
+type Envelope struct {
+	keys KeyProvider
+
+	mu        sync.Mutex
+	current   *dataKey
+	unwrapped map[string][]byte
+}
+
+type dataKey struct {
+	keyID   string
+	plain   []byte
+	wrapped []byte
+	created time.Time
+}
+
+func NewEnvelope(keys KeyProvider) *Envelope {
+	return &Envelope{keys: keys, unwrapped: map[string][]byte{}}
+}
+
+// Sealed reports whether data was sealed by an Envelope.
+func Sealed(data []byte) bool {
+	return bytes.HasPrefix(data, magic)
+}
+
+// Seal encrypts plaintext with AES-256-GCM under the current data key.
+func (e *Envelope) Seal(ctx context.Context, plaintext []byte) ([]byte, error) {
+	key, err := e.dataKey(ctx)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(key.plain)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return encode(key.keyID, key.wrapped, aead.Seal(nonce, nonce, plaintext, magic)), nil
+}
+
+// Open decrypts data sealed by Seal; data that isn't sealed is returned as is.
+func (e *Envelope) Open(ctx context.Context, data []byte) ([]byte, error) {
+	if !Sealed(data) {
+		return data, nil
+	}
+	keyID, wrapped, sealed, err := decode(data)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := e.unwrap(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newGCM(plain)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	out, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], magic)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt payload: %w", err)
+	}
+	return out, nil
+}
+
+// Rewrap returns data with its data key wrapped by the current master key,
+// sealing it if it isn't yet, and whether anything changed. rewrapped maps
+// data keys already rewrapped, wrapped by their old key, to their new form,
+// so payloads sharing a data key cost one Wrap.
+func (e *Envelope) Rewrap(ctx context.Context, data []byte, rewrapped map[string][]byte) ([]byte, bool, error) {
+	if !Sealed(data) {
+		sealed, err := e.Seal(ctx, data)
+		return sealed, err == nil, err
+	}
+	keyID, wrapped, sealed, err := decode(data)
+	if err != nil {
+		return nil, false, err
+	}
+	current := e.keys.KeyID()
+	if keyID == current {
+		return data, false, nil
+	}
+	cacheKey := keyID + "\x00" + string(wrapped)
+	next, ok := rewrapped[cacheKey]
+	if !ok {
+		plain, err := e.unwrap(ctx, keyID, wrapped)
+		if err != nil {
+			return nil, false, err
+		}
+		if next, err = e.keys.Wrap(ctx, plain); err != nil {
+			return nil, false, fmt.Errorf("wrap data key: %w", err)
+		}
+		rewrapped[cacheKey] = next
+	}
+	return encode(current, next, sealed), true, nil
+}
+
+func (e *Envelope) dataKey(ctx context.Context) (*dataKey, error) {
+	keyID := e.keys.KeyID()
+	e.mu.Lock()
+	key := e.current
+	e.mu.Unlock()
+	if key != nil && key.keyID == keyID && time.Since(key.created) < dataKeyTTL {
+		return key, nil
+	}
+	plain := make([]byte, 32)
+	if _, err := rand.Read(plain); err != nil {
+		return nil, err
+	}
+	wrapped, err := e.keys.Wrap(ctx, plain)
+	if err != nil {
+		return nil, fmt.Errorf("wrap data key: %w", err)
+	}
+	key = &dataKey{keyID: keyID, plain: plain, wrapped: wrapped, created: time.Now()}
+	e.mu.Lock()
+	e.current = key
+	e.mu.Unlock()
+	return key, nil
+}
+
+func (e *Envelope) unwrap(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	cacheKey := keyID + "\x00" + string(wrapped)
+	e.mu.Lock()
+	plain, ok := e.unwrapped[cacheKey]
+	e.mu.Unlock()
+	if ok {
+		return plain, nil
+	}
+	plain, err := e.keys.Unwrap(ctx, keyID, wrapped)
+	if err != nil {
+		return nil, fmt.Errorf("unwrap data key with %s: %w", keyID, err)
+	}
+	e.mu.Lock()
+	if len(e.unwrapped) >= maxUnwrapped {
+		clear(e.unwrapped)
+	}
+	e.unwrapped[cacheKey] = plain
+	e.mu.Unlock()
+	return plain, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// encode lays a sealed payload out as the magic, the master key ID and the
+// wrapped data key, each prefixed with its length, then the nonce and
+// ciphertext.
+func encode(keyID string, wrapped, sealed []byte) []byte {
+	out := make([]byte, 0, len(magic)+2*binary.MaxVarintLen64+len(keyID)+len(wrapped)+len(sealed))
+	out = append(out, magic...)
+	out = binary.AppendUvarint(out, uint64(len(keyID)))
+	out = append(out, keyID...)
+	out = binary.AppendUvarint(out, uint64(len(wrapped)))
+	out = append(out, wrapped...)
+	return append(out, sealed...)
+}
+
+func decode(data []byte) (keyID string, wrapped, sealed []byte, err error) {
+	rest := data[len(magic):]
+	field := func() ([]byte, bool) {
+		n, size := binary.Uvarint(rest)
+		if size <= 0 || n > uint64(len(rest)-size) {
+			return nil, false
+		}
+		f := rest[size : size+int(n)]
+		rest = rest[size+int(n):]
+		return f, true
+	}
+	id, ok := field()
+	if !ok {
+		return "", nil, nil, ErrMalformed
+	}
+	if wrapped, ok = field(); !ok {
+		return "", nil, nil, ErrMalformed
+	}
+	return string(id), wrapped, rest, nil
+}
@@ -0,0 +1,91 @@
+package encryption
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// FileKeys are master keys read from a file of id=key lines, each key 32
+// base64-encoded bytes; blank lines and lines starting with # are skipped.
+// To rotate, add a key and make it the active one, keeping the old ones
+// until everything is rewrapped.
+type FileKeys struct {
+	active string
+	keys   map[string][]byte
+}
+
+// NewFileKeys reads the keys in path. active names the key new data keys are
+// wrapped with; the last key in the file when empty.
+func NewFileKeys(path, active string) (*FileKeys, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open key file: %w", err)
+	}
+	defer f.Close()
+	k := &FileKeys{keys: map[string][]byte{}}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, encoded, ok := strings.Cut(line, "=")
+		id = strings.TrimSpace(id)
+		if !ok || id == "" {
+			return nil, fmt.Errorf("key file %s line %d: want id=key", path, n)
+		}
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil || len(key) != 32 {
+			return nil, fmt.Errorf("key file %s: key %s must be 32 base64-encoded bytes", path, id)
+		}
+		k.keys[id] = key
+		k.active = id
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read key file: %w", err)
+	}
+	if active != "" {
+		k.active = active
+	}
+	if _, ok := k.keys[k.active]; !ok {
+		return nil, fmt.Errorf("key file %s has no key %q", path, k.active)
+	}
+	return k, nil
+}
+
+func (k *FileKeys) KeyID() string {
+	return k.active
+}
+
+// Wrap seals dataKey with AES-256-GCM under the active key.
+func (k *FileKeys) Wrap(_ context.Context, dataKey []byte) ([]byte, error) {
+	aead, err := newGCM(k.keys[k.active])
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, dataKey, []byte(k.active)), nil
+}
+
+func (k *FileKeys) Unwrap(_ context.Context, keyID string, wrapped []byte) ([]byte, error) {
+	key, ok := k.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("no master key %q in the key file", keyID)
+	}
+	aead, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(wrapped) < aead.NonceSize() {
+		return nil, ErrMalformed
+	}
+	return aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(keyID))
+}
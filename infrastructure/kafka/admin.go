@@ -0,0 +1,129 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+type TopicsConfig struct {
+	// AutoCreate creates missing topics; otherwise a missing topic fails startup.
+	AutoCreate        bool
+	Partitions        int
+	ReplicationFactor int
+	Retention         time.Duration
+}
+
+// TopicSpec is what the service needs from one topic.
+type TopicSpec struct {
+	Name              string
+	Partitions        int
+	ReplicationFactor int
+	MinRetention      time.Duration
+}
+
+// RequiredTopics lists the ingest and audit topics and, for consumers, the retry
+// and dead-letter topics. Retry topics must retain messages past their delay.
+func (c Config) RequiredTopics(consumer bool) []TopicSpec {
+	spec := func(name string, retention time.Duration) TopicSpec {
+		return TopicSpec{
+			Name:              name,
+			Partitions:        c.Topics.Partitions,
+			ReplicationFactor: c.Topics.ReplicationFactor,
+			MinRetention:      retention,
+		}
+	}
+	specs := []TopicSpec{spec(c.IngestTopic, c.Topics.Retention)}
+	if c.AuditTopic != "" {
+		specs = append(specs, spec(c.AuditTopic, c.Topics.Retention))
+	}
+	if consumer {
+		for _, d := range c.RetryDelays {
+			specs = append(specs, spec(c.RetryTopic(d), max(c.Topics.Retention, 2*d)))
+		}
+		specs = append(specs, spec(c.DeadLetterTopic(), c.Topics.Retention))
+	}
+	return specs
+}
+
+// EnsureTopics checks partitions and retention of every topic, creating missing
+// ones when auto-creation is enabled. All problems are reported together.
+func EnsureTopics(cfg Config, specs []TopicSpec) error {
+	sc, err := cfg.saramaConfig()
+	if err != nil {
+		return err
+	}
+	admin, err := sarama.NewClusterAdmin(cfg.Brokers, sc)
+	if err != nil {
+		return fmt.Errorf("kafka: connect admin client: %w", err)
+	}
+	defer admin.Close()
+
+	existing, err := admin.ListTopics()
+	if err != nil {
+		return fmt.Errorf("kafka: list topics: %w", err)
+	}
+
+	var problems []error
+	for _, spec := range specs {
+		detail, ok := existing[spec.Name]
+		if !ok {
+			if !cfg.Topics.AutoCreate {
+				problems = append(problems, fmt.Errorf("topic %s does not exist", spec.Name))
+				continue
+			}
+			if err := createTopic(admin, spec); err != nil {
+				problems = append(problems, err)
+			}
+			continue
+		}
+		problems = append(problems, checkTopic(spec, detail)...)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("kafka: topics misconfigured: %w", errors.Join(problems...))
+	}
+	return nil
+}
+
+func createTopic(admin sarama.ClusterAdmin, spec TopicSpec) error {
+	detail := &sarama.TopicDetail{NumPartitions: -1, ReplicationFactor: -1}
+	if spec.Partitions > 0 {
+		detail.NumPartitions = int32(spec.Partitions)
+	}
+	if spec.ReplicationFactor > 0 {
+		detail.ReplicationFactor = int16(spec.ReplicationFactor)
+	}
+	if spec.MinRetention > 0 {
+		ms := strconv.FormatInt(spec.MinRetention.Milliseconds(), 10)
+		detail.ConfigEntries = map[string]*string{"retention.ms": &ms}
+	}
+	err := admin.CreateTopic(spec.Name, detail, false)
+	if err != nil && !errors.Is(err, sarama.ErrTopicAlreadyExists) {
+		return fmt.Errorf("create topic %s: %w", spec.Name, err)
+	}
+	return nil
+}
+
+func checkTopic(spec TopicSpec, detail sarama.TopicDetail) []error {
+	var problems []error
+	if spec.Partitions > 0 && int(detail.NumPartitions) < spec.Partitions {
+		problems = append(problems, fmt.Errorf("topic %s has %d partitions, need at least %d", spec.Name, detail.NumPartitions, spec.Partitions))
+	}
+	if spec.ReplicationFactor > 0 && int(detail.ReplicationFactor) < spec.ReplicationFactor {
+		problems = append(problems, fmt.Errorf("topic %s has replication factor %d, need at least %d", spec.Name, detail.ReplicationFactor, spec.ReplicationFactor))
+	}
+	if spec.MinRetention > 0 {
+		if v, ok := detail.ConfigEntries["retention.ms"]; ok && v != nil {
+			ms, err := strconv.ParseInt(strings.TrimSpace(*v), 10, 64)
+			// -1 means unlimited retention.
+			if err == nil && ms >= 0 && time.Duration(ms)*time.Millisecond < spec.MinRetention {
+				problems = append(problems, fmt.Errorf("topic %s retains messages for %s, need at least %s", spec.Name, time.Duration(ms)*time.Millisecond, spec.MinRetention))
+			}
+		}
+	}
+	return problems
+}
@@ -0,0 +1,72 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/IBM/sarama"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// AuditProducer publishes query audit events asynchronously so auditing never
+// adds broker round trips to a request. Delivery errors are logged.
+type AuditProducer struct {
+	producer sarama.AsyncProducer
+	topic    string
+	done     sync.WaitGroup
+}
+
+func NewAuditProducer(cfg Config) (*AuditProducer, error) {
+	if cfg.AuditTopic == "" {
+		return nil, fmt.Errorf("kafka: no audit topic configured")
+	}
+	sc, err := cfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	sc.Producer.Return.Successes = false
+	producer, err := sarama.NewAsyncProducer(cfg.Brokers, sc)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: create audit producer: %w", err)
+	}
+
+	p := &AuditProducer{producer: producer, topic: cfg.AuditTopic}
+	p.done.Add(1)
+	go func() {
+		defer p.done.Done()
+		for err := range producer.Errors() {
+			log.Printf("Publishing audit event failed: %v\n", err)
+		}
+	}()
+	return p, nil
+}
+
+func (p *AuditProducer) PublishQueryAudit(ctx context.Context, event domain.QueryAudit) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode audit event: %w", err)
+	}
+	msg := &sarama.ProducerMessage{
+		Topic:     p.topic,
+		Key:       sarama.StringEncoder(event.SessionID),
+		Value:     sarama.ByteEncoder(value),
+		Timestamp: event.At,
+	}
+	select {
+	case p.producer.Input() <- msg:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close flushes buffered events.
+func (p *AuditProducer) Close() error {
+	p.producer.AsyncClose()
+	p.done.Wait()
+	return nil
+}
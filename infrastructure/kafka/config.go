@@ -0,0 +1,167 @@
+package kafka
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+type Config struct {
+	Brokers  []string
+	ClientID string
+	Version  string
+
+	IngestTopic string
+	// AuditTopic receives query audit events; empty disables auditing.
+	AuditTopic   string
+	Partitioner  string
+	Compression  string
+	RequiredAcks string
+	Idempotent   bool
+	Timeout      time.Duration
+
+	TLS    TLSConfig
+	SASL   SASLConfig
+	Topics TopicsConfig
+
+	// Format is "json", or "avro"/"protobuf" with schemas managed in SchemaRegistry.
+	Format         string
+	SchemaRegistry SchemaRegistryConfig
+
+	ConsumerGroup string
+	// InitialOffset is "oldest" or "newest"; it applies when the group has no committed offset.
+	InitialOffset string
+	// RetryDelays defines one retry topic per delay; events still failing after
+	// the last one go to the dead-letter topic.
+	RetryDelays []time.Duration
+	DLQTopic    string
+	// LagInterval is how often consumer lag is measured; HealthInterval is how
+	// often dependencies are checked to pause or resume consumption.
+	LagInterval    time.Duration
+	HealthInterval time.Duration
+}
+
+// RetryTopic names the retry topic for the given delay, e.g. "events.retry-5m".
+func (c Config) RetryTopic(delay time.Duration) string {
+	name := delay.String()
+	if strings.HasSuffix(name, "m0s") {
+		name = strings.TrimSuffix(name, "0s")
+	}
+	if strings.HasSuffix(name, "h0m") {
+		name = strings.TrimSuffix(name, "0m")
+	}
+	return c.IngestTopic + ".retry-" + name
+}
+
+func (c Config) DeadLetterTopic() string {
+	if c.DLQTopic != "" {
+		return c.DLQTopic
+	}
+	return c.IngestTopic + ".dlq"
+}
+
+var partitioners = map[string]sarama.PartitionerConstructor{
+	"hash":       sarama.NewHashPartitioner,
+	"crc":        sarama.NewConsistentCRCHashPartitioner,
+	"random":     sarama.NewRandomPartitioner,
+	"roundrobin": sarama.NewRoundRobinPartitioner,
+}
+
+var compressions = map[string]sarama.CompressionCodec{
+	"none":   sarama.CompressionNone,
+	"gzip":   sarama.CompressionGZIP,
+	"snappy": sarama.CompressionSnappy,
+	"lz4":    sarama.CompressionLZ4,
+	"zstd":   sarama.CompressionZSTD,
+}
+
+var initialOffsets = map[string]int64{
+	"oldest": sarama.OffsetOldest,
+	"newest": sarama.OffsetNewest,
+}
+
+var acks = map[string]sarama.RequiredAcks{
+	"none":   sarama.NoResponse,
+	"leader": sarama.WaitForLocal,
+	"all":    sarama.WaitForAll,
+}
+
+// saramaConfig translates Config into a sarama client configuration.
+func (c Config) saramaConfig() (*sarama.Config, error) {
+	if len(c.Brokers) == 0 {
+		return nil, fmt.Errorf("kafka: no brokers configured")
+	}
+
+	sc := sarama.NewConfig()
+	if c.ClientID != "" {
+		sc.ClientID = c.ClientID
+	}
+	if c.Version != "" {
+		version, err := sarama.ParseKafkaVersion(c.Version)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: %w", err)
+		}
+		sc.Version = version
+	}
+	if c.Timeout > 0 {
+		sc.Net.DialTimeout = c.Timeout
+		sc.Net.ReadTimeout = c.Timeout
+		sc.Net.WriteTimeout = c.Timeout
+		sc.Producer.Timeout = c.Timeout
+	}
+
+	if err := c.TLS.apply(sc); err != nil {
+		return nil, fmt.Errorf("kafka: tls: %w", err)
+	}
+	if err := c.SASL.apply(sc); err != nil {
+		return nil, fmt.Errorf("kafka: sasl: %w", err)
+	}
+
+	partitioner, ok := partitioners[strings.ToLower(c.Partitioner)]
+	if !ok {
+		return nil, fmt.Errorf("kafka: unknown partitioner %q", c.Partitioner)
+	}
+	sc.Producer.Partitioner = partitioner
+
+	codec, ok := compressions[strings.ToLower(c.Compression)]
+	if !ok {
+		return nil, fmt.Errorf("kafka: unknown compression %q", c.Compression)
+	}
+	sc.Producer.Compression = codec
+
+	ack, ok := acks[strings.ToLower(c.RequiredAcks)]
+	if !ok {
+		return nil, fmt.Errorf("kafka: unknown required acks %q", c.RequiredAcks)
+	}
+	sc.Producer.RequiredAcks = ack
+
+	if c.Idempotent {
+		// The broker only deduplicates retries from a single in-flight request per connection.
+		if !sc.Version.IsAtLeast(sarama.V0_11_0_0) {
+			sc.Version = sarama.V0_11_0_0
+		}
+		sc.Producer.Idempotent = true
+		sc.Producer.RequiredAcks = sarama.WaitForAll
+		sc.Net.MaxOpenRequests = 1
+		if sc.Producer.Retry.Max == 0 {
+			sc.Producer.Retry.Max = 5
+		}
+	}
+	sc.Producer.Return.Successes = true
+
+	if c.InitialOffset != "" {
+		offset, ok := initialOffsets[strings.ToLower(c.InitialOffset)]
+		if !ok {
+			return nil, fmt.Errorf("kafka: unknown initial offset %q", c.InitialOffset)
+		}
+		sc.Consumer.Offsets.Initial = offset
+	}
+	sc.Consumer.Return.Errors = true
+
+	if err := sc.Validate(); err != nil {
+		return nil, fmt.Errorf("kafka: %w", err)
+	}
+	return sc, nil
+}
@@ -0,0 +1,313 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/IBM/sarama"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+)
+
+const (
+	minRetryBackoff = time.Second
+	maxRetryBackoff = time.Minute
+)
+
+// Headers set on messages forwarded to retry and dead-letter topics.
+const (
+	HeaderAttempt           = "x-attempt"
+	HeaderRetryAt           = "x-retry-at"
+	HeaderError             = "x-error"
+	HeaderOriginalTopic     = "x-original-topic"
+	HeaderOriginalPartition = "x-original-partition"
+	HeaderOriginalOffset    = "x-original-offset"
+)
+
+// Consumer reads ingest events as a member of a consumer group. A failed event
+// moves through the retry topics to the dead-letter topic; its offset is only
+// marked once it was handled or forwarded, so delivery is at-least-once.
+type Consumer struct {
+	client   sarama.Client
+	admin    sarama.ClusterAdmin
+	group    sarama.ConsumerGroup
+	producer *Producer
+	serde    serializer
+	cfg      Config
+
+	paused atomic.Bool
+	// lag is the total lag at the last measurement, or -1 before the first.
+	lag atomic.Int64
+}
+
+// stage is one hop of the retry chain: the main topic or a retry topic.
+type stage struct {
+	delay time.Duration
+	next  string
+}
+
+func NewConsumer(cfg Config, producer *Producer) (*Consumer, error) {
+	if cfg.IngestTopic == "" || cfg.ConsumerGroup == "" {
+		return nil, fmt.Errorf("kafka: consumer needs an ingest topic and consumer group")
+	}
+	sc, err := cfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	serde, err := newSerializer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sarama.NewClient(cfg.Brokers, sc)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: create client: %w", err)
+	}
+	group, err := sarama.NewConsumerGroupFromClient(cfg.ConsumerGroup, client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("kafka: create consumer group: %w", err)
+	}
+	// The admin client owns the shared client and closes it.
+	admin, err := sarama.NewClusterAdminFromClient(client)
+	if err != nil {
+		group.Close()
+		client.Close()
+		return nil, fmt.Errorf("kafka: create admin client: %w", err)
+	}
+	c := &Consumer{client: client, admin: admin, group: group, producer: producer, serde: serde, cfg: cfg}
+	c.lag.Store(-1)
+	return c, nil
+}
+
+// stages maps every consumed topic to its delay and the topic failures go to next.
+func (c *Consumer) stages() map[string]stage {
+	stages := make(map[string]stage, len(c.cfg.RetryDelays)+1)
+	topic, delay := c.cfg.IngestTopic, time.Duration(0)
+	for _, d := range c.cfg.RetryDelays {
+		next := c.cfg.RetryTopic(d)
+		stages[topic] = stage{delay: delay, next: next}
+		topic, delay = next, d
+	}
+	stages[topic] = stage{delay: delay, next: c.cfg.DeadLetterTopic()}
+	return stages
+}
+
+// ConsumeIngestEvents consumes until ctx is cancelled, rejoining the group after every rebalance.
+func (c *Consumer) ConsumeIngestEvents(ctx context.Context, handle domain.IngestHandler) error {
+	go func() {
+		for err := range c.group.Errors() {
+			log.Printf("Kafka consumer error: %v\n", err)
+		}
+	}()
+
+	h := &groupHandler{
+		consumer:   c,
+		handle:     handle,
+		producer:   c.producer,
+		serde:      c.serde,
+		stages:     c.stages(),
+		deadLetter: c.cfg.DeadLetterTopic(),
+	}
+	topics := make([]string, 0, len(h.stages))
+	for topic := range h.stages {
+		topics = append(topics, topic)
+	}
+	for {
+		if err := c.group.Consume(ctx, topics, h); err != nil {
+			if errors.Is(err, sarama.ErrClosedConsumerGroup) {
+				return nil
+			}
+			log.Printf("Kafka consume failed: %v\n", err)
+			sleep(ctx, minRetryBackoff)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+func (c *Consumer) Close() error {
+	return errors.Join(c.group.Close(), c.admin.Close())
+}
+
+type groupHandler struct {
+	consumer   *Consumer
+	handle     domain.IngestHandler
+	producer   *Producer
+	serde      serializer
+	stages     map[string]stage
+	deadLetter string
+}
+
+// Setup carries a health pause over to the partitions of a new session.
+func (h *groupHandler) Setup(sarama.ConsumerGroupSession) error {
+	if h.consumer.paused.Load() {
+		h.consumer.group.PauseAll()
+	}
+	return nil
+}
+
+func (h *groupHandler) Cleanup(sarama.ConsumerGroupSession) error { return nil }
+
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for {
+		select {
+		case msg, ok := <-claim.Messages():
+			if !ok {
+				return nil
+			}
+			if !h.process(session.Context(), msg) {
+				// The partition was revoked; the next owner redelivers the message.
+				return nil
+			}
+			session.MarkMessage(msg, "")
+		case <-session.Context().Done():
+			return nil
+		}
+	}
+}
+
+// process handles a message once it is due and forwards it to the next stage on
+// failure. It reports false if the session ended before the message was settled.
+func (h *groupHandler) process(ctx context.Context, msg *sarama.ConsumerMessage) bool {
+	event, err := h.decode(ctx, msg)
+	if errors.Is(err, errMalformed) {
+		return h.settle(ctx, msg, h.deadLetter, err)
+	}
+	if err != nil {
+		return false
+	}
+
+	// Retry topics hold messages in due order, so waiting here only delays later retries.
+	if !sleep(ctx, time.Until(retryAt(msg, h.stages[msg.Topic].delay))) {
+		return false
+	}
+
+	err = h.handleTraced(ctx, msg, event)
+	if err == nil {
+		return true
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	return h.settle(ctx, msg, h.stages[msg.Topic].next, err)
+}
+
+// handleTraced handles event in a consumer span continuing the trace of the
+// message's traceparent header, which retry hops keep.
+func (h *groupHandler) handleTraced(ctx context.Context, msg *sarama.ConsumerMessage, event domain.IngestEvent) error {
+	ctx = tracing.Extract(ctx, header(msg, tracing.Header, ""))
+	ctx, span := tracing.Start(ctx, msg.Topic+" process", tracing.WithKind(tracing.KindConsumer), tracing.WithAttributes(
+		tracing.String("messaging.system", "kafka"),
+		tracing.String("messaging.destination.name", msg.Topic),
+		tracing.String("messaging.message.id", event.ID),
+		tracing.Int("messaging.kafka.destination.partition", int(msg.Partition)),
+		tracing.Int("messaging.kafka.message.offset", int(msg.Offset)),
+	))
+	defer span.End()
+	err := h.handle(ctx, event)
+	span.SetError(err)
+	return err
+}
+
+// decode retries transient failures, such as an unreachable schema registry,
+// until the session ends; malformed messages fail immediately.
+func (h *groupHandler) decode(ctx context.Context, msg *sarama.ConsumerMessage) (domain.IngestEvent, error) {
+	backoff := minRetryBackoff
+	for {
+		event, err := h.serde.Unmarshal(ctx, msg.Value)
+		if err == nil || errors.Is(err, errMalformed) {
+			return event, err
+		}
+		log.Printf("Decoding message %s/%d/%d failed, retrying in %s: %v\n", msg.Topic, msg.Partition, msg.Offset, backoff, err)
+		if !sleep(ctx, backoff) {
+			return event, ctx.Err()
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// settle forwards msg to topic, retrying until the send succeeds or the session ends.
+func (h *groupHandler) settle(ctx context.Context, msg *sarama.ConsumerMessage, topic string, cause error) bool {
+	backoff := minRetryBackoff
+	for {
+		err := h.forward(msg, topic, cause)
+		if err == nil {
+			log.Printf("Message %s/%d/%d failed, moved to %s: %v\n", msg.Topic, msg.Partition, msg.Offset, topic, cause)
+			return true
+		}
+		log.Printf("Forwarding message %s/%d/%d to %s failed, retrying in %s: %v\n", msg.Topic, msg.Partition, msg.Offset, topic, backoff, err)
+		if !sleep(ctx, backoff) {
+			return false
+		}
+		backoff = min(backoff*2, maxRetryBackoff)
+	}
+}
+
+// sleep waits for d and reports false if ctx ended first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
+
+func (h *groupHandler) forward(msg *sarama.ConsumerMessage, topic string, cause error) error {
+	attempt, _ := strconv.Atoi(header(msg, HeaderAttempt, "1"))
+	// The first hop records where the event came from; later hops keep that origin.
+	headers := []sarama.RecordHeader{
+		{Key: []byte(HeaderAttempt), Value: []byte(strconv.Itoa(attempt + 1))},
+		{Key: []byte(HeaderError), Value: []byte(cause.Error())},
+		{Key: []byte(HeaderOriginalTopic), Value: []byte(header(msg, HeaderOriginalTopic, msg.Topic))},
+		{Key: []byte(HeaderOriginalPartition), Value: []byte(header(msg, HeaderOriginalPartition, strconv.Itoa(int(msg.Partition))))},
+		{Key: []byte(HeaderOriginalOffset), Value: []byte(header(msg, HeaderOriginalOffset, strconv.FormatInt(msg.Offset, 10)))},
+	}
+	if next, ok := h.stages[topic]; ok {
+		at := time.Now().Add(next.delay).UTC().Format(time.RFC3339Nano)
+		headers = append(headers, sarama.RecordHeader{Key: []byte(HeaderRetryAt), Value: []byte(at)})
+	}
+	for _, rh := range msg.Headers {
+		if !strings.HasPrefix(string(rh.Key), "x-") {
+			headers = append(headers, *rh)
+		}
+	}
+
+	_, _, err := h.producer.producer.SendMessage(&sarama.ProducerMessage{
+		Topic:   topic,
+		Key:     sarama.ByteEncoder(msg.Key),
+		Value:   sarama.ByteEncoder(msg.Value),
+		Headers: headers,
+	})
+	return err
+}
+
+func retryAt(msg *sarama.ConsumerMessage, delay time.Duration) time.Time {
+	if at, err := time.Parse(time.RFC3339Nano, header(msg, HeaderRetryAt, "")); err == nil {
+		return at
+	}
+	if delay == 0 || msg.Timestamp.IsZero() {
+		return time.Time{}
+	}
+	return msg.Timestamp.Add(delay)
+}
+
+func header(msg *sarama.ConsumerMessage, key, def string) string {
+	for _, h := range msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return def
+}
@@ -0,0 +1,192 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/IBM/sarama"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// Kafka can't delete single records, so handled dead letters are skipped by
+// committing a cursor under a consumer group of their own.
+func (c *Consumer) deadLetterGroup() string {
+	return c.cfg.ConsumerGroup + ".dlq-admin"
+}
+
+func (c *Consumer) PeekDeadLetters(ctx context.Context, limit int) ([]domain.DeadLetter, error) {
+	var letters []domain.DeadLetter
+	_, err := c.scanDeadLetters(ctx, limit, func(msg *sarama.ConsumerMessage) error {
+		letters = append(letters, c.deadLetter(ctx, msg))
+		return nil
+	})
+	return letters, err
+}
+
+// ReplayDeadLetters sends dead letters back to the ingest topic with their
+// retry history stripped, then moves the cursor past them.
+func (c *Consumer) ReplayDeadLetters(ctx context.Context, limit int) (int, error) {
+	next, err := c.scanDeadLetters(ctx, limit, func(msg *sarama.ConsumerMessage) error {
+		var headers []sarama.RecordHeader
+		for _, rh := range msg.Headers {
+			if !strings.HasPrefix(string(rh.Key), "x-") {
+				headers = append(headers, *rh)
+			}
+		}
+		_, _, err := c.producer.producer.SendMessage(&sarama.ProducerMessage{
+			Topic:   c.cfg.IngestTopic,
+			Key:     sarama.ByteEncoder(msg.Key),
+			Value:   sarama.ByteEncoder(msg.Value),
+			Headers: headers,
+		})
+		return err
+	})
+	// Commit whatever was replayed, even if a later send failed.
+	if cerr := c.commitDeadLetterCursor(next); cerr != nil {
+		err = errors.Join(err, cerr)
+	}
+	return countAdvanced(next), err
+}
+
+// PurgeDeadLetters moves the cursor to the end of every partition.
+func (c *Consumer) PurgeDeadLetters(ctx context.Context) (int, error) {
+	topic := c.cfg.DeadLetterTopic()
+	cursors, err := c.deadLetterCursors(topic)
+	if err != nil {
+		return 0, err
+	}
+	next := map[int32][2]int64{}
+	for p, from := range cursors {
+		newest, err := c.client.GetOffset(topic, p, sarama.OffsetNewest)
+		if err != nil {
+			return 0, err
+		}
+		next[p] = [2]int64{from, newest}
+	}
+	if err := c.commitDeadLetterCursor(next); err != nil {
+		return 0, err
+	}
+	return countAdvanced(next), nil
+}
+
+func (c *Consumer) deadLetter(ctx context.Context, msg *sarama.ConsumerMessage) domain.DeadLetter {
+	dl := domain.DeadLetter{
+		Position: fmt.Sprintf("%d/%d", msg.Partition, msg.Offset),
+		Error:    header(msg, HeaderError, ""),
+	}
+	dl.Attempts, _ = strconv.Atoi(header(msg, HeaderAttempt, "0"))
+	// Only malformed messages fail to decode, and their error says so already.
+	if event, err := c.serde.Unmarshal(ctx, msg.Value); err == nil {
+		dl.Event = &event
+	}
+	return dl
+}
+
+// deadLetterCursors returns the next unhandled offset of every partition.
+func (c *Consumer) deadLetterCursors(topic string) (map[int32]int64, error) {
+	partitions, err := c.client.Partitions(topic)
+	if err != nil {
+		return nil, err
+	}
+	committed, err := c.admin.ListConsumerGroupOffsets(c.deadLetterGroup(), map[string][]int32{topic: partitions})
+	if err != nil {
+		return nil, err
+	}
+	cursors := make(map[int32]int64, len(partitions))
+	for _, p := range partitions {
+		offset := int64(-1)
+		if block := committed.GetBlock(topic, p); block != nil {
+			offset = block.Offset
+		}
+		oldest, err := c.client.GetOffset(topic, p, sarama.OffsetOldest)
+		if err != nil {
+			return nil, err
+		}
+		// Retention may have removed records past the cursor.
+		cursors[p] = max(offset, oldest)
+	}
+	return cursors, nil
+}
+
+// scanDeadLetters calls fn for up to limit unhandled dead letters, partition
+// by partition, and returns each partition's [cursor, next offset] range.
+func (c *Consumer) scanDeadLetters(ctx context.Context, limit int, fn func(*sarama.ConsumerMessage) error) (map[int32][2]int64, error) {
+	topic := c.cfg.DeadLetterTopic()
+	cursors, err := c.deadLetterCursors(topic)
+	if err != nil {
+		return nil, err
+	}
+	consumer, err := sarama.NewConsumerFromClient(c.client)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	next := map[int32][2]int64{}
+	seen := 0
+	for p, from := range cursors {
+		newest, err := c.client.GetOffset(topic, p, sarama.OffsetNewest)
+		if err != nil {
+			return next, err
+		}
+		if from >= newest || seen >= limit {
+			continue
+		}
+		pc, err := consumer.ConsumePartition(topic, p, from)
+		if err != nil {
+			return next, err
+		}
+		offset := from
+		for offset < newest && seen < limit {
+			var msg *sarama.ConsumerMessage
+			select {
+			case msg = <-pc.Messages():
+			case <-ctx.Done():
+				pc.Close()
+				return next, ctx.Err()
+			}
+			if err := fn(msg); err != nil {
+				pc.Close()
+				return next, err
+			}
+			offset = msg.Offset + 1
+			next[p] = [2]int64{from, offset}
+			seen++
+		}
+		pc.Close()
+	}
+	return next, nil
+}
+
+func (c *Consumer) commitDeadLetterCursor(next map[int32][2]int64) error {
+	if len(next) == 0 {
+		return nil
+	}
+	om, err := sarama.NewOffsetManagerFromClient(c.deadLetterGroup(), c.client)
+	if err != nil {
+		return err
+	}
+	defer om.Close()
+	for p, r := range next {
+		pom, err := om.ManagePartition(c.cfg.DeadLetterTopic(), p)
+		if err != nil {
+			return err
+		}
+		pom.MarkOffset(r[1], "")
+		pom.Close()
+	}
+	om.Commit()
+	return nil
+}
+
+func countAdvanced(next map[int32][2]int64) int {
+	n := 0
+	for _, r := range next {
+		n += int(r[1] - r[0])
+	}
+	return n
+}
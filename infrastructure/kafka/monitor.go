@@ -0,0 +1,137 @@
+package kafka
+
+import (
+	"context"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/IBM/sarama"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+var (
+	consumerLag = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_group_lag",
+		Help: "Messages between the consumer group's committed offset and the partition's high watermark.",
+	}, []string{"group", "topic", "partition"})
+
+	consumerPaused = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kafka_consumer_paused",
+		Help: "1 while consumption is paused because a dependency is unhealthy.",
+	}, []string{"group"})
+)
+
+// MonitorLag updates the lag gauges for every consumed topic until ctx ends.
+func (c *Consumer) MonitorLag(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if err := c.recordLag(); err != nil {
+			log.Printf("Measuring Kafka consumer lag failed: %v\n", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Consumer) recordLag() error {
+	partitions := map[string][]int32{}
+	for topic := range c.stages() {
+		ps, err := c.client.Partitions(topic)
+		if err != nil {
+			return err
+		}
+		partitions[topic] = ps
+	}
+
+	committed, err := c.admin.ListConsumerGroupOffsets(c.cfg.ConsumerGroup, partitions)
+	if err != nil {
+		return err
+	}
+	var total int64
+	for topic, ps := range partitions {
+		for _, p := range ps {
+			newest, err := c.client.GetOffset(topic, p, sarama.OffsetNewest)
+			if err != nil {
+				return err
+			}
+			offset := int64(-1)
+			if block := committed.GetBlock(topic, p); block != nil {
+				offset = block.Offset
+			}
+			if offset < 0 {
+				// Nothing committed yet: everything retained is still to be read.
+				if offset, err = c.client.GetOffset(topic, p, sarama.OffsetOldest); err != nil {
+					return err
+				}
+			}
+			lag := max(newest-offset, 0)
+			total += lag
+			consumerLag.WithLabelValues(c.cfg.ConsumerGroup, topic, strconv.Itoa(int(p))).Set(float64(lag))
+		}
+	}
+	c.lag.Store(total)
+	return nil
+}
+
+// Lag returns the total lag across consumed partitions as of the last
+// MonitorLag measurement, and false if there has been none yet.
+func (c *Consumer) Lag() (int64, bool) {
+	lag := c.lag.Load()
+	return lag, lag >= 0
+}
+
+// PauseWhileUnhealthy stops fetching while any of checks fails and resumes once
+// all pass again, so events wait in Kafka instead of burning through retries.
+func (c *Consumer) PauseWhileUnhealthy(ctx context.Context, interval time.Duration, checks map[string]domain.HealthChecker) {
+	if interval <= 0 || len(checks) == 0 {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		var failing []string
+		for name, check := range checks {
+			pingCtx, cancel := context.WithTimeout(ctx, interval)
+			if err := check.Ping(pingCtx); err != nil {
+				failing = append(failing, name+": "+err.Error())
+			}
+			cancel()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		sort.Strings(failing)
+
+		switch {
+		case len(failing) > 0 && !c.paused.Load():
+			c.paused.Store(true)
+			c.group.PauseAll()
+			consumerPaused.WithLabelValues(c.cfg.ConsumerGroup).Set(1)
+			log.Printf("Pausing Kafka consumption, dependencies unhealthy: %s\n", strings.Join(failing, "; "))
+		case len(failing) == 0 && c.paused.Load():
+			c.paused.Store(false)
+			c.group.ResumeAll()
+			consumerPaused.WithLabelValues(c.cfg.ConsumerGroup).Set(0)
+			log.Println("Resuming Kafka consumption, dependencies healthy again")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/IBM/sarama"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+)
+
+// Producer publishes ingest events, keyed by document ID so all events
+// of a page land on the same partition in order.
+type Producer struct {
+	client   sarama.Client
+	producer sarama.SyncProducer
+	serde    serializer
+	topic    string
+}
+
+func NewProducer(cfg Config) (*Producer, error) {
+	if cfg.IngestTopic == "" {
+		return nil, fmt.Errorf("kafka: no ingest topic configured")
+	}
+	sc, err := cfg.saramaConfig()
+	if err != nil {
+		return nil, err
+	}
+	serde, err := newSerializer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	client, err := sarama.NewClient(cfg.Brokers, sc)
+	if err != nil {
+		return nil, fmt.Errorf("kafka: create client: %w", err)
+	}
+	producer, err := sarama.NewSyncProducerFromClient(client)
+	if err != nil {
+		client.Close()
+		return nil, fmt.Errorf("kafka: create producer: %w", err)
+	}
+	return &Producer{client: client, producer: producer, serde: serde, topic: cfg.IngestTopic}, nil
+}
+
+func (p *Producer) PublishIngestEvent(ctx context.Context, event domain.IngestEvent) (err error) {
+	ctx, span := tracing.Start(ctx, p.topic+" publish", tracing.WithKind(tracing.KindProducer), tracing.WithAttributes(
+		tracing.String("messaging.system", "kafka"),
+		tracing.String("messaging.destination.name", p.topic),
+		tracing.String("messaging.message.id", event.ID),
+	))
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	value, err := p.serde.Marshal(ctx, p.topic, event)
+	if err != nil {
+		return fmt.Errorf("encode ingest event: %w", err)
+	}
+	msg := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(event.DocumentID),
+		Value: sarama.ByteEncoder(value),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte("event-id"), Value: []byte(event.ID)},
+			{Key: []byte("source"), Value: []byte(event.Source)},
+			{Key: []byte("action"), Value: []byte(event.Action)},
+		},
+		Timestamp: event.ReceivedAt,
+	}
+	// Consumers continue the trace from the header.
+	if traceparent := tracing.Inject(ctx); traceparent != "" {
+		msg.Headers = append(msg.Headers, sarama.RecordHeader{Key: []byte(tracing.Header), Value: []byte(traceparent)})
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if _, _, err := p.producer.SendMessage(msg); err != nil {
+		return fmt.Errorf("publish ingest event %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+// Ping refreshes the ingest topic's metadata, which needs a live broker.
+func (p *Producer) Ping(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() { done <- p.client.RefreshMetadata(p.topic) }()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("kafka: refresh metadata: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close closes the producer, then the client it was created from, which a
+// producer built from a client leaves open.
+func (p *Producer) Close() error {
+	return errors.Join(p.producer.Close(), p.client.Close())
+}
@@ -0,0 +1,188 @@
+package kafka
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+type SchemaRegistryConfig struct {
+	URL      string
+	Username string
+	Password string
+	Timeout  time.Duration
+	// SubjectStrategy is "topic" (<topic>-value), "record" (<record name>) or
+	// "topic_record" (<topic>-<record name>), matching Confluent's naming strategies.
+	SubjectStrategy string
+	// AutoRegister registers the event schema after a compatibility check;
+	// otherwise it must already be registered under the subject.
+	AutoRegister bool
+}
+
+func (c SchemaRegistryConfig) subject(topic, record string) (string, error) {
+	switch c.SubjectStrategy {
+	case "", "topic":
+		return topic + "-value", nil
+	case "record":
+		return record, nil
+	case "topic_record":
+		return topic + "-" + record, nil
+	default:
+		return "", fmt.Errorf("unknown subject strategy %q", c.SubjectStrategy)
+	}
+}
+
+// registryClient talks to the Confluent Schema Registry REST API.
+type registryClient struct {
+	cfg        SchemaRegistryConfig
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	ids     map[string]int
+	schemas map[int]string
+}
+
+func newRegistryClient(cfg SchemaRegistryConfig) (*registryClient, error) {
+	if cfg.URL == "" {
+		return nil, fmt.Errorf("schema registry URL is required")
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	return &registryClient{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: timeout},
+		ids:        map[string]int{},
+		schemas:    map[int]string{},
+	}, nil
+}
+
+type schemaRequest struct {
+	Schema     string `json:"schema"`
+	SchemaType string `json:"schemaType,omitempty"`
+}
+
+// schemaID resolves the ID of schema under subject, registering it first when
+// auto-registration is on and the registry finds it compatible.
+func (r *registryClient) schemaID(ctx context.Context, subject, schemaType, schema string) (int, error) {
+	r.mu.Lock()
+	id, ok := r.ids[subject]
+	r.mu.Unlock()
+	if ok {
+		return id, nil
+	}
+
+	req := schemaRequest{Schema: schema, SchemaType: schemaType}
+	var resp struct {
+		ID int `json:"id"`
+	}
+	if r.cfg.AutoRegister {
+		if err := r.checkCompatibility(ctx, subject, req); err != nil {
+			return 0, err
+		}
+		if err := r.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject)+"/versions", req, &resp); err != nil {
+			return 0, fmt.Errorf("register schema for %s: %w", subject, err)
+		}
+	} else if err := r.do(ctx, http.MethodPost, "/subjects/"+url.PathEscape(subject), req, &resp); err != nil {
+		return 0, fmt.Errorf("look up schema for %s: %w", subject, err)
+	}
+
+	r.mu.Lock()
+	r.ids[subject] = resp.ID
+	r.schemas[resp.ID] = schema
+	r.mu.Unlock()
+	return resp.ID, nil
+}
+
+func (r *registryClient) checkCompatibility(ctx context.Context, subject string, req schemaRequest) error {
+	var resp struct {
+		IsCompatible bool     `json:"is_compatible"`
+		Messages     []string `json:"messages"`
+	}
+	err := r.do(ctx, http.MethodPost, "/compatibility/subjects/"+url.PathEscape(subject)+"/versions/latest?verbose=true", req, &resp)
+	if errors.Is(err, errSubjectNotFound) {
+		// First version of the subject; nothing to be compatible with.
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("check schema compatibility for %s: %w", subject, err)
+	}
+	if !resp.IsCompatible {
+		return fmt.Errorf("schema is incompatible with subject %s: %s", subject, strings.Join(resp.Messages, "; "))
+	}
+	return nil
+}
+
+// schemaByID fetches a writer schema for decoding.
+func (r *registryClient) schemaByID(ctx context.Context, id int) (string, error) {
+	r.mu.Lock()
+	schema, ok := r.schemas[id]
+	r.mu.Unlock()
+	if ok {
+		return schema, nil
+	}
+
+	var resp struct {
+		Schema string `json:"schema"`
+	}
+	if err := r.do(ctx, http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id), nil, &resp); err != nil {
+		return "", fmt.Errorf("fetch schema %d: %w", id, err)
+	}
+	r.mu.Lock()
+	r.schemas[id] = resp.Schema
+	r.mu.Unlock()
+	return resp.Schema, nil
+}
+
+var errSubjectNotFound = errors.New("subject not found")
+
+func (r *registryClient) do(ctx context.Context, method, path string, body, out any) error {
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		payload = bytes.NewReader(b)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimRight(r.cfg.URL, "/")+path, payload)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/vnd.schemaregistry.v1+json")
+	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+	if r.cfg.Username != "" {
+		req.SetBasicAuth(r.cfg.Username, r.cfg.Password)
+	}
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		var e struct {
+			ErrorCode int `json:"error_code"`
+		}
+		json.NewDecoder(resp.Body).Decode(&e)
+		// 40401 subject not found, 40402 version not found
+		if e.ErrorCode == 40401 || e.ErrorCode == 40402 {
+			return errSubjectNotFound
+		}
+		return fmt.Errorf("status 404 (error code %d)", e.ErrorCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,347 @@
+package kafka
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"google.golang.org/protobuf/encoding/protowire"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// errMalformed marks messages that can never be decoded, as opposed to
+// transient failures such as an unreachable schema registry.
+var errMalformed = errors.New("malformed message")
+
+// serializer encodes ingest events for a topic. Registry-backed formats use the
+// Confluent wire format: a zero magic byte and the big-endian schema ID.
+type serializer interface {
+	Marshal(ctx context.Context, topic string, event domain.IngestEvent) ([]byte, error)
+	Unmarshal(ctx context.Context, data []byte) (domain.IngestEvent, error)
+}
+
+func newSerializer(cfg Config) (serializer, error) {
+	switch cfg.Format {
+	case "", "json":
+		return jsonSerializer{}, nil
+	case "avro", "protobuf":
+		registry, err := newRegistryClient(cfg.SchemaRegistry)
+		if err != nil {
+			return nil, fmt.Errorf("kafka: %w", err)
+		}
+		if cfg.Format == "avro" {
+			return &avroSerializer{registry: registry, codecs: map[int]*goavro.Codec{}}, nil
+		}
+		return &protoSerializer{registry: registry}, nil
+	default:
+		return nil, fmt.Errorf("kafka: unknown message format %q", cfg.Format)
+	}
+}
+
+type jsonSerializer struct{}
+
+func (jsonSerializer) Marshal(_ context.Context, _ string, event domain.IngestEvent) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+func (jsonSerializer) Unmarshal(_ context.Context, data []byte) (domain.IngestEvent, error) {
+	var event domain.IngestEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return event, fmt.Errorf("%w: %v", errMalformed, err)
+	}
+	return event, nil
+}
+
+func frame(id int, payload []byte) []byte {
+	out := make([]byte, 5, 5+len(payload))
+	binary.BigEndian.PutUint32(out[1:], uint32(id))
+	return append(out, payload...)
+}
+
+func unframe(data []byte) (int, []byte, error) {
+	if len(data) < 5 || data[0] != 0 {
+		return 0, nil, fmt.Errorf("%w: not in schema registry wire format", errMalformed)
+	}
+	return int(binary.BigEndian.Uint32(data[1:5])), data[5:], nil
+}
+
+const (
+	avroRecordName = "sarama.ingest.IngestEvent"
+	// New fields need defaults so consumers on older schemas keep decoding.
+	avroSchema = `{
+  "type": "record",
+  "name": "IngestEvent",
+  "namespace": "sarama.ingest",
+  "fields": [
+    {"name": "id", "type": "string"},
+    {"name": "source", "type": "string"},
+    {"name": "action", "type": "string"},
+    {"name": "document_id", "type": "string"},
+    {"name": "title", "type": "string", "default": ""},
+    {"name": "space_key", "type": "string", "default": ""},
+    {"name": "raw_type", "type": "string", "default": ""},
+    {"name": "received_at", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+    {"name": "spaces", "type": {"type": "array", "items": "string"}, "default": []}
+  ]
+}`
+)
+
+type avroSerializer struct {
+	registry *registryClient
+
+	mu     sync.Mutex
+	codecs map[int]*goavro.Codec
+}
+
+func (s *avroSerializer) Marshal(ctx context.Context, topic string, event domain.IngestEvent) ([]byte, error) {
+	subject, err := s.registry.cfg.subject(topic, avroRecordName)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.registry.schemaID(ctx, subject, "", avroSchema)
+	if err != nil {
+		return nil, err
+	}
+	codec, err := s.codec(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	spaces := make([]any, len(event.Spaces))
+	for i, space := range event.Spaces {
+		spaces[i] = space
+	}
+	payload, err := codec.BinaryFromNative(nil, map[string]any{
+		"id":          event.ID,
+		"source":      event.Source,
+		"action":      string(event.Action),
+		"document_id": event.DocumentID,
+		"title":       event.Title,
+		"space_key":   event.SpaceKey,
+		"raw_type":    event.RawType,
+		"received_at": event.ReceivedAt,
+		"spaces":      spaces,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("encode avro: %w", err)
+	}
+	return frame(id, payload), nil
+}
+
+// Unmarshal decodes with the writer's schema and reads fields by name, so
+// fields added or removed by compatible schema versions are tolerated.
+func (s *avroSerializer) Unmarshal(ctx context.Context, data []byte) (domain.IngestEvent, error) {
+	id, payload, err := unframe(data)
+	if err != nil {
+		return domain.IngestEvent{}, err
+	}
+	codec, err := s.codec(ctx, id)
+	if err != nil {
+		return domain.IngestEvent{}, err
+	}
+	native, _, err := codec.NativeFromBinary(payload)
+	if err != nil {
+		return domain.IngestEvent{}, fmt.Errorf("%w: decode avro: %v", errMalformed, err)
+	}
+	record, ok := native.(map[string]any)
+	if !ok {
+		return domain.IngestEvent{}, fmt.Errorf("%w: decode avro: expected a record", errMalformed)
+	}
+
+	str := func(name string) string {
+		v, _ := record[name].(string)
+		return v
+	}
+	event := domain.IngestEvent{
+		ID:         str("id"),
+		Source:     str("source"),
+		Action:     domain.IngestAction(str("action")),
+		DocumentID: str("document_id"),
+		Title:      str("title"),
+		SpaceKey:   str("space_key"),
+		RawType:    str("raw_type"),
+	}
+	if at, ok := record["received_at"].(time.Time); ok {
+		event.ReceivedAt = at.UTC()
+	}
+	spaces, _ := record["spaces"].([]any)
+	for _, space := range spaces {
+		if space, ok := space.(string); ok {
+			event.Spaces = append(event.Spaces, space)
+		}
+	}
+	return event, nil
+}
+
+func (s *avroSerializer) codec(ctx context.Context, id int) (*goavro.Codec, error) {
+	s.mu.Lock()
+	codec, ok := s.codecs[id]
+	s.mu.Unlock()
+	if ok {
+		return codec, nil
+	}
+	schema, err := s.registry.schemaByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	codec, err = goavro.NewCodec(schema)
+	if err != nil {
+		return nil, fmt.Errorf("%w: parse avro schema %d: %v", errMalformed, id, err)
+	}
+	s.mu.Lock()
+	s.codecs[id] = codec
+	s.mu.Unlock()
+	return codec, nil
+}
+
+const (
+	protoRecordName = "sarama.ingest.IngestEvent"
+	// Field numbers are the wire contract: never reuse or renumber them.
+	protoSchema = `syntax = "proto3";
+package sarama.ingest;
+
+message IngestEvent {
+  string id = 1;
+  string source = 2;
+  string action = 3;
+  string document_id = 4;
+  string title = 5;
+  string space_key = 6;
+  string raw_type = 7;
+  int64 received_at_unix_ms = 8;
+  repeated string spaces = 9;
+}
+`
+)
+
+// protoSerializer hand-encodes the event message; unknown fields from newer
+// schema versions are skipped on decode.
+type protoSerializer struct {
+	registry *registryClient
+}
+
+func (s *protoSerializer) Marshal(ctx context.Context, topic string, event domain.IngestEvent) ([]byte, error) {
+	subject, err := s.registry.cfg.subject(topic, protoRecordName)
+	if err != nil {
+		return nil, err
+	}
+	id, err := s.registry.schemaID(ctx, subject, "PROTOBUF", protoSchema)
+	if err != nil {
+		return nil, err
+	}
+
+	// A single 0 message index selects the first message in the schema.
+	b := []byte{0}
+	for _, f := range []struct {
+		num   protowire.Number
+		value string
+	}{
+		{1, event.ID}, {2, event.Source}, {3, string(event.Action)}, {4, event.DocumentID},
+		{5, event.Title}, {6, event.SpaceKey}, {7, event.RawType},
+	} {
+		if f.value != "" {
+			b = protowire.AppendTag(b, f.num, protowire.BytesType)
+			b = protowire.AppendString(b, f.value)
+		}
+	}
+	if !event.ReceivedAt.IsZero() {
+		b = protowire.AppendTag(b, 8, protowire.VarintType)
+		b = protowire.AppendVarint(b, uint64(event.ReceivedAt.UnixMilli()))
+	}
+	for _, space := range event.Spaces {
+		b = protowire.AppendTag(b, 9, protowire.BytesType)
+		b = protowire.AppendString(b, space)
+	}
+	return frame(id, b), nil
+}
+
+func (s *protoSerializer) Unmarshal(_ context.Context, data []byte) (domain.IngestEvent, error) {
+	event, err := decodeProto(data)
+	if err != nil && !errors.Is(err, errMalformed) {
+		err = fmt.Errorf("%w: decode protobuf: %v", errMalformed, err)
+	}
+	return event, err
+}
+
+func decodeProto(data []byte) (domain.IngestEvent, error) {
+	_, b, err := unframe(data)
+	if err != nil {
+		return domain.IngestEvent{}, err
+	}
+	b, err = skipMessageIndexes(b)
+	if err != nil {
+		return domain.IngestEvent{}, err
+	}
+
+	var event domain.IngestEvent
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return domain.IngestEvent{}, protowire.ParseError(n)
+		}
+		b = b[n:]
+		switch {
+		case typ == protowire.BytesType && num >= 1 && num <= 9 && num != 8:
+			v, n := protowire.ConsumeString(b)
+			if n < 0 {
+				return domain.IngestEvent{}, protowire.ParseError(n)
+			}
+			b = b[n:]
+			switch num {
+			case 1:
+				event.ID = v
+			case 2:
+				event.Source = v
+			case 3:
+				event.Action = domain.IngestAction(v)
+			case 4:
+				event.DocumentID = v
+			case 5:
+				event.Title = v
+			case 6:
+				event.SpaceKey = v
+			case 7:
+				event.RawType = v
+			case 9:
+				event.Spaces = append(event.Spaces, v)
+			}
+		case typ == protowire.VarintType && num == 8:
+			v, n := protowire.ConsumeVarint(b)
+			if n < 0 {
+				return domain.IngestEvent{}, protowire.ParseError(n)
+			}
+			b = b[n:]
+			event.ReceivedAt = time.UnixMilli(int64(v)).UTC()
+		default:
+			n := protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return domain.IngestEvent{}, protowire.ParseError(n)
+			}
+			b = b[n:]
+		}
+	}
+	return event, nil
+}
+
+// skipMessageIndexes drops the zigzag-encoded message index path that precedes
+// Confluent protobuf payloads; a lone 0 is shorthand for [0].
+func skipMessageIndexes(b []byte) ([]byte, error) {
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return nil, protowire.ParseError(n)
+	}
+	b = b[n:]
+	for i := int64(0); i < protowire.DecodeZigZag(v); i++ {
+		_, n := protowire.ConsumeVarint(b)
+		if n < 0 {
+			return nil, protowire.ParseError(n)
+		}
+		b = b[n:]
+	}
+	return b, nil
+}
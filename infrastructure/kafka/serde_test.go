@@ -0,0 +1,106 @@
+package kafka
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// fakeRegistry serves the schema registry calls the serializers make, giving
+// every distinct schema the next ID.
+func fakeRegistry(t *testing.T) *httptest.Server {
+	var mu sync.Mutex
+	var schemas []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		switch {
+		case r.Method == http.MethodPost && strings.HasPrefix(r.URL.Path, "/subjects/"):
+			var req schemaRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			id := len(schemas) + 1
+			for i, schema := range schemas {
+				if schema == req.Schema {
+					id = i + 1
+				}
+			}
+			if id > len(schemas) {
+				schemas = append(schemas, req.Schema)
+			}
+			json.NewEncoder(w).Encode(map[string]int{"id": id})
+		case r.Method == http.MethodGet && strings.HasPrefix(r.URL.Path, "/schemas/ids/"):
+			id, err := strconv.Atoi(strings.TrimPrefix(r.URL.Path, "/schemas/ids/"))
+			if err != nil || id < 1 || id > len(schemas) {
+				http.NotFound(w, r)
+				return
+			}
+			json.NewEncoder(w).Encode(map[string]string{"schema": schemas[id-1]})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestSerializersRoundTrip(t *testing.T) {
+	registry := fakeRegistry(t)
+	event := domain.IngestEvent{
+		ID:         "evt-1",
+		Source:     "api",
+		Action:     domain.IngestDelete,
+		DocumentID: "12345",
+		Title:      "Runbook",
+		SpaceKey:   "OPS",
+		RawType:    "tenant-ingest",
+		ReceivedAt: time.UnixMilli(1760000000123).UTC(),
+		Spaces:     []string{"OPS", "SRE"},
+	}
+	unscoped := event
+	unscoped.Spaces = nil
+
+	for _, format := range []string{"json", "avro", "protobuf"} {
+		for name, want := range map[string]domain.IngestEvent{"scoped": event, "unscoped": unscoped} {
+			t.Run(format+"/"+name, func(t *testing.T) {
+				cfg := Config{Format: format, SchemaRegistry: SchemaRegistryConfig{URL: registry.URL}}
+				// Producer and consumer hold separate registry caches, as
+				// separate processes would.
+				producer, err := newSerializer(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				consumer, err := newSerializer(cfg)
+				if err != nil {
+					t.Fatal(err)
+				}
+				ctx := context.Background()
+				data, err := producer.Marshal(ctx, "ingest", want)
+				if err != nil {
+					t.Fatalf("Marshal: %v", err)
+				}
+				got, err := consumer.Unmarshal(ctx, data)
+				if err != nil {
+					t.Fatalf("Unmarshal: %v", err)
+				}
+				if len(got.Spaces) == 0 {
+					got.Spaces = nil
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("round trip = %+v, want %+v", got, want)
+				}
+			})
+		}
+	}
+}
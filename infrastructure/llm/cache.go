@@ -0,0 +1,83 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"log"
+	"math"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// CachedEmbedder reuses the embeddings of texts embedded within ttl, so
+// re-asked questions and unchanged chunks of re-indexed pages aren't paid for
+// twice. Cache failures fall back to embedding.
+type CachedEmbedder struct {
+	embedder domain.Embedder
+	cache    domain.Cache
+	// model keys the entries, so changing the embedding model misses.
+	model string
+	ttl   time.Duration
+}
+
+func NewCachedEmbedder(embedder domain.Embedder, cache domain.Cache, model string, ttl time.Duration) *CachedEmbedder {
+	return &CachedEmbedder{embedder: embedder, cache: cache, model: model, ttl: ttl}
+}
+
+func (e *CachedEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	keys := make([]string, len(texts))
+	var missing []int
+	for i, text := range texts {
+		sum := sha256.Sum256([]byte(e.model + "\x00" + text))
+		keys[i] = "embedding:" + hex.EncodeToString(sum[:])
+		b, ok, err := e.cache.Get(ctx, keys[i])
+		if err != nil {
+			log.Printf("Reading embedding cache failed: %v\n", err)
+		}
+		if ok && len(b)%4 == 0 {
+			vectors[i] = decodeVector(b)
+			continue
+		}
+		missing = append(missing, i)
+	}
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	inputs := make([]string, len(missing))
+	for j, i := range missing {
+		inputs[j] = texts[i]
+	}
+	embedded, err := e.embedder.Embed(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	for j, i := range missing {
+		vectors[i] = embedded[j]
+		if err := e.cache.Set(ctx, keys[i], encodeVector(embedded[j]), e.ttl); err != nil {
+			log.Printf("Writing embedding cache failed: %v\n", err)
+			break
+		}
+	}
+	return vectors, nil
+}
+
+func encodeVector(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(x))
+	}
+	return b
+}
+
+func decodeVector(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v
+}
@@ -0,0 +1,95 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ErrBusy is returned by calls that waited QueueTimeout without getting a
+// concurrency slot.
+var ErrBusy = errors.New("llm provider busy: too many calls in flight")
+
+var (
+	queued = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "llm_requests_queued",
+		Help: "LLM API calls waiting for a concurrency slot by endpoint.",
+	}, []string{"endpoint"})
+
+	inFlight = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "llm_requests_in_flight",
+		Help: "LLM API calls holding a concurrency slot by endpoint.",
+	}, []string{"endpoint"})
+)
+
+var (
+	limitersMu sync.Mutex
+	limiters   = map[string]chan struct{}{}
+)
+
+// slotsFor returns the slots shared by every client calling path at
+// cfg.BaseURL, sized by the first of them, or nil when calls aren't limited.
+// The process makes many clients from the same config, so a limit per client
+// wouldn't bound anything.
+func slotsFor(cfg Config, path string) chan struct{} {
+	if cfg.MaxConcurrency <= 0 {
+		return nil
+	}
+	key := path + " " + cfg.BaseURL
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+	slots, ok := limiters[key]
+	if !ok {
+		slots = make(chan struct{}, cfg.MaxConcurrency)
+		limiters[key] = slots
+	}
+	return slots
+}
+
+// acquire takes a concurrency slot for a call to path, waiting in line up
+// to QueueTimeout, and returns the func that frees it.
+func (c *Client) acquire(ctx context.Context, path string) (func(), error) {
+	slots := slotsFor(c.cfg, path)
+	if slots == nil {
+		return func() {}, nil
+	}
+	select {
+	case slots <- struct{}{}:
+	default:
+		queued.WithLabelValues(path).Inc()
+		err := wait(ctx, slots, c.cfg.QueueTimeout)
+		queued.WithLabelValues(path).Dec()
+		if err != nil {
+			return nil, fmt.Errorf("call %s: %w", path, err)
+		}
+	}
+	inFlight.WithLabelValues(path).Inc()
+	return func() {
+		inFlight.WithLabelValues(path).Dec()
+		<-slots
+	}, nil
+}
+
+// wait blocks until slots has room, ctx is done or timeout passes; a zero
+// timeout waits as long as ctx allows.
+func wait(ctx context.Context, slots chan struct{}, timeout time.Duration) error {
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+	select {
+	case slots <- struct{}{}:
+		return nil
+	case <-expired:
+		return ErrBusy
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+var (
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "llm_request_duration_seconds",
+		Help:    "Latency of LLM API calls, including waiting for a concurrency slot, by endpoint and outcome (ok, error, busy).",
+		Buckets: []float64{.05, .1, .25, .5, 1, 2.5, 5, 10, 20, 40, 80},
+	}, []string{"endpoint", "outcome"})
+
+	tokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "llm_tokens_total",
+		Help: "Tokens used by chat completions by model and kind (prompt, completion).",
+	}, []string{"model", "kind"})
+)
+
+func observe(path string, start time.Time, err error) {
+	outcome := "ok"
+	switch {
+	case errors.Is(err, ErrBusy):
+		outcome = "busy"
+	case err != nil:
+		outcome = "error"
+	}
+	requestDuration.WithLabelValues(path, outcome).Observe(time.Since(start).Seconds())
+}
+
+// countTokens counts usage under the requested model; the model a response
+// names can carry a version suffix that would add a series per release.
+func countTokens(model string, usage domain.Usage) {
+	tokens.WithLabelValues(model, "prompt").Add(float64(usage.PromptTokens))
+	tokens.WithLabelValues(model, "completion").Add(float64(usage.CompletionTokens))
+}
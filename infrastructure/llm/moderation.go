@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"context"
+	"sort"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type moderationRequest struct {
+	Input string `json:"input"`
+}
+
+type moderationResponse struct {
+	Results []struct {
+		Flagged    bool            `json:"flagged"`
+		Categories map[string]bool `json:"categories"`
+	} `json:"results"`
+}
+
+// Moderate uses the provider moderation endpoint; flagged content is blocked.
+func (c *Client) Moderate(ctx context.Context, text string) (domain.ModerationResult, error) {
+	var resp moderationResponse
+	if err := c.post(ctx, "/moderations", moderationRequest{Input: text}, &resp); err != nil {
+		return domain.ModerationResult{}, err
+	}
+
+	result := domain.ModerationResult{Action: domain.ModerationAllow, Text: text}
+	for _, r := range resp.Results {
+		if !r.Flagged {
+			continue
+		}
+		result.Action = domain.ModerationBlock
+		for category, hit := range r.Categories {
+			if hit {
+				result.Categories = append(result.Categories, category)
+			}
+		}
+	}
+	sort.Strings(result.Categories)
+	return result, nil
+}
@@ -0,0 +1,245 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/breaker"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+)
+
+type Config struct {
+	BaseURL        string
+	APIKey         string
+	ChatModel      string
+	EmbeddingModel string
+	Timeout        time.Duration
+	// MaxConcurrency caps the calls in flight to each endpoint of the
+	// provider, across every client of the process; 0 leaves them unlimited.
+	// Calls beyond it wait in line up to QueueTimeout, then fail with ErrBusy.
+	MaxConcurrency int
+	QueueTimeout   time.Duration
+	// EmbedBatchSize caps the texts sent per embeddings call, larger inputs
+	// being split into batches of which EmbedParallelism are sent at a time;
+	// 0 sends all texts in one call.
+	EmbedBatchSize   int
+	EmbedParallelism int
+}
+
+// Client talks to any OpenAI-compatible chat completion and embedding API.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func NewClient(cfg Config) *Client {
+	return &Client{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout, Transport: tracing.Transport(nil)},
+	}
+}
+
+type chatRequest struct {
+	Model       string           `json:"model"`
+	Messages    []domain.Message `json:"messages"`
+	Temperature float64          `json:"temperature"`
+	MaxTokens   int              `json:"max_tokens,omitempty"`
+}
+
+type chatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message domain.Message `json:"message"`
+	} `json:"choices"`
+	Usage domain.Usage `json:"usage"`
+}
+
+func (c *Client) Complete(ctx context.Context, req domain.CompletionRequest) (*domain.Completion, error) {
+	model := req.Model
+	if model == "" {
+		model = c.cfg.ChatModel
+	}
+
+	var resp chatResponse
+	err := c.post(ctx, "/chat/completions", chatRequest{
+		Model:       model,
+		Messages:    req.Messages,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("chat completion returned no choices")
+	}
+	countTokens(model, resp.Usage)
+
+	return &domain.Completion{
+		Content: resp.Choices[0].Message.Content,
+		Model:   resp.Model,
+		Usage:   resp.Usage,
+	}, nil
+}
+
+type embeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type embeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+func (c *Client) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	size := c.cfg.EmbedBatchSize
+	if size <= 0 || len(texts) <= size {
+		return c.embedBatch(ctx, texts)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	batches := (len(texts) + size - 1) / size
+	vectors := make([][]float32, len(texts))
+	errs := make([]error, batches)
+	sem := make(chan struct{}, max(c.cfg.EmbedParallelism, 1))
+	var wg sync.WaitGroup
+	for b := range batches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			if ctx.Err() != nil {
+				errs[b] = ctx.Err()
+				return
+			}
+
+			from, to := b*size, min((b+1)*size, len(texts))
+			batch, err := c.embedBatch(ctx, texts[from:to])
+			if err != nil {
+				// One failed batch fails the call, so the rest needn't run.
+				errs[b] = err
+				cancel()
+				return
+			}
+			copy(vectors[from:to], batch)
+		}()
+	}
+	wg.Wait()
+
+	for b, err := range errs {
+		// Batches cancelled because another failed report that one instead.
+		if err != nil && !errors.Is(err, context.Canceled) {
+			return nil, fmt.Errorf("embed batch %d of %d: %w", b+1, batches, err)
+		}
+	}
+	for b, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("embed batch %d of %d: %w", b+1, batches, err)
+		}
+	}
+	return vectors, nil
+}
+
+func (c *Client) embedBatch(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	var resp embeddingResponse
+	if err := c.post(ctx, "/embeddings", embeddingRequest{Model: c.cfg.EmbeddingModel, Input: texts}, &resp); err != nil {
+		return nil, err
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embedding response has %d vectors for %d inputs", len(resp.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || d.Index >= len(texts) {
+			return nil, fmt.Errorf("embedding response index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
+// post calls path once a concurrency slot is free, through the breaker of
+// its endpoint, so failing chat completions don't stop embeddings.
+func (c *Client) post(ctx context.Context, path string, body, out any) (err error) {
+	start := time.Now()
+	defer func() { observe(path, start, err) }()
+	release, err := c.acquire(ctx, path)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return breaker.For("llm"+path).Do(ctx, func() error { return c.send(ctx, path, body, out) })
+}
+
+func (c *Client) send(ctx context.Context, path string, body, out any) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("encode request: %w", err)
+	}
+
+	url := strings.TrimRight(c.cfg.BaseURL, "/") + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("call %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return breaker.BenignStatus(resp.StatusCode,
+			fmt.Errorf("call %s: status %d: %s", path, resp.StatusCode, strings.TrimSpace(string(msg))))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s response: %w", path, err)
+	}
+	return nil
+}
+
+// Ping lists models, which checks both reachability and the API key.
+func (c *Client) Ping(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(c.cfg.BaseURL, "/")+"/models", nil)
+	if err != nil {
+		return err
+	}
+	if c.cfg.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.cfg.APIKey)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("call /models: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("call /models: status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type contentPart struct {
+	Type     string    `json:"type"`
+	Text     string    `json:"text,omitempty"`
+	ImageURL *imageURL `json:"image_url,omitempty"`
+}
+
+type imageURL struct {
+	URL string `json:"url"`
+}
+
+type visionMessage struct {
+	Role    domain.Role   `json:"role"`
+	Content []contentPart `json:"content"`
+}
+
+type visionRequest struct {
+	Model     string          `json:"model"`
+	Messages  []visionMessage `json:"messages"`
+	MaxTokens int             `json:"max_tokens,omitempty"`
+}
+
+// DescribeImage sends an image inline as a data URL to a vision-capable chat model.
+func (c *Client) DescribeImage(ctx context.Context, img domain.ImageInput) (*domain.Completion, error) {
+	model := img.Model
+	if model == "" {
+		model = c.cfg.ChatModel
+	}
+	dataURL := "data:" + img.ContentType + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+
+	var resp chatResponse
+	err := c.post(ctx, "/chat/completions", visionRequest{
+		Model: model,
+		Messages: []visionMessage{{
+			Role: domain.RoleUser,
+			Content: []contentPart{
+				{Type: "text", Text: img.Prompt},
+				{Type: "image_url", ImageURL: &imageURL{URL: dataURL}},
+			},
+		}},
+		MaxTokens: img.MaxTokens,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("vision completion returned no choices")
+	}
+	countTokens(model, resp.Usage)
+
+	return &domain.Completion{
+		Content: resp.Choices[0].Message.Content,
+		Model:   resp.Model,
+		Usage:   resp.Usage,
+	}, nil
+}
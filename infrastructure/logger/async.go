@@ -0,0 +1,230 @@
+package logger
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Overflow decides what AsyncWriter does with a line when its buffer is full.
+type Overflow string
+
+const (
+	// OverflowBlock waits for room, slowing the caller down like a
+	// synchronous write would.
+	OverflowBlock Overflow = "block"
+	// OverflowDropNewest discards the line being written.
+	OverflowDropNewest Overflow = "drop_newest"
+	// OverflowDropOldest discards the oldest buffered line to make room.
+	OverflowDropOldest Overflow = "drop_oldest"
+)
+
+func ParseOverflow(s string) (Overflow, error) {
+	switch o := Overflow(strings.ToLower(strings.TrimSpace(s))); o {
+	case OverflowBlock, OverflowDropNewest, OverflowDropOldest:
+		return o, nil
+	}
+	return OverflowBlock, fmt.Errorf("unknown log overflow policy %q", s)
+}
+
+// asyncItem is a line to write or, when flushed is set, a marker that every
+// line queued before it has been written. leveled lines go to a LevelWriter
+// through WriteLevel.
+type asyncItem struct {
+	line    []byte
+	level   Level
+	leveled bool
+	flushed chan error
+}
+
+// AsyncWriter queues writes in a bounded buffer and writes them to the
+// underlying writer from a background goroutine, batching lines that arrive
+// together. A LevelWriter underneath gets each line on its own, with its
+// level, instead. Write only fails once the writer is closed; errors from the
+// underlying writer are returned by Flush.
+type AsyncWriter struct {
+	out      io.Writer
+	buf      *bufio.Writer
+	overflow Overflow
+	queue    chan asyncItem
+	dropped  atomic.Uint64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+	err       error
+}
+
+func NewAsyncWriter(out io.Writer, size int, overflow Overflow) *AsyncWriter {
+	if size <= 0 {
+		size = 8192
+	}
+	w := &AsyncWriter{
+		out:      out,
+		buf:      bufio.NewWriterSize(out, 64<<10),
+		overflow: overflow,
+		queue:    make(chan asyncItem, size),
+		closed:   make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	// Callers such as the standard library logger reuse p.
+	return w.enqueue(asyncItem{line: append([]byte(nil), p...)})
+}
+
+func (w *AsyncWriter) WriteLevel(level Level, p []byte) (int, error) {
+	return w.enqueue(asyncItem{line: append([]byte(nil), p...), level: level, leveled: true})
+}
+
+func (w *AsyncWriter) enqueue(item asyncItem) (int, error) {
+	p := item.line
+	select {
+	case <-w.closed:
+		return 0, io.ErrClosedPipe
+	default:
+	}
+	switch w.overflow {
+	case OverflowDropNewest:
+		select {
+		case w.queue <- item:
+		default:
+			w.dropped.Add(1)
+		}
+	case OverflowDropOldest:
+		for {
+			select {
+			case w.queue <- item:
+				return len(p), nil
+			default:
+			}
+			select {
+			case old := <-w.queue:
+				if old.flushed != nil {
+					// Never drop a flush marker; put it back and give up on
+					// this line instead.
+					w.queue <- old
+					w.dropped.Add(1)
+					return len(p), nil
+				}
+				w.dropped.Add(1)
+			default:
+			}
+		}
+	default:
+		select {
+		case w.queue <- item:
+		case <-w.closed:
+			return 0, io.ErrClosedPipe
+		}
+	}
+	return len(p), nil
+}
+
+// Dropped counts lines discarded because the buffer was full.
+func (w *AsyncWriter) Dropped() uint64 {
+	return w.dropped.Load()
+}
+
+// Flush waits until every line written before it reaches the underlying
+// writer, syncing it when it supports that, e.g. a file.
+func (w *AsyncWriter) Flush() error {
+	select {
+	case <-w.closed:
+		return io.ErrClosedPipe
+	default:
+	}
+	flushed := make(chan error, 1)
+	select {
+	case <-w.closed:
+		return io.ErrClosedPipe
+	case w.queue <- asyncItem{flushed: flushed}:
+	}
+	select {
+	case err := <-flushed:
+		return err
+	case <-w.done:
+		return w.err
+	}
+}
+
+// Sync is Flush, so AsyncWriter can stand in for a file.
+func (w *AsyncWriter) Sync() error {
+	return w.Flush()
+}
+
+// Close flushes the buffered lines and stops the background writer. It does
+// not close the underlying writer.
+func (w *AsyncWriter) Close() error {
+	err := w.Flush()
+	if errors.Is(err, io.ErrClosedPipe) {
+		return nil
+	}
+	w.closeOnce.Do(func() { close(w.closed) })
+	<-w.done
+	return err
+}
+
+func (w *AsyncWriter) run() {
+	defer close(w.done)
+	for {
+		select {
+		case item := <-w.queue:
+			w.handle(item)
+			// Write out whatever else is already queued before flushing the
+			// batch to the underlying writer.
+			w.drain()
+			w.record(w.buf.Flush())
+		case <-w.closed:
+			w.drain()
+			w.record(w.buf.Flush())
+			return
+		}
+	}
+}
+
+func (w *AsyncWriter) drain() {
+	for n := len(w.queue); n > 0; n-- {
+		select {
+		case item := <-w.queue:
+			w.handle(item)
+		default:
+			return
+		}
+	}
+}
+
+func (w *AsyncWriter) handle(item asyncItem) {
+	if item.flushed == nil {
+		if lw, ok := w.out.(LevelWriter); ok {
+			level := LevelInfo
+			if item.leveled {
+				level = item.level
+			}
+			_, err := lw.WriteLevel(level, item.line)
+			w.record(err)
+			return
+		}
+		_, err := w.buf.Write(item.line)
+		w.record(err)
+		return
+	}
+	err := w.buf.Flush()
+	if s, ok := w.out.(interface{ Sync() error }); ok && err == nil {
+		err = s.Sync()
+	}
+	item.flushed <- err
+}
+
+func (w *AsyncWriter) record(err error) {
+	if err != nil && w.err == nil {
+		w.err = err
+	}
+}
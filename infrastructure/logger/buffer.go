@@ -0,0 +1,126 @@
+package logger
+
+import (
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Buffer is the byte buffer encoders write an entry into. Buffers are pooled
+// and reused across entries, so encoding doesn't allocate once they've grown
+// to fit.
+type Buffer struct {
+	b []byte
+}
+
+// maxPooledBuffer keeps the occasional huge entry, e.g. one with a long
+// stack, from pinning its buffer's memory in the pool.
+const maxPooledBuffer = 64 << 10
+
+var bufferPool = sync.Pool{New: func() any { return &Buffer{b: make([]byte, 0, 1024)} }}
+
+func getBuffer() *Buffer {
+	b := bufferPool.Get().(*Buffer)
+	b.b = b.b[:0]
+	return b
+}
+
+func putBuffer(b *Buffer) {
+	if cap(b.b) <= maxPooledBuffer {
+		bufferPool.Put(b)
+	}
+}
+
+func (b *Buffer) Write(p []byte) (int, error) {
+	b.b = append(b.b, p...)
+	return len(p), nil
+}
+
+func (b *Buffer) WriteString(s string) (int, error) {
+	b.b = append(b.b, s...)
+	return len(s), nil
+}
+
+func (b *Buffer) WriteByte(c byte) error {
+	b.b = append(b.b, c)
+	return nil
+}
+
+func (b *Buffer) Bytes() []byte  { return b.b }
+func (b *Buffer) String() string { return string(b.b) }
+func (b *Buffer) Len() int       { return len(b.b) }
+func (b *Buffer) Reset()         { b.b = b.b[:0] }
+
+func (b *Buffer) AppendInt(n int64) {
+	b.b = strconv.AppendInt(b.b, n, 10)
+}
+
+func (b *Buffer) AppendFloat(f float64) {
+	b.b = strconv.AppendFloat(b.b, f, 'g', -1, 64)
+}
+
+func (b *Buffer) AppendBool(v bool) {
+	b.b = strconv.AppendBool(b.b, v)
+}
+
+func (b *Buffer) AppendTime(t time.Time, layout string) {
+	b.b = t.AppendFormat(b.b, layout)
+}
+
+// AppendQuoted appends s as a Go quoted string.
+func (b *Buffer) AppendQuoted(s string) {
+	b.b = strconv.AppendQuote(b.b, s)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// AppendJSONString appends s as a JSON string, escaping like encoding/json
+// including its HTML-safe escapes.
+func (b *Buffer) AppendJSONString(s string) {
+	b.b = append(b.b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			b.b = append(b.b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b.b = append(b.b, '\\', c)
+			case '\n':
+				b.b = append(b.b, '\\', 'n')
+			case '\r':
+				b.b = append(b.b, '\\', 'r')
+			case '\t':
+				b.b = append(b.b, '\\', 't')
+			default:
+				b.b = append(b.b, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.b = append(b.b, s[start:i]...)
+			b.b = append(b.b, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			b.b = append(b.b, s[start:i]...)
+			b.b = append(b.b, '\\', 'u', '2', '0', '2', hexDigits[r&0xf])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b.b = append(b.b, s[start:]...)
+	b.b = append(b.b, '"')
+}
@@ -0,0 +1,79 @@
+package logger
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// ComponentKey is the field naming the component a Named logger logs for.
+const ComponentKey = "component"
+
+// noOverride marks a component that follows the logger's level.
+const noOverride = -1
+
+// componentLevels holds the level overrides of components, shared by every
+// logger derived from the same New. Each component's level is an atomic its
+// Named loggers keep a pointer to, so checking it takes no lock.
+type componentLevels struct {
+	mu     sync.Mutex
+	levels map[string]*atomic.Int32
+}
+
+func (c *componentLevels) get(component string) *atomic.Int32 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	level, ok := c.levels[component]
+	if !ok {
+		level = &atomic.Int32{}
+		level.Store(noOverride)
+		c.levels[component] = level
+	}
+	return level
+}
+
+// WithComponentLevels sets the initial level overrides by component name,
+// e.g. ingestion=debug, http=warn.
+func WithComponentLevels(levels map[string]Level) Option {
+	return func(l *Logger) {
+		for component, level := range levels {
+			l.components.get(component).Store(int32(level))
+		}
+	}
+}
+
+// Named returns a logger for a component of the application, such as http or
+// ingestion, whose level can be overridden apart from the rest. Its entries
+// carry the component name, joined to l's with a dot when l is named too.
+func (l *Logger) Named(component string) *Logger {
+	if l.component != "" {
+		component = l.component + "." + component
+	}
+	clone := *l
+	clone.component = component
+	clone.componentLevel = l.components.get(component)
+	return &clone
+}
+
+// SetComponentLevel overrides the level of the component's loggers, taking
+// effect immediately.
+func (l *Logger) SetComponentLevel(component string, level Level) {
+	l.components.get(component).Store(int32(level))
+}
+
+// ResetComponentLevel puts the component's loggers back on the shared level.
+func (l *Logger) ResetComponentLevel(component string) {
+	l.components.get(component).Store(noOverride)
+}
+
+// ComponentLevels returns the overridden components and their levels.
+func (l *Logger) ComponentLevels() map[string]Level {
+	l.components.mu.Lock()
+	defer l.components.mu.Unlock()
+	levels := make(map[string]Level)
+	for component, level := range l.components.levels {
+		if v := level.Load(); v != noOverride {
+			levels[component] = Level(v)
+		}
+	}
+	return levels
+}
@@ -0,0 +1,133 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// ANSI escape codes used by ConsoleEncoder.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiDim    = "\x1b[2m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+	ansiPurple = "\x1b[35m"
+)
+
+var levelColors = map[Level]string{
+	LevelDebug:  ansiPurple,
+	LevelInfo:   ansiBlue,
+	LevelWarn:   ansiYellow,
+	LevelError:  ansiRed,
+	LevelDPanic: ansiBold + ansiRed,
+	LevelFatal:  ansiBold + ansiRed,
+}
+
+// Widths the console columns are padded to, so messages and fields line up.
+const (
+	consoleCallerWidth  = 20
+	consoleMessageWidth = 40
+)
+
+// ConsoleEncoder writes entries for a person at a terminal: local time, a
+// colored level, the caller and message in aligned columns, then the fields.
+// Structured values are pretty-printed as JSON and multi-line values, such as
+// errors with stacks, continue on indented lines below the entry.
+type ConsoleEncoder struct {
+	// NoColor leaves out the ANSI colors, e.g. when NO_COLOR is set.
+	NoColor bool
+}
+
+func (c ConsoleEncoder) Encode(b *Buffer, e Entry) {
+	c.color(b, ansiDim, e.Time.Local().Format("15:04:05.000"))
+	b.WriteByte(' ')
+	c.color(b, levelColors[e.Level], fmt.Sprintf("%-6s", strings.ToUpper(e.Level.String())))
+	b.WriteByte(' ')
+	c.color(b, ansiDim, pad(e.Caller, consoleCallerWidth))
+	fields := getBuffer()
+	defer putBuffer(fields)
+	var blocks []Field
+	for _, f := range e.Fields {
+		if f.Key == "" {
+			continue
+		}
+		value := consoleValue(f.AnyValue())
+		if strings.Contains(value, "\n") {
+			blocks = append(blocks, Field{Key: f.Key, Value: value})
+			continue
+		}
+		fields.WriteByte(' ')
+		c.color(fields, ansiCyan, f.Key+"=")
+		fields.WriteString(quote(value))
+	}
+	if e.Stack != "" {
+		blocks = append(blocks, Field{Key: "stack", Value: e.Stack})
+	}
+	b.WriteByte(' ')
+	if fields.Len() > 0 {
+		b.WriteString(pad(e.Message, consoleMessageWidth))
+		b.Write(fields.Bytes())
+	} else {
+		b.WriteString(e.Message)
+	}
+	b.WriteByte('\n')
+	for _, block := range blocks {
+		b.WriteString("    ")
+		c.color(b, ansiCyan, block.Key+":")
+		b.WriteByte('\n')
+		for _, line := range strings.Split(strings.TrimRight(block.Value.(string), "\n"), "\n") {
+			b.WriteString("      ")
+			b.WriteString(line)
+			b.WriteByte('\n')
+		}
+	}
+}
+
+func (c ConsoleEncoder) color(b *Buffer, code, s string) {
+	if c.NoColor || code == "" {
+		b.WriteString(s)
+		return
+	}
+	b.WriteString(code)
+	b.WriteString(s)
+	b.WriteString(ansiReset)
+}
+
+// pad right-pads s with spaces to width runes; longer values are left as is.
+func pad(s string, width int) string {
+	if n := utf8.RuneCountInString(s); n < width {
+		return s + strings.Repeat(" ", width-n)
+	}
+	return s
+}
+
+// consoleValue renders maps, slices and structs as indented JSON and
+// everything else as the text encoder would.
+func consoleValue(v any) string {
+	switch v.(type) {
+	case nil, string, error, time.Time, time.Duration, fmt.Stringer:
+		return text(v)
+	}
+	switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		if enc, err := json.MarshalIndent(v, "", "  "); err == nil {
+			return string(enc)
+		}
+	}
+	return text(v)
+}
+
+// IsTerminal reports whether f is attached to a terminal rather than a file
+// or pipe.
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
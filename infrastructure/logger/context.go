@@ -0,0 +1,24 @@
+package logger
+
+import "context"
+
+type contextKey struct{}
+
+// WithContext returns a copy of ctx carrying l, so code handling a request
+// logs with its request-scoped fields without l being passed down.
+func WithContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, contextKey{}, l)
+}
+
+// FromContext returns the logger stored on ctx, or the default logger.
+func FromContext(ctx context.Context) *Logger {
+	if l, ok := ctx.Value(contextKey{}).(*Logger); ok {
+		return l
+	}
+	return Default()
+}
+
+// ContextWithFields adds fields to the logger stored on ctx.
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	return WithContext(ctx, FromContext(ctx).WithFields(fields...))
+}
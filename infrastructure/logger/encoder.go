@@ -0,0 +1,206 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Format string
+
+const (
+	FormatText Format = "text"
+	FormatJSON Format = "json"
+	// FormatConsole is for people reading logs in a terminal; see
+	// ConsoleEncoder.
+	FormatConsole Format = "console"
+)
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(strings.ToLower(strings.TrimSpace(s))); f {
+	case FormatText, FormatJSON, FormatConsole:
+		return f, nil
+	case "logfmt":
+		return FormatText, nil
+	}
+	return FormatText, fmt.Errorf("unknown log format %q", s)
+}
+
+// Entry is a single log record as handed to an Encoder.
+type Entry struct {
+	Time    time.Time
+	Level   Level
+	Caller  string
+	Message string
+	Fields  []Field
+	// Stack is the goroutine's stack at the call site, for entries at or above
+	// the level set with WithStacktrace.
+	Stack string
+}
+
+// Encoder renders an entry as one line, including the trailing newline.
+type Encoder interface {
+	Encode(b *Buffer, e Entry)
+}
+
+func NewEncoder(f Format) Encoder {
+	switch f {
+	case FormatJSON:
+		return JSONEncoder{}
+	case FormatConsole:
+		return ConsoleEncoder{}
+	}
+	return TextEncoder{}
+}
+
+// TextEncoder writes key=value pairs, quoting values that need it.
+type TextEncoder struct{}
+
+func (TextEncoder) Encode(b *Buffer, e Entry) {
+	b.WriteString("ts=")
+	b.AppendTime(e.Time, time.RFC3339Nano)
+	b.WriteString(" level=")
+	b.WriteString(e.Level.String())
+	if e.Caller != "" {
+		b.WriteString(" caller=")
+		b.WriteString(e.Caller)
+	}
+	b.WriteString(" msg=")
+	writeTextString(b, e.Message)
+	for _, f := range e.Fields {
+		if f.Key == "" {
+			continue
+		}
+		b.WriteByte(' ')
+		b.WriteString(f.Key)
+		b.WriteByte('=')
+		writeTextValue(b, f)
+	}
+	if e.Stack != "" {
+		b.WriteString(" stack=")
+		writeTextString(b, e.Stack)
+	}
+	b.WriteByte('\n')
+}
+
+// writeTextValue appends numbers and booleans directly and everything else
+// through text, quoted when needed.
+func writeTextValue(b *Buffer, f Field) {
+	switch f.kind {
+	case kindString:
+		writeTextString(b, f.str)
+	case kindInt, kindInt64:
+		b.AppendInt(f.num)
+	case kindFloat64:
+		b.AppendFloat(math.Float64frombits(uint64(f.num)))
+	case kindBool:
+		b.AppendBool(f.num != 0)
+	case kindDuration:
+		b.WriteString(time.Duration(f.num).String())
+	default:
+		writeTextString(b, text(f.Value))
+	}
+}
+
+func writeTextString(b *Buffer, s string) {
+	if needsQuote(s) {
+		b.AppendQuoted(s)
+		return
+	}
+	b.WriteString(s)
+}
+
+func needsQuote(s string) bool {
+	return s == "" || strings.ContainsAny(s, " \t\n\"=")
+}
+
+func quote(s string) string {
+	if needsQuote(s) {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+// JSONEncoder writes one JSON object per line. Fields are top-level keys;
+// those clashing with ts, level, caller, msg or stack are prefixed with
+// "fields.".
+type JSONEncoder struct{}
+
+func (JSONEncoder) Encode(b *Buffer, e Entry) {
+	b.WriteString(`{"ts":"`)
+	b.AppendTime(e.Time, time.RFC3339Nano)
+	b.WriteString(`","level":"`)
+	b.WriteString(e.Level.String())
+	b.WriteByte('"')
+	if e.Caller != "" {
+		b.WriteString(`,"caller":`)
+		b.AppendJSONString(e.Caller)
+	}
+	b.WriteString(`,"msg":`)
+	b.AppendJSONString(e.Message)
+	for _, f := range e.Fields {
+		switch f.Key {
+		case "":
+			continue
+		case "ts", "level", "caller", "msg", "stack":
+			b.WriteString(`,"fields.`)
+			b.WriteString(f.Key)
+			b.WriteString(`":`)
+		default:
+			b.WriteByte(',')
+			b.AppendJSONString(f.Key)
+			b.WriteByte(':')
+		}
+		writeJSONField(b, f)
+	}
+	if e.Stack != "" {
+		b.WriteString(`,"stack":`)
+		b.AppendJSONString(e.Stack)
+	}
+	b.WriteString("}\n")
+}
+
+func writeJSONField(b *Buffer, f Field) {
+	switch f.kind {
+	case kindString:
+		b.AppendJSONString(f.str)
+	case kindInt, kindInt64:
+		b.AppendInt(f.num)
+	case kindFloat64:
+		writeJSONValue(b, math.Float64frombits(uint64(f.num)))
+	case kindBool:
+		b.AppendBool(f.num != 0)
+	case kindDuration:
+		b.AppendJSONString(time.Duration(f.num).String())
+	default:
+		writeJSONValue(b, f.Value)
+	}
+}
+
+// writeJSONValue keeps numbers, booleans and structured values as JSON and
+// renders errors, times and Stringers, such as durations, as their text.
+func writeJSONValue(b *Buffer, v any) {
+	switch v := v.(type) {
+	case nil:
+		b.WriteString("null")
+		return
+	case string:
+		b.AppendJSONString(v)
+		return
+	case error, time.Time:
+		b.AppendJSONString(text(v))
+		return
+	case fmt.Stringer:
+		b.AppendJSONString(v.String())
+		return
+	}
+	enc, err := json.Marshal(v)
+	if err != nil {
+		b.AppendJSONString(fmt.Sprint(v))
+		return
+	}
+	b.Write(enc)
+}
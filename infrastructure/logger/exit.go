@@ -0,0 +1,50 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"sync"
+)
+
+var exitHandlers struct {
+	mu       sync.Mutex
+	handlers []func()
+	exiting  bool
+}
+
+// RegisterExitHandler runs fn when the process exits through Exit or Fatal,
+// e.g. to flush logs or close a producer. Handlers run in reverse order of
+// registration, like deferred calls, so later ones may still log.
+func RegisterExitHandler(fn func()) {
+	exitHandlers.mu.Lock()
+	defer exitHandlers.mu.Unlock()
+	exitHandlers.handlers = append(exitHandlers.handlers, fn)
+}
+
+// Exit runs the exit handlers, then exits the process with code. Only the
+// first call runs them; a handler calling Exit exits straight away.
+func Exit(code int) {
+	exitHandlers.mu.Lock()
+	if exitHandlers.exiting {
+		exitHandlers.mu.Unlock()
+		os.Exit(code)
+	}
+	exitHandlers.exiting = true
+	handlers := exitHandlers.handlers
+	exitHandlers.mu.Unlock()
+
+	for i := len(handlers) - 1; i >= 0; i-- {
+		runExitHandler(handlers[i])
+	}
+	os.Exit(code)
+}
+
+// runExitHandler keeps a panicking handler from stopping the rest.
+func runExitHandler(fn func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "Exit handler panicked: %v\n", r)
+		}
+	}()
+	fn()
+}
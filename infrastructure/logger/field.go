@@ -0,0 +1,112 @@
+package logger
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// fieldKind says where a Field keeps its value. The typed constructors keep
+// strings and numbers out of Value, since boxing them in an interface would
+// allocate even for entries below the level.
+type fieldKind uint8
+
+const (
+	kindAny fieldKind = iota
+	kindString
+	kindInt
+	kindInt64
+	kindFloat64
+	kindBool
+	kindDuration
+)
+
+// Field is a key and value for an entry. Value only holds the values of
+// fields built by Any, Err or a literal; read any field's with AnyValue.
+type Field struct {
+	Key   string
+	Value any
+
+	kind fieldKind
+	str  string
+	num  int64
+}
+
+func String(key, value string) Field { return Field{Key: key, kind: kindString, str: value} }
+
+func Int(key string, value int) Field { return Field{Key: key, kind: kindInt, num: int64(value)} }
+
+func Int64(key string, value int64) Field { return Field{Key: key, kind: kindInt64, num: value} }
+
+func Float64(key string, value float64) Field {
+	return Field{Key: key, kind: kindFloat64, num: int64(math.Float64bits(value))}
+}
+
+func Bool(key string, value bool) Field {
+	f := Field{Key: key, kind: kindBool}
+	if value {
+		f.num = 1
+	}
+	return f
+}
+
+func Duration(key string, value time.Duration) Field {
+	return Field{Key: key, kind: kindDuration, num: int64(value)}
+}
+
+func Time(key string, value time.Time) Field { return Field{Key: key, Value: value} }
+
+// Err logs err under "error"; a nil error adds nothing.
+func Err(err error) Field {
+	if err == nil {
+		return Field{}
+	}
+	return Field{Key: "error", Value: err}
+}
+
+// NamedErr is Err under another key.
+func NamedErr(key string, err error) Field {
+	if err == nil {
+		return Field{}
+	}
+	return Field{Key: key, Value: err}
+}
+
+func Any(key string, value any) Field { return Field{Key: key, Value: value} }
+
+// AnyValue returns the field's value whichever way it was built.
+func (f Field) AnyValue() any {
+	switch f.kind {
+	case kindString:
+		return f.str
+	case kindInt:
+		return int(f.num)
+	case kindInt64:
+		return f.num
+	case kindFloat64:
+		return math.Float64frombits(uint64(f.num))
+	case kindBool:
+		return f.num != 0
+	case kindDuration:
+		return time.Duration(f.num)
+	}
+	return f.Value
+}
+
+// text renders a field value for the text encoder. Errors use %+v, which
+// includes the stack or context of errors that record one; other errors skip
+// fmt and use Error. Times use RFC 3339.
+func text(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case error:
+		if _, ok := v.(fmt.Formatter); !ok {
+			return v.Error()
+		}
+		return fmt.Sprintf("%+v", v)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,199 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Hook is told about entries at or above the level it was added with, after
+// redaction and before the entry is written. Fire runs on the logging
+// goroutine, so hooks that do I/O should hand the entry off, like WebhookHook.
+type Hook interface {
+	Fire(e Entry) error
+}
+
+type HookFunc func(e Entry) error
+
+func (f HookFunc) Fire(e Entry) error {
+	return f(e)
+}
+
+type leveledHook struct {
+	level Level
+	hook  Hook
+}
+
+// WithHook calls h for entries at level or above, e.g. LevelError to alert on
+// failures. Hook errors are reported through the standard library logger.
+func WithHook(level Level, h Hook) Option {
+	return func(l *Logger) {
+		l.hooks = append(l.hooks, leveledHook{level: level, hook: h})
+	}
+}
+
+func (l *Logger) fireHooks(e Entry) {
+	for _, h := range l.hooks {
+		if e.Level < h.level {
+			continue
+		}
+		if err := h.hook.Fire(e); err != nil {
+			log.Printf("Log hook failed: %v\n", err)
+		}
+	}
+}
+
+type WebhookConfig struct {
+	URL     string
+	Timeout time.Duration
+	// QueueSize bounds the alerts waiting to be sent; more are dropped.
+	QueueSize int
+}
+
+// webhookPayload is Slack incoming-webhook compatible through Text; other
+// receivers can use the structured fields.
+type webhookPayload struct {
+	Text    string         `json:"text"`
+	Level   string         `json:"level"`
+	Message string         `json:"message"`
+	Caller  string         `json:"caller,omitempty"`
+	Time    time.Time      `json:"time"`
+	Fields  map[string]any `json:"fields,omitempty"`
+	Stack   string         `json:"stack,omitempty"`
+}
+
+// WebhookHook posts entries as JSON to a URL, such as a Slack incoming webhook
+// or an alerting gateway. Entries are sent in the background so logging never
+// waits on the network, except fatal ones, which are sent before the process
+// exits.
+type WebhookHook struct {
+	cfg        WebhookConfig
+	httpClient *http.Client
+	queue      chan Entry
+	dropped    atomic.Uint64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+func NewWebhookHook(cfg WebhookConfig) *WebhookHook {
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 100
+	}
+	h := &WebhookHook{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		queue:      make(chan Entry, cfg.QueueSize),
+		closed:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go h.run()
+	return h
+}
+
+func (h *WebhookHook) Fire(e Entry) error {
+	if e.Level >= LevelFatal {
+		return h.send(e)
+	}
+	select {
+	case <-h.closed:
+		h.dropped.Add(1)
+		return nil
+	default:
+	}
+	select {
+	case h.queue <- e:
+	default:
+		h.dropped.Add(1)
+	}
+	return nil
+}
+
+// Dropped counts entries not sent because the queue was full or the hook
+// closed.
+func (h *WebhookHook) Dropped() uint64 {
+	return h.dropped.Load()
+}
+
+// Close sends the queued entries and stops the background sender.
+func (h *WebhookHook) Close() error {
+	h.closeOnce.Do(func() { close(h.closed) })
+	<-h.done
+	return nil
+}
+
+func (h *WebhookHook) run() {
+	defer close(h.done)
+	for {
+		select {
+		case e := <-h.queue:
+			h.deliver(e)
+		case <-h.closed:
+			for {
+				select {
+				case e := <-h.queue:
+					h.deliver(e)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
+
+func (h *WebhookHook) deliver(e Entry) {
+	if err := h.send(e); err != nil {
+		log.Printf("Sending log alert failed: %v\n", err)
+	}
+}
+
+func (h *WebhookHook) send(e Entry) error {
+	payload := webhookPayload{
+		Text:    fmt.Sprintf("[%s] %s", strings.ToUpper(e.Level.String()), e.Message),
+		Level:   e.Level.String(),
+		Message: e.Message,
+		Caller:  e.Caller,
+		Time:    e.Time,
+		Stack:   e.Stack,
+	}
+	for _, f := range e.Fields {
+		if f.Key == "" {
+			continue
+		}
+		if payload.Fields == nil {
+			payload.Fields = map[string]any{}
+		}
+		switch v := f.AnyValue().(type) {
+		case error, fmt.Stringer:
+			payload.Fields[f.Key] = text(v)
+		default:
+			if b, err := json.Marshal(v); err == nil {
+				payload.Fields[f.Key] = json.RawMessage(b)
+			} else {
+				payload.Fields[f.Key] = text(v)
+			}
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("encode log alert: %w", err)
+	}
+	resp, err := h.httpClient.Post(h.cfg.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("log alert webhook returned %s", resp.Status)
+	}
+	return nil
+}
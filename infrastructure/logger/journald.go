@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// JournaldSocket is where systemd-journald receives native protocol messages.
+const JournaldSocket = "/run/systemd/journal/socket"
+
+// JournaldWriter sends entries to systemd-journald over its native protocol,
+// with the level as PRIORITY and the encoded entry as MESSAGE.
+type JournaldWriter struct {
+	conn *net.UnixConn
+	tag  string
+}
+
+func DialJournald(socket, tag string) (*JournaldWriter, error) {
+	if socket == "" {
+		socket = JournaldSocket
+	}
+	if tag == "" {
+		tag = "sarama"
+	}
+	conn, err := net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if err != nil {
+		return nil, fmt.Errorf("journald: %w", err)
+	}
+	return &JournaldWriter{conn: conn, tag: tag}, nil
+}
+
+func (w *JournaldWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(LevelInfo, p)
+}
+
+func (w *JournaldWriter) WriteLevel(level Level, p []byte) (int, error) {
+	severity, ok := syslogSeverity[level]
+	if !ok {
+		severity = 6
+	}
+	var b bytes.Buffer
+	writeJournalField(&b, "PRIORITY", strconv.Itoa(severity))
+	writeJournalField(&b, "SYSLOG_IDENTIFIER", w.tag)
+	writeJournalField(&b, "MESSAGE", strings.TrimRight(string(p), "\n"))
+	if _, err := w.conn.Write(b.Bytes()); err != nil {
+		return 0, fmt.Errorf("journald: %w", err)
+	}
+	return len(p), nil
+}
+
+func (w *JournaldWriter) Close() error {
+	return w.conn.Close()
+}
+
+// writeJournalField writes KEY=value, or for values with newlines the
+// length-prefixed form the protocol requires.
+func writeJournalField(b *bytes.Buffer, key, value string) {
+	b.WriteString(key)
+	if !strings.Contains(value, "\n") {
+		b.WriteByte('=')
+		b.WriteString(value)
+		b.WriteByte('\n')
+		return
+	}
+	b.WriteByte('\n')
+	binary.Write(b, binary.LittleEndian, uint64(len(value)))
+	b.WriteString(value)
+	b.WriteByte('\n')
+}
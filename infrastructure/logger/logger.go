@@ -0,0 +1,361 @@
+package logger
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type Level int
+
+const (
+	LevelDebug Level = iota
+	LevelInfo
+	LevelWarn
+	LevelError
+	// LevelDPanic is for should-never-happen conditions: an error in
+	// production, a panic in development, so tests catch them.
+	LevelDPanic
+	LevelFatal
+)
+
+var levelNames = map[Level]string{
+	LevelDebug:  "debug",
+	LevelInfo:   "info",
+	LevelWarn:   "warn",
+	LevelError:  "error",
+	LevelDPanic: "dpanic",
+	LevelFatal:  "fatal",
+}
+
+func (l Level) String() string {
+	if name, ok := levelNames[l]; ok {
+		return name
+	}
+	return "level(" + strconv.Itoa(int(l)) + ")"
+}
+
+// ParseLevel accepts the level names case-insensitively, plus "warning".
+func ParseLevel(s string) (Level, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "warning" {
+		return LevelWarn, nil
+	}
+	for level, name := range levelNames {
+		if name == s {
+			return level, nil
+		}
+	}
+	return LevelInfo, fmt.Errorf("unknown log level %q", s)
+}
+
+// Logger writes leveled lines, key=value text by default, to one or more
+// sinks. Loggers derived with WithField share everything but their fields with
+// the parent, including the level.
+type Logger struct {
+	mu       *sync.Mutex
+	sinks    []Sink
+	enc      Encoder
+	level    *atomic.Int32
+	sampler  *sampler
+	redactor *Redactor
+	hooks    []leveledHook
+	fields   []Field
+	// noCaller skips the runtime lookup of the call site; callerSkip counts
+	// wrapper frames between the call site and the logging method.
+	noCaller   bool
+	callerSkip int
+	// stackLevel is the lowest level entries carry a stack trace at; none do
+	// while it is above LevelFatal.
+	stackLevel Level
+	// development makes DPanic panic.
+	development bool
+	// component names a Named logger; componentLevel, when not noOverride,
+	// replaces level for it.
+	component      string
+	componentLevel *atomic.Int32
+	components     *componentLevels
+}
+
+// Sink is a destination for log entries with its own encoder and minimum
+// level, on top of the logger's. A nil Encoder uses the logger's.
+type Sink struct {
+	Out     io.Writer
+	Encoder Encoder
+	Level   Level
+}
+
+type Option func(*Logger)
+
+// WithFormat selects one of the built-in encoders.
+func WithFormat(f Format) Option {
+	return func(l *Logger) {
+		l.enc = NewEncoder(f)
+	}
+}
+
+func WithEncoder(enc Encoder) Option {
+	return func(l *Logger) {
+		l.enc = enc
+	}
+}
+
+// WithSinks fans entries out to sinks instead of New's out.
+func WithSinks(sinks ...Sink) Option {
+	return func(l *Logger) {
+		l.sinks = sinks
+	}
+}
+
+// WithCaller turns the caller annotation on or off; it is on by default and
+// costs a stack lookup per entry.
+func WithCaller(enabled bool) Option {
+	return func(l *Logger) {
+		l.noCaller = !enabled
+	}
+}
+
+// WithCallerSkip reports the caller n frames further up the stack, for
+// loggers only ever used through a wrapper.
+func WithCallerSkip(n int) Option {
+	return func(l *Logger) {
+		l.callerSkip += n
+	}
+}
+
+// WithStacktrace attaches the stack to entries at level or above, e.g.
+// LevelError.
+func WithStacktrace(level Level) Option {
+	return func(l *Logger) {
+		l.stackLevel = level
+	}
+}
+
+// WithDevelopment makes DPanic panic after logging.
+func WithDevelopment(enabled bool) Option {
+	return func(l *Logger) {
+		l.development = enabled
+	}
+}
+
+func New(out io.Writer, level Level, opts ...Option) *Logger {
+	l := &Logger{
+		mu:         &sync.Mutex{},
+		enc:        TextEncoder{},
+		level:      &atomic.Int32{},
+		stackLevel: LevelFatal + 1,
+		components: &componentLevels{levels: make(map[string]*atomic.Int32)},
+	}
+	l.level.Store(int32(level))
+	for _, opt := range opts {
+		opt(l)
+	}
+	if l.sinks == nil {
+		l.sinks = []Sink{{Out: out}}
+	}
+	for i := range l.sinks {
+		if l.sinks[i].Encoder == nil {
+			l.sinks[i].Encoder = l.enc
+		}
+	}
+	return l
+}
+
+var std = New(os.Stderr, LevelInfo)
+
+// Default returns the process-wide logger.
+func Default() *Logger {
+	return std
+}
+
+// SetDefault replaces the process-wide logger.
+func SetDefault(l *Logger) {
+	std = l
+}
+
+func (l *Logger) WithField(key string, value any) *Logger {
+	return l.WithFields(Field{Key: key, Value: value})
+}
+
+// WithFields returns a logger adding fields to l's. Derived loggers never share
+// a backing array, with l or each other, so siblings can't overwrite each
+// other's fields, and callers may reuse the fields slice afterwards.
+func (l *Logger) WithFields(fields ...Field) *Logger {
+	// Always a new array: appending to l.fields could write into spare
+	// capacity a sibling also appended into.
+	merged := make([]Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	clone := *l
+	clone.fields = merged
+	return &clone
+}
+
+// AddCallerSkip returns a logger reporting the caller n frames further up the
+// stack, so helpers that log on their callers' behalf report the true call
+// site rather than themselves.
+func (l *Logger) AddCallerSkip(n int) *Logger {
+	clone := *l
+	clone.callerSkip += n
+	return &clone
+}
+
+// Level is the level l logs at: its component's override when it has one,
+// else the shared level.
+func (l *Logger) Level() Level {
+	if l.componentLevel != nil {
+		if level := l.componentLevel.Load(); level != noOverride {
+			return Level(level)
+		}
+	}
+	return Level(l.level.Load())
+}
+
+// SetLevel changes the level of l and of every logger related to it through
+// WithField or Named, taking effect immediately; component overrides still
+// take precedence.
+func (l *Logger) SetLevel(level Level) {
+	l.level.Store(int32(level))
+}
+
+// Sync flushes buffered output, such as an AsyncWriter's, of the sinks that
+// support it.
+func (l *Logger) Sync() error {
+	var errs []error
+	for _, sink := range l.sinks {
+		if s, ok := sink.Out.(interface{ Sync() error }); ok {
+			errs = append(errs, s.Sync())
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (l *Logger) Enabled(level Level) bool {
+	return level >= l.Level()
+}
+
+func (l *Logger) Debug(msg string, fields ...Field) { l.log(LevelDebug, msg, fields) }
+func (l *Logger) Info(msg string, fields ...Field)  { l.log(LevelInfo, msg, fields) }
+func (l *Logger) Warn(msg string, fields ...Field)  { l.log(LevelWarn, msg, fields) }
+func (l *Logger) Error(msg string, fields ...Field) { l.log(LevelError, msg, fields) }
+
+// DPanic logs an invariant violation, then panics with msg when the logger is
+// in development mode.
+func (l *Logger) DPanic(msg string, fields ...Field) {
+	l.log(LevelDPanic, msg, fields)
+	if l.development {
+		panic(msg)
+	}
+}
+
+// Fatal logs, flushes, runs the exit handlers and exits the process with
+// status 1.
+func (l *Logger) Fatal(msg string, fields ...Field) {
+	l.log(LevelFatal, msg, fields)
+	l.Sync()
+	Exit(1)
+}
+
+func (l *Logger) log(level Level, msg string, fields []Field) {
+	if !l.Enabled(level) {
+		return
+	}
+	now := time.Now()
+	if l.sampler != nil && !l.sampler.allow(level, msg, now) {
+		return
+	}
+	e := Entry{Time: now.UTC(), Level: level, Message: msg}
+	if !l.noCaller {
+		e.Caller = caller(3 + l.callerSkip)
+	}
+	if level >= l.stackLevel {
+		e.Stack = stack(3 + l.callerSkip)
+	}
+	if n := len(l.fields) + len(fields); n > 0 || l.component != "" {
+		e.Fields = make([]Field, 0, n+1)
+		if l.component != "" {
+			e.Fields = append(e.Fields, String(ComponentKey, l.component))
+		}
+		e.Fields = append(e.Fields, l.fields...)
+		e.Fields = append(e.Fields, fields...)
+	}
+	if l.redactor != nil {
+		l.redactor.redact(&e)
+	}
+	l.fireHooks(e)
+
+	b := getBuffer()
+	defer putBuffer(b)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, sink := range l.sinks {
+		if level < sink.Level {
+			continue
+		}
+		b.Reset()
+		sink.Encoder.Encode(b, e)
+		if lw, ok := sink.Out.(LevelWriter); ok {
+			lw.WriteLevel(level, b.Bytes())
+			continue
+		}
+		sink.Out.Write(b.Bytes())
+	}
+}
+
+var (
+	callersMu sync.RWMutex
+	// callers caches the file:line of each call site by program counter, so
+	// formatting it happens once per site rather than once per entry.
+	callers = make(map[uintptr]string)
+)
+
+// caller returns the file:line skip frames up, as caller's caller is 1.
+func caller(skip int) string {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+1, pcs[:]) == 0 {
+		return ""
+	}
+	pc := pcs[0]
+	callersMu.RLock()
+	s, ok := callers[pc]
+	callersMu.RUnlock()
+	if ok {
+		return s
+	}
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	s = filepath.Base(frame.File) + ":" + strconv.Itoa(frame.Line)
+	callersMu.Lock()
+	callers[pc] = s
+	callersMu.Unlock()
+	return s
+}
+
+// stack formats the calling goroutine's stack from skip frames up, one
+// function per line followed by its indented file and line.
+func stack(skip int) string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip+1, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	var b strings.Builder
+	for {
+		frame, more := frames.Next()
+		b.WriteString(frame.Function)
+		b.WriteString("\n\t")
+		b.WriteString(frame.File)
+		b.WriteByte(':')
+		b.WriteString(strconv.Itoa(frame.Line))
+		if !more {
+			break
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
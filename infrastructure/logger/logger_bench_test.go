@@ -0,0 +1,91 @@
+package logger
+
+import (
+	"errors"
+	"io"
+	"testing"
+	"time"
+)
+
+var errBench = errors.New("connection reset by peer")
+
+func benchFields() []Field {
+	return []Field{
+		String("request_id", "7f3c9a2e-41d0-4b8e-9a57-3c2d1e0f8b6a"),
+		Int("status", 502),
+		Duration("elapsed", 1250*time.Millisecond),
+		Err(errBench),
+	}
+}
+
+// BenchmarkDisabled logs below the level, which should cost nothing.
+func BenchmarkDisabled(b *testing.B) {
+	l := New(io.Discard, LevelInfo).WithFields(String("component", "ingest"))
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Debug("fetched page", String("document_id", "12345"), Int("chunks", 42), Duration("elapsed", time.Second))
+	}
+}
+
+func BenchmarkEnabled(b *testing.B) {
+	for _, format := range []Format{FormatText, FormatJSON, FormatConsole} {
+		b.Run(string(format), func(b *testing.B) {
+			l := New(io.Discard, LevelInfo, WithFormat(format)).WithFields(String("component", "ingest"))
+			fields := benchFields()
+			b.ReportAllocs()
+			for b.Loop() {
+				l.Info("upstream failed", fields...)
+			}
+		})
+	}
+}
+
+// BenchmarkEnabledNoCaller leaves out the call-site lookup.
+func BenchmarkEnabledNoCaller(b *testing.B) {
+	l := New(io.Discard, LevelInfo, WithCaller(false))
+	fields := benchFields()
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Info("upstream failed", fields...)
+	}
+}
+
+func BenchmarkEnabledAny(b *testing.B) {
+	l := New(io.Discard, LevelInfo, WithFormat(FormatJSON))
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Info("upstream failed", Any("spaces", []string{"ENG", "OPS"}), Any("retry", true))
+	}
+}
+
+func BenchmarkRedacted(b *testing.B) {
+	r, err := NewRedactor([]string{"password"}, []string{`sk-[A-Za-z0-9]{20,}`})
+	if err != nil {
+		b.Fatal(err)
+	}
+	l := New(io.Discard, LevelInfo, WithRedaction(r))
+	fields := append(benchFields(), String("password", "hunter2"))
+	b.ReportAllocs()
+	for b.Loop() {
+		l.Info("upstream failed", fields...)
+	}
+}
+
+func BenchmarkWithFields(b *testing.B) {
+	l := New(io.Discard, LevelInfo).WithFields(String("component", "ingest"))
+	b.ReportAllocs()
+	for b.Loop() {
+		l.WithFields(String("request_id", "7f3c9a2e"), String("tenant", "acme")).Info("handled")
+	}
+}
+
+func BenchmarkEnabledParallel(b *testing.B) {
+	l := New(io.Discard, LevelInfo)
+	fields := benchFields()
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Info("upstream failed", fields...)
+		}
+	})
+}
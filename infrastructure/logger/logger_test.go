@@ -0,0 +1,71 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"maps"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+func TestWithFieldsSiblingsDoNotShareFields(t *testing.T) {
+	var out bytes.Buffer
+	// Built up a field at a time, as request middleware does; a WithFields
+	// that appended to its parent's slice would leave this one with spare
+	// capacity for all its children to write into.
+	parent := New(&out, LevelInfo, WithFormat(FormatJSON), WithCaller(false)).
+		WithField("service", "api").
+		WithField("region", "eu").
+		WithField("version", "1.4.2")
+
+	const workers, lines = 32, 50
+	var wg sync.WaitGroup
+	for i := range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			id := strconv.Itoa(i)
+			child := parent.WithFields(String("worker", id))
+			for j := range lines {
+				child.WithField("line", j).WithFields(String("echo", id)).Info("working")
+				child.Info("working", String("echo", id))
+			}
+		}()
+	}
+	wg.Wait()
+	parent.Info("done")
+
+	entries := bytes.Split(bytes.TrimSuffix(out.Bytes(), []byte("\n")), []byte("\n"))
+	if want := workers*lines*2 + 1; len(entries) != want {
+		t.Fatalf("got %d entries, want %d", len(entries), want)
+	}
+	for _, line := range entries {
+		var e map[string]any
+		if err := json.Unmarshal(line, &e); err != nil {
+			t.Fatalf("entry %s: %v", line, err)
+		}
+		if e["service"] != "api" {
+			t.Errorf("entry %s: service = %v, want api", line, e["service"])
+		}
+		if e["worker"] != e["echo"] {
+			t.Errorf("entry %s: worker field leaked from a sibling", line)
+		}
+		keys := slices.Sorted(maps.Keys(e))
+		switch {
+		case e["msg"] == "done":
+			if !slices.Equal(keys, []string{"level", "msg", "region", "service", "ts", "version"}) {
+				t.Errorf("parent entry %s: keys %v", line, keys)
+			}
+		case e["line"] == nil:
+			if !slices.Equal(keys, []string{"echo", "level", "msg", "region", "service", "ts", "version", "worker"}) {
+				t.Errorf("child entry %s: keys %v", line, keys)
+			}
+		default:
+			if !slices.Equal(keys, []string{"echo", "level", "line", "msg", "region", "service", "ts", "version", "worker"}) {
+				t.Errorf("grandchild entry %s: keys %v", line, keys)
+			}
+		}
+	}
+}
@@ -0,0 +1,348 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Fields holding the W3C trace context of an entry; the OTLP exporter sends
+// them as the record's trace and span IDs rather than attributes.
+const (
+	TraceIDKey = "trace_id"
+	SpanIDKey  = "span_id"
+)
+
+const otlpScope = "github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+
+// otlpSeverity maps levels to OpenTelemetry severity numbers.
+var otlpSeverity = map[Level]int{
+	LevelDebug:  5,
+	LevelInfo:   9,
+	LevelWarn:   13,
+	LevelError:  17,
+	LevelDPanic: 18,
+	LevelFatal:  21,
+}
+
+type OTLPConfig struct {
+	// Endpoint is the full OTLP/HTTP logs URL, e.g.
+	// http://collector:4318/v1/logs.
+	Endpoint    string
+	Headers     map[string]string
+	ServiceName string
+	// Environment is sent as the deployment.environment resource attribute.
+	Environment string
+	Timeout     time.Duration
+	// Records are exported every FlushInterval or once BatchSize are
+	// waiting; beyond QueueSize waiting they are dropped.
+	FlushInterval time.Duration
+	BatchSize     int
+	QueueSize     int
+}
+
+func (c OTLPConfig) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+// OTLPExporter is a Hook that exports entries as OpenTelemetry log records
+// over OTLP/HTTP with JSON encoding, in batches from a background goroutine.
+// Fatal entries are exported before Fire returns, along with anything queued.
+type OTLPExporter struct {
+	cfg        OTLPConfig
+	httpClient *http.Client
+	resource   otlpResource
+	queue      chan Entry
+	flush      chan chan error
+	dropped    atomic.Uint64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+func NewOTLPExporter(cfg OTLPConfig) *OTLPExporter {
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "sarama"
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 512
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 2048
+	}
+	resource := otlpResource{Attributes: []otlpAttribute{stringAttribute("service.name", cfg.ServiceName)}}
+	if cfg.Environment != "" {
+		resource.Attributes = append(resource.Attributes, stringAttribute("deployment.environment", cfg.Environment))
+	}
+	x := &OTLPExporter{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		resource:   resource,
+		queue:      make(chan Entry, cfg.QueueSize),
+		flush:      make(chan chan error),
+		closed:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go x.run()
+	return x
+}
+
+func (x *OTLPExporter) Fire(e Entry) error {
+	select {
+	case <-x.closed:
+		x.dropped.Add(1)
+		return nil
+	default:
+	}
+	if e.Level >= LevelFatal {
+		select {
+		case x.queue <- e:
+		case <-x.closed:
+			return nil
+		}
+		return x.Flush()
+	}
+	select {
+	case x.queue <- e:
+	default:
+		x.dropped.Add(1)
+	}
+	return nil
+}
+
+// Flush exports the queued records and waits for the export to finish.
+func (x *OTLPExporter) Flush() error {
+	result := make(chan error, 1)
+	select {
+	case x.flush <- result:
+		return <-result
+	case <-x.done:
+		return nil
+	}
+}
+
+// Dropped counts records not exported because the queue was full or the
+// exporter closed.
+func (x *OTLPExporter) Dropped() uint64 {
+	return x.dropped.Load()
+}
+
+// Close exports the queued records and stops the background exporter.
+func (x *OTLPExporter) Close() error {
+	x.closeOnce.Do(func() { close(x.closed) })
+	<-x.done
+	return nil
+}
+
+func (x *OTLPExporter) run() {
+	defer close(x.done)
+	ticker := time.NewTicker(x.cfg.FlushInterval)
+	defer ticker.Stop()
+	var batch []Entry
+	export := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := x.export(batch)
+		if err != nil {
+			log.Printf("Exporting logs failed: %v\n", err)
+		}
+		batch = batch[:0]
+		return err
+	}
+	drain := func() {
+		for {
+			select {
+			case e := <-x.queue:
+				batch = append(batch, e)
+				if len(batch) >= x.cfg.BatchSize {
+					export()
+				}
+			default:
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case e := <-x.queue:
+			batch = append(batch, e)
+			if len(batch) >= x.cfg.BatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case result := <-x.flush:
+			drain()
+			result <- export()
+		case <-x.closed:
+			drain()
+			export()
+			return
+		}
+	}
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpRecord struct {
+	TimeUnixNano         string          `json:"timeUnixNano"`
+	ObservedTimeUnixNano string          `json:"observedTimeUnixNano"`
+	SeverityNumber       int             `json:"severityNumber"`
+	SeverityText         string          `json:"severityText"`
+	Body                 otlpValue       `json:"body"`
+	Attributes           []otlpAttribute `json:"attributes,omitempty"`
+	TraceID              string          `json:"traceId,omitempty"`
+	SpanID               string          `json:"spanId,omitempty"`
+}
+
+type otlpRequest struct {
+	ResourceLogs []otlpResourceLogs `json:"resourceLogs"`
+}
+
+type otlpResourceLogs struct {
+	Resource  otlpResource    `json:"resource"`
+	ScopeLogs []otlpScopeLogs `json:"scopeLogs"`
+}
+
+type otlpScopeLogs struct {
+	Scope      otlpScopeName `json:"scope"`
+	LogRecords []otlpRecord  `json:"logRecords"`
+}
+
+type otlpScopeName struct {
+	Name string `json:"name"`
+}
+
+func (x *OTLPExporter) export(batch []Entry) error {
+	now := strconv.FormatInt(time.Now().UnixNano(), 10)
+	records := make([]otlpRecord, 0, len(batch))
+	for _, e := range batch {
+		records = append(records, otlpRecordOf(e, now))
+	}
+	body, err := json.Marshal(otlpRequest{ResourceLogs: []otlpResourceLogs{{
+		Resource:  x.resource,
+		ScopeLogs: []otlpScopeLogs{{Scope: otlpScopeName{Name: otlpScope}, LogRecords: records}},
+	}}})
+	if err != nil {
+		return fmt.Errorf("encode log records: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, x.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range x.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := x.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp logs endpoint returned %s for %d records", resp.Status, len(batch))
+	}
+	return nil
+}
+
+func otlpRecordOf(e Entry, observed string) otlpRecord {
+	r := otlpRecord{
+		TimeUnixNano:         strconv.FormatInt(e.Time.UnixNano(), 10),
+		ObservedTimeUnixNano: observed,
+		SeverityNumber:       otlpSeverity[e.Level],
+		SeverityText:         strings.ToUpper(e.Level.String()),
+		Body:                 stringValue(e.Message),
+	}
+	if file, line, ok := strings.Cut(e.Caller, ":"); ok {
+		r.Attributes = append(r.Attributes, stringAttribute("code.filepath", file))
+		if n, err := strconv.Atoi(line); err == nil {
+			r.Attributes = append(r.Attributes, otlpAttribute{Key: "code.lineno", Value: attributeValue(n)})
+		}
+	}
+	for _, f := range e.Fields {
+		switch f.Key {
+		case "":
+			continue
+		case TraceIDKey:
+			if s, ok := f.AnyValue().(string); ok {
+				r.TraceID = s
+				continue
+			}
+		case SpanIDKey:
+			if s, ok := f.AnyValue().(string); ok {
+				r.SpanID = s
+				continue
+			}
+		}
+		r.Attributes = append(r.Attributes, otlpAttribute{Key: f.Key, Value: attributeValue(f.AnyValue())})
+	}
+	if e.Stack != "" {
+		r.Attributes = append(r.Attributes, stringAttribute("exception.stacktrace", e.Stack))
+	}
+	return r
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: stringValue(value)}
+}
+
+func stringValue(s string) otlpValue {
+	return otlpValue{StringValue: &s}
+}
+
+// attributeValue keeps numbers and booleans typed and renders everything
+// else, including durations, as the text encoder would.
+func attributeValue(v any) otlpValue {
+	var n int64
+	switch v := v.(type) {
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case float64:
+		return otlpValue{DoubleValue: &v}
+	case float32:
+		f := float64(v)
+		return otlpValue{DoubleValue: &f}
+	case int:
+		n = int64(v)
+	case int32:
+		n = int64(v)
+	case int64:
+		n = v
+	case uint32:
+		n = int64(v)
+	case string:
+		return stringValue(v)
+	default:
+		return stringValue(text(v))
+	}
+	s := strconv.FormatInt(n, 10)
+	return otlpValue{IntValue: &s}
+}
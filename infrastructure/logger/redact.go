@@ -0,0 +1,119 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const redacted = "[REDACTED]"
+
+// DefaultRedactKeys are field names whose values are never logged.
+var DefaultRedactKeys = []string{
+	"authorization", "cookie", "set_cookie", "password", "passwd", "secret", "client_secret",
+	"token", "access_token", "refresh_token", "api_key", "apikey", "private_key",
+}
+
+// DefaultRedactPatterns match credentials that turn up inside messages and
+// values: bearer tokens, JWTs, OpenAI-style and AWS access keys.
+var DefaultRedactPatterns = []string{
+	`(?i)bearer\s+[a-z0-9._~+/-]+=*`,
+	`eyJ[a-zA-Z0-9_-]+\.[a-zA-Z0-9_-]+\.[a-zA-Z0-9_-]+`,
+	`sk-[a-zA-Z0-9_-]{20,}`,
+	`AKIA[0-9A-Z]{16}`,
+}
+
+// Redactor replaces secrets in log entries with [REDACTED]: the values of
+// fields named like a sensitive key, and pattern matches in the message and in
+// text field values.
+type Redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+}
+
+func NewRedactor(keys, patterns []string) (*Redactor, error) {
+	r := &Redactor{}
+	for _, k := range keys {
+		r.keys = append(r.keys, normalizeKey(k))
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("redact pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+	return r, nil
+}
+
+func WithRedaction(r *Redactor) Option {
+	return func(l *Logger) {
+		l.redactor = r
+	}
+}
+
+// sensitive matches a key exactly or as its last word, so db_password and
+// X-Api-Key are caught but prompt_tokens isn't.
+func (r *Redactor) sensitive(key string) bool {
+	key = normalizeKey(key)
+	return slices.ContainsFunc(r.keys, func(k string) bool {
+		return key == k || strings.HasSuffix(key, "_"+k)
+	})
+}
+
+func (r *Redactor) scrub(s string) string {
+	for _, re := range r.patterns {
+		// Matching first spares a copy of the usual string without secrets.
+		if re.MatchString(s) {
+			s = re.ReplaceAllString(s, redacted)
+		}
+	}
+	return s
+}
+
+// redact rewrites e in place; e.Fields must not be shared with a logger.
+func (r *Redactor) redact(e *Entry) {
+	e.Message = r.scrub(e.Message)
+	for i, f := range e.Fields {
+		if r.sensitive(f.Key) {
+			e.Fields[i] = String(f.Key, redacted)
+			continue
+		}
+		if f.kind == kindString {
+			e.Fields[i].str = r.scrub(f.str)
+			continue
+		}
+		switch v := f.Value.(type) {
+		case string:
+			e.Fields[i].Value = r.scrub(v)
+		case error, fmt.Stringer:
+			if s := text(v); r.scrub(s) != s {
+				e.Fields[i] = String(f.Key, r.scrub(s))
+			}
+		}
+	}
+}
+
+// Writer scrubs pattern matches from everything written to w, for output
+// that bypasses the logger such as the standard library's.
+func (r *Redactor) Writer(w io.Writer) io.Writer {
+	return redactWriter{r: r, w: w}
+}
+
+type redactWriter struct {
+	r *Redactor
+	w io.Writer
+}
+
+func (rw redactWriter) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(rw.w, rw.r.scrub(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func normalizeKey(k string) string {
+	return strings.ReplaceAll(strings.ToLower(strings.TrimSpace(k)), "-", "_")
+}
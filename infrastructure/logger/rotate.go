@@ -0,0 +1,329 @@
+package logger
+
+import (
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// backupTimeFormat stamps rotated files; it sorts chronologically and avoids
+// characters that are awkward in file names.
+const backupTimeFormat = "2006-01-02T15-04-05.000"
+
+type RotateConfig struct {
+	Path string
+	// MaxSize rotates the file before it grows past this many bytes; 0 disables.
+	MaxSize int64
+	// Interval rotates the file once it has been written to for this long; 0
+	// disables.
+	Interval time.Duration
+	// MaxBackups and MaxAge bound how many rotated files are kept and for how
+	// long; 0 keeps them all.
+	MaxBackups int
+	MaxAge     time.Duration
+	// Compress gzips rotated files.
+	Compress bool
+}
+
+// RotatingFile is an append-only log file that is renamed aside with a
+// timestamp once it is too large or too old. Compressing and pruning rotated
+// files happens in the background so writes aren't held up.
+type RotatingFile struct {
+	cfg RotateConfig
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	// pruneMu keeps cleanup off the rotated files while they are rewritten.
+	pruneMu sync.Mutex
+	cleanup chan struct{}
+	done    chan struct{}
+}
+
+func OpenRotatingFile(cfg RotateConfig) (*RotatingFile, error) {
+	if cfg.Path == "" {
+		return nil, fmt.Errorf("log file path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(cfg.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	f := &RotatingFile{cfg: cfg, cleanup: make(chan struct{}, 1), done: make(chan struct{})}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	go f.cleanupLoop()
+	f.cleanup <- struct{}{}
+	return f, nil
+}
+
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	tooLarge := f.cfg.MaxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.cfg.MaxSize
+	tooOld := f.cfg.Interval > 0 && time.Since(f.opened) >= f.cfg.Interval
+	if tooLarge || tooOld {
+		if err := f.rotate(); err != nil {
+			return 0, err
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Rotate starts a new file now, e.g. when an external tool asks for it.
+func (f *RotatingFile) Rotate() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.rotate()
+}
+
+func (f *RotatingFile) Sync() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	return f.file.Sync()
+}
+
+// Close closes the file and waits for pending compression and pruning.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	if f.file == nil {
+		f.mu.Unlock()
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	close(f.cleanup)
+	f.mu.Unlock()
+	// Cleanup may itself be logging to this file, so wait without the lock.
+	<-f.done
+	return err
+}
+
+// Rewrite replaces the content of each rotated file, oldest first, and then of
+// the current file with what fn writes for it; compressed files stay
+// compressed. Writes and cleanup wait until it is done.
+func (f *RotatingFile) Rewrite(fn func(r io.Reader, w io.Writer) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.file == nil {
+		return os.ErrClosed
+	}
+	f.pruneMu.Lock()
+	defer f.pruneMu.Unlock()
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+	for _, b := range slices.Backward(backups) {
+		if err := rewriteFile(b.path, fn); err != nil {
+			return err
+		}
+	}
+	if err := rewriteFile(f.cfg.Path, fn); err != nil {
+		return err
+	}
+	// The current file was replaced; reopen it, keeping its age for rotation.
+	opened := f.opened
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	f.opened = opened
+	return nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.cfg.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("open log file: %w", err)
+	}
+	f.file, f.size, f.opened = file, info.Size(), time.Now()
+	return nil
+}
+
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("close log file: %w", err)
+	}
+	backup := f.backupName(time.Now().UTC())
+	if err := os.Rename(f.cfg.Path, backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("rotate log file: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return err
+	}
+	select {
+	case f.cleanup <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (f *RotatingFile) backupName(t time.Time) string {
+	ext := filepath.Ext(f.cfg.Path)
+	return strings.TrimSuffix(f.cfg.Path, ext) + "-" + t.Format(backupTimeFormat) + ext
+}
+
+func (f *RotatingFile) cleanupLoop() {
+	defer close(f.done)
+	for range f.cleanup {
+		f.pruneMu.Lock()
+		err := f.pruneBackups()
+		f.pruneMu.Unlock()
+		if err != nil {
+			log.Printf("Cleaning up rotated logs failed: %v\n", err)
+		}
+	}
+}
+
+type backup struct {
+	path string
+	time time.Time
+}
+
+// pruneBackups compresses rotated files when configured and removes those
+// beyond MaxBackups or older than MaxAge.
+func (f *RotatingFile) pruneBackups() error {
+	backups, err := f.backups()
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for i, b := range backups {
+		expired := f.cfg.MaxAge > 0 && time.Since(b.time) > f.cfg.MaxAge
+		if (f.cfg.MaxBackups > 0 && i >= f.cfg.MaxBackups) || expired {
+			if err := os.Remove(b.path); err != nil {
+				errs = append(errs, err)
+			}
+			continue
+		}
+		if f.cfg.Compress && !strings.HasSuffix(b.path, ".gz") {
+			if err := compress(b.path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// backups lists rotated files, newest first.
+func (f *RotatingFile) backups() ([]backup, error) {
+	dir := filepath.Dir(f.cfg.Path)
+	ext := filepath.Ext(f.cfg.Path)
+	prefix := strings.TrimSuffix(filepath.Base(f.cfg.Path), ext) + "-"
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []backup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		stamp := strings.TrimSuffix(strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".gz"), ext)
+		t, err := time.Parse(backupTimeFormat, stamp)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{path: filepath.Join(dir, name), time: t})
+	}
+	slices.SortFunc(backups, func(a, b backup) int { return b.time.Compare(a.time) })
+	return backups, nil
+}
+
+func compress(path string) error {
+	src, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(path+".gz", os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	zw := gzip.NewWriter(dst)
+	_, err = io.Copy(zw, src)
+	if cerr := zw.Close(); err == nil {
+		err = cerr
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path + ".gz")
+		return fmt.Errorf("compress %s: %w", path, err)
+	}
+	return os.Remove(path)
+}
+
+// rewriteFile writes fn's output for path next to it and moves it over path.
+func rewriteFile(path string, fn func(r io.Reader, w io.Writer) error) error {
+	src, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	tmp := path + ".tmp"
+	dst, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	var r io.Reader = src
+	var w io.Writer = dst
+	var zw *gzip.Writer
+	if strings.HasSuffix(path, ".gz") {
+		zr, err := gzip.NewReader(src)
+		if err != nil {
+			dst.Close()
+			os.Remove(tmp)
+			return fmt.Errorf("rewrite %s: %w", path, err)
+		}
+		zw = gzip.NewWriter(dst)
+		r, w = zr, zw
+	}
+	err = fn(r, w)
+	if zw != nil {
+		if cerr := zw.Close(); err == nil {
+			err = cerr
+		}
+	}
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("rewrite %s: %w", path, err)
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,78 @@
+package logger
+
+import (
+	"hash/fnv"
+	"sync/atomic"
+	"time"
+)
+
+// sampleBuckets bounds the sampler's memory; messages hashing to the same
+// bucket share a counter.
+const sampleBuckets = 4096
+
+// SamplingConfig logs the first First entries with the same level and message
+// in each Window, then every Thereafter-th; zero Thereafter drops the rest.
+// Fatal entries are never sampled.
+type SamplingConfig struct {
+	Window     time.Duration
+	First      int
+	Thereafter int
+}
+
+func (c SamplingConfig) Enabled() bool {
+	return c.Window > 0 && c.First > 0
+}
+
+func WithSampling(cfg SamplingConfig) Option {
+	return func(l *Logger) {
+		if cfg.Enabled() {
+			l.sampler = &sampler{cfg: cfg}
+		}
+	}
+}
+
+type sampleCounter struct {
+	resetAt atomic.Int64
+	n       atomic.Uint64
+}
+
+type sampler struct {
+	cfg     SamplingConfig
+	counts  [sampleBuckets]sampleCounter
+	dropped atomic.Uint64
+}
+
+func (s *sampler) allow(level Level, msg string, now time.Time) bool {
+	if level >= LevelFatal {
+		return true
+	}
+	h := fnv.New32a()
+	h.Write([]byte{byte(level)})
+	h.Write([]byte(msg))
+	c := &s.counts[h.Sum32()%sampleBuckets]
+
+	n := c.n.Add(1)
+	resetAt := c.resetAt.Load()
+	if nowNanos := now.UnixNano(); nowNanos > resetAt {
+		// The first entry of a new window restarts the count; racing
+		// entries just count towards it.
+		if c.resetAt.CompareAndSwap(resetAt, nowNanos+s.cfg.Window.Nanoseconds()) {
+			c.n.Store(1)
+			n = 1
+		}
+	}
+	first := uint64(s.cfg.First)
+	if n <= first || (s.cfg.Thereafter > 0 && (n-first)%uint64(s.cfg.Thereafter) == 0) {
+		return true
+	}
+	s.dropped.Add(1)
+	return false
+}
+
+// SampledOut counts entries dropped by sampling.
+func (l *Logger) SampledOut() uint64 {
+	if l.sampler == nil {
+		return 0
+	}
+	return l.sampler.dropped.Load()
+}
@@ -0,0 +1,153 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LevelWriter is an output that records each entry's level itself, such as
+// syslog's severity. Sinks write to it through WriteLevel, one entry per call.
+type LevelWriter interface {
+	io.Writer
+	WriteLevel(level Level, p []byte) (int, error)
+}
+
+var facilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+	"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+// syslogSeverity maps levels to syslog severities, which journald shares.
+var syslogSeverity = map[Level]int{
+	LevelDebug:  7,
+	LevelInfo:   6,
+	LevelWarn:   4,
+	LevelError:  3,
+	LevelDPanic: 2,
+	LevelFatal:  2,
+}
+
+type SyslogConfig struct {
+	// Network is udp, tcp or unix, with Address host:port or a socket path
+	// such as /dev/log.
+	Network  string
+	Address  string
+	Facility string
+	// Tag is the APP-NAME of each message.
+	Tag string
+}
+
+// SyslogWriter sends entries to a syslog server as RFC 5424 messages, framed
+// with octet counting over TCP. It redials once when a write fails, so a
+// restarted server doesn't lose the stream.
+type SyslogWriter struct {
+	cfg      SyslogConfig
+	facility int
+	hostname string
+	pid      string
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func DialSyslog(cfg SyslogConfig) (*SyslogWriter, error) {
+	if cfg.Facility == "" {
+		cfg.Facility = "local0"
+	}
+	facility, ok := facilities[strings.ToLower(cfg.Facility)]
+	if !ok {
+		return nil, fmt.Errorf("unknown syslog facility %q", cfg.Facility)
+	}
+	switch cfg.Network {
+	case "udp", "tcp", "unix":
+	default:
+		return nil, fmt.Errorf("unknown syslog network %q", cfg.Network)
+	}
+	if cfg.Tag == "" {
+		cfg.Tag = "sarama"
+	}
+	hostname, _ := os.Hostname()
+	if hostname == "" {
+		hostname = "-"
+	}
+	w := &SyslogWriter{cfg: cfg, facility: facility, hostname: hostname, pid: strconv.Itoa(os.Getpid())}
+	if err := w.dial(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+func (w *SyslogWriter) Write(p []byte) (int, error) {
+	return w.WriteLevel(LevelInfo, p)
+}
+
+func (w *SyslogWriter) WriteLevel(level Level, p []byte) (int, error) {
+	msg := w.format(level, p)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		if _, err := w.conn.Write(msg); err == nil {
+			return len(p), nil
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	if err := w.dial(); err != nil {
+		return 0, err
+	}
+	if _, err := w.conn.Write(msg); err != nil {
+		return 0, fmt.Errorf("syslog: %w", err)
+	}
+	return len(p), nil
+}
+
+func (w *SyslogWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn == nil {
+		return nil
+	}
+	err := w.conn.Close()
+	w.conn = nil
+	return err
+}
+
+func (w *SyslogWriter) dial() error {
+	network := w.cfg.Network
+	if network == "unix" {
+		// The local syslog socket is usually a datagram one.
+		network = "unixgram"
+	}
+	conn, err := net.DialTimeout(network, w.cfg.Address, 5*time.Second)
+	if err != nil && w.cfg.Network == "unix" {
+		conn, err = net.DialTimeout("unix", w.cfg.Address, 5*time.Second)
+	}
+	if err != nil {
+		return fmt.Errorf("syslog: dial %s: %w", w.cfg.Address, err)
+	}
+	w.conn = conn
+	return nil
+}
+
+// format renders p as <PRI>1 TIMESTAMP HOSTNAME APP-NAME PROCID - - MSG.
+func (w *SyslogWriter) format(level Level, p []byte) []byte {
+	severity, ok := syslogSeverity[level]
+	if !ok {
+		severity = 6
+	}
+	header := fmt.Sprintf("<%d>1 %s %s %s %s - - ",
+		w.facility*8+severity, time.Now().Format("2006-01-02T15:04:05.000000Z07:00"), w.hostname, w.cfg.Tag, w.pid)
+	msg := append([]byte(header), strings.TrimRight(string(p), "\n")...)
+	if w.cfg.Network == "tcp" {
+		return append([]byte(strconv.Itoa(len(msg))+" "), msg...)
+	}
+	return msg
+}
@@ -0,0 +1,174 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Config struct {
+	URL       string
+	CredsFile string
+	Stream    string
+	// Subject receives ingest events; failed events go to Subject+".dlq".
+	Subject    string
+	Durable    string
+	MaxDeliver int
+	// RetryDelays are the redelivery delays after the first, second, ... failure.
+	RetryDelays []time.Duration
+	AckWait     time.Duration
+	Retention   time.Duration
+}
+
+// Bus publishes ingest events to a JetStream stream and consumes them through a
+// durable consumer shared by all instances, so each event goes to one worker.
+type Bus struct {
+	conn   *nats.Conn
+	js     jetstream.JetStream
+	stream jetstream.Stream
+	cfg    Config
+}
+
+func NewBus(ctx context.Context, cfg Config) (*Bus, error) {
+	if cfg.Subject == "" || cfg.Stream == "" {
+		return nil, fmt.Errorf("nats: stream and subject are required")
+	}
+	opts := []nats.Option{nats.Name("sarama-ai"), nats.MaxReconnects(-1)}
+	if cfg.CredsFile != "" {
+		opts = append(opts, nats.UserCredentials(cfg.CredsFile))
+	}
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("nats: connect: %w", err)
+	}
+	js, err := jetstream.New(conn)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats: jetstream: %w", err)
+	}
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     cfg.Stream,
+		Subjects: []string{cfg.Subject, cfg.Subject + ".dlq"},
+		MaxAge:   cfg.Retention,
+		Storage:  jetstream.FileStorage,
+		// Publishing with the event ID as message ID drops duplicates in this window.
+		Duplicates: 2 * time.Minute,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("nats: ensure stream %s: %w", cfg.Stream, err)
+	}
+	return &Bus{conn: conn, js: js, stream: stream, cfg: cfg}, nil
+}
+
+func (b *Bus) PublishIngestEvent(ctx context.Context, event domain.IngestEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode ingest event: %w", err)
+	}
+	if _, err := b.js.Publish(ctx, b.cfg.Subject, data, jetstream.WithMsgID(event.ID)); err != nil {
+		return fmt.Errorf("publish ingest event %s: %w", event.ID, err)
+	}
+	return nil
+}
+
+func (b *Bus) ConsumeIngestEvents(ctx context.Context, handle domain.IngestHandler) error {
+	maxDeliver := b.cfg.MaxDeliver
+	if maxDeliver <= 0 {
+		maxDeliver = len(b.cfg.RetryDelays) + 1
+	}
+	consumer, err := b.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       b.cfg.Durable,
+		FilterSubject: b.cfg.Subject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       b.cfg.AckWait,
+		MaxDeliver:    maxDeliver,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return fmt.Errorf("nats: ensure consumer %s: %w", b.cfg.Durable, err)
+	}
+
+	cc, err := consumer.Consume(func(msg jetstream.Msg) {
+		b.process(ctx, msg, handle, maxDeliver)
+	})
+	if err != nil {
+		return fmt.Errorf("nats: consume: %w", err)
+	}
+	<-ctx.Done()
+	cc.Drain()
+	return nil
+}
+
+func (b *Bus) process(ctx context.Context, msg jetstream.Msg, handle domain.IngestHandler, maxDeliver int) {
+	var event domain.IngestEvent
+	if err := json.Unmarshal(msg.Data(), &event); err != nil {
+		b.deadLetter(ctx, msg, err)
+		return
+	}
+
+	err := handle(ctx, event)
+	if err == nil {
+		if err := msg.Ack(); err != nil {
+			log.Printf("Acknowledging ingest event %s failed: %v\n", event.ID, err)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		// Shutting down: let the message be redelivered to another worker.
+		msg.Nak()
+		return
+	}
+
+	meta, merr := msg.Metadata()
+	if merr == nil && int(meta.NumDelivered) >= maxDeliver {
+		b.deadLetter(ctx, msg, err)
+		return
+	}
+	delay := time.Minute
+	if merr == nil && len(b.cfg.RetryDelays) > 0 {
+		delay = b.cfg.RetryDelays[min(int(meta.NumDelivered), len(b.cfg.RetryDelays))-1]
+	}
+	log.Printf("Processing ingest event %s failed, redelivering in %s: %v\n", event.ID, delay, err)
+	msg.NakWithDelay(delay)
+}
+
+// deadLetter copies msg to the dead-letter subject and terminates it; if the copy
+// fails the message is left for redelivery.
+func (b *Bus) deadLetter(ctx context.Context, msg jetstream.Msg, cause error) {
+	out := nats.NewMsg(b.cfg.Subject + ".dlq")
+	out.Data = msg.Data()
+	out.Header.Set("X-Error", cause.Error())
+	if meta, err := msg.Metadata(); err == nil {
+		out.Header.Set("X-Original-Sequence", fmt.Sprint(meta.Sequence.Stream))
+		out.Header.Set("X-Attempt", fmt.Sprint(meta.NumDelivered))
+	}
+	if _, err := b.js.PublishMsg(ctx, out); err != nil {
+		log.Printf("Dead-lettering message failed: %v\n", err)
+		msg.Nak()
+		return
+	}
+	log.Printf("Message moved to %s: %v\n", out.Subject, cause)
+	if err := msg.Term(); err != nil && !errors.Is(err, jetstream.ErrMsgAlreadyAckd) {
+		log.Printf("Terminating message failed: %v\n", err)
+	}
+}
+
+func (b *Bus) Ping(ctx context.Context) error {
+	if !b.conn.IsConnected() {
+		return fmt.Errorf("nats: not connected")
+	}
+	return nil
+}
+
+func (b *Bus) Close() error {
+	return b.conn.Drain()
+}
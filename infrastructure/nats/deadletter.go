@@ -0,0 +1,95 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/nats-io/nats.go/jetstream"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+func (b *Bus) deadSubject() string { return b.cfg.Subject + ".dlq" }
+
+func (b *Bus) PeekDeadLetters(ctx context.Context, limit int) ([]domain.DeadLetter, error) {
+	msgs, err := b.fetchDeadLetters(ctx, limit)
+	if err != nil {
+		return nil, err
+	}
+	letters := make([]domain.DeadLetter, 0, len(msgs))
+	for _, msg := range msgs {
+		dl := domain.DeadLetter{Error: msg.Headers().Get("X-Error")}
+		dl.Attempts, _ = strconv.Atoi(msg.Headers().Get("X-Attempt"))
+		if meta, err := msg.Metadata(); err == nil {
+			dl.Position = strconv.FormatUint(meta.Sequence.Stream, 10)
+		}
+		var event domain.IngestEvent
+		if err := json.Unmarshal(msg.Data(), &event); err == nil {
+			dl.Event = &event
+		}
+		letters = append(letters, dl)
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters republishes dead letters to the ingest subject without a
+// message ID, so deduplication can't drop a replay of a recent failure, then
+// deletes them from the stream.
+func (b *Bus) ReplayDeadLetters(ctx context.Context, limit int) (int, error) {
+	msgs, err := b.fetchDeadLetters(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+	replayed := 0
+	for _, msg := range msgs {
+		meta, err := msg.Metadata()
+		if err != nil {
+			return replayed, err
+		}
+		if _, err := b.js.Publish(ctx, b.cfg.Subject, msg.Data()); err != nil {
+			return replayed, fmt.Errorf("nats: replay dead letter %d: %w", meta.Sequence.Stream, err)
+		}
+		if err := b.stream.DeleteMsg(ctx, meta.Sequence.Stream); err != nil {
+			return replayed, fmt.Errorf("nats: delete dead letter %d: %w", meta.Sequence.Stream, err)
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+func (b *Bus) PurgeDeadLetters(ctx context.Context) (int, error) {
+	info, err := b.stream.Info(ctx, jetstream.WithSubjectFilter(b.deadSubject()))
+	if err != nil {
+		return 0, fmt.Errorf("nats: stream info: %w", err)
+	}
+	if err := b.stream.Purge(ctx, jetstream.WithPurgeSubject(b.deadSubject())); err != nil {
+		return 0, fmt.Errorf("nats: purge %s: %w", b.deadSubject(), err)
+	}
+	return int(info.State.Subjects[b.deadSubject()]), nil
+}
+
+// fetchDeadLetters reads up to limit dead letters, oldest first, through an
+// ordered consumer that leaves them in the stream.
+func (b *Bus) fetchDeadLetters(ctx context.Context, limit int) ([]jetstream.Msg, error) {
+	consumer, err := b.stream.OrderedConsumer(ctx, jetstream.OrderedConsumerConfig{
+		FilterSubjects: []string{b.deadSubject()},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("nats: read %s: %w", b.deadSubject(), err)
+	}
+	batch, err := consumer.FetchNoWait(limit)
+	if err != nil {
+		return nil, fmt.Errorf("nats: read %s: %w", b.deadSubject(), err)
+	}
+	var msgs []jetstream.Msg
+	for msg := range batch.Messages() {
+		msgs = append(msgs, msg)
+	}
+	if err := batch.Error(); err != nil && !errors.Is(err, jetstream.ErrNoMessages) {
+		return msgs, err
+	}
+	return msgs, nil
+}
@@ -0,0 +1,233 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const headerAttempt = "x-attempt"
+
+type Config struct {
+	URL   string
+	Queue string
+	// Failed events wait RetryDelay in Queue+".retry" before returning to the
+	// queue; after MaxAttempts they are moved to Queue+".dlq".
+	RetryDelay  time.Duration
+	MaxAttempts int
+	Prefetch    int
+}
+
+// Bus publishes ingest events to a durable work queue that all instances
+// consume from, so each event goes to one worker.
+type Bus struct {
+	cfg Config
+
+	mu   sync.Mutex
+	conn *amqp.Connection
+	pub  *amqp.Channel
+}
+
+func NewBus(cfg Config) (*Bus, error) {
+	if cfg.Queue == "" {
+		return nil, fmt.Errorf("rabbitmq: queue is required")
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 3
+	}
+	b := &Bus{cfg: cfg}
+	if _, err := b.channel(); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func (b *Bus) retryQueue() string { return b.cfg.Queue + ".retry" }
+func (b *Bus) deadQueue() string  { return b.cfg.Queue + ".dlq" }
+
+// channel returns the publishing channel, reconnecting after connection loss.
+func (b *Bus) channel() (*amqp.Channel, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.pub != nil && !b.pub.IsClosed() {
+		return b.pub, nil
+	}
+	if b.conn == nil || b.conn.IsClosed() {
+		conn, err := amqp.Dial(b.cfg.URL)
+		if err != nil {
+			return nil, fmt.Errorf("rabbitmq: connect: %w", err)
+		}
+		b.conn = conn
+	}
+	ch, err := b.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("rabbitmq: open channel: %w", err)
+	}
+	if err := b.declare(ch); err != nil {
+		ch.Close()
+		return nil, err
+	}
+	if err := ch.Confirm(false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("rabbitmq: enable confirms: %w", err)
+	}
+	b.pub = ch
+	return ch, nil
+}
+
+// declare sets up the work queue, a retry queue that dead-letters expired
+// messages back to it, and the dead-letter queue.
+func (b *Bus) declare(ch *amqp.Channel) error {
+	if _, err := ch.QueueDeclare(b.cfg.Queue, true, false, false, false, nil); err != nil {
+		return fmt.Errorf("rabbitmq: declare %s: %w", b.cfg.Queue, err)
+	}
+	retryArgs := amqp.Table{
+		"x-dead-letter-exchange":    "",
+		"x-dead-letter-routing-key": b.cfg.Queue,
+		"x-message-ttl":             b.cfg.RetryDelay.Milliseconds(),
+	}
+	if _, err := ch.QueueDeclare(b.retryQueue(), true, false, false, false, retryArgs); err != nil {
+		return fmt.Errorf("rabbitmq: declare %s: %w", b.retryQueue(), err)
+	}
+	if _, err := ch.QueueDeclare(b.deadQueue(), true, false, false, false, nil); err != nil {
+		return fmt.Errorf("rabbitmq: declare %s: %w", b.deadQueue(), err)
+	}
+	return nil
+}
+
+func (b *Bus) PublishIngestEvent(ctx context.Context, event domain.IngestEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode ingest event: %w", err)
+	}
+	return b.publish(ctx, b.cfg.Queue, amqp.Publishing{MessageId: event.ID, Body: data})
+}
+
+// publish sends to a queue through the default exchange and waits for the broker's confirm.
+func (b *Bus) publish(ctx context.Context, queue string, msg amqp.Publishing) error {
+	ch, err := b.channel()
+	if err != nil {
+		return err
+	}
+	msg.ContentType = "application/json"
+	msg.DeliveryMode = amqp.Persistent
+	confirm, err := ch.PublishWithDeferredConfirmWithContext(ctx, "", queue, true, false, msg)
+	if err != nil {
+		return fmt.Errorf("rabbitmq: publish to %s: %w", queue, err)
+	}
+	ok, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("rabbitmq: publish to %s: %w", queue, err)
+	}
+	if !ok {
+		return fmt.Errorf("rabbitmq: publish to %s was rejected", queue)
+	}
+	return nil
+}
+
+// ConsumeIngestEvents consumes until ctx ends, reconnecting after failures.
+func (b *Bus) ConsumeIngestEvents(ctx context.Context, handle domain.IngestHandler) error {
+	for {
+		err := b.consume(ctx, handle)
+		if ctx.Err() != nil {
+			return nil
+		}
+		log.Printf("RabbitMQ consumer stopped, reconnecting: %v\n", err)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(5 * time.Second):
+		}
+	}
+}
+
+func (b *Bus) consume(ctx context.Context, handle domain.IngestHandler) error {
+	b.mu.Lock()
+	conn := b.conn
+	b.mu.Unlock()
+	if conn == nil || conn.IsClosed() {
+		if _, err := b.channel(); err != nil {
+			return err
+		}
+		b.mu.Lock()
+		conn = b.conn
+		b.mu.Unlock()
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer ch.Close()
+	if b.cfg.Prefetch > 0 {
+		if err := ch.Qos(b.cfg.Prefetch, 0, false); err != nil {
+			return err
+		}
+	}
+	deliveries, err := ch.ConsumeWithContext(ctx, b.cfg.Queue, "", false, false, false, false, nil)
+	if err != nil {
+		return err
+	}
+	for d := range deliveries {
+		b.process(ctx, d, handle)
+	}
+	return fmt.Errorf("delivery channel closed")
+}
+
+func (b *Bus) process(ctx context.Context, d amqp.Delivery, handle domain.IngestHandler) {
+	var event domain.IngestEvent
+	err := json.Unmarshal(d.Body, &event)
+	if err == nil {
+		err = handle(ctx, event)
+		if err == nil {
+			d.Ack(false)
+			return
+		}
+	}
+	if ctx.Err() != nil {
+		d.Nack(false, true)
+		return
+	}
+
+	attempt := 1
+	if n, ok := d.Headers[headerAttempt].(int32); ok {
+		attempt = int(n)
+	}
+	target := b.retryQueue()
+	if attempt >= b.cfg.MaxAttempts {
+		target = b.deadQueue()
+	}
+	headers := amqp.Table{headerAttempt: int32(attempt + 1), "x-error": err.Error()}
+	if perr := b.publish(ctx, target, amqp.Publishing{MessageId: d.MessageId, Headers: headers, Body: d.Body}); perr != nil {
+		log.Printf("Moving message %s to %s failed, requeueing: %v\n", d.MessageId, target, perr)
+		d.Nack(false, true)
+		return
+	}
+	log.Printf("Processing message %s failed, moved to %s: %v\n", d.MessageId, target, err)
+	d.Ack(false)
+}
+
+func (b *Bus) Ping(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn == nil || b.conn.IsClosed() {
+		return fmt.Errorf("rabbitmq: not connected")
+	}
+	return nil
+}
+
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.conn == nil {
+		return nil
+	}
+	return b.conn.Close()
+}
@@ -0,0 +1,104 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	amqp "github.com/rabbitmq/amqp091-go"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// PeekDeadLetters gets dead letters without acknowledging them and requeues
+// them once all are read, so their order is kept.
+func (b *Bus) PeekDeadLetters(ctx context.Context, limit int) ([]domain.DeadLetter, error) {
+	ch, err := b.adminChannel()
+	if err != nil {
+		return nil, err
+	}
+	// Closing the channel requeues every unacknowledged delivery.
+	defer ch.Close()
+
+	var letters []domain.DeadLetter
+	for len(letters) < limit {
+		d, ok, err := ch.Get(b.deadQueue(), false)
+		if err != nil {
+			return letters, fmt.Errorf("rabbitmq: read %s: %w", b.deadQueue(), err)
+		}
+		if !ok {
+			break
+		}
+		dl := domain.DeadLetter{Position: d.MessageId}
+		dl.Error, _ = d.Headers["x-error"].(string)
+		if n, ok := d.Headers[headerAttempt].(int32); ok {
+			// The header holds the attempt that would have come next.
+			dl.Attempts = int(n) - 1
+		}
+		var event domain.IngestEvent
+		if err := json.Unmarshal(d.Body, &event); err == nil {
+			dl.Event = &event
+		}
+		letters = append(letters, dl)
+	}
+	return letters, nil
+}
+
+// ReplayDeadLetters moves dead letters back to the work queue with their
+// attempt count reset.
+func (b *Bus) ReplayDeadLetters(ctx context.Context, limit int) (int, error) {
+	ch, err := b.adminChannel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+
+	replayed := 0
+	for replayed < limit {
+		d, ok, err := ch.Get(b.deadQueue(), false)
+		if err != nil {
+			return replayed, fmt.Errorf("rabbitmq: read %s: %w", b.deadQueue(), err)
+		}
+		if !ok {
+			break
+		}
+		if err := b.publish(ctx, b.cfg.Queue, amqp.Publishing{MessageId: d.MessageId, Body: d.Body}); err != nil {
+			d.Nack(false, true)
+			return replayed, err
+		}
+		if err := d.Ack(false); err != nil {
+			return replayed, fmt.Errorf("rabbitmq: ack dead letter %s: %w", d.MessageId, err)
+		}
+		replayed++
+	}
+	return replayed, nil
+}
+
+func (b *Bus) PurgeDeadLetters(ctx context.Context) (int, error) {
+	ch, err := b.adminChannel()
+	if err != nil {
+		return 0, err
+	}
+	defer ch.Close()
+	n, err := ch.QueuePurge(b.deadQueue(), false)
+	if err != nil {
+		return 0, fmt.Errorf("rabbitmq: purge %s: %w", b.deadQueue(), err)
+	}
+	return n, nil
+}
+
+// adminChannel opens a channel of its own so unacknowledged gets don't mix
+// with the publishing channel's confirms.
+func (b *Bus) adminChannel() (*amqp.Channel, error) {
+	if _, err := b.channel(); err != nil {
+		return nil, err
+	}
+	b.mu.Lock()
+	conn := b.conn
+	b.mu.Unlock()
+	ch, err := conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("rabbitmq: open channel: %w", err)
+	}
+	return ch, nil
+}
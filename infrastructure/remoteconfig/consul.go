@@ -0,0 +1,60 @@
+package remoteconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// consul reads the prefix from the Consul KV store.
+type consul struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func newConsul(cfg Config, httpClient *http.Client) *consul {
+	return &consul{cfg: cfg, httpClient: httpClient}
+}
+
+func (c *consul) Load(ctx context.Context) (map[string]string, uint64, error) {
+	prefix := strings.TrimLeft(c.cfg.Prefix, "/")
+	u := strings.TrimRight(c.cfg.Address, "/") + "/v1/kv/" + prefix + "?" + url.Values{"recurse": {"true"}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	if c.cfg.Token != "" {
+		req.Header.Set("X-Consul-Token", c.cfg.Token)
+	}
+	var pairs []struct {
+		Key   string
+		Value string
+	}
+	resp, err := doJSON(c.httpClient, req, "consul", &pairs)
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		// Nothing under the prefix yet.
+		index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+		return map[string]string{}, index, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	index, _ := strconv.ParseUint(resp.Header.Get("X-Consul-Index"), 10, 64)
+
+	values := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		name := settingName(strings.TrimPrefix(pair.Key, prefix))
+		if name == "" || strings.HasSuffix(pair.Key, "/") {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(pair.Value)
+		if err != nil {
+			return nil, 0, err
+		}
+		values[name] = string(value)
+	}
+	return values, index, nil
+}
@@ -0,0 +1,117 @@
+package remoteconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// etcd reads the prefix through the etcd v3 JSON gateway.
+type etcd struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu    sync.Mutex
+	token string
+}
+
+func newEtcd(cfg Config, httpClient *http.Client) *etcd {
+	return &etcd{cfg: cfg, httpClient: httpClient}
+}
+
+func (e *etcd) Load(ctx context.Context) (map[string]string, uint64, error) {
+	prefix := e.cfg.Prefix
+	var resp struct {
+		Header struct {
+			Revision string `json:"revision"`
+		} `json:"header"`
+		KVs []struct {
+			Key   []byte `json:"key"`
+			Value []byte `json:"value"`
+		} `json:"kvs"`
+	}
+	err := e.post(ctx, "/v3/kv/range", map[string][]byte{"key": []byte(prefix), "range_end": prefixEnd(prefix)}, &resp)
+	if err != nil {
+		return nil, 0, err
+	}
+	revision, _ := strconv.ParseUint(resp.Header.Revision, 10, 64)
+
+	values := make(map[string]string, len(resp.KVs))
+	for _, kv := range resp.KVs {
+		if name := settingName(strings.TrimPrefix(string(kv.Key), prefix)); name != "" {
+			values[name] = string(kv.Value)
+		}
+	}
+	return values, revision, nil
+}
+
+// prefixEnd is the end of the key range that holds every key with prefix.
+func prefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	// Every byte is 0xff, or the prefix is empty: range to the end.
+	return []byte{0}
+}
+
+// post calls the gateway, authenticating first when credentials are
+// configured and again when the token expired.
+func (e *etcd) post(ctx context.Context, path string, in, out any) error {
+	token, err := e.authToken(ctx, false)
+	if err != nil {
+		return err
+	}
+	err = e.call(ctx, path, token, in, out)
+	if err != nil && token != "" && strings.Contains(err.Error(), "invalid auth token") {
+		if token, err = e.authToken(ctx, true); err != nil {
+			return err
+		}
+		err = e.call(ctx, path, token, in, out)
+	}
+	return err
+}
+
+func (e *etcd) call(ctx context.Context, path, token string, in, out any) error {
+	b, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(e.cfg.Address, "/")+path, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", token)
+	}
+	_, err = doJSON(e.httpClient, req, "etcd", out)
+	return err
+}
+
+func (e *etcd) authToken(ctx context.Context, renew bool) (string, error) {
+	if e.cfg.Username == "" {
+		return "", nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.token != "" && !renew {
+		return e.token, nil
+	}
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := e.call(ctx, "/v3/auth/authenticate", "", map[string]string{"name": e.cfg.Username, "password": e.cfg.Password}, &resp); err != nil {
+		return "", fmt.Errorf("authenticate: %w", err)
+	}
+	e.token = resp.Token
+	return e.token, nil
+}
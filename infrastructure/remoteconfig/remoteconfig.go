@@ -0,0 +1,95 @@
+package remoteconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Config points at the key-value store a fleet of instances shares its
+// settings in. Every key under Prefix is a setting: sarama/LOG_LEVEL and
+// sarama/log/level both set LOG_LEVEL.
+type Config struct {
+	// Backend is etcd or consul; empty disables remote config.
+	Backend string
+	// Address is the base URL of the etcd v3 JSON gateway or the Consul agent.
+	Address string
+	Prefix  string
+	// Token is the Consul ACL token; Username and Password authenticate to etcd.
+	Token    string
+	Username string
+	Password string
+	// PollInterval is how often the store is checked for changes; zero loads
+	// the settings once at startup.
+	PollInterval time.Duration
+	Timeout      time.Duration
+}
+
+// Source reads the settings under a prefix.
+type Source interface {
+	// Load returns the settings keyed like environment variables, and a
+	// revision that changes whenever any of them does.
+	Load(ctx context.Context) (map[string]string, uint64, error)
+}
+
+func New(cfg Config) (Source, error) {
+	if cfg.Address == "" {
+		return nil, fmt.Errorf("remote config: %s needs an address", cfg.Backend)
+	}
+	httpClient := &http.Client{Timeout: cfg.Timeout}
+	switch cfg.Backend {
+	case "etcd":
+		return newEtcd(cfg, httpClient), nil
+	case "consul":
+		return newConsul(cfg, httpClient), nil
+	}
+	return nil, fmt.Errorf("remote config: unknown backend %q", cfg.Backend)
+}
+
+// Watch loads src every interval and calls onChange with the settings when
+// their revision moved past since. Failed loads are reported to onError and
+// retried on the next tick.
+func Watch(ctx context.Context, src Source, interval time.Duration, since uint64, onChange func(map[string]string), onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			values, revision, err := src.Load(ctx)
+			if err != nil {
+				onError(err)
+				continue
+			}
+			if revision != since {
+				since = revision
+				onChange(values)
+			}
+		}
+	}
+}
+
+// settingName turns a key below the prefix into the variable it sets.
+func settingName(key string) string {
+	key = strings.Trim(key, "/")
+	return strings.NewReplacer("/", "_", "-", "_", ".", "_").Replace(strings.ToUpper(key))
+}
+
+// doJSON sends req and decodes a successful JSON response into out.
+func doJSON(httpClient *http.Client, req *http.Request, backend string, out any) (*http.Response, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", backend, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return resp, fmt.Errorf("%s: status %d: %s", backend, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return resp, json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,114 @@
+package secrets
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// GCPConfig authenticates to Google Secret Manager with AccessToken when set,
+// and otherwise with the service account of the GCE/GKE metadata server.
+type GCPConfig struct {
+	AccessToken string
+	Endpoint    string
+	MetadataURL string
+}
+
+// gcpSecrets reads gcp-sm://projects/<project>/secrets/<secret>[/versions/<v>],
+// or the short gcp-sm://<project>/<secret>[/<version>]. The version defaults
+// to latest.
+type gcpSecrets struct {
+	cfg        GCPConfig
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func newGCP(cfg GCPConfig, httpClient *http.Client) *gcpSecrets {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://secretmanager.googleapis.com"
+	}
+	if cfg.MetadataURL == "" {
+		cfg.MetadataURL = "http://metadata.google.internal"
+	}
+	return &gcpSecrets{cfg: cfg, httpClient: httpClient}
+}
+
+func (g *gcpSecrets) Fetch(ctx context.Context, name string) (string, error) {
+	resource, err := gcpResourceName(name)
+	if err != nil {
+		return "", err
+	}
+	token, err := g.accessToken(ctx)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimRight(g.cfg.Endpoint, "/")+"/v1/"+resource+":access", nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var resp struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	if err := getJSON(g.httpClient, req, "gcp secret manager", &resp); err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcp secret manager: payload: %w", err)
+	}
+	return string(data), nil
+}
+
+func gcpResourceName(name string) (string, error) {
+	parts := strings.Split(strings.Trim(name, "/"), "/")
+	switch {
+	case len(parts) == 4 && parts[0] == "projects" && parts[2] == "secrets":
+		return strings.Join(parts, "/") + "/versions/latest", nil
+	case len(parts) == 6 && parts[0] == "projects" && parts[2] == "secrets" && parts[4] == "versions":
+		return strings.Join(parts, "/"), nil
+	case len(parts) == 2:
+		return "projects/" + parts[0] + "/secrets/" + parts[1] + "/versions/latest", nil
+	case len(parts) == 3:
+		return "projects/" + parts[0] + "/secrets/" + parts[1] + "/versions/" + parts[2], nil
+	}
+	return "", fmt.Errorf("gcp secret manager: want gcp-sm://<project>/<secret>[/<version>]")
+}
+
+// accessToken returns the configured token, or one from the metadata server
+// that is cached until shortly before it expires.
+func (g *gcpSecrets) accessToken(ctx context.Context) (string, error) {
+	if g.cfg.AccessToken != "" {
+		return g.cfg.AccessToken, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && time.Now().Before(g.expires) {
+		return g.token, nil
+	}
+	url := strings.TrimRight(g.cfg.MetadataURL, "/") + "/computeMetadata/v1/instance/service-accounts/default/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var resp struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := getJSON(g.httpClient, req, "gcp metadata server", &resp); err != nil {
+		return "", fmt.Errorf("%w (set GCP_ACCESS_TOKEN outside GCP)", err)
+	}
+	g.token = resp.AccessToken
+	g.expires = time.Now().Add(time.Duration(resp.ExpiresIn)*time.Second - time.Minute)
+	return g.token, nil
+}
@@ -0,0 +1,167 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Config configures the backends secret references can point at. A backend
+// is only contacted when a reference uses its scheme.
+type Config struct {
+	Vault VaultConfig
+	AWS   AWSConfig
+	GCP   GCPConfig
+	// RefreshInterval is how often resolved secrets are fetched again to pick
+	// up rotations; zero resolves them once.
+	RefreshInterval time.Duration
+	Timeout         time.Duration
+}
+
+// Provider fetches a secret by its reference with the scheme and field
+// removed.
+type Provider interface {
+	Fetch(ctx context.Context, name string) (string, error)
+}
+
+// Resolver turns references such as vault://secret/sarama#llm_api_key into
+// the secrets they name and remembers them, so config lookups can swap a
+// reference for its value without a round trip.
+type Resolver struct {
+	providers map[string]Provider
+
+	mu     sync.RWMutex
+	values map[string]string
+}
+
+func NewResolver(cfg Config) *Resolver {
+	httpClient := &http.Client{Timeout: cfg.Timeout}
+	return &Resolver{
+		providers: map[string]Provider{
+			"vault":  newVault(cfg.Vault, httpClient),
+			"aws-sm": newAWS(cfg.AWS, httpClient),
+			"gcp-sm": newGCP(cfg.GCP, httpClient),
+		},
+		values: make(map[string]string),
+	}
+}
+
+// IsReference reports whether value names a secret rather than being one.
+func IsReference(value string) bool {
+	for _, scheme := range []string{"vault://", "aws-sm://", "gcp-sm://"} {
+		if strings.HasPrefix(value, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// Resolve fetches every reference, reporting all that failed.
+func (r *Resolver) Resolve(ctx context.Context, refs []string) error {
+	var errs []error
+	for _, ref := range refs {
+		value, err := r.fetch(ctx, ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.mu.Lock()
+		r.values[ref] = value
+		r.mu.Unlock()
+	}
+	return errors.Join(errs...)
+}
+
+// Lookup returns the value a resolved reference stands for.
+func (r *Resolver) Lookup(ref string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	value, ok := r.values[ref]
+	return value, ok
+}
+
+// Len is the number of references resolved.
+func (r *Resolver) Len() int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.values)
+}
+
+// Refresh fetches the resolved references again and reports whether any
+// changed. A secret that can't be fetched keeps its last value.
+func (r *Resolver) Refresh(ctx context.Context) (bool, error) {
+	r.mu.RLock()
+	refs := make([]string, 0, len(r.values))
+	for ref := range r.values {
+		refs = append(refs, ref)
+	}
+	r.mu.RUnlock()
+
+	changed := false
+	var errs []error
+	for _, ref := range refs {
+		value, err := r.fetch(ctx, ref)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		r.mu.Lock()
+		if r.values[ref] != value {
+			r.values[ref] = value
+			changed = true
+		}
+		r.mu.Unlock()
+	}
+	return changed, errors.Join(errs...)
+}
+
+// fetch resolves one reference. A #field suffix picks a key out of a secret
+// that holds a JSON object; errors name the reference but never a value.
+func (r *Resolver) fetch(ctx context.Context, ref string) (string, error) {
+	scheme, rest, ok := strings.Cut(ref, "://")
+	provider := r.providers[scheme]
+	if !ok || provider == nil {
+		return "", fmt.Errorf("%s: unknown secret scheme", ref)
+	}
+	name, field, _ := strings.Cut(rest, "#")
+	value, err := provider.Fetch(ctx, name)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", ref, err)
+	}
+	if field == "" {
+		return value, nil
+	}
+	var fields map[string]any
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		return "", fmt.Errorf("%s: secret is not a JSON object, so it has no field %q", ref, field)
+	}
+	v, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("%s: secret has no field %q", ref, field)
+	}
+	if s, ok := v.(string); ok {
+		return s, nil
+	}
+	b, err := json.Marshal(v)
+	return string(b), err
+}
+
+// getJSON sends req and decodes a successful JSON response into out.
+func getJSON(httpClient *http.Client, req *http.Request, backend string, out any) error {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s: %w", backend, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return fmt.Errorf("%s: status %d: %s", backend, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -0,0 +1,58 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// VaultConfig reaches a HashiCorp Vault server with a token.
+type VaultConfig struct {
+	Address   string
+	Token     string
+	Namespace string
+}
+
+// vault reads KV version 2 secrets: vault://secret/sarama names the secret
+// sarama in the mount secret, and the value is its data as a JSON object.
+type vault struct {
+	cfg        VaultConfig
+	httpClient *http.Client
+}
+
+func newVault(cfg VaultConfig, httpClient *http.Client) *vault {
+	return &vault{cfg: cfg, httpClient: httpClient}
+}
+
+func (v *vault) Fetch(ctx context.Context, name string) (string, error) {
+	if v.cfg.Address == "" || v.cfg.Token == "" {
+		return "", fmt.Errorf("vault: VAULT_ADDR and VAULT_TOKEN are required")
+	}
+	mount, path, ok := strings.Cut(strings.Trim(name, "/"), "/")
+	if !ok {
+		return "", fmt.Errorf("vault: want vault://<mount>/<path>")
+	}
+	url := strings.TrimRight(v.cfg.Address, "/") + "/v1/" + mount + "/data/" + path
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", v.cfg.Token)
+	if v.cfg.Namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.cfg.Namespace)
+	}
+	var resp struct {
+		Data struct {
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	if err := getJSON(v.httpClient, req, "vault", &resp); err != nil {
+		return "", err
+	}
+	if len(resp.Data.Data) == 0 || string(resp.Data.Data) == "null" {
+		return "", fmt.Errorf("vault: secret has no data")
+	}
+	return string(resp.Data.Data), nil
+}
@@ -0,0 +1,148 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSkew is how old a signed request may be before it is rejected as a replay.
+const maxSkew = 5 * time.Minute
+
+var ErrInvalidSignature = errors.New("invalid slack signature")
+
+type Config struct {
+	BotToken      string
+	SigningSecret string
+	BaseURL       string
+	Timeout       time.Duration
+}
+
+func (c Config) Enabled() bool {
+	return c.BotToken != "" && c.SigningSecret != ""
+}
+
+// Client verifies requests from Slack and posts messages with a bot token.
+type Client struct {
+	cfg        Config
+	httpClient *http.Client
+}
+
+func NewClient(cfg Config) *Client {
+	cfg.BaseURL = strings.TrimRight(cfg.BaseURL, "/")
+	return &Client{cfg: cfg, httpClient: &http.Client{Timeout: cfg.Timeout}}
+}
+
+// Verify checks the request signature Slack computes over the timestamp and
+// raw body with the app's signing secret.
+func (c *Client) Verify(header http.Header, body []byte) error {
+	timestamp := header.Get("X-Slack-Request-Timestamp")
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+	if age := time.Since(time.Unix(sec, 0)); age > maxSkew || age < -maxSkew {
+		return fmt.Errorf("%w: stale timestamp", ErrInvalidSignature)
+	}
+	mac := hmac.New(sha256.New, []byte(c.cfg.SigningSecret))
+	fmt.Fprintf(mac, "v0:%s:", timestamp)
+	mac.Write(body)
+	expected := "v0=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(expected), []byte(header.Get("X-Slack-Signature"))) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// Message is a chat.postMessage request; Text is Slack mrkdwn.
+type Message struct {
+	Channel     string `json:"channel,omitempty"`
+	ThreadTS    string `json:"thread_ts,omitempty"`
+	Text        string `json:"text"`
+	UnfurlLinks bool   `json:"unfurl_links"`
+	// ResponseType is "ephemeral" or "in_channel" for slash command responses.
+	ResponseType string `json:"response_type,omitempty"`
+}
+
+type apiResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+	TS    string `json:"ts"`
+}
+
+// PostMessage posts msg and returns its timestamp, which identifies the
+// message as a thread parent.
+func (c *Client) PostMessage(ctx context.Context, msg Message) (string, error) {
+	var resp apiResponse
+	if err := c.call(ctx, "/chat.postMessage", msg, &resp); err != nil {
+		return "", err
+	}
+	return resp.TS, nil
+}
+
+// Respond replies to a slash command through its response URL, which works
+// even in channels the bot has not joined.
+func (c *Client) Respond(ctx context.Context, responseURL string, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack response url: status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Ping checks the bot token with auth.test.
+func (c *Client) Ping(ctx context.Context) error {
+	return c.call(ctx, "/auth.test", struct{}{}, &apiResponse{})
+}
+
+func (c *Client) call(ctx context.Context, method string, in any, out *apiResponse) error {
+	body, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.BaseURL+method, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Authorization", "Bearer "+c.cfg.BotToken)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("slack %s: status %d: %s", method, resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("slack %s: decode response: %w", method, err)
+	}
+	if !out.OK {
+		return fmt.Errorf("slack %s: %s", method, out.Error)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Package blob keeps objects in S3-compatible storage, Google Cloud Storage
+// or a local directory.
+package blob
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Config struct {
+	// Backend is s3, gcs or local; empty means blobs aren't stored.
+	Backend string
+	// Bucket names the S3 or GCS bucket.
+	Bucket string
+	// Dir is the root of the local backend.
+	Dir     string
+	S3      S3Config
+	GCS     GCSConfig
+	Timeout time.Duration
+}
+
+// Open returns the configured backend. It doesn't contact it; the first
+// request reports bad credentials or a missing bucket.
+func Open(cfg Config) (domain.BlobStore, error) {
+	httpClient := &http.Client{Timeout: cfg.Timeout}
+	switch cfg.Backend {
+	case "s3":
+		return NewS3(cfg.Bucket, cfg.S3, httpClient), nil
+	case "gcs":
+		return NewGCS(cfg.Bucket, cfg.GCS, httpClient), nil
+	case "local":
+		return NewLocal(cfg.Dir)
+	}
+	return nil, fmt.Errorf("unknown blob backend %q", cfg.Backend)
+}
+
+// send performs req, returning the response of a 2xx status for the caller
+// to close and domain.ErrNotFound for a 404.
+func send(httpClient *http.Client, req *http.Request, backend string) (*http.Response, error) {
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", backend, err)
+	}
+	if resp.StatusCode/100 == 2 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, domain.ErrNotFound
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	return nil, fmt.Errorf("%s: status %d: %s", backend, resp.StatusCode, strings.TrimSpace(string(msg)))
+}
@@ -0,0 +1,190 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// GCSConfig authenticates to Cloud Storage with AccessToken when set, and
+// otherwise with the service account of the GCE/GKE metadata server.
+type GCSConfig struct {
+	AccessToken string
+	Endpoint    string
+	MetadataURL string
+}
+
+// GCS stores blobs as objects of one bucket through the JSON API.
+type GCS struct {
+	bucket     string
+	cfg        GCSConfig
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	token   string
+	expires time.Time
+}
+
+func NewGCS(bucket string, cfg GCSConfig, httpClient *http.Client) *GCS {
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = "https://storage.googleapis.com"
+	}
+	if cfg.MetadataURL == "" {
+		cfg.MetadataURL = "http://metadata.google.internal"
+	}
+	return &GCS{bucket: bucket, cfg: cfg, httpClient: httpClient}
+}
+
+func (g *GCS) Put(ctx context.Context, key string, data []byte, contentType string) error {
+	query := url.Values{"uploadType": {"media"}, "name": {key}}
+	u := strings.TrimRight(g.cfg.Endpoint, "/") + "/upload/storage/v1/b/" + url.PathEscape(g.bucket) + "/o?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := g.send(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (g *GCS) Get(ctx context.Context, key string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, g.objectURL(key)+"?alt=media", nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := g.send(req)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("gcs: %w", err)
+	}
+	return data, resp.Header.Get("Content-Type"), nil
+}
+
+func (g *GCS) List(ctx context.Context, prefix string) ([]domain.Blob, error) {
+	var blobs []domain.Blob
+	token := ""
+	for {
+		query := url.Values{"prefix": {prefix}, "fields": {"items(name,size,updated),nextPageToken"}}
+		if token != "" {
+			query.Set("pageToken", token)
+		}
+		u := strings.TrimRight(g.cfg.Endpoint, "/") + "/storage/v1/b/" + url.PathEscape(g.bucket) + "/o?" + query.Encode()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := g.send(req)
+		if errors.Is(err, domain.ErrNotFound) {
+			return nil, fmt.Errorf("gcs: bucket %s not found", g.bucket)
+		}
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Items []struct {
+				Name    string    `json:"name"`
+				Size    string    `json:"size"`
+				Updated time.Time `json:"updated"`
+			} `json:"items"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("gcs: list: %w", err)
+		}
+		for _, obj := range page.Items {
+			size, _ := strconv.ParseInt(obj.Size, 10, 64)
+			blobs = append(blobs, domain.Blob{Key: obj.Name, Size: size, Modified: obj.Updated})
+		}
+		if page.NextPageToken == "" {
+			return blobs, nil
+		}
+		token = page.NextPageToken
+	}
+}
+
+func (g *GCS) Delete(ctx context.Context, key string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, g.objectURL(key), nil)
+	if err != nil {
+		return err
+	}
+	resp, err := g.send(req)
+	if errors.Is(err, domain.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	return nil
+}
+
+func (g *GCS) objectURL(key string) string {
+	return strings.TrimRight(g.cfg.Endpoint, "/") + "/storage/v1/b/" + url.PathEscape(g.bucket) + "/o/" + url.PathEscape(key)
+}
+
+func (g *GCS) send(req *http.Request) (*http.Response, error) {
+	token, err := g.accessToken(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	return send(g.httpClient, req, "gcs")
+}
+
+// accessToken returns the configured token, or one from the metadata server
+// that is cached until shortly before it expires.
+func (g *GCS) accessToken(ctx context.Context) (string, error) {
+	if g.cfg.AccessToken != "" {
+		return g.cfg.AccessToken, nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.token != "" && time.Now().Before(g.expires) {
+		return g.token, nil
+	}
+	tokenURL := strings.TrimRight(g.cfg.MetadataURL, "/") + "/computeMetadata/v1/instance/service-accounts/default/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	resp, err := send(g.httpClient, req, "gcp metadata server")
+	if err != nil {
+		return "", fmt.Errorf("%w (set GCP_ACCESS_TOKEN outside GCP)", err)
+	}
+	defer resp.Body.Close()
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("gcp metadata server: %w", err)
+	}
+	g.token = token.AccessToken
+	g.expires = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - time.Minute)
+	return g.token, nil
+}
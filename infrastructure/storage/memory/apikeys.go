@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type APIKeyRepository struct {
+	mu     sync.RWMutex
+	byHash map[string]domain.APIKey
+}
+
+func NewAPIKeyRepository() *APIKeyRepository {
+	return &APIKeyRepository{byHash: make(map[string]domain.APIKey)}
+}
+
+func (r *APIKeyRepository) SaveAPIKey(ctx context.Context, key domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.byHash[key.Hash] = key
+	return nil
+}
+
+func (r *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	key, ok := r.byHash[hash]
+	if !ok {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.APIKey, 0, len(r.byHash))
+	for _, k := range r.byHash {
+		out = append(out, k)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *APIKeyRepository) DeleteAPIKey(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for hash, k := range r.byHash {
+		if k.ID == id {
+			delete(r.byHash, hash)
+			return nil
+		}
+	}
+	return domain.ErrAPIKeyNotFound
+}
@@ -0,0 +1,100 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// Sessions beyond maxConversations evict the least recently active one, and
+// each keeps its last maxConversationMessages messages.
+const (
+	maxConversations        = 10000
+	maxConversationMessages = 100
+)
+
+type conversationMessage struct {
+	domain.Message
+	at time.Time
+}
+
+type ConversationRepository struct {
+	mu       sync.Mutex
+	sessions map[string][]conversationMessage
+	order    []string
+}
+
+func NewConversationRepository() *ConversationRepository {
+	return &ConversationRepository{sessions: make(map[string][]conversationMessage)}
+}
+
+func (r *ConversationRepository) AppendMessages(ctx context.Context, sessionID string, messages ...domain.Message) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.forget(sessionID)
+	r.order = append(r.order, sessionID)
+	if len(r.order) > maxConversations {
+		delete(r.sessions, r.order[0])
+		r.order = r.order[1:]
+	}
+	now := time.Now()
+	kept := r.sessions[sessionID]
+	for _, m := range messages {
+		kept = append(kept, conversationMessage{Message: m, at: now})
+	}
+	if len(kept) > maxConversationMessages {
+		kept = append([]conversationMessage(nil), kept[len(kept)-maxConversationMessages:]...)
+	}
+	r.sessions[sessionID] = kept
+	return nil
+}
+
+func (r *ConversationRepository) Messages(ctx context.Context, sessionID string, limit int) ([]domain.Message, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.sessions[sessionID]
+	if len(kept) > limit {
+		kept = kept[len(kept)-limit:]
+	}
+	messages := make([]domain.Message, len(kept))
+	for i, m := range kept {
+		messages[i] = m.Message
+	}
+	return messages, nil
+}
+
+func (r *ConversationRepository) PurgeMessages(ctx context.Context, before time.Time, scope domain.RetentionScope) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	purged := 0
+	for sessionID, kept := range r.sessions {
+		if !scope.Covers(domain.ConversationTenant(sessionID)) {
+			continue
+		}
+		// Messages are kept in the order they were added.
+		n := 0
+		for n < len(kept) && kept[n].at.Before(before) {
+			n++
+		}
+		purged += n
+		if n == len(kept) {
+			delete(r.sessions, sessionID)
+			r.forget(sessionID)
+		} else if n > 0 {
+			r.sessions[sessionID] = append([]conversationMessage(nil), kept[n:]...)
+		}
+	}
+	return purged, nil
+}
+
+// forget removes sessionID from the activity order.
+func (r *ConversationRepository) forget(sessionID string) {
+	for i, id := range r.order {
+		if id == sessionID {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			return
+		}
+	}
+}
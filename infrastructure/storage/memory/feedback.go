@@ -0,0 +1,49 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type FeedbackRepository struct {
+	mu       sync.RWMutex
+	feedback []domain.Feedback
+}
+
+func NewFeedbackRepository() *FeedbackRepository {
+	return &FeedbackRepository{}
+}
+
+func (r *FeedbackRepository) Save(ctx context.Context, f domain.Feedback) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.feedback = append(r.feedback, f)
+	return nil
+}
+
+func (r *FeedbackRepository) List(ctx context.Context) ([]domain.Feedback, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.Feedback, len(r.feedback))
+	copy(out, r.feedback)
+	return out, nil
+}
+
+func (r *FeedbackRepository) Purge(ctx context.Context, before time.Time, scope domain.RetentionScope) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.feedback[:0]
+	for _, f := range r.feedback {
+		if f.CreatedAt.Before(before) && scope.Covers(f.Principal) {
+			continue
+		}
+		kept = append(kept, f)
+	}
+	purged := len(r.feedback) - len(kept)
+	clear(r.feedback[len(kept):])
+	r.feedback = kept
+	return purged, nil
+}
@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const maxGaps = 10000
+
+type GapRepository struct {
+	mu   sync.RWMutex
+	gaps []domain.KnowledgeGap
+}
+
+func NewGapRepository() *GapRepository {
+	return &GapRepository{}
+}
+
+func (r *GapRepository) SaveGap(ctx context.Context, gap domain.KnowledgeGap) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.gaps = append(r.gaps, gap)
+	// Keep memory bounded by dropping the oldest entries.
+	if len(r.gaps) > maxGaps {
+		r.gaps = append([]domain.KnowledgeGap(nil), r.gaps[len(r.gaps)-maxGaps:]...)
+	}
+	return nil
+}
+
+func (r *GapRepository) ListGaps(ctx context.Context, since time.Time) ([]domain.KnowledgeGap, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []domain.KnowledgeGap
+	for _, g := range r.gaps {
+		if !g.At.Before(since) {
+			out = append(out, g)
+		}
+	}
+	return out, nil
+}
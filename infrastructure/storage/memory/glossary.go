@@ -0,0 +1,87 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type GlossaryRepository struct {
+	mu    sync.RWMutex
+	byDoc map[string][]domain.GlossaryEntry
+}
+
+func NewGlossaryRepository() *GlossaryRepository {
+	return &GlossaryRepository{byDoc: make(map[string][]domain.GlossaryEntry)}
+}
+
+func (r *GlossaryRepository) ReplaceDocumentEntries(ctx context.Context, documentID string, entries []domain.GlossaryEntry) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(entries) == 0 {
+		delete(r.byDoc, documentID)
+		return nil
+	}
+	r.byDoc[documentID] = append([]domain.GlossaryEntry(nil), entries...)
+	return nil
+}
+
+func (r *GlossaryRepository) RemoveDocumentEntries(ctx context.Context, documentID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byDoc, documentID)
+	return nil
+}
+
+func (r *GlossaryRepository) LookupTerms(ctx context.Context, terms []string) ([]domain.GlossaryEntry, error) {
+	wanted := make(map[string]bool, len(terms))
+	for _, t := range terms {
+		wanted[strings.ToLower(t)] = true
+	}
+
+	all, err := r.ListGlossary(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.GlossaryEntry
+	for _, e := range all {
+		if wanted[strings.ToLower(e.Term)] {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// ListGlossary merges per-document entries by term, keeping the most recent definition.
+func (r *GlossaryRepository) ListGlossary(ctx context.Context) ([]domain.GlossaryEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	merged := make(map[string]*domain.GlossaryEntry)
+	for docID, entries := range r.byDoc {
+		for _, e := range entries {
+			key := strings.ToLower(e.Term)
+			m, ok := merged[key]
+			if !ok {
+				e.Sources = []string{docID}
+				merged[key] = &e
+				continue
+			}
+			m.Sources = append(m.Sources, docID)
+			if e.UpdatedAt.After(m.UpdatedAt) {
+				m.Definition, m.Kind, m.UpdatedAt = e.Definition, e.Kind, e.UpdatedAt
+			}
+		}
+	}
+
+	out := make([]domain.GlossaryEntry, 0, len(merged))
+	for _, e := range merged {
+		sort.Strings(e.Sources)
+		out = append(out, *e)
+	}
+	sort.Slice(out, func(i, j int) bool { return strings.ToLower(out[i].Term) < strings.ToLower(out[j].Term) })
+	return out, nil
+}
@@ -0,0 +1,83 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type GraphRepository struct {
+	mu    sync.RWMutex
+	byDoc map[string][]domain.Relation
+}
+
+func NewGraphRepository() *GraphRepository {
+	return &GraphRepository{byDoc: make(map[string][]domain.Relation)}
+}
+
+func (r *GraphRepository) ReplaceDocumentGraph(ctx context.Context, documentID string, relations []domain.Relation) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(relations) == 0 {
+		delete(r.byDoc, documentID)
+		return nil
+	}
+	r.byDoc[documentID] = append([]domain.Relation(nil), relations...)
+	return nil
+}
+
+func (r *GraphRepository) RemoveDocumentGraph(ctx context.Context, documentID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.byDoc, documentID)
+	return nil
+}
+
+func (r *GraphRepository) FindEntities(ctx context.Context, ids []string) ([]domain.Entity, error) {
+	wanted := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		wanted[id] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	found := make(map[string]domain.Entity)
+	for _, relations := range r.byDoc {
+		for _, rel := range relations {
+			for _, e := range []domain.Entity{rel.Subject, rel.Object} {
+				if wanted[e.ID] {
+					found[e.ID] = e
+				}
+			}
+		}
+	}
+
+	out := make([]domain.Entity, 0, len(found))
+	for _, e := range found {
+		out = append(out, e)
+	}
+	return out, nil
+}
+
+func (r *GraphRepository) Neighbors(ctx context.Context, entityIDs []string, limit int) ([]domain.Relation, error) {
+	wanted := make(map[string]bool, len(entityIDs))
+	for _, id := range entityIDs {
+		wanted[id] = true
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var out []domain.Relation
+	for _, relations := range r.byDoc {
+		for _, rel := range relations {
+			if wanted[rel.Subject.ID] || wanted[rel.Object.ID] {
+				out = append(out, rel)
+				if limit > 0 && len(out) >= limit {
+					return out, nil
+				}
+			}
+		}
+	}
+	return out, nil
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// maxLedgerEvents bounds remembered event IDs; redeliveries arrive soon after
+// the original, so the oldest IDs are the safest to forget.
+const maxLedgerEvents = 100000
+
+type IngestLedger struct {
+	mu         sync.RWMutex
+	events     map[string]struct{}
+	order      []string
+	watermarks map[string]time.Time
+}
+
+func NewIngestLedger() *IngestLedger {
+	return &IngestLedger{events: map[string]struct{}{}, watermarks: map[string]time.Time{}}
+}
+
+func (l *IngestLedger) Applied(ctx context.Context, eventID string) (bool, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	_, ok := l.events[eventID]
+	return ok, nil
+}
+
+func (l *IngestLedger) Watermark(ctx context.Context, documentID string) (time.Time, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.watermarks[documentID], nil
+}
+
+func (l *IngestLedger) MarkApplied(ctx context.Context, event domain.IngestEvent) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.events[event.ID]; !ok {
+		l.events[event.ID] = struct{}{}
+		l.order = append(l.order, event.ID)
+		if len(l.order) > maxLedgerEvents {
+			drop := len(l.order) - maxLedgerEvents
+			for _, id := range l.order[:drop] {
+				delete(l.events, id)
+			}
+			l.order = append([]string(nil), l.order[drop:]...)
+		}
+	}
+	if event.ReceivedAt.After(l.watermarks[event.DocumentID]) {
+		l.watermarks[event.DocumentID] = event.ReceivedAt
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type RoleBindingRepository struct {
+	mu       sync.RWMutex
+	bindings map[string]domain.RoleBinding
+}
+
+func NewRoleBindingRepository() *RoleBindingRepository {
+	return &RoleBindingRepository{bindings: make(map[string]domain.RoleBinding)}
+}
+
+func (r *RoleBindingRepository) SaveRoleBinding(ctx context.Context, b domain.RoleBinding) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bindings[b.ID] = b
+	return nil
+}
+
+func (r *RoleBindingRepository) ListRoleBindings(ctx context.Context) ([]domain.RoleBinding, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.RoleBinding, 0, len(r.bindings))
+	for _, b := range r.bindings {
+		out = append(out, b)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *RoleBindingRepository) RoleBindingsFor(ctx context.Context, subject string) ([]domain.RoleBinding, error) {
+	all, err := r.ListRoleBindings(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var out []domain.RoleBinding
+	for _, b := range all {
+		if b.Subject == subject {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+func (r *RoleBindingRepository) DeleteRoleBinding(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.bindings[id]; !ok {
+		return domain.ErrRoleBindingNotFound
+	}
+	delete(r.bindings, id)
+	return nil
+}
@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RetrievalStats keeps per-document retrieval counts in daily buckets.
+type RetrievalStats struct {
+	mu      sync.Mutex
+	buckets map[string]map[string]int // document ID -> day (YYYY-MM-DD) -> count
+}
+
+func NewRetrievalStats() *RetrievalStats {
+	return &RetrievalStats{buckets: make(map[string]map[string]int)}
+}
+
+func (s *RetrievalStats) RecordRetrieval(ctx context.Context, documentIDs []string, at time.Time) error {
+	day := at.UTC().Format(time.DateOnly)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range documentIDs {
+		days, ok := s.buckets[id]
+		if !ok {
+			days = make(map[string]int)
+			s.buckets[id] = days
+		}
+		days[day]++
+	}
+	return nil
+}
+
+func (s *RetrievalStats) RetrievalCounts(ctx context.Context, since time.Time) (map[string]int, error) {
+	from := since.UTC().Format(time.DateOnly)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counts := make(map[string]int, len(s.buckets))
+	for id, days := range s.buckets {
+		for day, n := range days {
+			if day >= from {
+				counts[id] += n
+			}
+		}
+	}
+	return counts, nil
+}
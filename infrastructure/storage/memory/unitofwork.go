@@ -0,0 +1,23 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// UnitOfWork hands every unit the same stores, so writes take effect as they
+// are made and a failed unit keeps the ones before its error. It stands in for
+// a transactional backend in development and tests, and when the writes span
+// stores that share no transaction.
+type UnitOfWork struct {
+	stores domain.Stores
+}
+
+func NewUnitOfWork(stores domain.Stores) *UnitOfWork {
+	return &UnitOfWork{stores: stores}
+}
+
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context, stores domain.Stores) error) error {
+	return fn(ctx, u.stores)
+}
@@ -0,0 +1,46 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type WebhookRepository struct {
+	mu       sync.RWMutex
+	webhooks map[string]domain.Webhook
+}
+
+func NewWebhookRepository() *WebhookRepository {
+	return &WebhookRepository{webhooks: make(map[string]domain.Webhook)}
+}
+
+func (r *WebhookRepository) SaveWebhook(ctx context.Context, w domain.Webhook) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.webhooks[w.ID] = w
+	return nil
+}
+
+func (r *WebhookRepository) ListWebhooks(ctx context.Context) ([]domain.Webhook, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	out := make([]domain.Webhook, 0, len(r.webhooks))
+	for _, w := range r.webhooks {
+		out = append(out, w)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].CreatedAt.Before(out[j].CreatedAt) })
+	return out, nil
+}
+
+func (r *WebhookRepository) DeleteWebhook(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.webhooks[id]; !ok {
+		return domain.ErrWebhookNotFound
+	}
+	delete(r.webhooks, id)
+	return nil
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type APIKeyRepository struct {
+	q querier
+}
+
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{q: db.pool}
+}
+
+const apiKeyColumns = "id, name, prefix, hash, scopes, created_at, tenant"
+
+func (r *APIKeyRepository) SaveAPIKey(ctx context.Context, key domain.APIKey) error {
+	_, err := r.q.Exec(ctx, `INSERT INTO api_keys (`+apiKeyColumns+`) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (id) DO UPDATE SET name = $2, prefix = $3, hash = $4, scopes = $5, created_at = $6, tenant = $7`,
+		key.ID, key.Name, key.Prefix, key.Hash, scopeNames(key.Scopes), key.CreatedAt, key.Tenant)
+	return err
+}
+
+func (r *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	rows, _ := r.q.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE hash = $1`, hash)
+	key, err := pgx.CollectExactlyOneRow(rows, scanAPIKey)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, _ := r.q.Query(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at`)
+	return pgx.CollectRows(rows, scanAPIKey)
+}
+
+func (r *APIKeyRepository) DeleteAPIKey(ctx context.Context, id string) error {
+	tag, err := r.q.Exec(ctx, `DELETE FROM api_keys WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(row pgx.CollectableRow) (domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes []string
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &scopes, &key.CreatedAt, &key.Tenant)
+	for _, s := range scopes {
+		key.Scopes = append(key.Scopes, domain.Scope(s))
+	}
+	return key, err
+}
+
+func scopeNames(scopes []domain.Scope) []string {
+	names := make([]string, len(scopes))
+	for i, s := range scopes {
+		names[i] = string(s)
+	}
+	return names
+}
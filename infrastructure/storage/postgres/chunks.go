@@ -0,0 +1,156 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/vectorstore"
+)
+
+// VectorStore keeps documents and their chunks. Search scores every chunk
+// that passes the filter by cosine similarity inside the database, like the
+// in-memory store does, so it needs no extension and suits collections up to
+// a few hundred thousand chunks.
+type VectorStore struct {
+	db *DB
+	q  querier
+}
+
+func NewVectorStore(db *DB) *VectorStore {
+	return &VectorStore{db: db, q: db.pool}
+}
+
+const chunkColumns = "id, document_id, space_key, title, url, chunk_index, text, readers, embedding, updated_at, labels"
+
+func (s *VectorStore) Upsert(ctx context.Context, chunks []domain.Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	batch := &pgx.Batch{}
+	seen := make(map[string]bool)
+	for _, c := range chunks {
+		if !seen[c.DocumentID] {
+			seen[c.DocumentID] = true
+			batch.Queue(`INSERT INTO documents (id, space_key, title, url, updated_at) VALUES ($1, $2, $3, $4, $5)
+				ON CONFLICT (id) DO UPDATE SET space_key = $2, title = $3, url = $4, updated_at = $5`,
+				c.DocumentID, c.SpaceKey, c.Title, c.URL, c.UpdatedAt)
+		}
+		batch.Queue(`INSERT INTO chunks (`+chunkColumns+`, embedding_norm) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+			ON CONFLICT (id) DO UPDATE SET document_id = $2, space_key = $3, title = $4, url = $5, chunk_index = $6,
+				text = $7, readers = $8, embedding = $9, updated_at = $10, labels = $11, embedding_norm = $12`,
+			c.ID, c.DocumentID, c.SpaceKey, c.Title, c.URL, c.Index, c.Text, nonNil(c.Readers), c.Embedding, c.UpdatedAt, nonNil(c.Labels), norm(c.Embedding))
+	}
+	return s.q.SendBatch(ctx, batch).Close()
+}
+
+func (s *VectorStore) Search(ctx context.Context, vector []float32, topK int, filter domain.SearchFilter) (_ []domain.ScoredChunk, err error) {
+	start := time.Now()
+	defer func() { vectorstore.ObserveSearch("postgres", start, err) }()
+	where, args := searchFilter(filter, 3)
+	query := `SELECT ` + chunkColumns + `,
+			COALESCE((SELECT sum(a * b) FROM unnest(embedding, $1::real[]) AS v(a, b)) / NULLIF(embedding_norm * $2, 0), 0) AS score
+		FROM chunks` + where + ` ORDER BY score DESC`
+	if topK > 0 {
+		query += fmt.Sprintf(" LIMIT %d", topK)
+	}
+	rows, _ := s.q.Query(ctx, query, append([]any{vector, norm(vector)}, args...)...)
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.ScoredChunk, error) {
+		var c domain.ScoredChunk
+		err := row.Scan(chunkFields(&c.Chunk, &c.Score)...)
+		return c, err
+	})
+}
+
+// searchFilter renders filter as a WHERE clause whose placeholders start at n.
+func searchFilter(filter domain.SearchFilter, n int) (string, []any) {
+	var conds []string
+	var args []any
+	add := func(cond string, arg any) {
+		conds = append(conds, fmt.Sprintf(cond, n))
+		args = append(args, arg)
+		n++
+	}
+	if len(filter.SpaceKeys) > 0 {
+		add("space_key = ANY($%d)", filter.SpaceKeys)
+	}
+	if len(filter.DocumentIDs) > 0 {
+		add("document_id = ANY($%d)", filter.DocumentIDs)
+	}
+	if len(filter.ExcludeDocumentIDs) > 0 {
+		add("NOT document_id = ANY($%d)", filter.ExcludeDocumentIDs)
+	}
+	if len(filter.Readers) > 0 {
+		// Unrestricted chunks have no readers.
+		add("(readers = '{}' OR readers && $%d)", filter.Readers)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (s *VectorStore) DeleteDocument(ctx context.Context, documentID string) error {
+	// Chunks go with the document.
+	_, err := s.q.Exec(ctx, `DELETE FROM documents WHERE id = $1`, documentID)
+	return err
+}
+
+func (s *VectorStore) DocumentChunks(ctx context.Context, documentID string) ([]domain.Chunk, error) {
+	rows, _ := s.q.Query(ctx, `SELECT `+chunkColumns+` FROM chunks WHERE document_id = $1 ORDER BY chunk_index`, documentID)
+	return pgx.CollectRows(rows, scanChunk)
+}
+
+func (s *VectorStore) ScanChunks(ctx context.Context, fn func(domain.Chunk) error) error {
+	rows, err := s.q.Query(ctx, `SELECT `+chunkColumns+` FROM chunks ORDER BY document_id, chunk_index`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c, err := scanChunk(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *VectorStore) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
+func scanChunk(row pgx.CollectableRow) (domain.Chunk, error) {
+	var c domain.Chunk
+	err := row.Scan(chunkFields(&c)...)
+	return c, err
+}
+
+// chunkFields are the scan targets of chunkColumns, then of extra columns.
+func chunkFields(c *domain.Chunk, extra ...any) []any {
+	return append([]any{&c.ID, &c.DocumentID, &c.SpaceKey, &c.Title, &c.URL, &c.Index, &c.Text, &c.Readers, &c.Embedding, &c.UpdatedAt, &c.Labels}, extra...)
+}
+
+func norm(v []float32) float64 {
+	var sum float64
+	for _, x := range v {
+		sum += float64(x) * float64(x)
+	}
+	return math.Sqrt(sum)
+}
+
+// nonNil keeps an unrestricted chunk's readers, or an unlabelled one's labels,
+// an empty array rather than NULL.
+func nonNil(r []string) []string {
+	if r == nil {
+		return []string{}
+	}
+	return r
+}
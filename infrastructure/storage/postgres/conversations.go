@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type ConversationRepository struct {
+	q querier
+}
+
+func NewConversationRepository(db *DB) *ConversationRepository {
+	return &ConversationRepository{q: db.pool}
+}
+
+func (r *ConversationRepository) AppendMessages(ctx context.Context, sessionID string, messages ...domain.Message) error {
+	batch := &pgx.Batch{}
+	for _, m := range messages {
+		batch.Queue(`INSERT INTO conversation_messages (session_id, role, content) VALUES ($1, $2, $3)`, sessionID, string(m.Role), m.Content)
+	}
+	return r.q.SendBatch(ctx, batch).Close()
+}
+
+func (r *ConversationRepository) Messages(ctx context.Context, sessionID string, limit int) ([]domain.Message, error) {
+	rows, _ := r.q.Query(ctx, `SELECT role, content FROM (
+			SELECT id, role, content FROM conversation_messages WHERE session_id = $1 ORDER BY id DESC LIMIT $2
+		) AS recent ORDER BY id`, sessionID, limit)
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Message, error) {
+		var m domain.Message
+		var role string
+		err := row.Scan(&role, &m.Content)
+		m.Role = domain.Role(role)
+		return m, err
+	})
+}
+
+// PurgeMessages matches a tenant's sessions by the principal ID their keys
+// start with.
+func (r *ConversationRepository) PurgeMessages(ctx context.Context, before time.Time, scope domain.RetentionScope) (int, error) {
+	query, args := `DELETE FROM conversation_messages WHERE created_at < $1`, []any{before}
+	prefix := func(negate bool, tenant string) {
+		args = append(args, tenant+"/")
+		cond := fmt.Sprintf("left(session_id, char_length($%d)) = $%d", len(args), len(args))
+		if negate {
+			cond = "NOT " + cond
+		}
+		query += " AND " + cond
+	}
+	if scope.Tenant != "" {
+		prefix(false, scope.Tenant)
+	} else {
+		for _, tenant := range scope.Except {
+			prefix(true, tenant)
+		}
+	}
+	tag, err := r.q.Exec(ctx, query, args...)
+	return int(tag.RowsAffected()), err
+}
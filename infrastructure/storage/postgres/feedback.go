@@ -0,0 +1,46 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type FeedbackRepository struct {
+	q querier
+}
+
+func NewFeedbackRepository(db *DB) *FeedbackRepository {
+	return &FeedbackRepository{q: db.pool}
+}
+
+func (r *FeedbackRepository) Save(ctx context.Context, f domain.Feedback) error {
+	_, err := r.q.Exec(ctx, `INSERT INTO feedback (id, session_id, question, rating, comment, variants, created_at, principal_id)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		f.ID, f.SessionID, f.Question, f.Rating, f.Comment, f.Variants, f.CreatedAt, f.Principal)
+	return err
+}
+
+func (r *FeedbackRepository) List(ctx context.Context) ([]domain.Feedback, error) {
+	rows, _ := r.q.Query(ctx, `SELECT id, session_id, question, rating, comment, variants, created_at, principal_id
+		FROM feedback ORDER BY created_at, id`)
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (domain.Feedback, error) {
+		var f domain.Feedback
+		err := row.Scan(&f.ID, &f.SessionID, &f.Question, &f.Rating, &f.Comment, &f.Variants, &f.CreatedAt, &f.Principal)
+		return f, err
+	})
+}
+
+func (r *FeedbackRepository) Purge(ctx context.Context, before time.Time, scope domain.RetentionScope) (int, error) {
+	query, args := `DELETE FROM feedback WHERE created_at < $1`, []any{before}
+	switch {
+	case scope.Tenant != "":
+		query, args = query+` AND principal_id = $2`, append(args, scope.Tenant)
+	case len(scope.Except) > 0:
+		query, args = query+` AND NOT principal_id = ANY($2)`, append(args, scope.Except)
+	}
+	tag, err := r.q.Exec(ctx, query, args...)
+	return int(tag.RowsAffected()), err
+}
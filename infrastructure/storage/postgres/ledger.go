@@ -0,0 +1,49 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// IngestLedger keeps the sync state: which ingest events were applied, and
+// the newest change applied to each document.
+type IngestLedger struct {
+	q querier
+}
+
+func NewIngestLedger(db *DB) *IngestLedger {
+	return &IngestLedger{q: db.pool}
+}
+
+func (l *IngestLedger) Applied(ctx context.Context, eventID string) (bool, error) {
+	var applied bool
+	err := l.q.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM ingest_events WHERE id = $1)`, eventID).Scan(&applied)
+	return applied, err
+}
+
+func (l *IngestLedger) Watermark(ctx context.Context, documentID string) (time.Time, error) {
+	var at time.Time
+	err := l.q.QueryRow(ctx, `SELECT received_at FROM ingest_watermarks WHERE document_id = $1`, documentID).Scan(&at)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return at, err
+}
+
+func (l *IngestLedger) MarkApplied(ctx context.Context, event domain.IngestEvent) error {
+	return pgx.BeginFunc(ctx, l.q, func(tx pgx.Tx) error {
+		_, err := tx.Exec(ctx, `INSERT INTO ingest_events (id, document_id) VALUES ($1, $2) ON CONFLICT (id) DO NOTHING`,
+			event.ID, event.DocumentID)
+		if err != nil {
+			return err
+		}
+		_, err = tx.Exec(ctx, `INSERT INTO ingest_watermarks (document_id, received_at) VALUES ($1, $2)
+			ON CONFLICT (document_id) DO UPDATE SET received_at = GREATEST(ingest_watermarks.received_at, EXCLUDED.received_at)`,
+			event.DocumentID, event.ReceivedAt)
+		return err
+	})
+}
@@ -0,0 +1,69 @@
+CREATE TABLE api_keys (
+    id         text PRIMARY KEY,
+    name       text NOT NULL,
+    prefix     text NOT NULL DEFAULT '',
+    hash       text NOT NULL UNIQUE,
+    scopes     text[] NOT NULL DEFAULT '{}',
+    created_at timestamptz NOT NULL
+);
+
+CREATE TABLE feedback (
+    id         text PRIMARY KEY,
+    session_id text NOT NULL,
+    question   text NOT NULL DEFAULT '',
+    rating     integer NOT NULL,
+    comment    text NOT NULL DEFAULT '',
+    variants   jsonb,
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX feedback_created_at ON feedback (created_at);
+
+-- Sync state: the ingest events already applied and, per document, the
+-- receive time of the latest one.
+CREATE TABLE ingest_events (
+    id          text PRIMARY KEY,
+    document_id text NOT NULL,
+    applied_at  timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE TABLE ingest_watermarks (
+    document_id text PRIMARY KEY,
+    received_at timestamptz NOT NULL
+);
+
+CREATE TABLE conversation_messages (
+    id         bigserial PRIMARY KEY,
+    session_id text NOT NULL,
+    role       text NOT NULL,
+    content    text NOT NULL,
+    created_at timestamptz NOT NULL DEFAULT now()
+);
+
+CREATE INDEX conversation_messages_session ON conversation_messages (session_id, id);
+
+CREATE TABLE documents (
+    id         text PRIMARY KEY,
+    space_key  text NOT NULL DEFAULT '',
+    title      text NOT NULL DEFAULT '',
+    url        text NOT NULL DEFAULT '',
+    updated_at timestamptz NOT NULL
+);
+
+-- Embeddings are plain arrays, searched by a scan, so no extension is needed.
+CREATE TABLE chunks (
+    id             text PRIMARY KEY,
+    document_id    text NOT NULL REFERENCES documents (id) ON DELETE CASCADE,
+    space_key      text NOT NULL DEFAULT '',
+    title          text NOT NULL DEFAULT '',
+    url            text NOT NULL DEFAULT '',
+    chunk_index    integer NOT NULL,
+    text           text NOT NULL,
+    readers        text[] NOT NULL DEFAULT '{}',
+    embedding      real[] NOT NULL,
+    embedding_norm double precision NOT NULL,
+    updated_at     timestamptz NOT NULL
+);
+
+CREATE INDEX chunks_document ON chunks (document_id, chunk_index);
+CREATE INDEX chunks_space ON chunks (space_key);
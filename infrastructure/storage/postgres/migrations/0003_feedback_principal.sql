@@ -0,0 +1,3 @@
+-- Feedback left before this migration has no principal and is only purged by
+-- the default retention.
+ALTER TABLE feedback ADD COLUMN principal_id text NOT NULL DEFAULT '';
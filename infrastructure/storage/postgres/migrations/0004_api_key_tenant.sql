@@ -0,0 +1,2 @@
+-- Keys created before tenants existed belong to the deployment itself.
+ALTER TABLE api_keys ADD COLUMN tenant text NOT NULL DEFAULT '';
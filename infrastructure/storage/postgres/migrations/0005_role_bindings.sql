@@ -0,0 +1,9 @@
+CREATE TABLE role_bindings (
+    id         text PRIMARY KEY,
+    subject    text NOT NULL,
+    role       text NOT NULL,
+    spaces     text[] NOT NULL DEFAULT '{}',
+    created_at timestamptz NOT NULL
+);
+
+CREATE INDEX role_bindings_subject ON role_bindings (subject);
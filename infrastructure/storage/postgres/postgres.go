@@ -0,0 +1,67 @@
+// Package postgres keeps the service's state in PostgreSQL: API keys,
+// feedback, ingest sync state, conversations, and documents with their
+// chunks and embeddings.
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type Config struct {
+	// URL is a postgres:// connection URL or a key=value DSN.
+	URL      string
+	MaxConns int
+	Timeout  time.Duration
+}
+
+// DB is a connection pool shared by the repositories.
+type DB struct {
+	pool *pgxpool.Pool
+}
+
+// Open connects to the database; Migrate brings its schema up to date.
+func Open(ctx context.Context, cfg Config) (*DB, error) {
+	poolCfg, err := pgxpool.ParseConfig(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	if cfg.MaxConns > 0 {
+		poolCfg.MaxConns = int32(cfg.MaxConns)
+	}
+	if cfg.Timeout > 0 {
+		poolCfg.ConnConfig.ConnectTimeout = cfg.Timeout
+	}
+	pool, err := pgxpool.NewWithConfig(ctx, poolCfg)
+	if err != nil {
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	db := &DB{pool: pool}
+	if err := db.Ping(ctx); err != nil {
+		pool.Close()
+		return nil, fmt.Errorf("postgres: %w", err)
+	}
+	return db, nil
+}
+
+func (db *DB) Ping(ctx context.Context) error {
+	return db.pool.Ping(ctx)
+}
+
+func (db *DB) Close() {
+	db.pool.Close()
+}
+
+// querier runs statements on the pool, or inside a transaction.
+type querier interface {
+	Begin(ctx context.Context) (pgx.Tx, error)
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+	SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults
+}
@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type RoleBindingRepository struct {
+	q querier
+}
+
+func NewRoleBindingRepository(db *DB) *RoleBindingRepository {
+	return &RoleBindingRepository{q: db.pool}
+}
+
+const roleBindingColumns = "id, subject, role, spaces, created_at"
+
+func (r *RoleBindingRepository) SaveRoleBinding(ctx context.Context, b domain.RoleBinding) error {
+	spaces := b.Spaces
+	if spaces == nil {
+		spaces = []string{}
+	}
+	_, err := r.q.Exec(ctx, `INSERT INTO role_bindings (`+roleBindingColumns+`) VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (id) DO UPDATE SET subject = $2, role = $3, spaces = $4, created_at = $5`,
+		b.ID, b.Subject, string(b.Role), spaces, b.CreatedAt)
+	return err
+}
+
+func (r *RoleBindingRepository) ListRoleBindings(ctx context.Context) ([]domain.RoleBinding, error) {
+	rows, _ := r.q.Query(ctx, `SELECT `+roleBindingColumns+` FROM role_bindings ORDER BY created_at`)
+	return pgx.CollectRows(rows, scanRoleBinding)
+}
+
+func (r *RoleBindingRepository) RoleBindingsFor(ctx context.Context, subject string) ([]domain.RoleBinding, error) {
+	rows, _ := r.q.Query(ctx, `SELECT `+roleBindingColumns+` FROM role_bindings WHERE subject = $1 ORDER BY created_at`, subject)
+	return pgx.CollectRows(rows, scanRoleBinding)
+}
+
+func (r *RoleBindingRepository) DeleteRoleBinding(ctx context.Context, id string) error {
+	tag, err := r.q.Exec(ctx, `DELETE FROM role_bindings WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return domain.ErrRoleBindingNotFound
+	}
+	return nil
+}
+
+func scanRoleBinding(row pgx.CollectableRow) (domain.RoleBinding, error) {
+	var b domain.RoleBinding
+	var role string
+	err := row.Scan(&b.ID, &b.Subject, &role, &b.Spaces, &b.CreatedAt)
+	b.Role = domain.AccessRole(role)
+	if len(b.Spaces) == 0 {
+		b.Spaces = nil
+	}
+	return b, err
+}
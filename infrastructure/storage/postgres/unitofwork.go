@@ -0,0 +1,22 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// Do runs fn with repositories bound to one transaction, committed when fn
+// returns nil and rolled back otherwise.
+func (db *DB) Do(ctx context.Context, fn func(ctx context.Context, stores domain.Stores) error) error {
+	return pgx.BeginFunc(ctx, db.pool, func(tx pgx.Tx) error {
+		return fn(ctx, domain.Stores{
+			Chunks:        &VectorStore{db: db, q: tx},
+			Ledger:        &IngestLedger{q: tx},
+			APIKeys:       &APIKeyRepository{q: tx},
+			Feedback:      &FeedbackRepository{q: tx},
+			Conversations: &ConversationRepository{q: tx},
+		})
+	})
+}
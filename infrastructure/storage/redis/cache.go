@@ -0,0 +1,34 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// Cache keeps entries under cache: keys that Redis expires, and evicts under
+// its maxmemory policy.
+type Cache struct {
+	c *Client
+}
+
+func NewCache(c *Client) *Cache {
+	return &Cache{c: c}
+}
+
+func (c *Cache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, err := c.c.rdb.Get(ctx, c.c.key("cache:", key)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	return value, true, nil
+}
+
+func (c *Cache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	return c.c.rdb.Set(ctx, c.c.key("cache:", key), value, ttl).Err()
+}
@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+// Locker takes locks as lock: keys holding a token of the holder, which
+// renews the key's expiry while it holds the lock.
+type Locker struct {
+	c *Client
+}
+
+func NewLocker(c *Client) *Locker {
+	return &Locker{c: c}
+}
+
+// The scripts only touch the key while it still holds the caller's token, so
+// a holder whose lock expired can't extend or release the next holder's.
+var (
+	renewLock = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0`)
+	releaseLock = goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0`)
+)
+
+func (l *Locker) TryLock(ctx context.Context, name string, ttl time.Duration) (func(), error) {
+	key, token := l.c.key("lock:", name), id.New()
+	ok, err := l.c.rdb.SetNX(ctx, key, token, ttl).Result()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, domain.ErrLocked
+	}
+
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(ttl / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				renewed, err := renewLock.Run(context.Background(), l.c.rdb, []string{key}, token, ttl.Milliseconds()).Int()
+				if err != nil {
+					log.Printf("Renewing lock %s failed: %v\n", name, err)
+				} else if renewed == 0 {
+					log.Printf("Lock %s expired while held\n", name)
+					return
+				}
+			}
+		}
+	}()
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(done)
+			if err := releaseLock.Run(context.Background(), l.c.rdb, []string{key}, token).Err(); err != nil {
+				log.Printf("Releasing lock %s failed: %v\n", name, err)
+			}
+		})
+	}, nil
+}
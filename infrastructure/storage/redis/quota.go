@@ -0,0 +1,40 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// QuotaCounters keeps tenant quota counts as quota: keys.
+type QuotaCounters struct {
+	c *Client
+}
+
+func NewQuotaCounters(c *Client) *QuotaCounters {
+	return &QuotaCounters{c: c}
+}
+
+func (q *QuotaCounters) Incr(ctx context.Context, key string, ttl time.Duration) (int64, error) {
+	k := q.c.key("quota:", key)
+	var incr *goredis.IntCmd
+	_, err := q.c.rdb.TxPipelined(ctx, func(p goredis.Pipeliner) error {
+		incr = p.Incr(ctx, k)
+		p.ExpireNX(ctx, k, ttl)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+func (q *QuotaCounters) Count(ctx context.Context, key string) (int64, error) {
+	n, err := q.c.rdb.Get(ctx, q.c.key("quota:", key)).Int64()
+	if errors.Is(err, goredis.Nil) {
+		return 0, nil
+	}
+	return n, err
+}
@@ -0,0 +1,46 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// RateLimitCounters keeps token buckets as ratelimit: hashes, refilled by
+// Redis's clock so instances with skewed clocks agree.
+type RateLimitCounters struct {
+	c *Client
+}
+
+func NewRateLimitCounters(c *Client) *RateLimitCounters {
+	return &RateLimitCounters{c: c}
+}
+
+// takeToken refills the bucket at ARGV[1] tokens per second up to ARGV[2],
+// takes a token if there is one and keeps the bucket for ARGV[3] ms. It
+// returns whether a token was taken and otherwise the ms until one is free.
+var takeToken = goredis.NewScript(`
+local rate, burst, idle = tonumber(ARGV[1]), tonumber(ARGV[2]), tonumber(ARGV[3])
+local t = redis.call("TIME")
+local now = t[1] * 1000 + math.floor(t[2] / 1000)
+local b = redis.call("HMGET", KEYS[1], "tokens", "last")
+local tokens, last = tonumber(b[1]) or burst, tonumber(b[2]) or now
+tokens = math.min(burst, tokens + math.max(0, now - last) / 1000 * rate)
+local ok, wait = 0, 0
+if tokens >= 1 then
+	tokens, ok = tokens - 1, 1
+else
+	wait = math.ceil((1 - tokens) / rate * 1000)
+end
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "last", now)
+redis.call("PEXPIRE", KEYS[1], idle)
+return {ok, wait}`)
+
+func (r *RateLimitCounters) Take(ctx context.Context, key string, rate float64, burst int, idle time.Duration) (time.Duration, bool, error) {
+	res, err := takeToken.Run(ctx, r.c.rdb, []string{r.c.key("ratelimit:", key)}, rate, burst, idle.Milliseconds()).Int64Slice()
+	if err != nil {
+		return 0, false, err
+	}
+	return time.Duration(res[1]) * time.Millisecond, res[0] == 1, nil
+}
@@ -0,0 +1,57 @@
+// Package redis shares short-lived state between instances through Redis:
+// caches, rate-limit counters and locks.
+package redis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+type Config struct {
+	// URL is a redis:// or rediss:// URL; empty means Redis isn't used.
+	URL string
+	// Prefix starts every key, so instances of several deployments can share
+	// one Redis.
+	Prefix  string
+	Timeout time.Duration
+}
+
+// Client is a connection pool shared by the caches, counters and locks.
+type Client struct {
+	rdb    *goredis.Client
+	prefix string
+}
+
+func Open(ctx context.Context, cfg Config) (*Client, error) {
+	opts, err := goredis.ParseURL(cfg.URL)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	if cfg.Timeout > 0 {
+		opts.DialTimeout = cfg.Timeout
+		opts.ReadTimeout = cfg.Timeout
+		opts.WriteTimeout = cfg.Timeout
+	}
+	c := &Client{rdb: goredis.NewClient(opts), prefix: cfg.Prefix}
+	if err := c.Ping(ctx); err != nil {
+		c.Close()
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	return c, nil
+}
+
+func (c *Client) Ping(ctx context.Context) error {
+	return c.rdb.Ping(ctx).Err()
+}
+
+func (c *Client) Close() {
+	c.rdb.Close()
+}
+
+// key is the Redis key of name among keys of kind, e.g. cache:.
+func (c *Client) key(kind, name string) string {
+	return c.prefix + kind + name
+}
@@ -0,0 +1,108 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type APIKeyRepository struct {
+	q querier
+}
+
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{q: db.db}
+}
+
+const apiKeyColumns = "id, name, prefix, hash, scopes, created_at, tenant"
+
+func (r *APIKeyRepository) SaveAPIKey(ctx context.Context, key domain.APIKey) error {
+	scopes, err := json.Marshal(scopeNames(key.Scopes))
+	if err != nil {
+		return err
+	}
+	_, err = r.q.ExecContext(ctx, `INSERT INTO api_keys (`+apiKeyColumns+`) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7)
+		ON CONFLICT (id) DO UPDATE SET name = ?2, prefix = ?3, hash = ?4, scopes = ?5, created_at = ?6, tenant = ?7`,
+		key.ID, key.Name, key.Prefix, key.Hash, string(scopes), unixNano(key.CreatedAt), key.Tenant)
+	return err
+}
+
+func (r *APIKeyRepository) FindAPIKeyByHash(ctx context.Context, hash string) (*domain.APIKey, error) {
+	key, err := scanAPIKey(r.q.QueryRowContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys WHERE hash = ?`, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *APIKeyRepository) ListAPIKeys(ctx context.Context) ([]domain.APIKey, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT `+apiKeyColumns+` FROM api_keys ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanAPIKey)
+}
+
+func (r *APIKeyRepository) DeleteAPIKey(ctx context.Context, id string) error {
+	res, err := r.q.ExecContext(ctx, `DELETE FROM api_keys WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+func scanAPIKey(row scanner) (domain.APIKey, error) {
+	var key domain.APIKey
+	var scopes string
+	var createdAt int64
+	if err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, &scopes, &createdAt, &key.Tenant); err != nil {
+		return key, err
+	}
+	key.CreatedAt = fromUnixNano(createdAt)
+	var names []string
+	if err := json.Unmarshal([]byte(scopes), &names); err != nil {
+		return key, err
+	}
+	for _, s := range names {
+		key.Scopes = append(key.Scopes, domain.Scope(s))
+	}
+	return key, nil
+}
+
+func scopeNames(scopes []domain.Scope) []string {
+	names := make([]string, len(scopes))
+	for i, s := range scopes {
+		names[i] = string(s)
+	}
+	return names
+}
+
+// scanner is a *sql.Row or *sql.Rows.
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// collect scans every row with scan and closes rows.
+func collect[T any](rows *sql.Rows, scan func(scanner) (T, error)) ([]T, error) {
+	defer rows.Close()
+	var items []T
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, rows.Err()
+}
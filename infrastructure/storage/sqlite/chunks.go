@@ -0,0 +1,225 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/vectorstore"
+)
+
+// VectorStore keeps documents and their chunks. Search reads every chunk that
+// passes the filter and scores it by cosine similarity in the process, like
+// the in-memory store does, which suits the collections a single node holds.
+type VectorStore struct {
+	db *DB
+	q  querier
+}
+
+func NewVectorStore(db *DB) *VectorStore {
+	return &VectorStore{db: db, q: db.db}
+}
+
+const chunkColumns = "id, document_id, space_key, title, url, chunk_index, text, readers, embedding, updated_at, labels"
+
+func (s *VectorStore) Upsert(ctx context.Context, chunks []domain.Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	return withTx(ctx, s.q, func(tx querier) error {
+		seen := make(map[string]bool)
+		for _, c := range chunks {
+			if !seen[c.DocumentID] {
+				seen[c.DocumentID] = true
+				_, err := tx.ExecContext(ctx, `INSERT INTO documents (id, space_key, title, url, updated_at) VALUES (?1, ?2, ?3, ?4, ?5)
+					ON CONFLICT (id) DO UPDATE SET space_key = ?2, title = ?3, url = ?4, updated_at = ?5`,
+					c.DocumentID, c.SpaceKey, c.Title, c.URL, unixNano(c.UpdatedAt))
+				if err != nil {
+					return err
+				}
+			}
+			readers, err := json.Marshal(nonNil(c.Readers))
+			if err != nil {
+				return err
+			}
+			labels, err := json.Marshal(nonNil(c.Labels))
+			if err != nil {
+				return err
+			}
+			_, err = tx.ExecContext(ctx, `INSERT INTO chunks (`+chunkColumns+`) VALUES (?1, ?2, ?3, ?4, ?5, ?6, ?7, ?8, ?9, ?10, ?11)
+				ON CONFLICT (id) DO UPDATE SET document_id = ?2, space_key = ?3, title = ?4, url = ?5, chunk_index = ?6,
+					text = ?7, readers = ?8, embedding = ?9, updated_at = ?10, labels = ?11`,
+				c.ID, c.DocumentID, c.SpaceKey, c.Title, c.URL, c.Index, c.Text, string(readers), encodeEmbedding(c.Embedding), unixNano(c.UpdatedAt), string(labels))
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (s *VectorStore) Search(ctx context.Context, vector []float32, topK int, filter domain.SearchFilter) (_ []domain.ScoredChunk, err error) {
+	start := time.Now()
+	defer func() { vectorstore.ObserveSearch("sqlite", start, err) }()
+	where, args := searchFilter(filter)
+	rows, err := s.q.QueryContext(ctx, `SELECT `+chunkColumns+` FROM chunks`+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	chunks, err := collect(rows, scanChunk)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]domain.ScoredChunk, 0, len(chunks))
+	for _, c := range chunks {
+		// Unrestricted chunks have no readers.
+		if len(filter.Readers) > 0 && len(c.Readers) > 0 && !slices.ContainsFunc(filter.Readers, func(r string) bool { return slices.Contains(c.Readers, r) }) {
+			continue
+		}
+		results = append(results, domain.ScoredChunk{Chunk: c, Score: cosine(vector, c.Embedding)})
+	}
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// searchFilter renders the space and document conditions of filter as a
+// WHERE clause; readers are matched after reading, since they're JSON.
+func searchFilter(filter domain.SearchFilter) (string, []any) {
+	var conds []string
+	var args []any
+	in := func(column string, values []string, not bool) {
+		op := " IN ("
+		if not {
+			op = " NOT IN ("
+		}
+		conds = append(conds, column+op+strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")+")")
+		for _, v := range values {
+			args = append(args, v)
+		}
+	}
+	if len(filter.SpaceKeys) > 0 {
+		in("space_key", filter.SpaceKeys, false)
+	}
+	if len(filter.DocumentIDs) > 0 {
+		in("document_id", filter.DocumentIDs, false)
+	}
+	if len(filter.ExcludeDocumentIDs) > 0 {
+		in("document_id", filter.ExcludeDocumentIDs, true)
+	}
+	if len(conds) == 0 {
+		return "", nil
+	}
+	return " WHERE " + strings.Join(conds, " AND "), args
+}
+
+func (s *VectorStore) DeleteDocument(ctx context.Context, documentID string) error {
+	// Chunks go with the document.
+	_, err := s.q.ExecContext(ctx, `DELETE FROM documents WHERE id = ?`, documentID)
+	return err
+}
+
+func (s *VectorStore) DocumentChunks(ctx context.Context, documentID string) ([]domain.Chunk, error) {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+chunkColumns+` FROM chunks WHERE document_id = ? ORDER BY chunk_index`, documentID)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanChunk)
+}
+
+func (s *VectorStore) ScanChunks(ctx context.Context, fn func(domain.Chunk) error) error {
+	rows, err := s.q.QueryContext(ctx, `SELECT `+chunkColumns+` FROM chunks ORDER BY document_id, chunk_index`)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		c, err := scanChunk(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+func (s *VectorStore) Ping(ctx context.Context) error {
+	return s.db.Ping(ctx)
+}
+
+func scanChunk(row scanner) (domain.Chunk, error) {
+	var c domain.Chunk
+	var readers, labels string
+	var embedding []byte
+	var updatedAt int64
+	err := row.Scan(&c.ID, &c.DocumentID, &c.SpaceKey, &c.Title, &c.URL, &c.Index, &c.Text, &readers, &embedding, &updatedAt, &labels)
+	if err != nil {
+		return c, err
+	}
+	c.Embedding = decodeEmbedding(embedding)
+	c.UpdatedAt = fromUnixNano(updatedAt)
+	if err := json.Unmarshal([]byte(readers), &c.Readers); err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal([]byte(labels), &c.Labels); err != nil {
+		return c, err
+	}
+	if len(c.Readers) == 0 {
+		c.Readers = nil
+	}
+	if len(c.Labels) == 0 {
+		c.Labels = nil
+	}
+	return c, nil
+}
+
+func encodeEmbedding(v []float32) []byte {
+	b := make([]byte, 4*len(v))
+	for i, x := range v {
+		binary.LittleEndian.PutUint32(b[4*i:], math.Float32bits(x))
+	}
+	return b
+}
+
+func decodeEmbedding(b []byte) []float32 {
+	v := make([]float32, len(b)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(b[4*i:]))
+	}
+	return v
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// nonNil keeps an unrestricted chunk's readers, or an unlabelled one's labels,
+// an empty array rather than null.
+func nonNil(r []string) []string {
+	if r == nil {
+		return []string{}
+	}
+	return r
+}
@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type ConversationRepository struct {
+	q querier
+}
+
+func NewConversationRepository(db *DB) *ConversationRepository {
+	return &ConversationRepository{q: db.db}
+}
+
+func (r *ConversationRepository) AppendMessages(ctx context.Context, sessionID string, messages ...domain.Message) error {
+	now := time.Now().UnixNano()
+	return withTx(ctx, r.q, func(tx querier) error {
+		for _, m := range messages {
+			_, err := tx.ExecContext(ctx, `INSERT INTO conversation_messages (session_id, role, content, created_at) VALUES (?, ?, ?, ?)`,
+				sessionID, string(m.Role), m.Content, now)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *ConversationRepository) Messages(ctx context.Context, sessionID string, limit int) ([]domain.Message, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT role, content FROM (
+			SELECT id, role, content FROM conversation_messages WHERE session_id = ? ORDER BY id DESC LIMIT ?
+		) ORDER BY id`, sessionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, func(row scanner) (domain.Message, error) {
+		var m domain.Message
+		var role string
+		err := row.Scan(&role, &m.Content)
+		m.Role = domain.Role(role)
+		return m, err
+	})
+}
+
+// PurgeMessages matches a tenant's sessions by the principal ID their keys
+// start with.
+func (r *ConversationRepository) PurgeMessages(ctx context.Context, before time.Time, scope domain.RetentionScope) (int, error) {
+	query, args := `DELETE FROM conversation_messages WHERE created_at < ?`, []any{unixNano(before)}
+	if scope.Tenant != "" {
+		query, args = query+` AND substr(session_id, 1, length(?2)) = ?2`, append(args, scope.Tenant+"/")
+	} else {
+		for _, tenant := range scope.Except {
+			args = append(args, tenant+"/")
+			query += fmt.Sprintf(` AND NOT substr(session_id, 1, length(?%d)) = ?%d`, len(args), len(args))
+		}
+	}
+	return rowsAffected(r.q.ExecContext(ctx, query, args...))
+}
@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type FeedbackRepository struct {
+	q querier
+}
+
+func NewFeedbackRepository(db *DB) *FeedbackRepository {
+	return &FeedbackRepository{q: db.db}
+}
+
+func (r *FeedbackRepository) Save(ctx context.Context, f domain.Feedback) error {
+	var variants sql.NullString
+	if f.Variants != nil {
+		b, err := json.Marshal(f.Variants)
+		if err != nil {
+			return err
+		}
+		variants = sql.NullString{String: string(b), Valid: true}
+	}
+	_, err := r.q.ExecContext(ctx, `INSERT INTO feedback (id, session_id, question, rating, comment, variants, created_at, principal_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		f.ID, f.SessionID, f.Question, f.Rating, f.Comment, variants, unixNano(f.CreatedAt), f.Principal)
+	return err
+}
+
+func (r *FeedbackRepository) List(ctx context.Context) ([]domain.Feedback, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT id, session_id, question, rating, comment, variants, created_at, principal_id
+		FROM feedback ORDER BY created_at, id`)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, func(row scanner) (domain.Feedback, error) {
+		var f domain.Feedback
+		var variants sql.NullString
+		var createdAt int64
+		if err := row.Scan(&f.ID, &f.SessionID, &f.Question, &f.Rating, &f.Comment, &variants, &createdAt, &f.Principal); err != nil {
+			return f, err
+		}
+		f.CreatedAt = fromUnixNano(createdAt)
+		if variants.Valid {
+			if err := json.Unmarshal([]byte(variants.String), &f.Variants); err != nil {
+				return f, err
+			}
+		}
+		return f, nil
+	})
+}
+
+func (r *FeedbackRepository) Purge(ctx context.Context, before time.Time, scope domain.RetentionScope) (int, error) {
+	query, args := `DELETE FROM feedback WHERE created_at < ?`, []any{unixNano(before)}
+	switch {
+	case scope.Tenant != "":
+		query, args = query+` AND principal_id = ?`, append(args, scope.Tenant)
+	case len(scope.Except) > 0:
+		query += ` AND principal_id NOT IN (` + strings.TrimSuffix(strings.Repeat("?, ", len(scope.Except)), ", ") + `)`
+		for _, tenant := range scope.Except {
+			args = append(args, tenant)
+		}
+	}
+	return rowsAffected(r.q.ExecContext(ctx, query, args...))
+}
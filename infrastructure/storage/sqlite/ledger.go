@@ -0,0 +1,49 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// IngestLedger keeps the sync state: which ingest events were applied, and
+// the newest change applied to each document.
+type IngestLedger struct {
+	q querier
+}
+
+func NewIngestLedger(db *DB) *IngestLedger {
+	return &IngestLedger{q: db.db}
+}
+
+func (l *IngestLedger) Applied(ctx context.Context, eventID string) (bool, error) {
+	var applied bool
+	err := l.q.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM ingest_events WHERE id = ?)`, eventID).Scan(&applied)
+	return applied, err
+}
+
+func (l *IngestLedger) Watermark(ctx context.Context, documentID string) (time.Time, error) {
+	var at int64
+	err := l.q.QueryRowContext(ctx, `SELECT received_at FROM ingest_watermarks WHERE document_id = ?`, documentID).Scan(&at)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, nil
+	}
+	return fromUnixNano(at), err
+}
+
+func (l *IngestLedger) MarkApplied(ctx context.Context, event domain.IngestEvent) error {
+	return withTx(ctx, l.q, func(tx querier) error {
+		_, err := tx.ExecContext(ctx, `INSERT INTO ingest_events (id, document_id, applied_at) VALUES (?, ?, ?) ON CONFLICT (id) DO NOTHING`,
+			event.ID, event.DocumentID, time.Now().UnixNano())
+		if err != nil {
+			return err
+		}
+		_, err = tx.ExecContext(ctx, `INSERT INTO ingest_watermarks (document_id, received_at) VALUES (?, ?)
+			ON CONFLICT (document_id) DO UPDATE SET received_at = max(received_at, excluded.received_at)`,
+			event.DocumentID, unixNano(event.ReceivedAt))
+		return err
+	})
+}
@@ -0,0 +1,63 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migrate applies the embedded migrations the database hasn't seen, in file
+// name order, each in its own transaction, and returns their names.
+func (db *DB) Migrate(ctx context.Context) ([]string, error) {
+	_, err := db.db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    TEXT PRIMARY KEY,
+		applied_at INTEGER NOT NULL
+	)`)
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: migrate: %w", err)
+	}
+
+	names, err := fs.Glob(migrations, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+	slices.Sort(names)
+	var ran []string
+	for _, name := range names {
+		version := strings.TrimSuffix(strings.TrimPrefix(name, "migrations/"), ".sql")
+		script, err := migrations.ReadFile(name)
+		if err != nil {
+			return ran, err
+		}
+		applied := false
+		err = inTx(ctx, db.db, func(tx *sql.Tx) error {
+			// Checked under the write lock, in case another process is
+			// migrating the same file.
+			var n int
+			if err := tx.QueryRowContext(ctx, `SELECT count(*) FROM schema_migrations WHERE version = ?`, version).Scan(&n); err != nil || n > 0 {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, string(script)); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`, version, time.Now().UnixNano())
+			applied = err == nil
+			return err
+		})
+		if err != nil {
+			return ran, fmt.Errorf("sqlite: migration %s: %w", version, err)
+		}
+		if applied {
+			ran = append(ran, version)
+		}
+	}
+	return ran, nil
+}
@@ -0,0 +1,69 @@
+-- Times are Unix nanoseconds and lists are JSON arrays.
+CREATE TABLE api_keys (
+    id         TEXT PRIMARY KEY,
+    name       TEXT NOT NULL,
+    prefix     TEXT NOT NULL DEFAULT '',
+    hash       TEXT NOT NULL UNIQUE,
+    scopes     TEXT NOT NULL DEFAULT '[]',
+    created_at INTEGER NOT NULL
+);
+
+CREATE TABLE feedback (
+    id         TEXT PRIMARY KEY,
+    session_id TEXT NOT NULL,
+    question   TEXT NOT NULL DEFAULT '',
+    rating     INTEGER NOT NULL,
+    comment    TEXT NOT NULL DEFAULT '',
+    variants   TEXT,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX feedback_created_at ON feedback (created_at);
+
+-- Sync state: the ingest events already applied and, per document, the
+-- receive time of the latest one.
+CREATE TABLE ingest_events (
+    id          TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL,
+    applied_at  INTEGER NOT NULL
+);
+
+CREATE TABLE ingest_watermarks (
+    document_id TEXT PRIMARY KEY,
+    received_at INTEGER NOT NULL
+);
+
+CREATE TABLE conversation_messages (
+    id         INTEGER PRIMARY KEY AUTOINCREMENT,
+    session_id TEXT NOT NULL,
+    role       TEXT NOT NULL,
+    content    TEXT NOT NULL,
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX conversation_messages_session ON conversation_messages (session_id, id);
+
+CREATE TABLE documents (
+    id         TEXT PRIMARY KEY,
+    space_key  TEXT NOT NULL DEFAULT '',
+    title      TEXT NOT NULL DEFAULT '',
+    url        TEXT NOT NULL DEFAULT '',
+    updated_at INTEGER NOT NULL
+);
+
+-- Embeddings are little-endian float32 blobs, scored by the process.
+CREATE TABLE chunks (
+    id          TEXT PRIMARY KEY,
+    document_id TEXT NOT NULL REFERENCES documents (id) ON DELETE CASCADE,
+    space_key   TEXT NOT NULL DEFAULT '',
+    title       TEXT NOT NULL DEFAULT '',
+    url         TEXT NOT NULL DEFAULT '',
+    chunk_index INTEGER NOT NULL,
+    text        TEXT NOT NULL,
+    readers     TEXT NOT NULL DEFAULT '[]',
+    embedding   BLOB NOT NULL,
+    updated_at  INTEGER NOT NULL
+);
+
+CREATE INDEX chunks_document ON chunks (document_id, chunk_index);
+CREATE INDEX chunks_space ON chunks (space_key);
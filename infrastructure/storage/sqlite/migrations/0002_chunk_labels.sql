@@ -0,0 +1,2 @@
+-- Chunks indexed before this migration have no labels until they're reindexed.
+ALTER TABLE chunks ADD COLUMN labels TEXT NOT NULL DEFAULT '[]';
@@ -0,0 +1,9 @@
+CREATE TABLE role_bindings (
+    id         TEXT PRIMARY KEY,
+    subject    TEXT NOT NULL,
+    role       TEXT NOT NULL,
+    spaces     TEXT NOT NULL DEFAULT '[]',
+    created_at INTEGER NOT NULL
+);
+
+CREATE INDEX role_bindings_subject ON role_bindings (subject);
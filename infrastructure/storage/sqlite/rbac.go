@@ -0,0 +1,80 @@
+package sqlite
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type RoleBindingRepository struct {
+	q querier
+}
+
+func NewRoleBindingRepository(db *DB) *RoleBindingRepository {
+	return &RoleBindingRepository{q: db.db}
+}
+
+const roleBindingColumns = "id, subject, role, spaces, created_at"
+
+func (r *RoleBindingRepository) SaveRoleBinding(ctx context.Context, b domain.RoleBinding) error {
+	spaces := b.Spaces
+	if spaces == nil {
+		spaces = []string{}
+	}
+	encoded, err := json.Marshal(spaces)
+	if err != nil {
+		return err
+	}
+	_, err = r.q.ExecContext(ctx, `INSERT INTO role_bindings (`+roleBindingColumns+`) VALUES (?1, ?2, ?3, ?4, ?5)
+		ON CONFLICT (id) DO UPDATE SET subject = ?2, role = ?3, spaces = ?4, created_at = ?5`,
+		b.ID, b.Subject, string(b.Role), string(encoded), unixNano(b.CreatedAt))
+	return err
+}
+
+func (r *RoleBindingRepository) ListRoleBindings(ctx context.Context) ([]domain.RoleBinding, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT `+roleBindingColumns+` FROM role_bindings ORDER BY created_at`)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanRoleBinding)
+}
+
+func (r *RoleBindingRepository) RoleBindingsFor(ctx context.Context, subject string) ([]domain.RoleBinding, error) {
+	rows, err := r.q.QueryContext(ctx, `SELECT `+roleBindingColumns+` FROM role_bindings WHERE subject = ? ORDER BY created_at`, subject)
+	if err != nil {
+		return nil, err
+	}
+	return collect(rows, scanRoleBinding)
+}
+
+func (r *RoleBindingRepository) DeleteRoleBinding(ctx context.Context, id string) error {
+	res, err := r.q.ExecContext(ctx, `DELETE FROM role_bindings WHERE id = ?`, id)
+	if err != nil {
+		return err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return err
+	} else if n == 0 {
+		return domain.ErrRoleBindingNotFound
+	}
+	return nil
+}
+
+func scanRoleBinding(row scanner) (domain.RoleBinding, error) {
+	var b domain.RoleBinding
+	var role, spaces string
+	var createdAt int64
+	if err := row.Scan(&b.ID, &b.Subject, &role, &spaces, &createdAt); err != nil {
+		return b, err
+	}
+	b.Role = domain.AccessRole(role)
+	b.CreatedAt = fromUnixNano(createdAt)
+	if err := json.Unmarshal([]byte(spaces), &b.Spaces); err != nil {
+		return b, err
+	}
+	if len(b.Spaces) == 0 {
+		b.Spaces = nil
+	}
+	return b, nil
+}
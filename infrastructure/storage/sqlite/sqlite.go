@@ -0,0 +1,108 @@
+// Package sqlite keeps the service's state in a single SQLite file, for
+// deployments that run as one process: API keys, feedback, ingest sync
+// state, conversations, and documents with their chunks and embeddings.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/url"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+type Config struct {
+	// Path is the database file, created if missing.
+	Path string
+	// Timeout is how long a statement waits for another writer's lock.
+	Timeout time.Duration
+}
+
+// DB is the database file shared by the repositories.
+type DB struct {
+	db *sql.DB
+}
+
+// Open opens the database file; Migrate brings its schema up to date.
+func Open(ctx context.Context, cfg Config) (*DB, error) {
+	pragmas := url.Values{"_pragma": {
+		"journal_mode(WAL)",
+		"foreign_keys(1)",
+		fmt.Sprintf("busy_timeout(%d)", cfg.Timeout.Milliseconds()),
+	}}
+	// Transactions take the write lock up front, so two of them can't both
+	// read and then fail to upgrade.
+	pragmas.Set("_txlock", "immediate")
+	db, err := sql.Open("sqlite", "file:"+cfg.Path+"?"+pragmas.Encode())
+	if err != nil {
+		return nil, fmt.Errorf("sqlite: %w", err)
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("sqlite: %w", err)
+	}
+	return &DB{db: db}, nil
+}
+
+func (db *DB) Ping(ctx context.Context) error {
+	return db.db.PingContext(ctx)
+}
+
+func (db *DB) Close() {
+	db.db.Close()
+}
+
+// querier runs statements on the database, or inside a transaction.
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// inTx runs fn in a transaction, committing if it returns nil.
+func inTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	if err := fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// withTx runs fn in a transaction on q, or in the one q already is.
+func withTx(ctx context.Context, q querier, fn func(querier) error) error {
+	db, ok := q.(*sql.DB)
+	if !ok {
+		return fn(q)
+	}
+	return inTx(ctx, db, func(tx *sql.Tx) error { return fn(tx) })
+}
+
+// Times are stored as Unix nanoseconds, so they sort and compare in SQL.
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n).UTC()
+}
+
+// rowsAffected returns how many rows the statement behind res changed.
+func rowsAffected(res sql.Result, err error) (int, error) {
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
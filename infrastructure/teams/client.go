@@ -0,0 +1,204 @@
+package teams
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/oidc"
+)
+
+// Bot Framework tokens are issued by this issuer for the bot's app ID.
+const botFrameworkIssuer = "https://api.botframework.com"
+
+// tokenExpiryMargin renews the outbound token before it actually expires.
+const tokenExpiryMargin = 5 * time.Minute
+
+var ErrUnauthorized = errors.New("invalid bot framework token")
+
+type Config struct {
+	AppID       string
+	AppPassword string
+	// TokenURL issues tokens for calling the Bot Connector service.
+	TokenURL string
+	// JWKSURL publishes the keys the Bot Connector signs its requests with.
+	JWKSURL string
+	Timeout time.Duration
+}
+
+func (c Config) Enabled() bool {
+	return c.AppID != "" && c.AppPassword != ""
+}
+
+// Activity is the subset of a Bot Framework activity the bot reads and sends.
+type Activity struct {
+	Type         string              `json:"type"`
+	ID           string              `json:"id,omitempty"`
+	Text         string              `json:"text,omitempty"`
+	TextFormat   string              `json:"textFormat,omitempty"`
+	ServiceURL   string              `json:"serviceUrl,omitempty"`
+	ChannelID    string              `json:"channelId,omitempty"`
+	From         ChannelAccount      `json:"from"`
+	Recipient    ChannelAccount      `json:"recipient"`
+	Conversation ConversationAccount `json:"conversation"`
+	ReplyToID    string              `json:"replyToId,omitempty"`
+}
+
+type ChannelAccount struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+type ConversationAccount struct {
+	ID               string `json:"id"`
+	ConversationType string `json:"conversationType,omitempty"`
+}
+
+// Client authenticates Bot Connector requests to the bot and replies to them
+// with the bot's app credentials.
+type Client struct {
+	cfg        Config
+	verifier   *oidc.Verifier
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+func NewClient(cfg Config) (*Client, error) {
+	verifier, err := oidc.NewVerifier(oidc.Config{
+		Issuer:   botFrameworkIssuer,
+		Audience: cfg.AppID,
+		JWKSURL:  cfg.JWKSURL,
+		CacheTTL: 24 * time.Hour,
+		Leeway:   5 * time.Minute,
+		Timeout:  cfg.Timeout,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cfg: cfg, verifier: verifier, httpClient: &http.Client{Timeout: cfg.Timeout}}, nil
+}
+
+// Verify checks the bearer token of a request from the Bot Connector and that
+// it was issued for the activity's service URL.
+func (c *Client) Verify(ctx context.Context, authorization string, a Activity) error {
+	token, ok := strings.CutPrefix(authorization, "Bearer ")
+	if !ok || token == "" {
+		return ErrUnauthorized
+	}
+	claims, err := c.verifier.VerifyToken(ctx, token)
+	if errors.Is(err, domain.ErrInvalidToken) {
+		return fmt.Errorf("%w: %v", ErrUnauthorized, err)
+	}
+	if err != nil {
+		return err
+	}
+	// The Bot Connector always sets the claim. Without the check, a replayed
+	// token with a forged serviceUrl would have Reply send the bot's own
+	// access token to that URL.
+	serviceURL, _ := claims["serviceurl"].(string)
+	if serviceURL == "" {
+		return fmt.Errorf("%w: token has no serviceurl claim", ErrUnauthorized)
+	}
+	if !strings.EqualFold(serviceURL, a.ServiceURL) {
+		return fmt.Errorf("%w: service url mismatch", ErrUnauthorized)
+	}
+	return nil
+}
+
+// Reply posts a markdown message in reply to a, which in a channel threads it
+// under the message a was sent in.
+func (c *Client) Reply(ctx context.Context, a Activity, text string) error {
+	return c.send(ctx, a, Activity{Type: "message", Text: text, TextFormat: "markdown"})
+}
+
+// Typing shows the bot as typing until its reply arrives.
+func (c *Client) Typing(ctx context.Context, a Activity) error {
+	return c.send(ctx, a, Activity{Type: "typing"})
+}
+
+// Ping checks the app credentials by obtaining a token.
+func (c *Client) Ping(ctx context.Context) error {
+	_, err := c.accessToken(ctx)
+	return err
+}
+
+func (c *Client) send(ctx context.Context, to, reply Activity) error {
+	reply.From, reply.Recipient = to.Recipient, to.From
+	reply.Conversation = to.Conversation
+	reply.ReplyToID = to.ID
+	body, err := json.Marshal(reply)
+	if err != nil {
+		return err
+	}
+	endpoint := strings.TrimRight(to.ServiceURL, "/") + "/v3/conversations/" + url.PathEscape(to.Conversation.ID) + "/activities/" + url.PathEscape(to.ID)
+	token, err := c.accessToken(ctx)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		b, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("bot connector: status %d: %s", resp.StatusCode, strings.TrimSpace(string(b)))
+	}
+	return nil
+}
+
+// accessToken returns a cached client-credentials token for the Bot Connector.
+func (c *Client) accessToken(ctx context.Context) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.token != "" && time.Now().Before(c.expiresAt) {
+		return c.token, nil
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {c.cfg.AppID},
+		"client_secret": {c.cfg.AppPassword},
+		"scope":         {botFrameworkIssuer + "/.default"},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.cfg.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("bot framework token: status %d", resp.StatusCode)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return "", fmt.Errorf("bot framework token: %w", err)
+	}
+	c.token = token.AccessToken
+	c.expiresAt = time.Now().Add(time.Duration(token.ExpiresIn)*time.Second - tokenExpiryMargin)
+	return c.token, nil
+}
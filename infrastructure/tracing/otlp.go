@@ -0,0 +1,305 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const otlpScope = "github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+
+type OTLPConfig struct {
+	// Endpoint is the full OTLP/HTTP traces URL, e.g.
+	// http://collector:4318/v1/traces.
+	Endpoint    string
+	Headers     map[string]string
+	ServiceName string
+	// Environment is sent as the deployment.environment resource attribute.
+	Environment string
+	Timeout     time.Duration
+	// SampleRatio is the share of new traces recorded, from 0 to 1.
+	SampleRatio float64
+	// Spans are exported every FlushInterval or once BatchSize are waiting;
+	// beyond QueueSize waiting they are dropped.
+	FlushInterval time.Duration
+	BatchSize     int
+	QueueSize     int
+}
+
+func (c OTLPConfig) Enabled() bool {
+	return c.Endpoint != ""
+}
+
+// OTLPExporter exports ended spans over OTLP/HTTP with JSON encoding, in
+// batches from a background goroutine.
+type OTLPExporter struct {
+	cfg        OTLPConfig
+	httpClient *http.Client
+	resource   otlpResource
+	queue      chan *Span
+	flush      chan chan error
+	dropped    atomic.Uint64
+
+	closeOnce sync.Once
+	closed    chan struct{}
+	done      chan struct{}
+}
+
+func NewOTLPExporter(cfg OTLPConfig) *OTLPExporter {
+	if cfg.ServiceName == "" {
+		cfg.ServiceName = "sarama"
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 5 * time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 512
+	}
+	if cfg.QueueSize <= 0 {
+		cfg.QueueSize = 2048
+	}
+	resource := otlpResource{Attributes: []otlpAttribute{stringAttribute("service.name", cfg.ServiceName)}}
+	if cfg.Environment != "" {
+		resource.Attributes = append(resource.Attributes, stringAttribute("deployment.environment", cfg.Environment))
+	}
+	x := &OTLPExporter{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+		resource:   resource,
+		queue:      make(chan *Span, cfg.QueueSize),
+		flush:      make(chan chan error),
+		closed:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go x.run()
+	return x
+}
+
+func (x *OTLPExporter) ExportSpan(s *Span) {
+	select {
+	case <-x.closed:
+		x.dropped.Add(1)
+		return
+	default:
+	}
+	select {
+	case x.queue <- s:
+	default:
+		x.dropped.Add(1)
+	}
+}
+
+// Flush exports the queued spans and waits for the export to finish.
+func (x *OTLPExporter) Flush() error {
+	result := make(chan error, 1)
+	select {
+	case x.flush <- result:
+		return <-result
+	case <-x.done:
+		return nil
+	}
+}
+
+// Dropped counts spans not exported because the queue was full or the
+// exporter closed.
+func (x *OTLPExporter) Dropped() uint64 {
+	return x.dropped.Load()
+}
+
+// Close exports the queued spans and stops the background exporter.
+func (x *OTLPExporter) Close() error {
+	x.closeOnce.Do(func() { close(x.closed) })
+	<-x.done
+	return nil
+}
+
+func (x *OTLPExporter) run() {
+	defer close(x.done)
+	ticker := time.NewTicker(x.cfg.FlushInterval)
+	defer ticker.Stop()
+	var batch []*Span
+	export := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		err := x.export(batch)
+		if err != nil {
+			log.Printf("Exporting spans failed: %v\n", err)
+		}
+		batch = batch[:0]
+		return err
+	}
+	drain := func() {
+		for {
+			select {
+			case s := <-x.queue:
+				batch = append(batch, s)
+				if len(batch) >= x.cfg.BatchSize {
+					export()
+				}
+			default:
+				return
+			}
+		}
+	}
+	for {
+		select {
+		case s := <-x.queue:
+			batch = append(batch, s)
+			if len(batch) >= x.cfg.BatchSize {
+				export()
+			}
+		case <-ticker.C:
+			export()
+		case result := <-x.flush:
+			drain()
+			result <- export()
+		case <-x.closed:
+			drain()
+			export()
+			return
+		}
+	}
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+}
+
+type otlpResource struct {
+	Attributes []otlpAttribute `json:"attributes"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+// OTLP status codes.
+const (
+	statusUnset = 0
+	statusError = 2
+)
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+type otlpRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+type otlpResourceSpans struct {
+	Resource   otlpResource     `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpScopeSpans struct {
+	Scope otlpScopeName `json:"scope"`
+	Spans []otlpSpan    `json:"spans"`
+}
+
+type otlpScopeName struct {
+	Name string `json:"name"`
+}
+
+func (x *OTLPExporter) export(batch []*Span) error {
+	spans := make([]otlpSpan, 0, len(batch))
+	for _, s := range batch {
+		spans = append(spans, otlpSpanOf(s))
+	}
+	body, err := json.Marshal(otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource:   x.resource,
+		ScopeSpans: []otlpScopeSpans{{Scope: otlpScopeName{Name: otlpScope}, Spans: spans}},
+	}}})
+	if err != nil {
+		return fmt.Errorf("encode spans: %w", err)
+	}
+	req, err := http.NewRequest(http.MethodPost, x.cfg.Endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range x.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := x.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("otlp traces endpoint returned %s for %d spans", resp.Status, len(batch))
+	}
+	return nil
+}
+
+func otlpSpanOf(s *Span) otlpSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := otlpSpan{
+		TraceID:           s.sc.TraceID.String(),
+		SpanID:            s.sc.SpanID.String(),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Status:            otlpStatus{Code: statusUnset},
+	}
+	if s.parent.IsValid() {
+		out.ParentSpanID = s.parent.String()
+	}
+	for _, a := range s.attrs {
+		out.Attributes = append(out.Attributes, otlpAttribute{Key: a.Key, Value: attributeValue(a.Value)})
+	}
+	if s.err != nil {
+		out.Status = otlpStatus{Code: statusError, Message: s.err.Error()}
+	}
+	return out
+}
+
+func stringAttribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: stringValue(value)}
+}
+
+func stringValue(s string) otlpValue {
+	return otlpValue{StringValue: &s}
+}
+
+func attributeValue(v any) otlpValue {
+	switch v := v.(type) {
+	case bool:
+		return otlpValue{BoolValue: &v}
+	case int:
+		s := strconv.Itoa(v)
+		return otlpValue{IntValue: &s}
+	case string:
+		return stringValue(v)
+	default:
+		return stringValue(fmt.Sprint(v))
+	}
+}
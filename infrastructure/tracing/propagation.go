@@ -0,0 +1,105 @@
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Header is the W3C trace context header, also used as a message header.
+const Header = "traceparent"
+
+// ParseTraceparent parses a header such as
+// 00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01.
+func ParseTraceparent(traceparent string) (SpanContext, bool) {
+	parts := strings.Split(traceparent, "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || len(parts[3]) != 2 || parts[0] == "ff" {
+		return SpanContext{}, false
+	}
+	var sc SpanContext
+	if !decodeHex(sc.TraceID[:], parts[1]) || !decodeHex(sc.SpanID[:], parts[2]) || !sc.Valid() {
+		return SpanContext{}, false
+	}
+	var flags [1]byte
+	if !decodeHex(flags[:], parts[3]) {
+		return SpanContext{}, false
+	}
+	sc.Sampled = flags[0]&1 == 1
+	return sc, true
+}
+
+// decodeHex decodes exactly len(dst) bytes of lowercase hex.
+func decodeHex(dst []byte, s string) bool {
+	if len(s) != 2*len(dst) || strings.ToLower(s) != s {
+		return false
+	}
+	_, err := hex.Decode(dst, []byte(s))
+	return err == nil
+}
+
+func (sc SpanContext) Traceparent() string {
+	flags := 0
+	if sc.Sampled {
+		flags = 1
+	}
+	return fmt.Sprintf("00-%s-%s-%02x", sc.TraceID, sc.SpanID, flags)
+}
+
+// Extract continues the trace of a traceparent header; an invalid or empty
+// one leaves ctx as it is.
+func Extract(ctx context.Context, traceparent string) context.Context {
+	sc, ok := ParseTraceparent(traceparent)
+	if !ok {
+		return ctx
+	}
+	return ContextWithRemote(ctx, sc)
+}
+
+// Inject returns the traceparent header of the span in ctx, or "" outside
+// any trace.
+func Inject(ctx context.Context) string {
+	sc := SpanContextFromContext(ctx)
+	if !sc.Valid() {
+		return ""
+	}
+	return sc.Traceparent()
+}
+
+// Transport wraps base, http.DefaultTransport when nil, to record a client
+// span for each request and pass the trace on in its traceparent header.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return transport{base: base}
+}
+
+type transport struct {
+	base http.RoundTripper
+}
+
+func (t transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	ctx, span := Start(r.Context(), "HTTP "+r.Method, WithKind(KindClient), WithAttributes(
+		String("http.request.method", r.Method),
+		String("server.address", r.URL.Host),
+		String("url.path", r.URL.Path),
+	))
+	defer span.End()
+	if traceparent := Inject(ctx); traceparent != "" {
+		// RoundTrip must not change the caller's request.
+		r = r.Clone(ctx)
+		r.Header.Set(Header, traceparent)
+	}
+	resp, err := t.base.RoundTrip(r)
+	if err != nil {
+		span.SetError(err)
+		return nil, err
+	}
+	span.SetAttributes(Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= http.StatusInternalServerError {
+		span.SetError(fmt.Errorf("status %d", resp.StatusCode))
+	}
+	return resp, nil
+}
@@ -0,0 +1,211 @@
+// Package tracing records OpenTelemetry spans and propagates W3C trace
+// context. Spans are exported by the default Tracer; until one is set they
+// aren't recorded, but the caller's trace context is still passed on.
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type TraceID [16]byte
+
+func (t TraceID) String() string { return hex.EncodeToString(t[:]) }
+
+func (t TraceID) IsValid() bool { return t != TraceID{} }
+
+type SpanID [8]byte
+
+func (s SpanID) String() string { return hex.EncodeToString(s[:]) }
+
+func (s SpanID) IsValid() bool { return s != SpanID{} }
+
+// SpanContext identifies a span across process boundaries.
+type SpanContext struct {
+	TraceID TraceID
+	SpanID  SpanID
+	Sampled bool
+}
+
+func (sc SpanContext) Valid() bool {
+	return sc.TraceID.IsValid() && sc.SpanID.IsValid()
+}
+
+// SpanKind values are the OTLP span kinds.
+type SpanKind int
+
+const (
+	KindInternal SpanKind = 1
+	KindServer   SpanKind = 2
+	KindClient   SpanKind = 3
+	KindProducer SpanKind = 4
+	KindConsumer SpanKind = 5
+)
+
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+func String(key, value string) Attribute { return Attribute{Key: key, Value: value} }
+
+func Int(key string, value int) Attribute { return Attribute{Key: key, Value: value} }
+
+func Bool(key string, value bool) Attribute { return Attribute{Key: key, Value: value} }
+
+// Exporter receives spans once they end.
+type Exporter interface {
+	ExportSpan(s *Span)
+}
+
+// Tracer samples new traces and hands ended spans to its exporter.
+type Tracer struct {
+	exporter Exporter
+	// sampleRatio is the share of traces started here that are recorded;
+	// spans continuing a caller's trace follow the caller's decision.
+	sampleRatio float64
+}
+
+func NewTracer(exporter Exporter, sampleRatio float64) *Tracer {
+	return &Tracer{exporter: exporter, sampleRatio: sampleRatio}
+}
+
+var defaultTracer atomic.Pointer[Tracer]
+
+// SetDefault makes t the tracer Start records spans with; nil stops recording.
+func SetDefault(t *Tracer) {
+	defaultTracer.Store(t)
+}
+
+// Span is one timed operation of a trace. A nil *Span, as Start returns
+// without a default tracer, ignores every call.
+type Span struct {
+	tracer *Tracer
+	name   string
+	kind   SpanKind
+	sc     SpanContext
+	parent SpanID
+	start  time.Time
+
+	mu    sync.Mutex
+	end   time.Time
+	attrs []Attribute
+	err   error
+	ended bool
+}
+
+type startConfig struct {
+	kind  SpanKind
+	attrs []Attribute
+}
+
+type StartOption func(*startConfig)
+
+func WithKind(kind SpanKind) StartOption {
+	return func(c *startConfig) { c.kind = kind }
+}
+
+func WithAttributes(attrs ...Attribute) StartOption {
+	return func(c *startConfig) { c.attrs = append(c.attrs, attrs...) }
+}
+
+type spanKey struct{}
+
+type remoteKey struct{}
+
+// Start begins a span as a child of the span in ctx, or of the remote span
+// Extract put there, and returns a context carrying it. End must be called.
+func Start(ctx context.Context, name string, opts ...StartOption) (context.Context, *Span) {
+	t := defaultTracer.Load()
+	if t == nil {
+		return ctx, nil
+	}
+	cfg := startConfig{kind: KindInternal}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	s := &Span{tracer: t, name: name, kind: cfg.kind, start: time.Now(), attrs: cfg.attrs}
+	if parent := SpanContextFromContext(ctx); parent.Valid() {
+		s.sc = SpanContext{TraceID: parent.TraceID, Sampled: parent.Sampled}
+		s.parent = parent.SpanID
+	} else {
+		fill(s.sc.TraceID[:])
+		s.sc.Sampled = rand.Float64() < t.sampleRatio
+	}
+	fill(s.sc.SpanID[:])
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func fill(b []byte) {
+	for i := range b {
+		b[i] = byte(rand.Uint32())
+	}
+}
+
+// SpanContextFromContext returns the context of the span in ctx, or of the
+// remote span Extract put there; it is invalid when there is neither.
+func SpanContextFromContext(ctx context.Context) SpanContext {
+	if s, ok := ctx.Value(spanKey{}).(*Span); ok {
+		return s.sc
+	}
+	sc, _ := ctx.Value(remoteKey{}).(SpanContext)
+	return sc
+}
+
+// ContextWithRemote makes sc, a span of another process, the parent of spans
+// started from the returned context.
+func ContextWithRemote(ctx context.Context, sc SpanContext) context.Context {
+	if !sc.Valid() {
+		return ctx
+	}
+	return context.WithValue(ctx, remoteKey{}, sc)
+}
+
+func (s *Span) Context() SpanContext {
+	if s == nil {
+		return SpanContext{}
+	}
+	return s.sc
+}
+
+func (s *Span) SetAttributes(attrs ...Attribute) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attrs = append(s.attrs, attrs...)
+}
+
+// SetError marks the span failed with err; a nil err leaves it as it is.
+func (s *Span) SetError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err
+}
+
+// End records the span's end and exports it if its trace is sampled. Calls
+// after the first are ignored.
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+	if s.sc.Sampled && s.tracer.exporter != nil {
+		s.tracer.exporter.ExportSpan(s)
+	}
+}
@@ -0,0 +1,122 @@
+package vectorstore
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// Memory is a brute-force cosine similarity store for development and tests.
+type Memory struct {
+	mu     sync.RWMutex
+	chunks map[string]domain.Chunk
+}
+
+func NewMemory() *Memory {
+	return &Memory{chunks: make(map[string]domain.Chunk)}
+}
+
+func (m *Memory) Upsert(ctx context.Context, chunks []domain.Chunk) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, c := range chunks {
+		m.chunks[c.ID] = c
+	}
+	return nil
+}
+
+func (m *Memory) Search(ctx context.Context, vector []float32, topK int, filter domain.SearchFilter) (_ []domain.ScoredChunk, err error) {
+	start := time.Now()
+	defer func() { ObserveSearch("memory", start, err) }()
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	results := make([]domain.ScoredChunk, 0, len(m.chunks))
+	for _, c := range m.chunks {
+		if !filter.Matches(c) {
+			continue
+		}
+		results = append(results, domain.ScoredChunk{Chunk: c, Score: cosine(vector, c.Embedding)})
+	}
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+func (m *Memory) DeleteDocument(ctx context.Context, documentID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for id, c := range m.chunks {
+		if c.DocumentID == documentID {
+			delete(m.chunks, id)
+		}
+	}
+	return nil
+}
+
+func (m *Memory) DocumentChunks(ctx context.Context, documentID string) ([]domain.Chunk, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var chunks []domain.Chunk
+	for _, c := range m.chunks {
+		if c.DocumentID == documentID {
+			chunks = append(chunks, c)
+		}
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Index < chunks[j].Index })
+	return chunks, nil
+}
+
+func (m *Memory) ScanChunks(ctx context.Context, fn func(domain.Chunk) error) error {
+	m.mu.RLock()
+	chunks := make([]domain.Chunk, 0, len(m.chunks))
+	for _, c := range m.chunks {
+		chunks = append(chunks, c)
+	}
+	m.mu.RUnlock()
+
+	for _, c := range chunks {
+		if err := fn(c); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+func (m *Memory) Ping(ctx context.Context) error {
+	return nil
+}
+
+// OpenVersion returns a new, empty store; dropping it leaves it to the
+// garbage collector.
+func (m *Memory) OpenVersion(name string) domain.VectorStore {
+	return NewMemory()
+}
+
+func (m *Memory) DropVersion(ctx context.Context, name string) error {
+	return nil
+}
@@ -0,0 +1,24 @@
+package vectorstore
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var searchDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "vector_store_query_duration_seconds",
+	Help:    "Latency of vector store searches by backend and outcome.",
+	Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"backend", "outcome"})
+
+// ObserveSearch records a search of backend started at start; the stores of
+// the storage packages report theirs through it too.
+func ObserveSearch(backend string, start time.Time, err error) {
+	outcome := "ok"
+	if err != nil {
+		outcome = "error"
+	}
+	searchDuration.WithLabelValues(backend, outcome).Observe(time.Since(start).Seconds())
+}
@@ -0,0 +1,320 @@
+package vectorstore
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/breaker"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+)
+
+// Qdrant stores chunks in a Qdrant collection over its REST API.
+type Qdrant struct {
+	cfg        Config
+	httpClient *http.Client
+
+	mu      sync.Mutex
+	created bool
+}
+
+func NewQdrant(cfg Config) *Qdrant {
+	return &Qdrant{cfg: cfg, httpClient: &http.Client{Timeout: cfg.Timeout, Transport: tracing.Transport(nil)}}
+}
+
+type qdrantPayload struct {
+	ChunkID    string    `json:"chunk_id"`
+	DocumentID string    `json:"document_id"`
+	SpaceKey   string    `json:"space_key"`
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+	Index      int       `json:"index"`
+	Text       string    `json:"text"`
+	Readers    []string  `json:"readers,omitempty"`
+	Labels     []string  `json:"labels,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type qdrantPoint struct {
+	ID      string        `json:"id"`
+	Vector  []float32     `json:"vector"`
+	Payload qdrantPayload `json:"payload"`
+}
+
+// qdrantCondition is a field match, an is_empty check or a nested should filter.
+type qdrantCondition struct {
+	Key     string            `json:"key,omitempty"`
+	Match   map[string]any    `json:"match,omitempty"`
+	IsEmpty map[string]string `json:"is_empty,omitempty"`
+	Should  []qdrantCondition `json:"should,omitempty"`
+}
+
+type qdrantFilter struct {
+	Must    []qdrantCondition `json:"must,omitempty"`
+	MustNot []qdrantCondition `json:"must_not,omitempty"`
+}
+
+func (q *Qdrant) Upsert(ctx context.Context, chunks []domain.Chunk) error {
+	if len(chunks) == 0 {
+		return nil
+	}
+	if err := q.ensureCollection(ctx, len(chunks[0].Embedding)); err != nil {
+		return err
+	}
+
+	points := make([]qdrantPoint, len(chunks))
+	for i, c := range chunks {
+		points[i] = qdrantPoint{
+			ID:     pointID(c.ID),
+			Vector: c.Embedding,
+			Payload: qdrantPayload{
+				ChunkID:    c.ID,
+				DocumentID: c.DocumentID,
+				SpaceKey:   c.SpaceKey,
+				Title:      c.Title,
+				URL:        c.URL,
+				Index:      c.Index,
+				Text:       c.Text,
+				Readers:    c.Readers,
+				Labels:     c.Labels,
+				UpdatedAt:  c.UpdatedAt,
+			},
+		}
+	}
+	return q.do(ctx, http.MethodPut, "/points?wait=true", map[string]any{"points": points}, nil)
+}
+
+func (q *Qdrant) Search(ctx context.Context, vector []float32, topK int, filter domain.SearchFilter) (_ []domain.ScoredChunk, err error) {
+	start := time.Now()
+	defer func() { ObserveSearch("qdrant", start, err) }()
+	body := map[string]any{
+		"vector":       vector,
+		"limit":        topK,
+		"with_payload": true,
+	}
+	if f := toQdrantFilter(filter); len(f.Must) > 0 || len(f.MustNot) > 0 {
+		body["filter"] = f
+	}
+
+	var resp struct {
+		Result []struct {
+			Score   float64       `json:"score"`
+			Payload qdrantPayload `json:"payload"`
+		} `json:"result"`
+	}
+	if err := q.do(ctx, http.MethodPost, "/points/search", body, &resp); err != nil {
+		return nil, err
+	}
+
+	results := make([]domain.ScoredChunk, len(resp.Result))
+	for i, r := range resp.Result {
+		results[i] = domain.ScoredChunk{Chunk: r.Payload.toChunk(), Score: r.Score}
+	}
+	return results, nil
+}
+
+func (q *Qdrant) DeleteDocument(ctx context.Context, documentID string) error {
+	filter := toQdrantFilter(domain.SearchFilter{DocumentIDs: []string{documentID}})
+	return q.do(ctx, http.MethodPost, "/points/delete?wait=true", map[string]any{"filter": filter}, nil)
+}
+
+func (q *Qdrant) DocumentChunks(ctx context.Context, documentID string) ([]domain.Chunk, error) {
+	var chunks []domain.Chunk
+	err := q.scroll(ctx, toQdrantFilter(domain.SearchFilter{DocumentIDs: []string{documentID}}), func(c domain.Chunk) error {
+		chunks = append(chunks, c)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(chunks, func(i, j int) bool { return chunks[i].Index < chunks[j].Index })
+	return chunks, nil
+}
+
+func (q *Qdrant) ScanChunks(ctx context.Context, fn func(domain.Chunk) error) error {
+	return q.scroll(ctx, qdrantFilter{}, fn)
+}
+
+func (q *Qdrant) scroll(ctx context.Context, filter qdrantFilter, fn func(domain.Chunk) error) error {
+	var offset any
+	for {
+		body := map[string]any{
+			"limit":        256,
+			"with_payload": true,
+			"with_vector":  true,
+		}
+		if len(filter.Must) > 0 || len(filter.MustNot) > 0 {
+			body["filter"] = filter
+		}
+		if offset != nil {
+			body["offset"] = offset
+		}
+
+		var resp struct {
+			Result struct {
+				Points []struct {
+					Vector  []float32     `json:"vector"`
+					Payload qdrantPayload `json:"payload"`
+				} `json:"points"`
+				NextPageOffset any `json:"next_page_offset"`
+			} `json:"result"`
+		}
+		if err := q.do(ctx, http.MethodPost, "/points/scroll", body, &resp); err != nil {
+			return err
+		}
+		for _, p := range resp.Result.Points {
+			c := p.Payload.toChunk()
+			c.Embedding = p.Vector
+			if err := fn(c); err != nil {
+				return err
+			}
+		}
+		if resp.Result.NextPageOffset == nil {
+			return nil
+		}
+		offset = resp.Result.NextPageOffset
+	}
+}
+
+func (q *Qdrant) ensureCollection(ctx context.Context, dim int) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.created {
+		return nil
+	}
+
+	err := q.do(ctx, http.MethodGet, "", nil, nil)
+	if err == domain.ErrNotFound {
+		err = q.do(ctx, http.MethodPut, "", map[string]any{
+			"vectors": map[string]any{"size": dim, "distance": "Cosine"},
+		}, nil)
+	}
+	if err != nil {
+		return fmt.Errorf("ensure collection %s: %w", q.cfg.Collection, err)
+	}
+	q.created = true
+	return nil
+}
+
+func (q *Qdrant) do(ctx context.Context, method, path string, body, out any) error {
+	return breaker.For("qdrant").Do(ctx, func() error { return q.send(ctx, method, path, body, out) })
+}
+
+func (q *Qdrant) send(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	url := strings.TrimRight(q.cfg.URL, "/") + "/collections/" + q.cfg.Collection + path
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if q.cfg.APIKey != "" {
+		req.Header.Set("api-key", q.cfg.APIKey)
+	}
+
+	resp, err := q.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("qdrant %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return breaker.Benign(domain.ErrNotFound)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return breaker.BenignStatus(resp.StatusCode,
+			fmt.Errorf("qdrant %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg))))
+	}
+	if out != nil {
+		return json.NewDecoder(resp.Body).Decode(out)
+	}
+	return nil
+}
+
+func (p qdrantPayload) toChunk() domain.Chunk {
+	return domain.Chunk{
+		ID:         p.ChunkID,
+		DocumentID: p.DocumentID,
+		SpaceKey:   p.SpaceKey,
+		Title:      p.Title,
+		URL:        p.URL,
+		Index:      p.Index,
+		Text:       p.Text,
+		Readers:    p.Readers,
+		Labels:     p.Labels,
+		UpdatedAt:  p.UpdatedAt,
+	}
+}
+
+func toQdrantFilter(filter domain.SearchFilter) qdrantFilter {
+	var f qdrantFilter
+	if len(filter.SpaceKeys) > 0 {
+		f.Must = append(f.Must, qdrantCondition{Key: "space_key", Match: map[string]any{"any": filter.SpaceKeys}})
+	}
+	if len(filter.DocumentIDs) > 0 {
+		f.Must = append(f.Must, qdrantCondition{Key: "document_id", Match: map[string]any{"any": filter.DocumentIDs}})
+	}
+	if len(filter.Readers) > 0 {
+		f.Must = append(f.Must, qdrantCondition{Should: []qdrantCondition{
+			{IsEmpty: map[string]string{"key": "readers"}},
+			{Key: "readers", Match: map[string]any{"any": filter.Readers}},
+		}})
+	}
+	if len(filter.ExcludeDocumentIDs) > 0 {
+		f.MustNot = append(f.MustNot, qdrantCondition{Key: "document_id", Match: map[string]any{"any": filter.ExcludeDocumentIDs}})
+	}
+	return f
+}
+
+// pointID maps an arbitrary chunk ID onto the UUID format Qdrant requires.
+func pointID(chunkID string) string {
+	h := sha1.Sum([]byte(chunkID))
+	h[6] = (h[6] & 0x0f) | 0x50
+	h[8] = (h[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", h[0:4], h[4:6], h[6:8], h[8:10], h[10:16])
+}
+
+// Ping checks Qdrant is reachable; a collection that doesn't exist yet is fine
+// because it is created on first upsert.
+func (q *Qdrant) Ping(ctx context.Context) error {
+	if err := q.do(ctx, http.MethodGet, "", nil, nil); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// OpenVersion returns the store of the collection named after this one and
+// the version, created on first upsert like this one.
+func (q *Qdrant) OpenVersion(name string) domain.VectorStore {
+	cfg := q.cfg
+	cfg.Collection += "_" + name
+	return NewQdrant(cfg)
+}
+
+// DropVersion deletes the version's collection.
+func (q *Qdrant) DropVersion(ctx context.Context, name string) error {
+	version := q.OpenVersion(name).(*Qdrant)
+	if err := version.do(ctx, http.MethodDelete, "", nil, nil); err != nil && !errors.Is(err, domain.ErrNotFound) {
+		return fmt.Errorf("drop collection %s: %w", version.cfg.Collection, err)
+	}
+	return nil
+}
@@ -0,0 +1,27 @@
+package vectorstore
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Config struct {
+	Backend    string
+	URL        string
+	APIKey     string
+	Collection string
+	Timeout    time.Duration
+}
+
+func New(cfg Config) (domain.VectorStore, error) {
+	switch cfg.Backend {
+	case "", "memory":
+		return NewMemory(), nil
+	case "qdrant":
+		return NewQdrant(cfg), nil
+	default:
+		return nil, fmt.Errorf("unknown vector store backend %q", cfg.Backend)
+	}
+}
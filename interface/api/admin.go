@@ -0,0 +1,335 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+	"github.com/shubhamgptln/sarama-ai/usecase/reindex"
+)
+
+const connectorTimeout = 5 * time.Second
+
+type reindexResponse struct {
+	JobID  string `json:"job_id"`
+	Queued int    `json:"queued"`
+}
+
+func (h *Handler) handleReindex(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Reindex == nil {
+		apierror.Write(w, apierror.Unavailable, "Ingestion is not available")
+		return
+	}
+	var req reindex.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	// The job outlives the request; sync.completed webhooks report when it ends.
+	jobID, queued, err := h.services.Reindex.Reindex(context.WithoutCancel(r.Context()), req)
+	if errors.Is(err, reindex.ErrRunning) {
+		apierror.Write(w, apierror.Conflict, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Starting reindex failed: %v\n", err)
+		apierror.Write(w, apierror.Internal, "Failed to start reindex")
+		return
+	}
+	log.Printf("Reindex %s started for %d documents\n", jobID, queued)
+	writeJSON(w, http.StatusAccepted, reindexResponse{JobID: jobID, Queued: queued})
+}
+
+type logLevelRequest struct {
+	// Level changes the shared level; empty leaves it.
+	Level string `json:"level,omitempty"`
+	// Components overrides the level by component; "default" removes the
+	// override.
+	Components map[string]string `json:"components,omitempty"`
+}
+
+type logLevelResponse struct {
+	Level      string            `json:"level"`
+	Components map[string]string `json:"components,omitempty"`
+}
+
+func (h *Handler) handleLogLevel(w http.ResponseWriter, r *http.Request) {
+	l := h.services.Logger
+	if l == nil {
+		l = logger.Default()
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, logLevelResponseOf(l))
+
+	case http.MethodPut:
+		var req logLevelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+			return
+		}
+		if req.Level == "" && len(req.Components) == 0 {
+			apierror.Write(w, apierror.InvalidArgument, "level or components is required")
+			return
+		}
+		// Check every level before changing any.
+		components := make(map[string]logger.Level)
+		for component, name := range req.Components {
+			if name == "default" {
+				continue
+			}
+			level, err := logger.ParseLevel(name)
+			if err != nil {
+				apierror.Write(w, apierror.InvalidArgument, component+": "+err.Error())
+				return
+			}
+			components[component] = level
+		}
+		if req.Level != "" {
+			level, err := logger.ParseLevel(req.Level)
+			if err != nil {
+				apierror.Write(w, apierror.InvalidArgument, err.Error())
+				return
+			}
+			log.Printf("Log level changed from %s to %s\n", l.Level(), level)
+			l.SetLevel(level)
+		}
+		for component := range req.Components {
+			if level, ok := components[component]; ok {
+				log.Printf("Log level of %s set to %s\n", component, level)
+				l.SetComponentLevel(component, level)
+			} else {
+				log.Printf("Log level of %s reset\n", component)
+				l.ResetComponentLevel(component)
+			}
+		}
+		writeJSON(w, http.StatusOK, logLevelResponseOf(l))
+
+	default:
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+	}
+}
+
+func logLevelResponseOf(l *logger.Logger) logLevelResponse {
+	resp := logLevelResponse{Level: l.Level().String()}
+	if levels := l.ComponentLevels(); len(levels) > 0 {
+		resp.Components = make(map[string]string, len(levels))
+		for component, level := range levels {
+			resp.Components[component] = level.String()
+		}
+	}
+	return resp
+}
+
+func (h *Handler) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Config == nil {
+		apierror.Write(w, apierror.Unavailable, "Configuration is not available")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.services.Config())
+}
+
+type cacheFlushRequest struct {
+	// Caches to flush; all of them when empty.
+	Caches []string `json:"caches,omitempty"`
+}
+
+type cacheFlushResponse struct {
+	Flushed []string `json:"flushed"`
+}
+
+func (h *Handler) handleCacheFlush(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req cacheFlushRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	names := req.Caches
+	if len(names) == 0 {
+		for name := range h.services.Caches {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		if _, ok := h.services.Caches[name]; !ok {
+			apierror.WriteDetails(w, apierror.InvalidArgument, "Unknown cache "+name, map[string][]string{"caches": h.cacheNames()})
+			return
+		}
+	}
+	slices.Sort(names)
+	names = slices.Compact(names)
+	for _, name := range names {
+		h.services.Caches[name]()
+	}
+	log.Printf("Flushed caches: %v\n", names)
+	writeJSON(w, http.StatusOK, cacheFlushResponse{Flushed: names})
+}
+
+func (h *Handler) cacheNames() []string {
+	names := make([]string, 0, len(h.services.Caches))
+	for name := range h.services.Caches {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type connectorStatus struct {
+	Status    string `json:"status"`
+	LatencyMS int64  `json:"latency_ms"`
+	Error     string `json:"error,omitempty"`
+}
+
+type connectorsResponse struct {
+	Connectors map[string]connectorStatus `json:"connectors"`
+}
+
+type sloResponse struct {
+	Routes []middleware.SLOStatus `json:"routes"`
+}
+
+// handleSLO reports the SLOs of this instance's routes.
+func (h *Handler) handleSLO(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.SLO == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "SLO tracking is off; set SLO_AVAILABILITY_TARGET")
+		return
+	}
+	writeJSON(w, http.StatusOK, sloResponse{Routes: h.services.SLO.Summary()})
+}
+
+// handleConnectors pings every external system concurrently. Unlike /ready it
+// never caches, so operators see the state right now.
+func (h *Handler) handleConnectors(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	ctx, cancel := context.WithTimeout(r.Context(), connectorTimeout)
+	defer cancel()
+
+	resp := connectorsResponse{Connectors: make(map[string]connectorStatus, len(h.services.Connectors))}
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+	for name, connector := range h.services.Connectors {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			err := connector.Ping(ctx)
+			status := connectorStatus{Status: "ok", LatencyMS: time.Since(start).Milliseconds()}
+			if err != nil {
+				status.Status = "fail"
+				status.Error = err.Error()
+			}
+			mu.Lock()
+			resp.Connectors[name] = status
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	writeJSON(w, http.StatusOK, resp)
+}
+
+type deadLettersResponse struct {
+	DeadLetters []domain.DeadLetter `json:"dead_letters"`
+}
+
+type replayResponse struct {
+	Replayed int `json:"replayed"`
+}
+
+type purgeResponse struct {
+	Purged int `json:"purged"`
+}
+
+func (h *Handler) handleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if h.services.DeadLetters == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "No dead-letter queue is configured")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		limit, err := intParam(r, "limit", 50, 1, 500)
+		if err != nil {
+			apierror.Write(w, apierror.InvalidArgument, err.Error())
+			return
+		}
+		letters, err := h.services.DeadLetters.PeekDeadLetters(r.Context(), limit)
+		if err != nil {
+			log.Printf("Reading dead letters failed: %v\n", err)
+			apierror.Write(w, apierror.Upstream, "Failed to read dead letters")
+			return
+		}
+		if letters == nil {
+			letters = []domain.DeadLetter{}
+		}
+		writeJSON(w, http.StatusOK, deadLettersResponse{DeadLetters: letters})
+
+	case http.MethodDelete:
+		purged, err := h.services.DeadLetters.PurgeDeadLetters(r.Context())
+		if err != nil {
+			log.Printf("Purging dead letters failed: %v\n", err)
+			apierror.Write(w, apierror.Upstream, "Failed to purge dead letters")
+			return
+		}
+		log.Printf("Purged %d dead letters\n", purged)
+		writeJSON(w, http.StatusOK, purgeResponse{Purged: purged})
+
+	default:
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h *Handler) handleReplayDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.DeadLetters == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "No dead-letter queue is configured")
+		return
+	}
+	limit, err := intParam(r, "limit", 50, 1, 500)
+	if err != nil {
+		apierror.Write(w, apierror.InvalidArgument, err.Error())
+		return
+	}
+	replayed, err := h.services.DeadLetters.ReplayDeadLetters(r.Context(), limit)
+	if err != nil {
+		// Part of the batch may have been replayed; report it alongside the error.
+		log.Printf("Replaying dead letters failed after %d: %v\n", replayed, err)
+		apierror.WriteDetails(w, apierror.Upstream, "Failed to replay dead letters", replayResponse{Replayed: replayed})
+		return
+	}
+	log.Printf("Replayed %d dead letters\n", replayed)
+	writeJSON(w, http.StatusOK, replayResponse{Replayed: replayed})
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/gaps"
+)
+
+type knowledgeGapsResponse struct {
+	Since  string       `json:"since"`
+	Topics []gaps.Topic `json:"topics"`
+}
+
+func (h *Handler) handleKnowledgeGaps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	days, err := intParam(r, "days", 30, 1, 365)
+	if err != nil {
+		apierror.Write(w, apierror.InvalidArgument, err.Error())
+		return
+	}
+	limit, err := intParam(r, "limit", 20, 1, 200)
+	if err != nil {
+		apierror.Write(w, apierror.InvalidArgument, err.Error())
+		return
+	}
+
+	since := time.Now().AddDate(0, 0, -days)
+	topics, err := h.services.Gaps.Topics(r.Context(), since, limit)
+	if err != nil {
+		log.Printf("Loading knowledge gaps failed: %v\n", err)
+		apierror.Write(w, apierror.Internal, "Failed to load knowledge gaps")
+		return
+	}
+	writeJSON(w, http.StatusOK, knowledgeGapsResponse{Since: since.UTC().Format(time.RFC3339), Topics: topics})
+}
+
+func intParam(r *http.Request, name string, def, min, max int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < min || n > max {
+		return 0, fmt.Errorf("%s must be between %d and %d", name, min, max)
+	}
+	return n, nil
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/encryption"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/slack"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/teams"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+	"github.com/shubhamgptln/sarama-ai/usecase/access"
+	"github.com/shubhamgptln/sarama-ai/usecase/audit"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+	"github.com/shubhamgptln/sarama-ai/usecase/catalog"
+	"github.com/shubhamgptln/sarama-ai/usecase/content"
+	"github.com/shubhamgptln/sarama-ai/usecase/digest"
+	"github.com/shubhamgptln/sarama-ai/usecase/erasure"
+	"github.com/shubhamgptln/sarama-ai/usecase/experiment"
+	"github.com/shubhamgptln/sarama-ai/usecase/gaps"
+	"github.com/shubhamgptln/sarama-ai/usecase/glossary"
+	"github.com/shubhamgptln/sarama-ai/usecase/graph"
+	"github.com/shubhamgptln/sarama-ai/usecase/indexversion"
+	"github.com/shubhamgptln/sarama-ai/usecase/ingest"
+	"github.com/shubhamgptln/sarama-ai/usecase/notify"
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+	"github.com/shubhamgptln/sarama-ai/usecase/rbac"
+	"github.com/shubhamgptln/sarama-ai/usecase/reindex"
+	"github.com/shubhamgptln/sarama-ai/usecase/related"
+	"github.com/shubhamgptln/sarama-ai/usecase/research"
+	"github.com/shubhamgptln/sarama-ai/usecase/retention"
+	"github.com/shubhamgptln/sarama-ai/usecase/snapshot"
+	"github.com/shubhamgptln/sarama-ai/usecase/tenant"
+	"github.com/shubhamgptln/sarama-ai/usecase/writeback"
+)
+
+type Services struct {
+	Query       *query.Service
+	Experiments *experiment.Manager
+	Feedback    domain.FeedbackRepository
+	Related     *related.Service
+	Auditor     *content.Auditor
+	Gaps        *gaps.Tracker
+	Glossary    *glossary.Service
+	Graph       *graph.Service
+	Research    *research.Service
+	Audit       *audit.Recorder
+	// AuditLog records auth failures, denied access and admin changes.
+	AuditLog    domain.AuditLog
+	Auth        *auth.Service
+	RBAC        *rbac.Service
+	Access      *access.Service
+	Catalog     *catalog.Service
+	Reindex     *reindex.Service
+	Notify      *notify.Service
+	Digest      *digest.Service
+	Writeback   *writeback.Service
+	Connectors  map[string]domain.HealthChecker
+	DeadLetters domain.DeadLetterQueue
+	Slack       *slack.Client
+	Teams       *teams.Client
+	// ChatSpaceKeys limits the spaces questions from chat tools are answered
+	// from; every space when empty.
+	ChatSpaceKeys []string
+	// Ingest processes an ingest event asynchronously, in the trace of ctx.
+	Ingest func(ctx context.Context, event domain.IngestEvent)
+	// AddDocument indexes content posted through the API.
+	AddDocument func(ctx context.Context, doc ingest.Manual) (string, error)
+	// DeleteDocument removes a document and its chunks from the index.
+	DeleteDocument func(ctx context.Context, documentID string) error
+	// Background runs work that outlives its request; shutdown waits for it.
+	Background func(func())
+	// Logger is the logger whose level the admin API controls.
+	Logger *logger.Logger
+	// Caches flush the named in-memory caches.
+	Caches map[string]func()
+	// Config returns the configuration in effect, secrets masked.
+	Config func() map[string]any
+	// Conversations keep each session's turns; questions without history are
+	// answered with the last ConversationHistory messages of their session.
+	Conversations       domain.ConversationRepository
+	ConversationHistory int
+	// Snapshots exports and restores the index; nil without blob storage.
+	Snapshots *snapshot.Service
+	// IndexVersions builds and switches versions of the index; nil when the
+	// vector store can't hold more than one.
+	IndexVersions *indexversion.Service
+	// Retention purges conversations and feedback past their retention.
+	Retention *retention.Service
+	// Erasure deletes what is stored about a user on request.
+	Erasure *erasure.Service
+	// Encryption rotates the master key of the blobs; nil when they aren't
+	// encrypted.
+	Encryption *encryption.BlobStore
+	// Tenants isolates the data of the teams or customers sharing the
+	// deployment; nil when there are none.
+	Tenants *tenant.Service
+	// SLO reports burn rates against the SLOs; nil when they aren't tracked.
+	SLO *middleware.SLOTracker
+}
+
+type Handler struct {
+	services Services
+	graphQL  *graphql.Schema
+}
+
+func NewHandler(services Services) *Handler {
+	h := &Handler{services: services}
+	h.graphQL = h.newGraphQLSchema()
+	return h
+}
+
+// Register adds the versioned API, GraphQL, their documentation, the chat UI
+// and the configured chat integrations. Admin routes are added separately with
+// RegisterAdmin so they can be served on another port.
+func (h *Handler) Register(mux *http.ServeMux) {
+	h.register(mux, h.versionedRoutes())
+	h.register(mux, h.graphQLRoutes())
+	mux.HandleFunc("/openapi.json", h.handleOpenAPI)
+	mux.HandleFunc("/docs", handleDocs)
+	mux.Handle("/ui/", uiHandler())
+	if h.services.Slack != nil {
+		h.registerSlack(mux)
+	}
+	if h.services.Teams != nil {
+		mux.HandleFunc("/integrations/teams/messages", h.handleTeamsActivity)
+	}
+}
+
+func (h *Handler) RegisterAdmin(mux *http.ServeMux) {
+	h.register(mux, h.adminRoutes())
+}
+
+func (h *Handler) register(mux *http.ServeMux, routes []route) {
+	for _, rt := range routes {
+		mux.Handle(rt.Path, withDeprecation(rt.Deprecation, h.requireScope(rt.Scope, rt.Handler)))
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Printf("Error writing response: %v\n", err)
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+)
+
+// audit appends an event about r to the security audit log, when enabled.
+func (h *Handler) audit(r *http.Request, action, actor, outcome string, details map[string]any) {
+	if h.services.AuditLog == nil {
+		return
+	}
+	event := domain.AuditEvent{
+		At:        time.Now(),
+		Action:    action,
+		Actor:     actor,
+		Resource:  r.Method + " " + r.URL.Path,
+		Outcome:   outcome,
+		RemoteIP:  middleware.ClientIP(r),
+		RequestID: middleware.RequestIDFromContext(r.Context()),
+		Details:   details,
+	}
+	if err := h.services.AuditLog.Append(r.Context(), event); err != nil {
+		log.Printf("Writing audit log failed: %v\n", err)
+	}
+}
+
+// auditAdmin records changes made through admin routes with the status they
+// ended with; reads aren't audited. actor is empty when auth is disabled.
+func (h *Handler) auditAdmin(actor string, next http.HandlerFunc) http.HandlerFunc {
+	if h.services.AuditLog == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet || r.Method == http.MethodHead || r.Method == http.MethodOptions {
+			next(w, r)
+			return
+		}
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		next(rec, r)
+		outcome := "succeeded"
+		if rec.status >= http.StatusBadRequest {
+			outcome = "failed"
+		}
+		h.audit(r, domain.AuditAdminAction, actor, outcome, map[string]any{"status": rec.status})
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
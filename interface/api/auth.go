@@ -0,0 +1,156 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/interface/middleware"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+)
+
+// requireScope authenticates the caller and checks it has been granted scope.
+// Without an auth service every request is let through.
+func (h *Handler) requireScope(scope domain.Scope, next http.HandlerFunc) http.Handler {
+	if h.services.Auth == nil {
+		if scope == domain.ScopeAdmin {
+			return h.auditAdmin("", next)
+		}
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := h.authenticate(r)
+		switch {
+		case errors.Is(err, auth.ErrMissingCredentials), errors.Is(err, auth.ErrInvalidCredentials):
+			if errors.Is(err, auth.ErrInvalidCredentials) {
+				h.audit(r, domain.AuditAuthFailure, "", "denied", map[string]any{"required_scope": scope})
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer realm="sarama-ai"`)
+			apierror.Write(w, apierror.Unauthorized, "Unauthorized")
+			return
+		case err != nil:
+			log.Printf("Authenticating request failed: %v\n", err)
+			apierror.Write(w, apierror.Unavailable, "Authentication unavailable")
+			return
+		}
+		middleware.RecordCaller(r.Context(), principal)
+		if h.services.RBAC != nil {
+			if err := h.services.RBAC.Resolve(r.Context(), principal); err != nil {
+				log.Printf("Resolving roles for %s failed: %v\n", principal.ID, err)
+				apierror.Write(w, apierror.Unavailable, "Authorization unavailable")
+				return
+			}
+		}
+		if !principal.HasScope(scope) {
+			h.audit(r, domain.AuditAccessDenied, principal.ID, "denied", map[string]any{"required_scope": scope})
+			apierror.WriteDetails(w, apierror.Forbidden, "Forbidden: "+string(scope)+" scope required", map[string]domain.Scope{"required_scope": scope})
+			return
+		}
+		if principal.Tenant != "" {
+			if message := h.tenantDenied(principal, scope); message != "" {
+				h.audit(r, domain.AuditAccessDenied, principal.ID, "denied", map[string]any{"required_scope": scope, "tenant": principal.Tenant})
+				apierror.Write(w, apierror.Forbidden, message)
+				return
+			}
+		}
+		ctx := r.Context()
+		if auth.PrincipalFromContext(ctx) == nil {
+			ctx = logger.ContextWithFields(ctx, logger.String("user", principal.ID))
+		}
+		handler := next
+		if scope == domain.ScopeAdmin {
+			handler = h.auditAdmin(principal.ID, next)
+		}
+		handler(w, r.WithContext(auth.WithPrincipal(ctx, principal)))
+	})
+}
+
+// authenticate reuses the principal identified by the middleware chain, if
+// any, and otherwise authenticates the request's credential.
+func (h *Handler) authenticate(r *http.Request) (*domain.Principal, error) {
+	if principal := auth.PrincipalFromContext(r.Context()); principal != nil {
+		return principal, nil
+	}
+	return h.services.Auth.Authenticate(r.Context(), middleware.Credential(r))
+}
+
+type createAPIKeyRequest struct {
+	Name   string   `json:"name"`
+	Scopes []string `json:"scopes"`
+	// Tenant limits the key to one tenant's data.
+	Tenant string `json:"tenant,omitempty"`
+}
+
+type createAPIKeyResponse struct {
+	Key    string         `json:"key"`
+	APIKey *domain.APIKey `json:"api_key"`
+}
+
+func (h *Handler) handleAPIKeys(w http.ResponseWriter, r *http.Request) {
+	if h.services.Auth == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Authentication is disabled")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		keys, err := h.services.Auth.ListKeys(r.Context())
+		if err != nil {
+			log.Printf("Listing API keys failed: %v\n", err)
+			apierror.Write(w, apierror.Internal, "Failed to list API keys")
+			return
+		}
+		writeJSON(w, http.StatusOK, keys)
+
+	case http.MethodPost:
+		var req createAPIKeyRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+			apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+			return
+		}
+		scopes, err := auth.ParseScopes(req.Scopes)
+		if err != nil {
+			apierror.Write(w, apierror.InvalidArgument, err.Error())
+			return
+		}
+		if req.Tenant != "" && !h.knownTenant(req.Tenant) {
+			apierror.Write(w, apierror.InvalidArgument, "Unknown tenant "+req.Tenant)
+			return
+		}
+		raw, key, err := h.services.Auth.CreateKey(r.Context(), req.Name, scopes, req.Tenant)
+		if errors.Is(err, auth.ErrTenantAdmin) {
+			apierror.Write(w, apierror.InvalidArgument, err.Error())
+			return
+		}
+		if err != nil {
+			log.Printf("Creating API key failed: %v\n", err)
+			apierror.Write(w, apierror.Internal, "Failed to create API key")
+			return
+		}
+		writeJSON(w, http.StatusCreated, createAPIKeyResponse{Key: raw, APIKey: key})
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			apierror.Write(w, apierror.InvalidArgument, "Missing id parameter")
+			return
+		}
+		err := h.services.Auth.RevokeKey(r.Context(), id)
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			apierror.Write(w, apierror.NotFound, "API key not found")
+			return
+		}
+		if err != nil {
+			log.Printf("Revoking API key %s failed: %v\n", id, err)
+			apierror.Write(w, apierror.Internal, "Failed to revoke API key")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"context"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// chatAnswerTimeout bounds answering a question asked from a chat tool, which
+// happens after the request has been acknowledged.
+const chatAnswerTimeout = 2 * time.Minute
+
+// chatAnswer answers a question asked from a chat tool and formats the answer,
+// or the reason there is none, as a message. Chat users are not mapped to
+// Confluence identities, so answers come from ChatSpaceKeys, or every space
+// when unset; with access control on, only from the spaces and pages among
+// those that anyone may read.
+func (h *Handler) chatAnswer(ctx context.Context, sessionID, question string, format func(*domain.Answer) string) string {
+	q := domain.Question{Text: question, SessionID: sessionID, SpaceKeys: h.services.ChatSpaceKeys}
+	if h.services.Access != nil {
+		if err := h.services.Access.RestrictAnonymous(ctx, &q); err != nil {
+			_, message := restrictError(err)
+			return "Sorry, I couldn't answer that: " + message
+		}
+	}
+	answer, _, err := h.ask(ctx, &q)
+	if err != nil {
+		_, message := askError(err)
+		return "Sorry, I couldn't answer that: " + message
+	}
+	return format(answer)
+}
+
+// background runs fn after the response is written, bounded by
+// chatAnswerTimeout; shutdown waits for it when Background is set.
+func (h *Handler) background(fn func(ctx context.Context)) {
+	run := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), chatAnswerTimeout)
+		defer cancel()
+		fn(ctx)
+	}
+	if h.services.Background != nil {
+		h.services.Background(run)
+		return
+	}
+	go run()
+}
@@ -0,0 +1,45 @@
+package api
+
+import (
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// RegisterDebug serves the net/http/pprof profiles under /debug/pprof/, the
+// expvar variables on /debug/vars and a dump of every goroutine's stack on
+// /debug/goroutines, all to admins only. They expose memory contents and
+// process internals, so they belong on the admin listener.
+func (h *Handler) RegisterDebug(mux *http.ServeMux) {
+	handlers := map[string]http.HandlerFunc{
+		"/debug/pprof/":        pprof.Index,
+		"/debug/pprof/cmdline": pprof.Cmdline,
+		"/debug/pprof/profile": pprof.Profile,
+		"/debug/pprof/symbol":  pprof.Symbol,
+		"/debug/pprof/trace":   pprof.Trace,
+		"/debug/vars":          expvar.Handler().ServeHTTP,
+		"/debug/goroutines":    handleGoroutines,
+	}
+	for path, handler := range handlers {
+		mux.Handle(path, h.requireScope(domain.ScopeAdmin, handler))
+	}
+}
+
+// handleGoroutines writes the stacks of all goroutines as a panic would,
+// which is what leaks are usually diagnosed from.
+func handleGoroutines(w http.ResponseWriter, r *http.Request) {
+	buf := make([]byte, 1<<20)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(buf)
+}
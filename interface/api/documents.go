@@ -0,0 +1,255 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/catalog"
+	"github.com/shubhamgptln/sarama-ai/usecase/ingest"
+)
+
+// documentMaxBody bounds an added document, upload included.
+const documentMaxBody = 10 << 20
+
+type documentsResponse struct {
+	Documents []catalog.Document `json:"documents"`
+	// Total counts every match, including those past limit and offset.
+	Total int `json:"total"`
+}
+
+type documentResponse struct {
+	Document *catalog.Document `json:"document"`
+	Chunks   []domain.Chunk    `json:"chunks"`
+}
+
+// handleDocuments lets operators see what is indexed, across every space and
+// regardless of page restrictions, and drop a document from the index.
+func (h *Handler) handleDocuments(w http.ResponseWriter, r *http.Request) {
+	if h.services.Catalog == nil {
+		apierror.Write(w, apierror.Unavailable, "The document catalog is not available")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		limit, err := intParam(r, "limit", 100, 1, 1000)
+		if err != nil {
+			apierror.Write(w, apierror.InvalidArgument, err.Error())
+			return
+		}
+		offset, err := intParam(r, "offset", 0, 0, 1<<30)
+		if err != nil {
+			apierror.Write(w, apierror.InvalidArgument, err.Error())
+			return
+		}
+		q := r.URL.Query()
+		docs, err := h.services.Catalog.Search(r.Context(), domain.SearchFilter{}, catalog.Query{
+			Title:    q.Get("title"),
+			SpaceKey: q.Get("space"),
+			Label:    q.Get("label"),
+		})
+		if err != nil {
+			log.Printf("Searching documents failed: %v\n", err)
+			apierror.Write(w, apierror.Internal, "Failed to search documents")
+			return
+		}
+		resp := documentsResponse{Documents: []catalog.Document{}, Total: len(docs)}
+		if offset < len(docs) {
+			resp.Documents = docs[offset:min(offset+limit, len(docs))]
+		}
+		writeJSON(w, http.StatusOK, resp)
+
+	case http.MethodDelete:
+		if h.services.DeleteDocument == nil {
+			apierror.Write(w, apierror.Unavailable, "Ingestion is not available")
+			return
+		}
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			apierror.Write(w, apierror.InvalidArgument, "Missing id parameter")
+			return
+		}
+		if _, _, err := h.services.Catalog.Document(r.Context(), id); errors.Is(err, domain.ErrNotFound) {
+			apierror.Write(w, apierror.NotFound, "Document not indexed")
+			return
+		}
+		// Finish the delete even if the client goes away.
+		if err := h.services.DeleteDocument(context.WithoutCancel(r.Context()), id); err != nil {
+			log.Printf("Deleting document %s failed: %v\n", id, err)
+			apierror.Write(w, apierror.Internal, "Failed to delete document")
+			return
+		}
+		log.Printf("Deleted document %s from the index\n", id)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+	}
+}
+
+func (h *Handler) handleDocumentChunks(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Catalog == nil {
+		apierror.Write(w, apierror.Unavailable, "The document catalog is not available")
+		return
+	}
+	id := r.URL.Query().Get("id")
+	if id == "" {
+		apierror.Write(w, apierror.InvalidArgument, "Missing id parameter")
+		return
+	}
+	doc, chunks, err := h.services.Catalog.Document(r.Context(), id)
+	if errors.Is(err, domain.ErrNotFound) {
+		apierror.Write(w, apierror.NotFound, "Document not indexed")
+		return
+	}
+	if err != nil {
+		log.Printf("Reading document %s failed: %v\n", id, err)
+		apierror.Write(w, apierror.Internal, "Failed to read document")
+		return
+	}
+	writeJSON(w, http.StatusOK, documentResponse{Document: doc, Chunks: chunks})
+}
+
+// addDocumentRequest is the JSON form of an added document; a multipart
+// upload carries the same fields as form values and the content as file.
+type addDocumentRequest struct {
+	// ID names the document so posting it again replaces it; one is
+	// generated when empty.
+	ID       string   `json:"id,omitempty"`
+	Title    string   `json:"title"`
+	SpaceKey string   `json:"space_key,omitempty"`
+	URL      string   `json:"url,omitempty"`
+	Labels   []string `json:"labels,omitempty"`
+	// Readers restricts the document to these readers, e.g. user:alice or
+	// group:sre.
+	Readers []string `json:"readers,omitempty"`
+	// Format is text, markdown or html; text when empty.
+	Format  string `json:"format,omitempty"`
+	Content string `json:"content"`
+}
+
+type addDocumentResponse struct {
+	ID string `json:"id"`
+}
+
+// handleAddDocument indexes content that isn't in Confluence, such as a
+// runbook or a pasted page, posted as JSON or uploaded as a file.
+func (h *Handler) handleAddDocument(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.AddDocument == nil {
+		apierror.Write(w, apierror.Unavailable, "Ingestion is not available")
+		return
+	}
+	r.Body = http.MaxBytesReader(w, r.Body, documentMaxBody)
+	var req addDocumentRequest
+	var err error
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		req, err = uploadedDocument(r)
+	} else {
+		err = json.NewDecoder(r.Body).Decode(&req)
+	}
+	if err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	if tenantID := callerTenant(r.Context()); tenantID != "" {
+		if err := h.forTenant(tenantID, &req); err != nil {
+			apierror.Write(w, apierror.Forbidden, err.Error())
+			return
+		}
+	}
+
+	docID, err := h.services.AddDocument(r.Context(), ingest.Manual{
+		ID:       req.ID,
+		Title:    req.Title,
+		SpaceKey: req.SpaceKey,
+		URL:      req.URL,
+		Labels:   req.Labels,
+		Readers:  req.Readers,
+		Format:   req.Format,
+		Content:  req.Content,
+	})
+	if errors.Is(err, ingest.ErrInvalidDocument) {
+		apierror.Write(w, apierror.InvalidArgument, err.Error())
+		return
+	}
+	if err != nil {
+		log.Printf("Adding document %q failed: %v\n", req.Title, err)
+		apierror.Write(w, apierror.Internal, "Failed to index document")
+		return
+	}
+	writeJSON(w, http.StatusCreated, addDocumentResponse{ID: docID})
+}
+
+// uploadedDocument reads a multipart upload. The title defaults to the file
+// name and the format to the one its extension suggests.
+func uploadedDocument(r *http.Request) (addDocumentRequest, error) {
+	if err := r.ParseMultipartForm(documentMaxBody); err != nil {
+		return addDocumentRequest{}, err
+	}
+	req := addDocumentRequest{
+		ID:       r.FormValue("id"),
+		Title:    r.FormValue("title"),
+		SpaceKey: r.FormValue("space_key"),
+		URL:      r.FormValue("url"),
+		Labels:   formList(r, "labels"),
+		Readers:  formList(r, "readers"),
+		Format:   r.FormValue("format"),
+		Content:  r.FormValue("content"),
+	}
+	file, header, err := r.FormFile("file")
+	if errors.Is(err, http.ErrMissingFile) {
+		return req, nil
+	}
+	if err != nil {
+		return req, err
+	}
+	defer file.Close()
+	content, err := io.ReadAll(file)
+	if err != nil {
+		return req, err
+	}
+	req.Content = string(content)
+	name := path.Base(header.Filename)
+	ext := strings.ToLower(path.Ext(name))
+	if req.Title == "" {
+		req.Title = strings.TrimSuffix(name, path.Ext(name))
+	}
+	if req.Format == "" {
+		switch ext {
+		case ".md", ".markdown":
+			req.Format = ingest.FormatMarkdown
+		case ".html", ".htm":
+			req.Format = ingest.FormatHTML
+		}
+	}
+	return req, nil
+}
+
+// formList reads a form value given repeatedly or as a comma-separated list.
+func formList(r *http.Request, name string) []string {
+	var list []string
+	for _, v := range r.Form[name] {
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+	}
+	return list
+}
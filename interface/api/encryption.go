@@ -0,0 +1,29 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+// handleRotateEncryption rewraps every blob under the current master key and
+// encrypts those stored before encryption was turned on.
+func (h *Handler) handleRotateEncryption(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Encryption == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Encryption at rest is not configured")
+		return
+	}
+	res, err := h.services.Encryption.Rotate(r.Context())
+	if err != nil {
+		log.Printf("Rotating encryption keys failed after rewrapping %d and encrypting %d blobs: %v\n", res.Rewrapped, res.Encrypted, err)
+		apierror.Write(w, apierror.Internal, "Failed to rotate encryption keys")
+		return
+	}
+	log.Printf("Rotated encryption keys: %d blobs, %d rewrapped, %d encrypted\n", res.Blobs, res.Rewrapped, res.Encrypted)
+	writeJSON(w, http.StatusOK, res)
+}
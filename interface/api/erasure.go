@@ -0,0 +1,43 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/erasure"
+)
+
+type erasureRequest struct {
+	// Subject is the principal ID of the user whose data is erased.
+	Subject string `json:"subject"`
+}
+
+// handleErasure deletes everything stored about a user and returns the
+// report of what was deleted.
+func (h *Handler) handleErasure(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Erasure == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Erasure is not configured")
+		return
+	}
+	var req erasureRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	report, err := h.services.Erasure.Erase(r.Context(), req.Subject)
+	if errors.Is(err, erasure.ErrInvalidSubject) {
+		apierror.Write(w, apierror.InvalidArgument, err.Error())
+		return
+	}
+	if err != nil {
+		apierror.Write(w, apierror.Internal, "Failed to erase subject")
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+	"github.com/shubhamgptln/sarama-ai/usecase/experiment"
+)
+
+type feedbackRequest struct {
+	SessionID string `json:"session_id"`
+	Question  string `json:"question"`
+	Rating    int    `json:"rating"`
+	Comment   string `json:"comment"`
+}
+
+func (h *Handler) handleFeedback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req feedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	if req.SessionID == "" || req.Rating < 1 || req.Rating > 5 {
+		apierror.Write(w, apierror.InvalidArgument, "session_id is required and rating must be between 1 and 5")
+		return
+	}
+
+	f := domain.Feedback{
+		ID:        id.New(),
+		SessionID: req.SessionID,
+		Question:  req.Question,
+		Rating:    req.Rating,
+		Comment:   req.Comment,
+		CreatedAt: time.Now().UTC(),
+	}
+	if p := auth.PrincipalFromContext(r.Context()); p != nil {
+		f.Principal = p.ID
+	}
+	if h.services.Experiments != nil {
+		f.Variants = h.services.Experiments.Assign(req.SessionID)
+	}
+
+	if err := h.services.Feedback.Save(r.Context(), f); err != nil {
+		log.Printf("Saving feedback failed: %v\n", err)
+		apierror.Write(w, apierror.Internal, "Failed to save feedback")
+		return
+	}
+	writeJSON(w, http.StatusCreated, f)
+}
+
+type experimentsResponse struct {
+	Experiments []experiment.Experiment    `json:"experiments"`
+	Results     []experiment.VariantResult `json:"results,omitempty"`
+}
+
+func (h *Handler) handleExperiments(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Experiments == nil {
+		writeJSON(w, http.StatusOK, experimentsResponse{Experiments: []experiment.Experiment{}})
+		return
+	}
+
+	results, err := h.services.Experiments.Results(r.Context(), h.services.Feedback)
+	if err != nil {
+		log.Printf("Loading experiment results failed: %v\n", err)
+		apierror.Write(w, apierror.Internal, "Failed to load experiment results")
+		return
+	}
+	writeJSON(w, http.StatusOK, experimentsResponse{
+		Experiments: h.services.Experiments.Experiments(),
+		Results:     results,
+	})
+}
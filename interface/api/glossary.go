@@ -0,0 +1,47 @@
+package api
+
+import (
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+type glossaryResponse struct {
+	Entries []domain.GlossaryEntry `json:"entries"`
+}
+
+func (h *Handler) handleGlossary(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	// The glossary is built from every space, other tenants' included.
+	if callerTenant(r.Context()) != "" {
+		apierror.Write(w, apierror.Forbidden, "The glossary isn't available to tenants")
+		return
+	}
+
+	entries, err := h.services.Glossary.List(r.Context())
+	if err != nil {
+		log.Printf("Loading glossary failed: %v\n", err)
+		apierror.Write(w, apierror.Internal, "Failed to load glossary")
+		return
+	}
+
+	if prefix := strings.ToLower(r.URL.Query().Get("q")); prefix != "" {
+		filtered := entries[:0]
+		for _, e := range entries {
+			if strings.HasPrefix(strings.ToLower(e.Term), prefix) {
+				filtered = append(filtered, e)
+			}
+		}
+		entries = filtered
+	}
+	if entries == nil {
+		entries = []domain.GlossaryEntry{}
+	}
+	writeJSON(w, http.StatusOK, glossaryResponse{Entries: entries})
+}
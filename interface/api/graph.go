@@ -0,0 +1,49 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+type graphEntityResponse struct {
+	Entity    *domain.Entity    `json:"entity"`
+	Relations []domain.Relation `json:"relations"`
+}
+
+func (h *Handler) handleGraphEntity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Graph == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Knowledge graph is disabled")
+		return
+	}
+	// The graph is built from every space, other tenants' included.
+	if callerTenant(r.Context()) != "" {
+		apierror.Write(w, apierror.Forbidden, "The knowledge graph isn't available to tenants")
+		return
+	}
+
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		apierror.Write(w, apierror.InvalidArgument, "name is required")
+		return
+	}
+
+	entity, relations, err := h.services.Graph.Neighborhood(r.Context(), name)
+	if errors.Is(err, domain.ErrNotFound) {
+		apierror.Write(w, apierror.NotFound, "Entity not found")
+		return
+	}
+	if err != nil {
+		log.Printf("Graph lookup failed: %v\n", err)
+		apierror.Write(w, apierror.Internal, "Failed to load entity")
+		return
+	}
+	writeJSON(w, http.StatusOK, graphEntityResponse{Entity: entity, Relations: relations})
+}
@@ -0,0 +1,387 @@
+package api
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+	"github.com/shubhamgptln/sarama-ai/usecase/catalog"
+	"github.com/shubhamgptln/sarama-ai/usecase/reindex"
+)
+
+//go:embed schema.graphql
+var graphQLSchema string
+
+const (
+	graphQLMaxBody        = 1 << 20
+	graphQLMaxQueryLength = 16 << 10
+	graphQLMaxDepth       = 8
+	graphQLMaxPage        = 500
+)
+
+type graphQLRequest struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName,omitempty"`
+	Variables     map[string]any `json:"variables,omitempty"`
+}
+
+// graphQLResponse documents the response; errors carry the API error code in
+// extensions.code.
+type graphQLResponse struct {
+	Data   map[string]any          `json:"data,omitempty"`
+	Errors []*gqlerrors.QueryError `json:"errors,omitempty"`
+}
+
+func (h *Handler) newGraphQLSchema() *graphql.Schema {
+	return graphql.MustParseSchema(graphQLSchema, &graphQLResolver{h: h},
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(graphQLMaxDepth),
+		graphql.MaxQueryLength(graphQLMaxQueryLength),
+	)
+}
+
+// handleGraphQL serves queries and mutations over POST only, so mutations
+// can't be triggered by a cross-site GET.
+func (h *Handler) handleGraphQL(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	var req graphQLRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, graphQLMaxBody)).Decode(&req); err != nil || req.Query == "" {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	// GraphQL reports errors in the body, so the status is 200 even when
+	// some or all fields failed.
+	writeJSON(w, http.StatusOK, h.graphQL.Exec(r.Context(), req.Query, req.OperationName, req.Variables))
+}
+
+// graphQLError is a resolver error with an API error code, returned in the
+// error's extensions so clients can branch on it as they do on REST errors.
+type graphQLError struct {
+	code    apierror.Code
+	message string
+}
+
+func (e *graphQLError) Error() string { return e.message }
+
+func (e *graphQLError) Extensions() map[string]any {
+	return map[string]any{"code": e.code.Name}
+}
+
+var errCatalogUnavailable = &graphQLError{apierror.Unavailable, "The document catalog is not available"}
+
+type graphQLResolver struct {
+	h *Handler
+}
+
+type documentsArgs struct {
+	SpaceKeys *[]string
+	First     int32
+	Offset    int32
+}
+
+func (r *graphQLResolver) Documents(ctx context.Context, args documentsArgs) (*documentPageResolver, error) {
+	first, offset := int(args.First), int(args.Offset)
+	if first < 1 || first > graphQLMaxPage || offset < 0 {
+		return nil, &graphQLError{apierror.InvalidArgument, "first must be between 1 and 500 and offset not negative"}
+	}
+	docs, err := r.documents(ctx, deref(args.SpaceKeys), nil)
+	if err != nil {
+		return nil, err
+	}
+	page := &documentPageResolver{total: len(docs)}
+	if offset < len(docs) {
+		page.documents = docs[offset:min(offset+first, len(docs))]
+	}
+	return page, nil
+}
+
+func (r *graphQLResolver) Document(ctx context.Context, args struct{ ID graphql.ID }) (*documentResolver, error) {
+	docs, err := r.documents(ctx, nil, []string{string(args.ID)})
+	if err != nil || len(docs) == 0 {
+		return nil, err
+	}
+	return &documentResolver{docs[0]}, nil
+}
+
+func (r *graphQLResolver) Spaces(ctx context.Context) ([]*spaceResolver, error) {
+	if r.h.services.Catalog == nil {
+		return nil, errCatalogUnavailable
+	}
+	filter, err := r.filter(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	spaces, err := r.h.services.Catalog.Spaces(ctx, filter)
+	if err != nil {
+		return nil, internalError("Listing spaces", err)
+	}
+	out := make([]*spaceResolver, len(spaces))
+	for i, s := range spaces {
+		out[i] = &spaceResolver{s}
+	}
+	return out, nil
+}
+
+func (r *graphQLResolver) SyncJobs(ctx context.Context) ([]*syncJobResolver, error) {
+	if err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	jobs := r.h.services.Reindex.Jobs()
+	out := make([]*syncJobResolver, len(jobs))
+	for i, j := range jobs {
+		out[i] = &syncJobResolver{j}
+	}
+	return out, nil
+}
+
+func (r *graphQLResolver) SyncJob(ctx context.Context, args struct{ ID graphql.ID }) (*syncJobResolver, error) {
+	if err := r.requireAdmin(ctx); err != nil {
+		return nil, err
+	}
+	job, ok := r.h.services.Reindex.Job(string(args.ID))
+	if !ok {
+		return nil, nil
+	}
+	return &syncJobResolver{job}, nil
+}
+
+type askInput struct {
+	Question  string
+	SessionID *string
+	Mode      *string
+	SpaceKeys *[]string
+	TopK      *int32
+	Language  *string
+	History   *[]messageInput
+}
+
+type messageInput struct {
+	Role    string
+	Content string
+}
+
+func (r *graphQLResolver) Ask(ctx context.Context, args struct{ Input askInput }) (*askPayloadResolver, error) {
+	in := args.Input
+	q := domain.Question{
+		Text:      in.Question,
+		Mode:      domain.QueryMode(deref(in.Mode)),
+		SessionID: deref(in.SessionID),
+		TopK:      int(deref(in.TopK)),
+		SpaceKeys: deref(in.SpaceKeys),
+		Language:  deref(in.Language),
+	}
+	for _, m := range deref(in.History) {
+		q.History = append(q.History, domain.Message{Role: domain.Role(m.Role), Content: m.Content})
+	}
+	if err := r.h.restrict(ctx, &q); err != nil {
+		code, message := restrictError(err)
+		return nil, &graphQLError{code, message}
+	}
+	answer, variants, err := r.h.ask(ctx, &q)
+	if err != nil {
+		code, message := askError(err)
+		return nil, &graphQLError{code, message}
+	}
+	return &askPayloadResolver{answer: answer, sessionID: q.SessionID, variants: variants}, nil
+}
+
+// filter limits listings to what the caller may read, as questions are.
+func (r *graphQLResolver) filter(ctx context.Context, spaceKeys []string) (domain.SearchFilter, error) {
+	q := domain.Question{SpaceKeys: spaceKeys}
+	if err := r.h.restrict(ctx, &q); err != nil {
+		code, message := restrictError(err)
+		return domain.SearchFilter{}, &graphQLError{code, message}
+	}
+	return domain.SearchFilter{SpaceKeys: q.SpaceKeys, Readers: q.Readers}, nil
+}
+
+func (r *graphQLResolver) documents(ctx context.Context, spaceKeys, documentIDs []string) ([]catalog.Document, error) {
+	if r.h.services.Catalog == nil {
+		return nil, errCatalogUnavailable
+	}
+	filter, err := r.filter(ctx, spaceKeys)
+	if err != nil {
+		return nil, err
+	}
+	filter.DocumentIDs = documentIDs
+	docs, err := r.h.services.Catalog.Documents(ctx, filter)
+	if err != nil {
+		return nil, internalError("Listing documents", err)
+	}
+	return docs, nil
+}
+
+// requireAdmin restricts reindex jobs to admins, as the admin API does.
+func (r *graphQLResolver) requireAdmin(ctx context.Context) error {
+	if r.h.services.Reindex == nil {
+		return &graphQLError{apierror.Unavailable, "Ingestion is not available"}
+	}
+	if r.h.services.Auth == nil {
+		return nil
+	}
+	if principal := auth.PrincipalFromContext(ctx); principal == nil || !principal.HasScope(domain.ScopeAdmin) {
+		return &graphQLError{apierror.Forbidden, "Forbidden: " + string(domain.ScopeAdmin) + " scope required"}
+	}
+	return nil
+}
+
+func internalError(action string, err error) error {
+	log.Printf("%s failed: %v\n", action, err)
+	return &graphQLError{apierror.Internal, action + " failed"}
+}
+
+func deref[T any](p *T) T {
+	var zero T
+	if p == nil {
+		return zero
+	}
+	return *p
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+type documentPageResolver struct {
+	total     int
+	documents []catalog.Document
+}
+
+func (p *documentPageResolver) Total() int32 { return int32(p.total) }
+
+func (p *documentPageResolver) Documents() []*documentResolver {
+	out := make([]*documentResolver, len(p.documents))
+	for i, d := range p.documents {
+		out[i] = &documentResolver{d}
+	}
+	return out
+}
+
+type documentResolver struct{ d catalog.Document }
+
+func (r *documentResolver) ID() graphql.ID          { return graphql.ID(r.d.ID) }
+func (r *documentResolver) Title() string           { return r.d.Title }
+func (r *documentResolver) SpaceKey() string        { return r.d.SpaceKey }
+func (r *documentResolver) URL() string             { return r.d.URL }
+func (r *documentResolver) Chunks() int32           { return int32(r.d.Chunks) }
+func (r *documentResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.d.UpdatedAt} }
+
+type spaceResolver struct{ s catalog.Space }
+
+func (r *spaceResolver) Key() string             { return r.s.Key }
+func (r *spaceResolver) Documents() int32        { return int32(r.s.Documents) }
+func (r *spaceResolver) Chunks() int32           { return int32(r.s.Chunks) }
+func (r *spaceResolver) UpdatedAt() graphql.Time { return graphql.Time{Time: r.s.UpdatedAt} }
+
+type syncJobResolver struct{ j reindex.Job }
+
+func (r *syncJobResolver) ID() graphql.ID          { return graphql.ID(r.j.ID) }
+func (r *syncJobResolver) SpaceKey() *string       { return optional(r.j.SpaceKey) }
+func (r *syncJobResolver) Status() string          { return string(r.j.Status) }
+func (r *syncJobResolver) Documents() int32        { return int32(r.j.Documents) }
+func (r *syncJobResolver) Processed() int32        { return int32(r.j.Processed) }
+func (r *syncJobResolver) Failed() int32           { return int32(r.j.Failed) }
+func (r *syncJobResolver) StartedAt() graphql.Time { return graphql.Time{Time: r.j.StartedAt} }
+
+func (r *syncJobResolver) FinishedAt() *graphql.Time {
+	if r.j.FinishedAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.j.FinishedAt}
+}
+
+func (r *syncJobResolver) ETA() *graphql.Time {
+	if r.j.ETA == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.j.ETA}
+}
+
+type askPayloadResolver struct {
+	answer    *domain.Answer
+	sessionID string
+	variants  map[string]string
+}
+
+func (r *askPayloadResolver) SessionID() string   { return r.sessionID }
+func (r *askPayloadResolver) Answer() string      { return r.answer.Text }
+func (r *askPayloadResolver) Language() *string   { return optional(r.answer.Language) }
+func (r *askPayloadResolver) Model() string       { return r.answer.Model }
+func (r *askPayloadResolver) Moderation() *string { return optional(string(r.answer.Moderation)) }
+func (r *askPayloadResolver) LatencyMs() int32    { return int32(r.answer.Latency.Milliseconds()) }
+func (r *askPayloadResolver) Usage() *usageResolver {
+	return &usageResolver{r.answer.Usage}
+}
+
+func (r *askPayloadResolver) Citations() []*citationResolver {
+	out := make([]*citationResolver, len(r.answer.Citations))
+	for i, c := range r.answer.Citations {
+		out[i] = &citationResolver{c}
+	}
+	return out
+}
+
+func (r *askPayloadResolver) Sections() []*sectionResolver {
+	out := make([]*sectionResolver, len(r.answer.Sections))
+	for i, s := range r.answer.Sections {
+		out[i] = &sectionResolver{s}
+	}
+	return out
+}
+
+func (r *askPayloadResolver) Variants() []*variantResolver {
+	out := make([]*variantResolver, 0, len(r.variants))
+	for experiment, variant := range r.variants {
+		out = append(out, &variantResolver{experiment, variant})
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].experiment < out[j].experiment })
+	return out
+}
+
+type citationResolver struct{ c domain.Citation }
+
+func (r *citationResolver) DocumentID() graphql.ID { return graphql.ID(r.c.DocumentID) }
+func (r *citationResolver) Title() string          { return r.c.Title }
+func (r *citationResolver) URL() string            { return r.c.URL }
+func (r *citationResolver) Score() float64         { return r.c.Score }
+
+type sectionResolver struct{ s domain.AnswerSection }
+
+func (r *sectionResolver) Question() string { return r.s.Question }
+func (r *sectionResolver) Answer() string   { return r.s.Answer }
+
+func (r *sectionResolver) Citations() []int32 {
+	out := make([]int32, len(r.s.Citations))
+	for i, c := range r.s.Citations {
+		out[i] = int32(c)
+	}
+	return out
+}
+
+type usageResolver struct{ u domain.Usage }
+
+func (r *usageResolver) PromptTokens() int32     { return int32(r.u.PromptTokens) }
+func (r *usageResolver) CompletionTokens() int32 { return int32(r.u.CompletionTokens) }
+func (r *usageResolver) TotalTokens() int32      { return int32(r.u.TotalTokens) }
+
+type variantResolver struct {
+	experiment string
+	variant    string
+}
+
+func (r *variantResolver) Experiment() string { return r.experiment }
+func (r *variantResolver) Variant() string    { return r.variant }
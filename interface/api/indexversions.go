@@ -0,0 +1,130 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/indexversion"
+)
+
+type indexVersionsResponse struct {
+	Versions []indexversion.Version `json:"versions"`
+	Active   string                 `json:"active"`
+	// Previous is the version Rollback serves again.
+	Previous string `json:"previous,omitempty"`
+}
+
+type buildIndexVersionRequest struct {
+	Name string `json:"name"`
+	// Settings left zero are taken from the version being served.
+	indexversion.Settings
+}
+
+type activateIndexVersionRequest struct {
+	Name string `json:"name"`
+}
+
+// handleIndexVersions lists index versions, starts building one and drops one.
+func (h *Handler) handleIndexVersions(w http.ResponseWriter, r *http.Request) {
+	if h.services.IndexVersions == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Index versions need the memory or qdrant vector store")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		versions, active, previous := h.services.IndexVersions.Versions()
+		writeJSON(w, http.StatusOK, indexVersionsResponse{Versions: versions, Active: active, Previous: previous})
+
+	case http.MethodPost:
+		var req buildIndexVersionRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+			return
+		}
+		// The build outlives the request; the version's status reports when it ends.
+		version, err := h.services.IndexVersions.Build(context.WithoutCancel(r.Context()), req.Name, req.Settings)
+		if err != nil {
+			writeIndexVersionError(w, "Failed to start building index version", err)
+			return
+		}
+		log.Printf("Building index version %s\n", version.Name)
+		writeJSON(w, http.StatusAccepted, version)
+
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			apierror.Write(w, apierror.InvalidArgument, "Missing name parameter")
+			return
+		}
+		if err := h.services.IndexVersions.Drop(context.WithoutCancel(r.Context()), name); err != nil {
+			writeIndexVersionError(w, "Failed to drop index version", err)
+			return
+		}
+		log.Printf("Dropped index version %s\n", name)
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleActivateIndexVersion switches the index served to a built version.
+func (h *Handler) handleActivateIndexVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.IndexVersions == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Index versions need the memory or qdrant vector store")
+		return
+	}
+	var req activateIndexVersionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Name == "" {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	version, err := h.services.IndexVersions.Activate(req.Name)
+	if err != nil {
+		writeIndexVersionError(w, "Failed to activate index version", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, version)
+}
+
+// handleRollbackIndexVersion serves the previously active version again.
+func (h *Handler) handleRollbackIndexVersion(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.IndexVersions == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Index versions need the memory or qdrant vector store")
+		return
+	}
+	version, err := h.services.IndexVersions.Rollback()
+	if err != nil {
+		writeIndexVersionError(w, "Failed to roll back index version", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, version)
+}
+
+func writeIndexVersionError(w http.ResponseWriter, msg string, err error) {
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		apierror.Write(w, apierror.NotFound, err.Error())
+	case errors.Is(err, indexversion.ErrInvalid):
+		apierror.Write(w, apierror.InvalidArgument, err.Error())
+	case errors.Is(err, indexversion.ErrBuilding), errors.Is(err, indexversion.ErrExists),
+		errors.Is(err, indexversion.ErrNotReady), errors.Is(err, indexversion.ErrActive):
+		apierror.Write(w, apierror.Conflict, err.Error())
+	default:
+		log.Printf("%s: %v\n", msg, err)
+		apierror.Write(w, apierror.Internal, msg)
+	}
+}
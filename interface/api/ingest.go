@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+type ingestRequest struct {
+	Action     domain.IngestAction `json:"action"`
+	DocumentID string              `json:"document_id"`
+	Source     string              `json:"source,omitempty"`
+}
+
+type ingestResponse struct {
+	ID string `json:"id"`
+}
+
+// handleIngest accepts a change notification from API clients, processed the
+// same way as a webhook event.
+func (h *Handler) handleIngest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Ingest == nil {
+		apierror.Write(w, apierror.Unavailable, "Ingestion is not available")
+		return
+	}
+
+	var req ingestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	if req.DocumentID == "" || (req.Action != domain.IngestUpsert && req.Action != domain.IngestDelete) {
+		apierror.Write(w, apierror.InvalidArgument, "document_id is required and action must be upsert or delete")
+		return
+	}
+	if req.Source == "" {
+		req.Source = "confluence"
+	}
+
+	event := domain.IngestEvent{
+		ID:         id.New(),
+		Source:     req.Source,
+		Action:     req.Action,
+		DocumentID: req.DocumentID,
+		RawType:    "api",
+		ReceivedAt: time.Now().UTC(),
+	}
+	// A tenant's events only reach documents in its spaces.
+	if tenantID := callerTenant(r.Context()); tenantID != "" {
+		spaces, err := h.services.Tenants.AllowedSpaces(tenantID, nil)
+		if err != nil {
+			apierror.Write(w, apierror.Forbidden, err.Error())
+			return
+		}
+		event.Spaces = spaces
+	}
+	h.services.Ingest(r.Context(), event)
+	writeJSON(w, http.StatusAccepted, ingestResponse{ID: event.ID})
+}
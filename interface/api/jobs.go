@@ -0,0 +1,85 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/reindex"
+)
+
+type jobsResponse struct {
+	Jobs []reindex.Job `json:"jobs"`
+}
+
+type jobRequest struct {
+	JobID string `json:"job_id"`
+}
+
+// handleJobs lists the recent sync jobs of this instance, newest first, or
+// the one named by id.
+func (h *Handler) handleJobs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Reindex == nil {
+		apierror.Write(w, apierror.Unavailable, "Ingestion is not available")
+		return
+	}
+	if jobID := r.URL.Query().Get("id"); jobID != "" {
+		job, ok := h.services.Reindex.Job(jobID)
+		if !ok {
+			apierror.Write(w, apierror.NotFound, reindex.ErrJobNotFound.Error())
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+		return
+	}
+	writeJSON(w, http.StatusOK, jobsResponse{Jobs: h.services.Reindex.Jobs()})
+}
+
+func (h *Handler) handlePauseJob(w http.ResponseWriter, r *http.Request) {
+	h.changeJob(w, r, "pause", (*reindex.Service).Pause)
+}
+
+func (h *Handler) handleResumeJob(w http.ResponseWriter, r *http.Request) {
+	h.changeJob(w, r, "resume", (*reindex.Service).Resume)
+}
+
+func (h *Handler) handleCancelJob(w http.ResponseWriter, r *http.Request) {
+	h.changeJob(w, r, "cancel", (*reindex.Service).Cancel)
+}
+
+func (h *Handler) changeJob(w http.ResponseWriter, r *http.Request, verb string, change func(*reindex.Service, string) (reindex.Job, error)) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Reindex == nil {
+		apierror.Write(w, apierror.Unavailable, "Ingestion is not available")
+		return
+	}
+	var req jobRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.JobID == "" {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	job, err := change(h.services.Reindex, req.JobID)
+	switch {
+	case errors.Is(err, reindex.ErrJobNotFound):
+		apierror.Write(w, apierror.NotFound, err.Error())
+		return
+	case errors.Is(err, reindex.ErrJobState):
+		apierror.Write(w, apierror.Conflict, err.Error())
+		return
+	case err != nil:
+		log.Printf("Job %s: %s failed: %v\n", req.JobID, verb, err)
+		apierror.Write(w, apierror.Internal, "Failed to "+verb+" job")
+		return
+	}
+	log.Printf("Reindex %s: %s requested\n", req.JobID, verb)
+	writeJSON(w, http.StatusOK, job)
+}
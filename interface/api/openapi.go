@@ -0,0 +1,210 @@
+package api
+
+import (
+	_ "embed"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+func handleDocs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsPage)
+}
+
+func (h *Handler) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	writeJSON(w, http.StatusOK, h.openAPISpec())
+}
+
+// openAPISpec describes the registered routes; schemas are derived from the
+// request and response types by reflection, following their json tags.
+func (h *Handler) openAPISpec() map[string]any {
+	b := &schemaBuilder{schemas: map[string]any{}, names: map[reflect.Type]string{}}
+	paths := map[string]any{}
+	for _, rt := range h.routes() {
+		item := map[string]any{}
+		for _, op := range rt.Operations {
+			item[strings.ToLower(op.Method)] = b.operation(rt, op, h.services.Auth != nil)
+		}
+		paths[rt.Path] = item
+	}
+
+	codes := apierror.Codes()
+	names := make([]string, len(codes))
+	for i, c := range codes {
+		names[i] = c.Name
+	}
+	if problem, ok := b.schemas["Problem"].(map[string]any); ok {
+		problem["properties"].(map[string]any)["code"] = map[string]any{"type": "string", "enum": names}
+	}
+
+	components := map[string]any{"schemas": b.schemas}
+	if h.services.Auth != nil {
+		components["securitySchemes"] = map[string]any{
+			"apiKey": map[string]any{"type": "apiKey", "in": "header", "name": "X-API-Key"},
+			"bearer": map[string]any{"type": "http", "scheme": "bearer", "description": "API key or OIDC access token"},
+		}
+	}
+	return map[string]any{
+		"openapi":    "3.0.3",
+		"info":       map[string]any{"title": "Sarama AI API", "version": "1"},
+		"paths":      paths,
+		"components": components,
+	}
+}
+
+func (b *schemaBuilder) operation(rt route, op operation, secured bool) map[string]any {
+	status := op.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	response := map[string]any{"description": http.StatusText(status)}
+	if op.Response != nil {
+		response["content"] = jsonContent(b.schema(reflect.TypeOf(op.Response)))
+	}
+	failure := map[string]any{"description": "Error", "content": jsonContent(b.schema(reflect.TypeOf(apierror.Envelope{})))}
+	out := map[string]any{
+		"summary":          op.Summary,
+		"tags":             []string{tag(rt.Path)},
+		"responses":        map[string]any{strconv.Itoa(status): response, "default": failure},
+		"x-required-scope": rt.Scope,
+	}
+	if rt.Deprecation != nil {
+		out["deprecated"] = true
+	}
+	if op.Request != nil {
+		out["requestBody"] = map[string]any{"required": true, "content": jsonContent(b.schema(reflect.TypeOf(op.Request)))}
+	}
+	if len(op.Params) > 0 {
+		params := make([]map[string]any, len(op.Params))
+		for i, p := range op.Params {
+			params[i] = map[string]any{"name": p.Name, "in": "query", "required": p.Required, "schema": map[string]any{"type": p.Type}}
+			if p.Description != "" {
+				params[i]["description"] = p.Description
+			}
+		}
+		out["parameters"] = params
+	}
+	if secured {
+		out["security"] = []map[string][]string{{"apiKey": {}}, {"bearer": {}}}
+	}
+	return out
+}
+
+func jsonContent(schema map[string]any) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+type schemaBuilder struct {
+	schemas map[string]any
+	names   map[reflect.Type]string
+}
+
+var (
+	timeType     = reflect.TypeOf(time.Time{})
+	durationType = reflect.TypeOf(time.Duration(0))
+)
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t {
+	case timeType:
+		return map[string]any{"type": "string", "format": "date-time"}
+	case durationType:
+		return map[string]any{"type": "integer", "description": "nanoseconds"}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + b.component(t)}
+	}
+	return map[string]any{}
+}
+
+// component registers a named struct once and returns its schema name.
+func (b *schemaBuilder) component(t reflect.Type) string {
+	if name, ok := b.names[t]; ok {
+		return name
+	}
+	name := t.Name()
+	if _, taken := b.schemas[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = pkg + "." + name
+	}
+	b.names[t] = name
+	b.schemas[name] = map[string]any{} // placeholder for recursive types
+	b.schemas[name] = b.object(t)
+	return name
+}
+
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	b.addFields(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (b *schemaBuilder) addFields(t reflect.Type, props map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct {
+			b.addFields(ft, props)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = b.schema(f.Type)
+	}
+}
+
+// tag groups operations by API version, or "admin".
+func tag(path string) string {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if parts[0] == "api" && len(parts) > 1 {
+		return parts[1]
+	}
+	return parts[0]
+}
@@ -0,0 +1,195 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/breaker"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/llm"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+	"github.com/shubhamgptln/sarama-ai/usecase/access"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+	"github.com/shubhamgptln/sarama-ai/usecase/rbac"
+	"github.com/shubhamgptln/sarama-ai/usecase/tenant"
+)
+
+type queryResponse struct {
+	*domain.Answer
+	SessionID string            `json:"session_id"`
+	Variants  map[string]string `json:"variants,omitempty"`
+	LatencyMS int64             `json:"latency_ms"`
+}
+
+func (h *Handler) handleQuery(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var q domain.Question
+	if err := json.NewDecoder(r.Body).Decode(&q); err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	answer, variants, ok := h.answer(w, r, &q)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, queryResponse{
+		Answer:    answer,
+		SessionID: q.SessionID,
+		Variants:  variants,
+		LatencyMS: answer.Latency.Milliseconds(),
+	})
+}
+
+var errInvalidMode = errors.New("mode must be standard or deep_research")
+
+// answer authorizes and answers q for any API version; on failure it has
+// already written the error response.
+func (h *Handler) answer(w http.ResponseWriter, r *http.Request, q *domain.Question) (*domain.Answer, map[string]string, bool) {
+	if err := h.restrict(r.Context(), q); err != nil {
+		code, message := restrictError(err)
+		apierror.Write(w, code, message)
+		return nil, nil, false
+	}
+	answer, variants, err := h.ask(r.Context(), q)
+	if err != nil {
+		code, message := askError(err)
+		apierror.Write(w, code, message)
+		return nil, nil, false
+	}
+	return answer, variants, true
+}
+
+// restrict narrows q to the spaces and pages the caller may read.
+func (h *Handler) restrict(ctx context.Context, q *domain.Question) error {
+	principal := auth.PrincipalFromContext(ctx)
+	if principal != nil && principal.Tenant != "" {
+		if h.services.Tenants == nil {
+			return tenant.ErrUnknownTenant
+		}
+		spaces, err := h.services.Tenants.AllowedSpaces(principal.Tenant, q.SpaceKeys)
+		if err != nil {
+			return err
+		}
+		q.SpaceKeys, q.Tenant = spaces, principal.Tenant
+	}
+	spaces, err := rbac.AllowedSpaces(principal, q.SpaceKeys)
+	if err != nil {
+		return err
+	}
+	q.SpaceKeys = spaces
+	if h.services.Access != nil {
+		return h.services.Access.Restrict(ctx, principal, q)
+	}
+	return nil
+}
+
+func restrictError(err error) (apierror.Code, string) {
+	if errors.Is(err, rbac.ErrSpaceForbidden) || errors.Is(err, access.ErrNoReadableSpaces) ||
+		errors.Is(err, tenant.ErrSpaceForbidden) || errors.Is(err, tenant.ErrUnknownTenant) {
+		return apierror.Forbidden, err.Error()
+	}
+	log.Printf("Resolving document access failed: %v\n", err)
+	return apierror.Unavailable, "Authorization unavailable"
+}
+
+// ask answers an already restricted q and records it for auditing.
+func (h *Handler) ask(ctx context.Context, q *domain.Question) (*domain.Answer, map[string]string, error) {
+	if q.SessionID == "" {
+		q.SessionID = id.New()
+	} else if len(q.History) == 0 {
+		q.History = h.conversation(ctx, q.SessionID)
+	}
+
+	service := h.services.Query
+	var variants map[string]string
+	if h.services.Experiments != nil {
+		var cfg query.Config
+		cfg, variants = h.services.Experiments.Apply(service.Config(), q.SessionID)
+		service = service.WithConfig(cfg)
+	}
+	// A tenant's own configuration wins over experiments run across tenants.
+	if q.Tenant != "" {
+		if err := h.services.Tenants.TakeQuery(ctx, q.Tenant); err != nil {
+			return nil, nil, err
+		}
+		service = service.WithConfig(h.services.Tenants.Apply(service.Config(), q.Tenant))
+	}
+
+	start := time.Now()
+	var answer *domain.Answer
+	var err error
+	switch q.Mode {
+	case "", domain.ModeStandard:
+		answer, err = service.Ask(ctx, *q)
+	case domain.ModeDeepResearch:
+		answer, err = h.services.Research.WithAsker(service).Research(ctx, *q)
+	default:
+		return nil, nil, errInvalidMode
+	}
+	if h.services.Audit != nil {
+		h.services.Audit.Record(ctx, *q, answer, variants, time.Since(start), err)
+	}
+	if err == nil {
+		h.remember(ctx, q.SessionID, q.Text, answer.Text)
+	}
+	return answer, variants, err
+}
+
+// conversation returns the stored turns of the caller's session.
+func (h *Handler) conversation(ctx context.Context, sessionID string) []domain.Message {
+	if h.services.Conversations == nil || h.services.ConversationHistory <= 0 {
+		return nil
+	}
+	history, err := h.services.Conversations.Messages(ctx, conversationKey(ctx, sessionID), h.services.ConversationHistory)
+	if err != nil {
+		log.Printf("Loading conversation %s failed: %v\n", sessionID, err)
+	}
+	return history
+}
+
+func (h *Handler) remember(ctx context.Context, sessionID, question, answer string) {
+	if h.services.Conversations == nil || h.services.ConversationHistory <= 0 {
+		return
+	}
+	err := h.services.Conversations.AppendMessages(ctx, conversationKey(ctx, sessionID),
+		domain.Message{Role: domain.RoleUser, Content: question},
+		domain.Message{Role: domain.RoleAssistant, Content: answer})
+	if err != nil {
+		log.Printf("Saving conversation %s failed: %v\n", sessionID, err)
+	}
+}
+
+// conversationKey scopes a session to its caller, so guessing another
+// caller's session ID doesn't bring their conversation into an answer.
+func conversationKey(ctx context.Context, sessionID string) string {
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		return p.ID + "/" + sessionID
+	}
+	return sessionID
+}
+
+func askError(err error) (apierror.Code, string) {
+	switch {
+	case errors.Is(err, errInvalidMode), errors.Is(err, query.ErrEmptyQuestion), errors.Is(err, query.ErrInvalidLanguage):
+		return apierror.InvalidArgument, err.Error()
+	case errors.Is(err, query.ErrQuestionBlocked):
+		return apierror.Unprocessable, err.Error()
+	case errors.Is(err, tenant.ErrQuotaExceeded):
+		return apierror.RateLimited, err.Error()
+	case errors.Is(err, breaker.ErrOpen), errors.Is(err, llm.ErrBusy):
+		log.Printf("Query failed: %v\n", err)
+		return apierror.Unavailable, "A service the answer depends on is unavailable, try again later"
+	}
+	log.Printf("Query failed: %v\n", err)
+	return apierror.Upstream, "Failed to answer question"
+}
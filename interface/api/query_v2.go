@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+// v2 separates the question from retrieval filters and generation options, and
+// the answer from request metadata.
+type queryRequestV2 struct {
+	Question  string           `json:"question"`
+	SessionID string           `json:"session_id,omitempty"`
+	Mode      domain.QueryMode `json:"mode,omitempty"`
+	Filters   queryFiltersV2   `json:"filters"`
+	Options   queryOptionsV2   `json:"options"`
+	History   []domain.Message `json:"history,omitempty"`
+}
+
+type queryFiltersV2 struct {
+	SpaceKeys []string `json:"space_keys,omitempty"`
+}
+
+type queryOptionsV2 struct {
+	TopK     int    `json:"top_k,omitempty"`
+	Language string `json:"language,omitempty"`
+}
+
+type queryResponseV2 struct {
+	SessionID string      `json:"session_id"`
+	Answer    answerV2    `json:"answer"`
+	Meta      queryMetaV2 `json:"meta"`
+}
+
+type answerV2 struct {
+	Text      string                 `json:"text"`
+	Language  string                 `json:"language,omitempty"`
+	Citations []domain.Citation      `json:"citations"`
+	Sections  []domain.AnswerSection `json:"sections,omitempty"`
+}
+
+type queryMetaV2 struct {
+	Model      string                  `json:"model"`
+	Usage      domain.Usage            `json:"usage"`
+	Moderation domain.ModerationAction `json:"moderation,omitempty"`
+	LatencyMS  int64                   `json:"latency_ms"`
+	Variants   map[string]string       `json:"variants,omitempty"`
+}
+
+func (h *Handler) handleQueryV2(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	var req queryRequestV2
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	q := domain.Question{
+		Text:      req.Question,
+		Mode:      req.Mode,
+		SessionID: req.SessionID,
+		TopK:      req.Options.TopK,
+		SpaceKeys: req.Filters.SpaceKeys,
+		Language:  req.Options.Language,
+		History:   req.History,
+	}
+	answer, variants, ok := h.answer(w, r, &q)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, queryResponseV2{
+		SessionID: q.SessionID,
+		Answer: answerV2{
+			Text:      answer.Text,
+			Language:  answer.Language,
+			Citations: answer.Citations,
+			Sections:  answer.Sections,
+		},
+		Meta: queryMetaV2{
+			Model:      answer.Model,
+			Usage:      answer.Usage,
+			Moderation: answer.Moderation,
+			LatencyMS:  answer.Latency.Milliseconds(),
+			Variants:   variants,
+		},
+	})
+}
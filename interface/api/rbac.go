@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/rbac"
+)
+
+type roleResponse struct {
+	Role   domain.AccessRole `json:"role"`
+	Scopes []domain.Scope    `json:"scopes"`
+}
+
+func (h *Handler) handleRoles(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	var roles []roleResponse
+	for _, role := range domain.AccessRoles() {
+		roles = append(roles, roleResponse{Role: role, Scopes: role.Scopes()})
+	}
+	writeJSON(w, http.StatusOK, roles)
+}
+
+type roleBindingRequest struct {
+	Subject string            `json:"subject"`
+	Role    domain.AccessRole `json:"role"`
+	Spaces  []string          `json:"spaces,omitempty"`
+}
+
+func (h *Handler) handleRoleBindings(w http.ResponseWriter, r *http.Request) {
+	if h.services.RBAC == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Authentication is disabled")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		bindings, err := h.services.RBAC.Bindings(r.Context(), r.URL.Query().Get("subject"))
+		if err != nil {
+			log.Printf("Listing role bindings failed: %v\n", err)
+			apierror.Write(w, apierror.Internal, "Failed to list role bindings")
+			return
+		}
+		writeJSON(w, http.StatusOK, bindings)
+
+	case http.MethodPost:
+		var req roleBindingRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+			return
+		}
+		if req.Subject == "" || !req.Role.Valid() {
+			apierror.Write(w, apierror.InvalidArgument, "subject is required and role must be reader, ingester or admin")
+			return
+		}
+		binding, err := h.services.RBAC.Bind(r.Context(), req.Subject, req.Role, req.Spaces)
+		if errors.Is(err, rbac.ErrInvalidBinding) {
+			apierror.Write(w, apierror.InvalidArgument, err.Error())
+			return
+		}
+		if err != nil {
+			log.Printf("Creating role binding failed: %v\n", err)
+			apierror.Write(w, apierror.Internal, "Failed to create role binding")
+			return
+		}
+		writeJSON(w, http.StatusCreated, binding)
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			apierror.Write(w, apierror.InvalidArgument, "Missing id parameter")
+			return
+		}
+		err := h.services.RBAC.Unbind(r.Context(), id)
+		if errors.Is(err, domain.ErrRoleBindingNotFound) {
+			apierror.Write(w, apierror.NotFound, "Role binding not found")
+			return
+		}
+		if err != nil {
+			log.Printf("Deleting role binding %s failed: %v\n", id, err)
+			apierror.Write(w, apierror.Internal, "Failed to delete role binding")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/related"
+)
+
+const (
+	defaultRelatedLimit = 5
+	maxRelatedLimit     = 50
+)
+
+type relatedResponse struct {
+	PageID  string         `json:"page_id"`
+	Related []related.Page `json:"related"`
+}
+
+func (h *Handler) handleRelated(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+
+	pageID := r.URL.Query().Get("page_id")
+	if pageID == "" {
+		apierror.Write(w, apierror.InvalidArgument, "page_id is required")
+		return
+	}
+
+	limit, err := intParam(r, "limit", defaultRelatedLimit, 1, maxRelatedLimit)
+	if err != nil {
+		apierror.Write(w, apierror.InvalidArgument, err.Error())
+		return
+	}
+
+	// Related pages are limited to what the caller may read, as answers are.
+	q := domain.Question{}
+	if err := h.restrict(r.Context(), &q); err != nil {
+		code, message := restrictError(err)
+		apierror.Write(w, code, message)
+		return
+	}
+	filter := domain.SearchFilter{SpaceKeys: q.SpaceKeys, Readers: q.Readers}
+	pages, err := h.services.Related.Related(r.Context(), pageID, limit, filter)
+	if errors.Is(err, domain.ErrNotFound) {
+		apierror.Write(w, apierror.NotFound, "Page is not indexed")
+		return
+	}
+	if err != nil {
+		log.Printf("Related pages lookup failed: %v\n", err)
+		apierror.Write(w, apierror.Upstream, "Failed to load related pages")
+		return
+	}
+
+	// Widgets embedded in Confluence fetch this cross-origin and can cache it
+	// briefly; shared caches only when it's the same for every caller.
+	if len(filter.SpaceKeys) > 0 || len(filter.Readers) > 0 {
+		w.Header().Set("Cache-Control", "private, max-age=300")
+	} else {
+		w.Header().Set("Cache-Control", "public, max-age=300")
+	}
+	writeJSON(w, http.StatusOK, relatedResponse{PageID: pageID, Related: pages})
+}
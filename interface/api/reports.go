@@ -0,0 +1,57 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/content"
+	"github.com/shubhamgptln/sarama-ai/usecase/digest"
+)
+
+type statusResponse struct {
+	Status string `json:"status"`
+}
+
+func (h *Handler) handleContentReport(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		report := h.services.Auditor.LastReport()
+		if report == nil {
+			apierror.Write(w, apierror.NotFound, "No content report has been generated yet")
+			return
+		}
+		writeJSON(w, http.StatusOK, report)
+
+	case http.MethodPost:
+		// Scanning a large index takes a while, so run detached and let callers poll GET.
+		err := h.services.Auditor.Trigger(context.WithoutCancel(r.Context()))
+		if errors.Is(err, content.ErrRunning) {
+			apierror.Write(w, apierror.Conflict, err.Error())
+			return
+		}
+		writeJSON(w, http.StatusAccepted, statusResponse{Status: "running"})
+
+	default:
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleDigest sends the email digest now instead of waiting for the schedule.
+func (h *Handler) handleDigest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Digest == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Email digests are disabled")
+		return
+	}
+	err := h.services.Digest.Trigger(context.WithoutCancel(r.Context()))
+	if errors.Is(err, digest.ErrRunning) {
+		apierror.Write(w, apierror.Conflict, err.Error())
+		return
+	}
+	writeJSON(w, http.StatusAccepted, statusResponse{Status: "running"})
+}
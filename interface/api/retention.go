@@ -0,0 +1,29 @@
+package api
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+// handlePurgeRetention runs the scheduled purge now, e.g. after a retention
+// was shortened.
+func (h *Handler) handlePurgeRetention(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Retention == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Retention is not configured")
+		return
+	}
+	res, err := h.services.Retention.Purge(r.Context())
+	if err != nil {
+		log.Printf("Purging expired records failed: %v\n", err)
+		apierror.Write(w, apierror.Internal, "Failed to purge expired records")
+		return
+	}
+	log.Printf("Purged %d conversation messages and %d feedback entries\n", res.Conversations, res.Feedback)
+	writeJSON(w, http.StatusOK, res)
+}
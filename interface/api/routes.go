@@ -0,0 +1,288 @@
+package api
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/encryption"
+	"github.com/shubhamgptln/sarama-ai/usecase/content"
+	"github.com/shubhamgptln/sarama-ai/usecase/erasure"
+	"github.com/shubhamgptln/sarama-ai/usecase/indexversion"
+	"github.com/shubhamgptln/sarama-ai/usecase/reindex"
+	"github.com/shubhamgptln/sarama-ai/usecase/retention"
+	"github.com/shubhamgptln/sarama-ai/usecase/snapshot"
+	"github.com/shubhamgptln/sarama-ai/usecase/writeback"
+)
+
+// route is a registered endpoint; its operations document it in the OpenAPI spec.
+type route struct {
+	Path        string
+	Scope       domain.Scope
+	Handler     http.HandlerFunc
+	Operations  []operation
+	Deprecation *deprecation
+}
+
+// deprecation marks a route that clients should migrate away from.
+type deprecation struct {
+	Since     time.Time
+	Sunset    time.Time
+	Successor string
+}
+
+// apiVersion is a route group served under /api/<Name>. Routes a version does
+// not redefine are inherited from the previous version.
+type apiVersion struct {
+	Name   string
+	Routes []route
+}
+
+type operation struct {
+	Method   string
+	Summary  string
+	Params   []param
+	Request  any
+	Response any
+	Status   int
+}
+
+// param is a query parameter; Type is an OpenAPI primitive type.
+type param struct {
+	Name        string
+	Type        string
+	Required    bool
+	Description string
+}
+
+// routes are every documented endpoint.
+func (h *Handler) routes() []route {
+	routes := append(h.versionedRoutes(), h.graphQLRoutes()...)
+	return append(routes, h.adminRoutes()...)
+}
+
+func (h *Handler) versionedRoutes() []route {
+	var routes []route
+	var inherited []route
+	for _, v := range h.versions() {
+		inherited = inherit(inherited, v.Routes)
+		for _, rt := range inherited {
+			rt.Path = "/api/" + v.Name + rt.Path
+			routes = append(routes, rt)
+		}
+	}
+	return routes
+}
+
+// inherit overlays a version's routes on those of the previous version.
+func inherit(previous, routes []route) []route {
+	merged := append([]route(nil), routes...)
+	for _, rt := range previous {
+		if !slices.ContainsFunc(routes, func(r route) bool { return r.Path == rt.Path }) {
+			merged = append(merged, rt)
+		}
+	}
+	return merged
+}
+
+func (h *Handler) versions() []apiVersion {
+	return []apiVersion{
+		{Name: "v1", Routes: h.v1Routes()},
+		{Name: "v2", Routes: []route{
+			{Path: "/query", Scope: domain.ScopeQuery, Handler: h.handleQueryV2, Operations: []operation{
+				{Method: http.MethodPost, Summary: "Answer a question from the indexed documentation", Request: queryRequestV2{}, Response: queryResponseV2{}},
+			}},
+		}},
+	}
+}
+
+func (h *Handler) v1Routes() []route {
+	return []route{
+		{Path: "/query", Scope: domain.ScopeQuery, Handler: h.handleQuery, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Answer a question from the indexed documentation", Request: domain.Question{}, Response: queryResponse{}},
+		}, Deprecation: &deprecation{Since: time.Date(2026, 10, 16, 0, 0, 0, 0, time.UTC), Successor: "/api/v2/query"}},
+		{Path: "/feedback", Scope: domain.ScopeQuery, Handler: h.handleFeedback, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Rate an answer", Request: feedbackRequest{}, Response: domain.Feedback{}, Status: http.StatusCreated},
+		}},
+		{Path: "/related", Scope: domain.ScopeQuery, Handler: h.handleRelated, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List pages related to a page", Response: relatedResponse{}, Params: []param{
+				{Name: "page_id", Type: "string", Required: true},
+				{Name: "limit", Type: "integer", Description: "1-50, default 5"},
+			}},
+		}},
+		{Path: "/analytics/knowledge-gaps", Scope: domain.ScopeAdmin, Handler: h.handleKnowledgeGaps, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List topics the documentation answers poorly", Response: knowledgeGapsResponse{}, Params: []param{
+				{Name: "days", Type: "integer", Description: "1-365, default 30"},
+				{Name: "limit", Type: "integer", Description: "1-200, default 20"},
+			}},
+		}},
+		{Path: "/glossary", Scope: domain.ScopeQuery, Handler: h.handleGlossary, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List glossary terms", Response: glossaryResponse{}, Params: []param{
+				{Name: "q", Type: "string", Description: "Term prefix"},
+			}},
+		}},
+		{Path: "/graph/entities", Scope: domain.ScopeQuery, Handler: h.handleGraphEntity, Operations: []operation{
+			{Method: http.MethodGet, Summary: "Look up an entity and its relations", Response: graphEntityResponse{}, Params: []param{
+				{Name: "name", Type: "string", Required: true},
+			}},
+		}},
+		{Path: "/documents", Scope: domain.ScopeIngest, Handler: h.handleAddDocument, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Index text, Markdown or HTML that isn't in Confluence, as JSON or a multipart file upload", Request: addDocumentRequest{}, Response: addDocumentResponse{}, Status: http.StatusCreated},
+		}},
+		{Path: "/ingest", Scope: domain.ScopeIngest, Handler: h.handleIngest, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Queue a document for (re)indexing or removal", Request: ingestRequest{}, Response: ingestResponse{}, Status: http.StatusAccepted},
+		}},
+	}
+}
+
+// graphQLRoutes are unversioned; the schema evolves by adding fields instead.
+func (h *Handler) graphQLRoutes() []route {
+	return []route{
+		{Path: "/graphql", Scope: domain.ScopeQuery, Handler: h.handleGraphQL, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Query documents, spaces and sync jobs, or ask a question, with GraphQL", Request: graphQLRequest{}, Response: graphQLResponse{}},
+		}},
+	}
+}
+
+func (h *Handler) adminRoutes() []route {
+	return []route{
+		{Path: "/admin/experiments", Scope: domain.ScopeAdmin, Handler: h.handleExperiments, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List experiments and their results", Response: experimentsResponse{}},
+		}},
+		{Path: "/admin/reports/content", Scope: domain.ScopeAdmin, Handler: h.handleContentReport, Operations: []operation{
+			{Method: http.MethodGet, Summary: "Get the latest content health report", Response: content.Report{}},
+			{Method: http.MethodPost, Summary: "Start a content health scan", Response: statusResponse{}, Status: http.StatusAccepted},
+		}},
+		{Path: "/admin/digest", Scope: domain.ScopeAdmin, Handler: h.handleDigest, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Send the email digest now", Response: statusResponse{}, Status: http.StatusAccepted},
+		}},
+		{Path: "/admin/writeback", Scope: domain.ScopeAdmin, Handler: h.handleWriteback, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Post a page summary or an answer back to the page in Confluence", Request: writeback.Request{}, Response: writeback.Result{}},
+		}},
+		{Path: "/admin/api-keys", Scope: domain.ScopeAdmin, Handler: h.handleAPIKeys, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List API keys", Response: []domain.APIKey{}},
+			{Method: http.MethodPost, Summary: "Create an API key; the secret is only returned once", Request: createAPIKeyRequest{}, Response: createAPIKeyResponse{}, Status: http.StatusCreated},
+			{Method: http.MethodDelete, Summary: "Revoke an API key", Params: []param{{Name: "id", Type: "string", Required: true}}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/tenants", Scope: domain.ScopeAdmin, Handler: h.handleTenants, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List tenants with the questions they asked today, refused ones included", Response: []tenantResponse{}},
+		}},
+		{Path: "/admin/rbac/roles", Scope: domain.ScopeAdmin, Handler: h.handleRoles, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List roles and the scopes they grant", Response: []roleResponse{}},
+		}},
+		{Path: "/admin/rbac/bindings", Scope: domain.ScopeAdmin, Handler: h.handleRoleBindings, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List role bindings", Response: []domain.RoleBinding{}, Params: []param{{Name: "subject", Type: "string"}}},
+			{Method: http.MethodPost, Summary: "Grant a role", Request: roleBindingRequest{}, Response: domain.RoleBinding{}, Status: http.StatusCreated},
+			{Method: http.MethodDelete, Summary: "Remove a role binding", Params: []param{{Name: "id", Type: "string", Required: true}}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/reindex", Scope: domain.ScopeAdmin, Handler: h.handleReindex, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Start reindexing documents, a space, or everything indexed", Request: reindex.Request{}, Response: reindexResponse{}, Status: http.StatusAccepted},
+		}},
+		{Path: "/admin/jobs", Scope: domain.ScopeAdmin, Handler: h.handleJobs, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List this instance's recent sync jobs, newest first, with their progress and ETA", Response: jobsResponse{}, Params: []param{
+				{Name: "id", Type: "string", Description: "Get only this job"},
+			}},
+		}},
+		{Path: "/admin/jobs/pause", Scope: domain.ScopeAdmin, Handler: h.handlePauseJob, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Pause a running sync job before its next document", Request: jobRequest{}, Response: reindex.Job{}},
+		}},
+		{Path: "/admin/jobs/resume", Scope: domain.ScopeAdmin, Handler: h.handleResumeJob, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Resume a paused sync job", Request: jobRequest{}, Response: reindex.Job{}},
+		}},
+		{Path: "/admin/jobs/cancel", Scope: domain.ScopeAdmin, Handler: h.handleCancelJob, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Cancel a running or paused sync job; it turns cancelled once the current document is abandoned", Request: jobRequest{}, Response: reindex.Job{}},
+		}},
+		{Path: "/admin/webhooks", Scope: domain.ScopeAdmin, Handler: h.handleWebhooks, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List outbound webhooks", Response: []domain.Webhook{}},
+			{Method: http.MethodPost, Summary: "Subscribe a URL to indexing events; the signing secret is only returned once", Request: createWebhookRequest{}, Response: createWebhookResponse{}, Status: http.StatusCreated},
+			{Method: http.MethodDelete, Summary: "Delete an outbound webhook", Params: []param{{Name: "id", Type: "string", Required: true}}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/loglevel", Scope: domain.ScopeAdmin, Handler: h.handleLogLevel, Operations: []operation{
+			{Method: http.MethodGet, Summary: "Get the log level and its overrides by component", Response: logLevelResponse{}},
+			{Method: http.MethodPut, Summary: "Change the log level or a component's until restart", Request: logLevelRequest{}, Response: logLevelResponse{}},
+		}},
+		{Path: "/admin/config", Scope: domain.ScopeAdmin, Handler: h.handleConfig, Operations: []operation{
+			{Method: http.MethodGet, Summary: "Get the configuration in effect, with secrets masked", Response: map[string]any{}},
+		}},
+		{Path: "/admin/cache/flush", Scope: domain.ScopeAdmin, Handler: h.handleCacheFlush, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Flush in-memory caches", Request: cacheFlushRequest{}, Response: cacheFlushResponse{}},
+		}},
+		{Path: "/admin/slo", Scope: domain.ScopeAdmin, Handler: h.handleSLO, Operations: []operation{
+			{Method: http.MethodGet, Summary: "Summarize each route's availability and latency against the SLOs, with burn rates and firing alerts", Response: sloResponse{}},
+		}},
+		{Path: "/admin/connectors", Scope: domain.ScopeAdmin, Handler: h.handleConnectors, Operations: []operation{
+			{Method: http.MethodGet, Summary: "Check connectivity to external systems", Response: connectorsResponse{}},
+		}},
+		{Path: "/admin/documents", Scope: domain.ScopeAdmin, Handler: h.handleDocuments, Operations: []operation{
+			{Method: http.MethodGet, Summary: "Search indexed documents by title, space and label", Response: documentsResponse{}, Params: []param{
+				{Name: "title", Type: "string", Description: "Part of the title, any case"},
+				{Name: "space", Type: "string"},
+				{Name: "label", Type: "string"},
+				{Name: "limit", Type: "integer", Description: "1-1000, default 100"},
+				{Name: "offset", Type: "integer", Description: "Matches to skip, default 0"},
+			}},
+			{Method: http.MethodDelete, Summary: "Remove a document and its chunks from the index", Params: []param{{Name: "id", Type: "string", Required: true}}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/documents/chunks", Scope: domain.ScopeAdmin, Handler: h.handleDocumentChunks, Operations: []operation{
+			{Method: http.MethodGet, Summary: "Get an indexed document and its chunks", Response: documentResponse{}, Params: []param{{Name: "id", Type: "string", Required: true}}},
+		}},
+		{Path: "/admin/index/versions", Scope: domain.ScopeAdmin, Handler: h.handleIndexVersions, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List index versions and which one is served", Response: indexVersionsResponse{}},
+			{Method: http.MethodPost, Summary: "Start building a new index version, e.g. with another chunk size or embedding model, while the current one serves", Request: buildIndexVersionRequest{}, Response: indexversion.Version{}, Status: http.StatusAccepted},
+			{Method: http.MethodDelete, Summary: "Drop an index version that isn't served", Params: []param{{Name: "name", Type: "string", Required: true}}, Status: http.StatusNoContent},
+		}},
+		{Path: "/admin/index/activate", Scope: domain.ScopeAdmin, Handler: h.handleActivateIndexVersion, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Serve a built index version from now on; the one served until now is kept up to date for rollback", Request: activateIndexVersionRequest{}, Response: indexversion.Version{}},
+		}},
+		{Path: "/admin/index/rollback", Scope: domain.ScopeAdmin, Handler: h.handleRollbackIndexVersion, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Serve the previously active index version again", Response: indexversion.Version{}},
+		}},
+		{Path: "/admin/snapshots", Scope: domain.ScopeAdmin, Handler: h.handleSnapshots, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List index snapshots and recent snapshot jobs", Response: snapshotsResponse{}},
+			{Method: http.MethodPost, Summary: "Start exporting the index, embeddings included, to a snapshot in blob storage", Response: snapshot.Job{}, Status: http.StatusAccepted},
+		}},
+		{Path: "/admin/snapshots/restore", Scope: domain.ScopeAdmin, Handler: h.handleRestoreSnapshot, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Start restoring a snapshot into the index", Request: restoreSnapshotRequest{}, Response: snapshot.Job{}, Status: http.StatusAccepted},
+		}},
+		{Path: "/admin/retention/purge", Scope: domain.ScopeAdmin, Handler: h.handlePurgeRetention, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Delete the conversations and feedback past their retention now", Response: retention.Result{}},
+		}},
+		{Path: "/admin/erasure", Scope: domain.ScopeAdmin, Handler: h.handleErasure, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Delete the conversations, feedback and audit entries of a user and report what was deleted", Request: erasureRequest{}, Response: erasure.Report{}},
+		}},
+		{Path: "/admin/encryption/rotate", Scope: domain.ScopeAdmin, Handler: h.handleRotateEncryption, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Rewrap every blob under the current master key and encrypt those stored in the clear", Response: encryption.RotateResult{}},
+		}},
+		{Path: "/admin/dlq", Scope: domain.ScopeAdmin, Handler: h.handleDeadLetters, Operations: []operation{
+			{Method: http.MethodGet, Summary: "List dead-lettered ingest events, oldest first", Response: deadLettersResponse{}, Params: []param{
+				{Name: "limit", Type: "integer", Description: "1-500, default 50"},
+			}},
+			{Method: http.MethodDelete, Summary: "Drop every dead-lettered ingest event", Response: purgeResponse{}},
+		}},
+		{Path: "/admin/dlq/replay", Scope: domain.ScopeAdmin, Handler: h.handleReplayDeadLetters, Operations: []operation{
+			{Method: http.MethodPost, Summary: "Requeue dead-lettered ingest events, oldest first", Response: replayResponse{}, Params: []param{
+				{Name: "limit", Type: "integer", Description: "1-500, default 50"},
+			}},
+		}},
+	}
+}
+
+// withDeprecation announces a deprecated route with the Deprecation (RFC 9745),
+// Sunset (RFC 8594) and successor-version Link headers.
+func withDeprecation(d *deprecation, next http.Handler) http.Handler {
+	if d == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@"+strconv.FormatInt(d.Since.Unix(), 10))
+		if !d.Sunset.IsZero() {
+			w.Header().Set("Sunset", d.Sunset.UTC().Format(http.TimeFormat))
+		}
+		if d.Successor != "" {
+			w.Header().Add("Link", "<"+d.Successor+`>; rel="successor-version"`)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
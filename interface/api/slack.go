@@ -0,0 +1,171 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/slack"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+const slackMaxBody = 1 << 20
+
+// slackMention matches user mentions such as <@U024BE7LH>.
+var slackMention = regexp.MustCompile(`<@[A-Z0-9]+(\|[^>]*)?>`)
+
+func (h *Handler) registerSlack(mux *http.ServeMux) {
+	mux.HandleFunc("/integrations/slack/commands", h.handleSlackCommand)
+	mux.HandleFunc("/integrations/slack/events", h.handleSlackEvent)
+}
+
+// handleSlackCommand answers the /ask slash command. Slack wants a reply
+// within three seconds, so the command is acknowledged and the question is
+// posted to the channel with the answer in its thread.
+func (h *Handler) handleSlackCommand(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readSlackRequest(w, r)
+	if !ok {
+		return
+	}
+	form, err := url.ParseQuery(string(body))
+	if err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	question := strings.TrimSpace(form.Get("text"))
+	if question == "" {
+		writeJSON(w, http.StatusOK, slack.Message{ResponseType: "ephemeral", Text: "Ask a question, e.g. `" + form.Get("command") + " How do I request VPN access?`"})
+		return
+	}
+	channel, user, responseURL := form.Get("channel_id"), form.Get("user_id"), form.Get("response_url")
+	h.background(func(ctx context.Context) {
+		ts, err := h.services.Slack.PostMessage(ctx, slack.Message{
+			Channel: channel,
+			Text:    fmt.Sprintf("<@%s> asked: %s", user, slackEscape(question)),
+		})
+		if err != nil {
+			// Typically the bot hasn't been invited to the channel; answer the
+			// caller privately instead.
+			log.Printf("Posting Slack question to %s failed: %v\n", channel, err)
+			text := h.chatAnswer(ctx, "slack-"+channel+"-"+user, question, slackFormat)
+			if err := h.services.Slack.Respond(ctx, responseURL, slack.Message{ResponseType: "ephemeral", Text: text}); err != nil {
+				log.Printf("Responding to Slack command failed: %v\n", err)
+			}
+			return
+		}
+		h.replySlack(ctx, channel, ts, question)
+	})
+	writeJSON(w, http.StatusOK, slack.Message{ResponseType: "ephemeral", Text: "Looking that up…"})
+}
+
+type slackEventEnvelope struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	EventID   string `json:"event_id"`
+	Event     struct {
+		Type     string `json:"type"`
+		User     string `json:"user"`
+		BotID    string `json:"bot_id"`
+		Text     string `json:"text"`
+		Channel  string `json:"channel"`
+		TS       string `json:"ts"`
+		ThreadTS string `json:"thread_ts"`
+	} `json:"event"`
+}
+
+// handleSlackEvent receives Events API callbacks and answers app_mention
+// events in the mention's thread.
+func (h *Handler) handleSlackEvent(w http.ResponseWriter, r *http.Request) {
+	body, ok := h.readSlackRequest(w, r)
+	if !ok {
+		return
+	}
+	var env slackEventEnvelope
+	if err := json.Unmarshal(body, &env); err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	switch env.Type {
+	case "url_verification":
+		writeJSON(w, http.StatusOK, map[string]string{"challenge": env.Challenge})
+		return
+	case "event_callback":
+	default:
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	// Slack retries events it thinks timed out; the first delivery is
+	// already being answered.
+	if r.Header.Get("X-Slack-Retry-Num") != "" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	event := env.Event
+	question := strings.TrimSpace(slackMention.ReplaceAllString(event.Text, ""))
+	if event.Type == "app_mention" && event.BotID == "" && question != "" {
+		thread := event.ThreadTS
+		if thread == "" {
+			thread = event.TS
+		}
+		h.background(func(ctx context.Context) {
+			h.replySlack(ctx, event.Channel, thread, question)
+		})
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// readSlackRequest reads and verifies a request signed by Slack.
+func (h *Handler) readSlackRequest(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return nil, false
+	}
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, slackMaxBody))
+	if err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return nil, false
+	}
+	if err := h.services.Slack.Verify(r.Header, body); err != nil {
+		if !errors.Is(err, slack.ErrInvalidSignature) {
+			log.Printf("Verifying Slack request failed: %v\n", err)
+		}
+		apierror.Write(w, apierror.Unauthorized, "Invalid signature")
+		return nil, false
+	}
+	return body, true
+}
+
+// replySlack answers question in the thread of the message ts. The thread is
+// the session, so follow-ups in the thread share experiment variants.
+func (h *Handler) replySlack(ctx context.Context, channel, ts, question string) {
+	text := h.chatAnswer(ctx, "slack-"+channel+"-"+ts, question, slackFormat)
+	_, err := h.services.Slack.PostMessage(ctx, slack.Message{Channel: channel, ThreadTS: ts, Text: text})
+	if err != nil {
+		log.Printf("Posting Slack answer to %s failed: %v\n", channel, err)
+	}
+}
+
+func slackFormat(a *domain.Answer) string {
+	var b strings.Builder
+	b.WriteString(a.Text)
+	if len(a.Citations) > 0 {
+		b.WriteString("\n\n*Sources*")
+		for _, c := range a.Citations {
+			fmt.Fprintf(&b, "\n• <%s|%s>", c.URL, slackEscape(c.Title))
+		}
+	}
+	return b.String()
+}
+
+// slackEscape escapes the characters Slack treats as control sequences.
+func slackEscape(s string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(s)
+}
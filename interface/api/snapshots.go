@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/snapshot"
+)
+
+type snapshotsResponse struct {
+	Snapshots []snapshot.Snapshot `json:"snapshots"`
+	// Jobs are the recent exports and restores on this instance, newest first.
+	Jobs []snapshot.Job `json:"jobs"`
+}
+
+type restoreSnapshotRequest struct {
+	Key string `json:"key"`
+	// Force restores a snapshot embedded with another model.
+	Force bool `json:"force,omitempty"`
+}
+
+// handleSnapshots lists snapshots of the index and starts exporting one.
+func (h *Handler) handleSnapshots(w http.ResponseWriter, r *http.Request) {
+	if h.services.Snapshots == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Snapshots need blob storage")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		snapshots, err := h.services.Snapshots.List(r.Context())
+		if err != nil {
+			log.Printf("Listing snapshots failed: %v\n", err)
+			apierror.Write(w, apierror.Internal, "Failed to list snapshots")
+			return
+		}
+		writeJSON(w, http.StatusOK, snapshotsResponse{Snapshots: snapshots, Jobs: h.services.Snapshots.Jobs()})
+
+	case http.MethodPost:
+		// The export outlives the request; its job reports when it ends.
+		job, err := h.services.Snapshots.Export(context.WithoutCancel(r.Context()))
+		if errors.Is(err, snapshot.ErrRunning) {
+			apierror.Write(w, apierror.Conflict, err.Error())
+			return
+		}
+		if err != nil {
+			log.Printf("Starting snapshot failed: %v\n", err)
+			apierror.Write(w, apierror.Internal, "Failed to start snapshot")
+			return
+		}
+		log.Printf("Snapshot %s started\n", job.Key)
+		writeJSON(w, http.StatusAccepted, job)
+
+	default:
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+	}
+}
+
+// handleRestoreSnapshot loads a snapshot into the index, e.g. to rebuild an
+// instance from a backup or to clone another environment's index.
+func (h *Handler) handleRestoreSnapshot(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Snapshots == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Snapshots need blob storage")
+		return
+	}
+	var req restoreSnapshotRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	if req.Key == "" {
+		apierror.Write(w, apierror.InvalidArgument, "Missing key")
+		return
+	}
+	job, err := h.services.Snapshots.Restore(context.WithoutCancel(r.Context()), req.Key, req.Force)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		apierror.Write(w, apierror.NotFound, "Snapshot not found")
+		return
+	case errors.Is(err, snapshot.ErrRunning), errors.Is(err, snapshot.ErrIncompatible):
+		apierror.Write(w, apierror.Conflict, err.Error())
+		return
+	case err != nil:
+		log.Printf("Starting restore of %s failed: %v\n", req.Key, err)
+		apierror.Write(w, apierror.Internal, "Failed to start restore")
+		return
+	}
+	log.Printf("Restore of snapshot %s started\n", job.Key)
+	writeJSON(w, http.StatusAccepted, job)
+}
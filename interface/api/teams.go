@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/teams"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+const teamsMaxBody = 1 << 20
+
+// teamsMention matches the <at>Name</at> tags Teams puts around mentions.
+var teamsMention = regexp.MustCompile(`<at>[^<]*</at>`)
+
+// handleTeamsActivity is the Bot Framework messaging endpoint. Messages sent
+// to the bot, or mentioning it in a channel, are acknowledged at once and
+// answered in a reply.
+func (h *Handler) handleTeamsActivity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	var activity teams.Activity
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, teamsMaxBody)).Decode(&activity); err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	err := h.services.Teams.Verify(r.Context(), r.Header.Get("Authorization"), activity)
+	if errors.Is(err, teams.ErrUnauthorized) {
+		apierror.Write(w, apierror.Unauthorized, "Unauthorized")
+		return
+	}
+	if err != nil {
+		log.Printf("Verifying Teams request failed: %v\n", err)
+		apierror.Write(w, apierror.Unavailable, "Authentication unavailable")
+		return
+	}
+
+	question := strings.TrimSpace(teamsMention.ReplaceAllString(activity.Text, ""))
+	if activity.Type == "message" && question != "" {
+		h.background(func(ctx context.Context) {
+			if err := h.services.Teams.Typing(ctx, activity); err != nil {
+				log.Printf("Sending Teams typing indicator failed: %v\n", err)
+			}
+			text := h.chatAnswer(ctx, "teams-"+activity.Conversation.ID, question, teamsFormat)
+			if err := h.services.Teams.Reply(ctx, activity, text); err != nil {
+				log.Printf("Posting Teams answer to %s failed: %v\n", activity.Conversation.ID, err)
+			}
+		})
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func teamsFormat(a *domain.Answer) string {
+	var b strings.Builder
+	b.WriteString(a.Text)
+	if len(a.Citations) > 0 {
+		b.WriteString("\n\n**Sources**\n")
+		for _, c := range a.Citations {
+			fmt.Fprintf(&b, "\n- [%s](%s)", strings.NewReplacer("[", "\\[", "]", "\\]").Replace(c.Title), c.URL)
+		}
+	}
+	return b.String()
+}
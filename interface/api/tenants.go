@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+	"github.com/shubhamgptln/sarama-ai/usecase/tenant"
+)
+
+type tenantResponse struct {
+	tenant.Tenant
+	QueriesToday int64 `json:"queries_today"`
+}
+
+// handleTenants lists the tenants with how much of their quota they used today.
+func (h *Handler) handleTenants(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	resp := []tenantResponse{}
+	if h.services.Tenants != nil {
+		for _, t := range h.services.Tenants.Tenants() {
+			resp = append(resp, tenantResponse{Tenant: t, QueriesToday: h.services.Tenants.QueriesToday(r.Context(), t.ID)})
+		}
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// tenantDenied returns why a tenant's principal may not use a route of scope,
+// or "" when it may. Admin routes reach every tenant's data.
+func (h *Handler) tenantDenied(p *domain.Principal, scope domain.Scope) string {
+	if scope == domain.ScopeAdmin {
+		return "Forbidden: admin routes aren't available to tenants"
+	}
+	if !h.knownTenant(p.Tenant) {
+		return "Forbidden: unknown tenant " + p.Tenant
+	}
+	return ""
+}
+
+func (h *Handler) knownTenant(id string) bool {
+	if h.services.Tenants == nil {
+		return false
+	}
+	_, err := h.services.Tenants.Tenant(id)
+	return err == nil
+}
+
+// callerTenant returns the caller's tenant, or "" for the deployment's own
+// callers.
+func callerTenant(ctx context.Context) string {
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		return p.Tenant
+	}
+	return ""
+}
+
+// forTenant fits a document added by a tenant's caller into its namespace:
+// into its first space when none is given, and with an ID under the
+// tenant's, so tenants can't replace each other's documents.
+func (h *Handler) forTenant(tenantID string, req *addDocumentRequest) error {
+	t, err := h.services.Tenants.Tenant(tenantID)
+	if err != nil {
+		return err
+	}
+	if req.SpaceKey == "" {
+		req.SpaceKey = t.Spaces[0]
+	} else if !slices.Contains(t.Spaces, req.SpaceKey) {
+		return tenant.ErrSpaceForbidden
+	}
+	docID := strings.TrimPrefix(req.ID, domain.ManualDocumentPrefix)
+	if docID == "" {
+		docID = id.New()
+	}
+	if !strings.HasPrefix(docID, tenantID+"/") {
+		docID = tenantID + "/" + docID
+	}
+	req.ID = docID
+	return nil
+}
@@ -0,0 +1,35 @@
+package api
+
+import (
+	"embed"
+	"io/fs"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+// uiFiles is a minimal chat page for trying the assistant without building a
+// frontend. It calls /api/v2/query with the API key the user enters.
+//
+//go:embed ui
+var uiFiles embed.FS
+
+func uiHandler() http.Handler {
+	files, err := fs.Sub(uiFiles, "ui")
+	if err != nil {
+		panic(err)
+	}
+	fileServer := http.StripPrefix("/ui/", http.FileServerFS(files))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+			return
+		}
+		// Embedded files have no modification time, so make browsers
+		// revalidate rather than keep a stale page after an upgrade.
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		w.Header().Set("Content-Security-Policy", "default-src 'self'; img-src 'self' data:; frame-ancestors 'none'")
+		fileServer.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,95 @@
+"use strict";
+
+const messages = document.getElementById("messages");
+const form = document.getElementById("ask");
+const question = document.getElementById("question");
+const apiKey = document.getElementById("api-key");
+
+let sessionID = "";
+let history = [];
+
+apiKey.value = sessionStorage.getItem("sarama-api-key") || "";
+apiKey.addEventListener("change", () => sessionStorage.setItem("sarama-api-key", apiKey.value));
+
+document.getElementById("new-chat").addEventListener("click", () => {
+  sessionID = "";
+  history = [];
+  messages.replaceChildren();
+  question.focus();
+});
+
+question.addEventListener("keydown", (e) => {
+  if (e.key === "Enter" && !e.shiftKey) {
+    e.preventDefault();
+    form.requestSubmit();
+  }
+});
+
+form.addEventListener("submit", async (e) => {
+  e.preventDefault();
+  const text = question.value.trim();
+  if (!text) return;
+  question.value = "";
+  append("user", text);
+  const pending = append("assistant pending", "Thinking…");
+  form.querySelector("button").disabled = true;
+
+  try {
+    const resp = await ask(text);
+    pending.remove();
+    const el = append("assistant", resp.answer.text);
+    if (resp.answer.citations && resp.answer.citations.length) {
+      el.append(citations(resp.answer.citations));
+    }
+    sessionID = resp.session_id;
+    history.push({ role: "user", content: text }, { role: "assistant", content: resp.answer.text });
+  } catch (err) {
+    pending.remove();
+    append("error", err.message);
+  } finally {
+    form.querySelector("button").disabled = false;
+    question.focus();
+  }
+});
+
+async function ask(text) {
+  const headers = { "Content-Type": "application/json" };
+  if (apiKey.value) headers["X-API-Key"] = apiKey.value;
+  const resp = await fetch("/api/v2/query", {
+    method: "POST",
+    headers,
+    body: JSON.stringify({ question: text, session_id: sessionID, history }),
+  });
+  const body = await resp.json().catch(() => null);
+  if (!resp.ok) {
+    throw new Error((body && body.error && body.error.message) || `Request failed with status ${resp.status}`);
+  }
+  return body;
+}
+
+function append(kind, text) {
+  const el = document.createElement("div");
+  el.className = "message " + kind;
+  el.textContent = text;
+  messages.append(el);
+  messages.scrollTop = messages.scrollHeight;
+  return el;
+}
+
+function citations(list) {
+  const ol = document.createElement("ol");
+  ol.className = "citations";
+  for (const c of list) {
+    const li = document.createElement("li");
+    const a = document.createElement("a");
+    a.textContent = c.title || c.document_id;
+    if (c.url) {
+      a.href = c.url;
+      a.target = "_blank";
+      a.rel = "noopener";
+    }
+    li.append(a);
+    ol.append(li);
+  }
+  return ol;
+}
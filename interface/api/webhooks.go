@@ -0,0 +1,80 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/notify"
+)
+
+type createWebhookRequest struct {
+	URL string `json:"url"`
+	// Secret signs deliveries; one is generated when empty.
+	Secret string             `json:"secret,omitempty"`
+	Events []domain.EventType `json:"events,omitempty"`
+}
+
+type createWebhookResponse struct {
+	Webhook *domain.Webhook `json:"webhook"`
+	Secret  string          `json:"secret"`
+}
+
+func (h *Handler) handleWebhooks(w http.ResponseWriter, r *http.Request) {
+	if h.services.Notify == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Outbound webhooks are disabled")
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		hooks, err := h.services.Notify.Webhooks(r.Context())
+		if err != nil {
+			log.Printf("Listing webhooks failed: %v\n", err)
+			apierror.Write(w, apierror.Internal, "Failed to list webhooks")
+			return
+		}
+		writeJSON(w, http.StatusOK, hooks)
+
+	case http.MethodPost:
+		var req createWebhookRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+			return
+		}
+		secret, hook, err := h.services.Notify.Subscribe(r.Context(), req.URL, req.Secret, req.Events)
+		if errors.Is(err, notify.ErrInvalidWebhook) {
+			apierror.WriteDetails(w, apierror.InvalidArgument, err.Error(), map[string][]domain.EventType{"events": domain.EventTypes()})
+			return
+		}
+		if err != nil {
+			log.Printf("Creating webhook failed: %v\n", err)
+			apierror.Write(w, apierror.Internal, "Failed to create webhook")
+			return
+		}
+		writeJSON(w, http.StatusCreated, createWebhookResponse{Webhook: hook, Secret: secret})
+
+	case http.MethodDelete:
+		id := r.URL.Query().Get("id")
+		if id == "" {
+			apierror.Write(w, apierror.InvalidArgument, "Missing id parameter")
+			return
+		}
+		err := h.services.Notify.Unsubscribe(r.Context(), id)
+		if errors.Is(err, domain.ErrWebhookNotFound) {
+			apierror.Write(w, apierror.NotFound, "Webhook not found")
+			return
+		}
+		if err != nil {
+			log.Printf("Deleting webhook %s failed: %v\n", id, err)
+			apierror.Write(w, apierror.Internal, "Failed to delete webhook")
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+
+	default:
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+	}
+}
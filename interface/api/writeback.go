@@ -0,0 +1,46 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/usecase/writeback"
+)
+
+// handleWriteback posts a page summary, or the answer to a question, back to
+// the page in Confluence.
+func (h *Handler) handleWriteback(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		apierror.Write(w, apierror.MethodNotAllowed, "Method not allowed")
+		return
+	}
+	if h.services.Writeback == nil {
+		apierror.Write(w, apierror.FeatureDisabled, "Confluence write-back is disabled")
+		return
+	}
+	var req writeback.Request
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		apierror.Write(w, apierror.InvalidPayload, "Invalid payload")
+		return
+	}
+	if req.DocumentID == "" {
+		apierror.Write(w, apierror.InvalidArgument, "document_id is required")
+		return
+	}
+	result, err := h.services.Writeback.Post(r.Context(), req)
+	switch {
+	case errors.Is(err, domain.ErrNotFound):
+		apierror.Write(w, apierror.NotFound, "Document not indexed")
+	case errors.Is(err, writeback.ErrSpaceNotAllowed):
+		apierror.Write(w, apierror.Forbidden, err.Error())
+	case err != nil:
+		log.Printf("Confluence write-back for %s failed: %v\n", req.DocumentID, err)
+		apierror.Write(w, apierror.Upstream, "Failed to write back to Confluence")
+	default:
+		writeJSON(w, http.StatusOK, result)
+	}
+}
@@ -0,0 +1,89 @@
+package apierror
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"sort"
+)
+
+// Code is a stable, machine-readable error identifier and the HTTP status it
+// is returned with. Clients should branch on the code, not the message.
+type Code struct {
+	Name   string
+	Status int
+}
+
+var registry = map[string]Code{}
+
+func register(name string, status int) Code {
+	if _, ok := registry[name]; ok {
+		panic("apierror: duplicate code " + name)
+	}
+	c := Code{Name: name, Status: status}
+	registry[name] = c
+	return c
+}
+
+var (
+	InvalidPayload    = register("invalid_payload", http.StatusBadRequest)
+	InvalidArgument   = register("invalid_argument", http.StatusBadRequest)
+	Unauthorized      = register("unauthorized", http.StatusUnauthorized)
+	ClientCertMissing = register("client_cert_required", http.StatusUnauthorized)
+	Forbidden         = register("forbidden", http.StatusForbidden)
+	ClientCertDenied  = register("client_cert_not_allowed", http.StatusForbidden)
+	NotFound          = register("not_found", http.StatusNotFound)
+	FeatureDisabled   = register("feature_disabled", http.StatusNotFound)
+	MethodNotAllowed  = register("method_not_allowed", http.StatusMethodNotAllowed)
+	Conflict          = register("conflict", http.StatusConflict)
+	Unprocessable     = register("unprocessable", http.StatusUnprocessableEntity)
+	RateLimited       = register("rate_limited", http.StatusTooManyRequests)
+	Internal          = register("internal_error", http.StatusInternalServerError)
+	Upstream          = register("upstream_error", http.StatusBadGateway)
+	Unavailable       = register("unavailable", http.StatusServiceUnavailable)
+	Timeout           = register("timeout", http.StatusGatewayTimeout)
+)
+
+// Codes lists the registered codes sorted by status, then name.
+func Codes() []Code {
+	codes := make([]Code, 0, len(registry))
+	for _, c := range registry {
+		codes = append(codes, c)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if codes[i].Status != codes[j].Status {
+			return codes[i].Status < codes[j].Status
+		}
+		return codes[i].Name < codes[j].Name
+	})
+	return codes
+}
+
+// Envelope is the shape every error is returned in.
+type Envelope struct {
+	Error Problem `json:"error"`
+}
+
+type Problem struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Details   any    `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// Write sends an error envelope with the code's status. The request ID is
+// taken from the X-Request-ID response header set by the request ID middleware.
+func Write(w http.ResponseWriter, code Code, message string) {
+	WriteDetails(w, code, message, nil)
+}
+
+func WriteDetails(w http.ResponseWriter, code Code, message string, details any) {
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(code.Status)
+	body := Envelope{Error: Problem{Code: code.Name, Message: message, Details: details, RequestID: h.Get("X-Request-ID")}}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Error writing response: %v\n", err)
+	}
+}
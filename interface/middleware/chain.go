@@ -0,0 +1,93 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Middleware wraps a handler with cross-cutting behaviour.
+type Middleware func(http.Handler) http.Handler
+
+// Chain wraps h so that the first middleware is the outermost one and sees the
+// request first.
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		if mws[i] != nil {
+			h = mws[i](h)
+		}
+	}
+	return h
+}
+
+// Build resolves the configured middleware names, in order, against the
+// available middleware. A nil entry is known but disabled and is skipped.
+func Build(names []string, available map[string]Middleware) ([]Middleware, error) {
+	mws := make([]Middleware, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		mw, ok := available[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown middleware %q", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("middleware %q listed twice", name)
+		}
+		seen[name] = true
+		if mw != nil {
+			mws = append(mws, mw)
+		}
+	}
+	return mws, nil
+}
+
+// matchRoute looks up path exactly, then by the longest key ending in "/"
+// that prefixes it.
+func matchRoute[T any](routes map[string]T, path string) (T, bool) {
+	if v, ok := routes[path]; ok {
+		return v, true
+	}
+	var match T
+	longest := -1
+	for prefix, v := range routes {
+		if strings.HasSuffix(prefix, "/") && strings.HasPrefix(path, prefix) && len(prefix) > longest {
+			match, longest = v, len(prefix)
+		}
+	}
+	return match, longest >= 0
+}
+
+// responseRecorder captures the status and size of a response while keeping
+// the underlying writer reachable for flushing.
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.size += n
+	return n, err
+}
+
+func (r *responseRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,213 @@
+package middleware
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+)
+
+// CompressionConfig compresses responses of ContentTypes once they reach
+// MinSize bytes. Event streams are never compressed.
+type CompressionConfig struct {
+	MinSize      int
+	ContentTypes []string
+}
+
+type encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(io.Writer)
+}
+
+var encoders = map[string]*sync.Pool{
+	"br": {New: func() any { return brotli.NewWriterLevel(io.Discard, 4) }},
+	"gzip": {New: func() any {
+		w, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+		return w
+	}},
+}
+
+// Compress negotiates brotli or gzip from Accept-Encoding, preferring brotli.
+func Compress(cfg CompressionConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			encoding := negotiateEncoding(r.Header.Get("Accept-Encoding"))
+			if encoding == "" || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			cw := &compressWriter{ResponseWriter: w, cfg: &cfg, encoding: encoding}
+			// Not deferred: after a panic the held-back response is dropped so
+			// the recovery middleware can still write its error.
+			next.ServeHTTP(cw, r)
+			cw.close()
+		})
+	}
+}
+
+// negotiateEncoding picks the acceptable encoding we support with the highest
+// q-value; ties go to brotli.
+func negotiateEncoding(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				q = f
+			}
+		}
+		if name == "*" {
+			name = "gzip"
+		}
+		if _, ok := encoders[name]; !ok || q <= 0 {
+			continue
+		}
+		if q > bestQ || (q == bestQ && name == "br") {
+			best, bestQ = name, q
+		}
+	}
+	return best
+}
+
+// compressWriter holds back the status and the first MinSize bytes until it
+// knows whether the response is worth compressing.
+type compressWriter struct {
+	http.ResponseWriter
+	cfg      *CompressionConfig
+	encoding string
+	status   int
+	buf      []byte
+	decided  bool
+	enc      encoder
+	// checked and compressible cache the eligibility decision.
+	checked      bool
+	compressible bool
+}
+
+func (c *compressWriter) WriteHeader(status int) {
+	if c.decided || status < http.StatusOK {
+		c.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if c.status == 0 {
+		c.status = status
+	}
+}
+
+func (c *compressWriter) Write(b []byte) (int, error) {
+	if !c.decided {
+		if c.status == 0 {
+			c.status = http.StatusOK
+		}
+		if !c.eligible() {
+			if err := c.start(false); err != nil {
+				return 0, err
+			}
+		} else {
+			c.buf = append(c.buf, b...)
+			if len(c.buf) < c.cfg.MinSize {
+				return len(b), nil
+			}
+			if err := c.start(true); err != nil {
+				return 0, err
+			}
+			return len(b), nil
+		}
+	}
+	if c.enc != nil {
+		return c.enc.Write(b)
+	}
+	return c.ResponseWriter.Write(b)
+}
+
+// Flush commits to compressing eligible responses, since more data is coming.
+func (c *compressWriter) Flush() {
+	if !c.decided {
+		if c.status == 0 {
+			c.status = http.StatusOK
+		}
+		c.start(c.eligible())
+	}
+	if c.enc != nil {
+		c.enc.Flush()
+	}
+	if f, ok := c.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (c *compressWriter) Unwrap() http.ResponseWriter {
+	return c.ResponseWriter
+}
+
+func (c *compressWriter) eligible() bool {
+	if c.checked {
+		return c.compressible
+	}
+	c.checked = true
+	h := c.Header()
+	if h.Get("Content-Encoding") != "" || c.status == http.StatusNoContent || c.status == http.StatusNotModified {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil || mediaType == "text/event-stream" {
+		return false
+	}
+	if !slices.Contains(c.cfg.ContentTypes, mediaType) {
+		return false
+	}
+	h.Add("Vary", "Accept-Encoding")
+	c.compressible = true
+	return true
+}
+
+func (c *compressWriter) start(compress bool) error {
+	c.decided = true
+	if compress {
+		h := c.Header()
+		h.Del("Content-Length")
+		h.Set("Content-Encoding", c.encoding)
+		c.enc = encoders[c.encoding].Get().(encoder)
+		c.enc.Reset(c.ResponseWriter)
+	}
+	c.ResponseWriter.WriteHeader(c.status)
+	buf := c.buf
+	c.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if c.enc != nil {
+		_, err = c.enc.Write(buf)
+	} else {
+		_, err = c.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// close writes out a response that stayed under MinSize uncompressed, or
+// finishes the compressed stream.
+func (c *compressWriter) close() {
+	if !c.decided {
+		if c.status == 0 {
+			return
+		}
+		c.start(false)
+		return
+	}
+	if c.enc != nil {
+		c.enc.Close()
+		c.enc.Reset(io.Discard)
+		encoders[c.encoding].Put(c.enc)
+		c.enc = nil
+	}
+}
@@ -0,0 +1,127 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+// Drainer tracks in-flight requests and background work so shutdown can let
+// them finish before the listener is closed.
+type Drainer struct {
+	exempt   []string
+	draining atomic.Bool
+
+	mu     sync.Mutex
+	closed bool
+	active int
+	idle   chan struct{}
+}
+
+// NewDrainer exempts the given paths, typically probes and metrics, from
+// tracking and from being rejected while draining.
+func NewDrainer(exempt ...string) *Drainer {
+	return &Drainer{exempt: exempt}
+}
+
+// Draining reports whether shutdown has begun; readiness should fail from then on.
+func (d *Drainer) Draining() bool {
+	return d.draining.Load()
+}
+
+// Track counts requests while they are served. Once draining has closed the
+// door, new requests get 503 so clients retry against another instance.
+func (d *Drainer) Track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slices.Contains(d.exempt, r.URL.Path) {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !d.acquire() {
+			w.Header().Set("Connection", "close")
+			w.Header().Set("Retry-After", "1")
+			apierror.Write(w, apierror.Unavailable, "Server is shutting down")
+			return
+		}
+		defer d.release()
+		if d.Draining() {
+			w.Header().Set("Connection", "close")
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Go runs fn in the background and makes Drain wait for it. Work started by an
+// in-flight request is always accepted.
+func (d *Drainer) Go(fn func()) {
+	d.mu.Lock()
+	d.active++
+	d.mu.Unlock()
+	go func() {
+		defer d.release()
+		fn()
+	}()
+}
+
+// Drain fails readiness, keeps serving for delay so load balancers notice, then
+// rejects new work and waits for in-flight work until ctx expires.
+func (d *Drainer) Drain(ctx context.Context, delay time.Duration) error {
+	d.draining.Store(true)
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
+	d.mu.Lock()
+	d.closed = true
+	if d.active == 0 {
+		d.mu.Unlock()
+		return nil
+	}
+	if d.idle == nil {
+		d.idle = make(chan struct{})
+	}
+	idle := d.idle
+	d.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Active returns the number of in-flight requests and background tasks.
+func (d *Drainer) Active() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.active
+}
+
+func (d *Drainer) acquire() bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.closed {
+		return false
+	}
+	d.active++
+	return true
+}
+
+func (d *Drainer) release() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.active--
+	if d.active == 0 && d.idle != nil {
+		close(d.idle)
+		d.idle = nil
+	}
+}
@@ -0,0 +1,86 @@
+package middleware
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+)
+
+// LoggingConfig samples successful requests per route: Sampling maps a path,
+// or a prefix ending in "/", to the fraction of requests logged. Unlisted
+// routes and server errors are always logged.
+type LoggingConfig struct {
+	Sampling map[string]float64
+}
+
+func (c LoggingConfig) rate(path string) float64 {
+	if rate, ok := matchRoute(c.Sampling, path); ok {
+		return rate
+	}
+	return 1
+}
+
+type callerKey struct{}
+
+// callerInfo lets middleware further down the chain report who the caller
+// turned out to be.
+type callerInfo struct {
+	id     string
+	method string
+}
+
+// RecordCaller attaches the authenticated principal to the request log line.
+func RecordCaller(ctx context.Context, p *domain.Principal) {
+	if info, ok := ctx.Value(callerKey{}).(*callerInfo); ok {
+		info.id, info.method = p.ID, p.Method
+	}
+}
+
+// Logging logs method, path, status, latency, size and caller for each request
+// once the response has been written.
+func Logging(l *logger.Logger, cfg LoggingConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			info := &callerInfo{}
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), callerKey{}, info)))
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			if rec.status < http.StatusInternalServerError {
+				if rate := cfg.rate(r.URL.Path); rate < 1 && rand.Float64() >= rate {
+					return
+				}
+			}
+
+			fields := []logger.Field{
+				logger.String("method", r.Method),
+				logger.String("path", r.URL.Path),
+				logger.Int("status", rec.status),
+				logger.Duration("duration", time.Since(start).Round(time.Microsecond)),
+				logger.Int("bytes", rec.size),
+				logger.String("remote", ClientIP(r)),
+				logger.String("request_id", RequestIDFromContext(r.Context())),
+			}
+			if traceID, spanID := traceContextOf(r); traceID != "" {
+				fields = append(fields, logger.String(logger.TraceIDKey, traceID), logger.String(logger.SpanIDKey, spanID))
+			}
+			if info.id != "" {
+				fields = append(fields, logger.String("caller_id", info.id), logger.String("auth", info.method))
+			}
+			switch {
+			case rec.status >= http.StatusInternalServerError:
+				l.Error("HTTP request", fields...)
+			case rec.status >= http.StatusBadRequest:
+				l.Warn("HTTP request", fields...)
+			default:
+				l.Info("HTTP request", fields...)
+			}
+		})
+	}
+}
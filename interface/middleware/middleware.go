@@ -0,0 +1,167 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"runtime/debug"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the ID assigned to the request, if any.
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// Recover turns a panicking handler into a 500 JSON error instead of a dropped
+// connection, logging the panic with its stack trace.
+func Recover(l *logger.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			rec := &responseRecorder{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				l.Error("Handler panicked",
+					logger.Any("panic", v),
+					logger.String("method", r.Method),
+					logger.String("path", r.URL.Path),
+					logger.String("request_id", RequestIDFromContext(r.Context())),
+					logger.String("stack", string(debug.Stack())),
+				)
+				// Once the response has started the status can't change; let
+				// the client see a truncated body instead.
+				if rec.status != 0 {
+					return
+				}
+				apierror.Write(w, apierror.Internal, "Internal server error")
+			}()
+			next.ServeHTTP(rec, r)
+		})
+	}
+}
+
+// RequestID propagates the caller's X-Request-ID or assigns a new one, and
+// echoes it on the response. The ID, and the trace and span IDs of the
+// request, are added to the request's context logger.
+func RequestID() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requestID := r.Header.Get(RequestIDHeader)
+			if requestID == "" || len(requestID) > 128 {
+				requestID = id.New()
+			}
+			w.Header().Set(RequestIDHeader, requestID)
+			fields := []logger.Field{logger.String("request_id", requestID)}
+			if traceID, spanID := traceContextOf(r); traceID != "" {
+				fields = append(fields, logger.String(logger.TraceIDKey, traceID), logger.String(logger.SpanIDKey, spanID))
+			}
+			ctx := context.WithValue(r.Context(), requestIDKey{}, requestID)
+			next.ServeHTTP(w, r.WithContext(logger.ContextWithFields(ctx, fields...)))
+		})
+	}
+}
+
+// traceContextOf returns the trace and span IDs of the request's server span
+// or, outside Tracing, of the caller's span from its traceparent header.
+func traceContextOf(r *http.Request) (traceID, spanID string) {
+	sc := tracing.SpanContextFromContext(r.Context())
+	if !sc.Valid() {
+		sc, _ = tracing.ParseTraceparent(r.Header.Get(tracing.Header))
+	}
+	if !sc.Valid() {
+		return "", ""
+	}
+	return sc.TraceID.String(), sc.SpanID.String()
+}
+
+// Authenticate identifies the caller and stores the principal on the request
+// context. It never rejects a request: routes enforce their own scope, so
+// anonymous and invalid credentials pass through unidentified.
+func Authenticate(svc *auth.Service) Middleware {
+	if svc == nil {
+		return nil
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			credential := Credential(r)
+			if credential == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			principal, err := svc.Authenticate(r.Context(), credential)
+			if err != nil {
+				next.ServeHTTP(w, r)
+				return
+			}
+			RecordCaller(r.Context(), principal)
+			ctx := logger.ContextWithFields(r.Context(), logger.String("user", principal.ID))
+			next.ServeHTTP(w, r.WithContext(auth.WithPrincipal(ctx, principal)))
+		})
+	}
+}
+
+// RequireClientCert rejects requests that verify reports as lacking an
+// acceptable client certificate.
+func RequireClientCert(verify func(*http.Request) error) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch err := verify(r); {
+			case errors.Is(err, domain.ErrClientCertRequired):
+				apierror.Write(w, apierror.ClientCertMissing, "Client certificate required")
+			case err != nil:
+				apierror.Write(w, apierror.ClientCertDenied, "Client certificate not allowed")
+			default:
+				next.ServeHTTP(w, r)
+			}
+		})
+	}
+}
+
+// Credential reads an API key from X-API-Key, or an API key or JWT from the
+// Authorization bearer token.
+func Credential(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// clientKey identifies the caller for rate limiting: the authenticated
+// principal when there is one, otherwise the remote address.
+func clientKey(r *http.Request) string {
+	if p := auth.PrincipalFromContext(r.Context()); p != nil {
+		return "principal:" + p.ID
+	}
+	return "ip:" + ClientIP(r)
+}
+
+// ClientIP is the address of the peer that sent r.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+// RateLimitConfig limits each client to Rate requests per second with bursts
+// of up to Burst. A zero Rate disables limiting.
+type RateLimitConfig struct {
+	Rate  float64
+	Burst int
+	// IdleTTL is how long an unused client bucket is kept.
+	IdleTTL time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// RateLimitCounters keep the clients' token buckets outside the process, e.g.
+// in Redis so every instance draws on the same bucket.
+type RateLimitCounters interface {
+	// Take refills key's bucket at rate tokens per second up to burst and
+	// takes a token, or reports how long until one is free. Buckets unused
+	// for idle may be dropped.
+	Take(ctx context.Context, key string, rate float64, burst int, idle time.Duration) (time.Duration, bool, error)
+}
+
+// RateLimiter is a rate limit whose config can be changed while it's serving.
+type RateLimiter struct {
+	cfg       RateLimitConfig
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	counters  RateLimitCounters
+	// lastFailure is when counter failures were last logged.
+	lastFailure time.Time
+}
+
+type RateLimiterOption func(*RateLimiter)
+
+// WithCounters keeps the buckets in c. While c fails, each instance limits
+// clients with its own buckets.
+func WithCounters(c RateLimitCounters) RateLimiterOption {
+	return func(l *RateLimiter) { l.counters = c }
+}
+
+func NewRateLimiter(cfg RateLimitConfig, opts ...RateLimiterOption) *RateLimiter {
+	l := &RateLimiter{buckets: make(map[string]*bucket), lastSweep: time.Now()}
+	l.SetConfig(cfg)
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// SetConfig replaces the limits; clients keep the tokens they have, capped at
+// the new burst. A zero Rate lets every request through.
+func (l *RateLimiter) SetConfig(cfg RateLimitConfig) {
+	if cfg.Burst < 1 {
+		cfg.Burst = int(math.Ceil(cfg.Rate))
+	}
+	if cfg.IdleTTL <= 0 {
+		cfg.IdleTTL = 10 * time.Minute
+	}
+	l.mu.Lock()
+	l.cfg = cfg
+	l.mu.Unlock()
+}
+
+// Middleware rejects requests with 429 once a client exhausts its bucket.
+func (l *RateLimiter) Middleware() Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if wait, ok := l.take(r.Context(), clientKey(r)); !ok {
+				w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+				apierror.Write(w, apierror.RateLimited, "Too many requests")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RateLimit rejects requests with 429 once a client exhausts its bucket.
+func RateLimit(cfg RateLimitConfig) Middleware {
+	if cfg.Rate <= 0 {
+		return nil
+	}
+	return NewRateLimiter(cfg).Middleware()
+}
+
+// take takes a token from key's bucket in the counters, falling back to the
+// local buckets when there are none or they fail.
+func (l *RateLimiter) take(ctx context.Context, key string) (time.Duration, bool) {
+	l.mu.Lock()
+	cfg := l.cfg
+	l.mu.Unlock()
+	if l.counters != nil && cfg.Rate > 0 {
+		wait, ok, err := l.counters.Take(ctx, key, cfg.Rate, cfg.Burst, cfg.IdleTTL)
+		if err == nil {
+			return wait, ok
+		}
+		l.mu.Lock()
+		if time.Since(l.lastFailure) > time.Minute {
+			l.lastFailure = time.Now()
+			log.Printf("Rate limit counters failed, limiting locally: %v\n", err)
+		}
+		l.mu.Unlock()
+	}
+	return l.allow(key, time.Now())
+}
+
+// allow takes a token from key's bucket, or reports how long until one is free.
+func (l *RateLimiter) allow(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cfg.Rate <= 0 {
+		return 0, true
+	}
+	if now.Sub(l.lastSweep) > l.cfg.IdleTTL {
+		for k, b := range l.buckets {
+			if now.Sub(b.last) > l.cfg.IdleTTL {
+				delete(l.buckets, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{tokens: float64(l.cfg.Burst), last: now}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(l.cfg.Burst), b.tokens+now.Sub(b.last).Seconds()*l.cfg.Rate)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / l.cfg.Rate * float64(time.Second)), false
+	}
+	b.tokens--
+	return 0, true
+}
@@ -0,0 +1,342 @@
+package middleware
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// SLOConfig sets the objectives each tracked route is held to.
+type SLOConfig struct {
+	// Availability is the share of requests to answer without a 5xx, e.g.
+	// 0.999; zero turns tracking off.
+	Availability float64
+	// Latency is the share of the other requests to answer within
+	// LatencyThreshold, or the route's threshold in RouteThresholds. Event
+	// streams are left out.
+	Latency          float64
+	LatencyThreshold time.Duration
+	RouteThresholds  map[string]time.Duration
+	// Routes are the paths, or prefixes ending in /, tracked; probes and
+	// scrapes would only dilute the error rate.
+	Routes []string
+}
+
+func (c SLOConfig) Enabled() bool {
+	return c.Availability > 0
+}
+
+func (c SLOConfig) threshold(route string) time.Duration {
+	if d, ok := matchRoute(c.RouteThresholds, route); ok {
+		return d
+	}
+	return c.LatencyThreshold
+}
+
+// The SLIs a route is tracked by.
+const (
+	SLIAvailability = "availability"
+	SLILatency      = "latency"
+)
+
+// sloWindows are the windows burn rates are measured over, by minute.
+var sloWindows = []time.Duration{5 * time.Minute, 30 * time.Minute, time.Hour, 6 * time.Hour}
+
+const sloMinutes = 6 * 60
+
+// windowLabel writes a window as 5m or 6h.
+func windowLabel(d time.Duration) string {
+	if d%time.Hour == 0 {
+		return strconv.Itoa(int(d/time.Hour)) + "h"
+	}
+	return strconv.Itoa(int(d/time.Minute)) + "m"
+}
+
+// burnAlerts are the multiwindow alerts of the SRE workbook: one fires while
+// both its windows burn the error budget faster than Rate, the short window
+// making it stop soon after the burn does.
+var burnAlerts = []struct {
+	Severity    string
+	Long, Short time.Duration
+	Rate        float64
+}{
+	{"page", time.Hour, 5 * time.Minute, 14.4},
+	{"ticket", 6 * time.Hour, 30 * time.Minute, 6},
+}
+
+var (
+	burnRateDesc = prometheus.NewDesc("slo_burn_rate",
+		"How many times faster than the SLO allows a route burns its error budget, by SLI and window.",
+		[]string{"route", "sli", "window"}, nil)
+	alertDesc = prometheus.NewDesc("slo_alert_firing",
+		"Whether a route's burn-rate alert fires (1) or not (0), by SLI and severity.",
+		[]string{"route", "sli", "severity"}, nil)
+)
+
+// SLOTracker counts the good and bad requests of each tracked route by minute
+// and reports burn rates against the SLOs, as /admin/slo and Prometheus
+// gauges.
+type SLOTracker struct {
+	cfg     SLOConfig
+	tracked map[string]bool
+
+	mu     sync.Mutex
+	routes map[string]*sloRoute
+	// firing are the alerts firing as of the last Run evaluation, by
+	// route, SLI and severity.
+	firing map[[3]string]bool
+}
+
+type sloRoute struct {
+	minutes [sloMinutes]sloMinute
+}
+
+type sloMinute struct {
+	minute int64
+	// total requests, the failed ones (5xx), the timed ones (the rest,
+	// streams aside) and the slow ones among them.
+	total, failed, timed, slow int
+}
+
+func NewSLOTracker(cfg SLOConfig) *SLOTracker {
+	t := &SLOTracker{cfg: cfg, tracked: map[string]bool{}, routes: map[string]*sloRoute{}, firing: map[[3]string]bool{}}
+	for _, route := range cfg.Routes {
+		t.tracked[route] = true
+	}
+	return t
+}
+
+// Middleware records the outcome of each request under the pattern routes
+// matches it with, as Metrics does.
+func (t *SLOTracker) Middleware(routes Router) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, route := routes.Handler(r)
+			if _, ok := matchRoute(t.tracked, route); !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+			start := time.Now()
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			stream := strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+			t.record(route, rec.status, time.Since(start), stream)
+		})
+	}
+}
+
+func (t *SLOTracker) record(route string, status int, elapsed time.Duration, stream bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	rt, ok := t.routes[route]
+	if !ok {
+		rt = &sloRoute{}
+		t.routes[route] = rt
+	}
+	minute := time.Now().Unix() / 60
+	m := &rt.minutes[minute%sloMinutes]
+	if m.minute != minute {
+		*m = sloMinute{minute: minute}
+	}
+	m.total++
+	switch {
+	case status >= http.StatusInternalServerError:
+		m.failed++
+	case !stream:
+		m.timed++
+		if elapsed > t.cfg.threshold(route) {
+			m.slow++
+		}
+	}
+}
+
+// sum adds up the minutes of rt in the window ending now; t.mu must be held.
+func (rt *sloRoute) sum(now time.Time, window time.Duration) sloMinute {
+	var s sloMinute
+	from := now.Unix()/60 - int64(window/time.Minute)
+	for _, m := range rt.minutes {
+		if m.minute > from {
+			s.total += m.total
+			s.failed += m.failed
+			s.timed += m.timed
+			s.slow += m.slow
+		}
+	}
+	return s
+}
+
+// burnRate is the bad share of the requests over the share the target
+// allows; no requests burn nothing.
+func burnRate(bad, total int, target float64) float64 {
+	if total == 0 || target >= 1 {
+		return 0
+	}
+	return float64(bad) / float64(total) / (1 - target)
+}
+
+// SLOWindow is how an SLI fared in one window.
+type SLOWindow struct {
+	Window   string  `json:"window"`
+	Requests int     `json:"requests"`
+	Good     float64 `json:"good"`
+	BurnRate float64 `json:"burn_rate"`
+}
+
+type SLOObjective struct {
+	SLI       string  `json:"sli"`
+	Target    float64 `json:"target"`
+	Threshold string  `json:"threshold,omitempty"`
+	// Alerts are the severities of the burn-rate alerts firing.
+	Alerts  []string    `json:"alerts,omitempty"`
+	Windows []SLOWindow `json:"windows"`
+}
+
+type SLOStatus struct {
+	Route      string         `json:"route"`
+	Objectives []SLOObjective `json:"objectives"`
+}
+
+// Summary reports every route that had requests in the longest window, by
+// route.
+func (t *SLOTracker) Summary() []SLOStatus {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	var out []SLOStatus
+	for route, rt := range t.routes {
+		availability := SLOObjective{SLI: SLIAvailability, Target: t.cfg.Availability}
+		latency := SLOObjective{SLI: SLILatency, Target: t.cfg.Latency, Threshold: t.cfg.threshold(route).String()}
+		for _, window := range sloWindows {
+			s := rt.sum(now, window)
+			availability.Windows = append(availability.Windows, SLOWindow{
+				Window:   windowLabel(window),
+				Requests: s.total,
+				Good:     goodShare(s.total-s.failed, s.total),
+				BurnRate: burnRate(s.failed, s.total, t.cfg.Availability),
+			})
+			latency.Windows = append(latency.Windows, SLOWindow{
+				Window:   windowLabel(window),
+				Requests: s.timed,
+				Good:     goodShare(s.timed-s.slow, s.timed),
+				BurnRate: burnRate(s.slow, s.timed, t.cfg.Latency),
+			})
+		}
+		if availability.Windows[len(sloWindows)-1].Requests == 0 {
+			continue
+		}
+		objectives := []SLOObjective{availability}
+		if t.cfg.Latency > 0 {
+			objectives = append(objectives, latency)
+		}
+		for i := range objectives {
+			for _, alert := range burnAlerts {
+				if t.firing[[3]string{route, objectives[i].SLI, alert.Severity}] {
+					objectives[i].Alerts = append(objectives[i].Alerts, alert.Severity)
+				}
+			}
+		}
+		out = append(out, SLOStatus{Route: route, Objectives: objectives})
+	}
+	slices.SortFunc(out, func(a, b SLOStatus) int { return strings.Compare(a.Route, b.Route) })
+	return out
+}
+
+// goodShare is good over total, or 1 without requests.
+func goodShare(good, total int) float64 {
+	if total == 0 {
+		return 1
+	}
+	return float64(good) / float64(total)
+}
+
+// burnRates returns the burn rate of each SLI of rt by window; t.mu must be
+// held.
+func (t *SLOTracker) burnRates(rt *sloRoute, now time.Time) map[string]map[time.Duration]float64 {
+	rates := map[string]map[time.Duration]float64{SLIAvailability: {}}
+	if t.cfg.Latency > 0 {
+		rates[SLILatency] = map[time.Duration]float64{}
+	}
+	for _, window := range sloWindows {
+		s := rt.sum(now, window)
+		rates[SLIAvailability][window] = burnRate(s.failed, s.total, t.cfg.Availability)
+		if t.cfg.Latency > 0 {
+			rates[SLILatency][window] = burnRate(s.slow, s.timed, t.cfg.Latency)
+		}
+	}
+	return rates
+}
+
+// Run evaluates the burn-rate alerts every interval until ctx is done,
+// logging each one that starts or stops firing.
+func (t *SLOTracker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			t.evaluate()
+		}
+	}
+}
+
+func (t *SLOTracker) evaluate() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	for route, rt := range t.routes {
+		for sli, rates := range t.burnRates(rt, now) {
+			for _, alert := range burnAlerts {
+				key := [3]string{route, sli, alert.Severity}
+				firing := rates[alert.Long] > alert.Rate && rates[alert.Short] > alert.Rate
+				switch {
+				case firing && !t.firing[key]:
+					log.Printf("SLO alert (%s): %s %s burns its error budget %.1fx over %s\n",
+						alert.Severity, route, sli, rates[alert.Long], windowLabel(alert.Long))
+				case !firing && t.firing[key]:
+					log.Printf("SLO alert (%s) resolved: %s %s\n", alert.Severity, route, sli)
+				}
+				if firing {
+					t.firing[key] = true
+				} else {
+					delete(t.firing, key)
+				}
+			}
+		}
+	}
+}
+
+func (t *SLOTracker) Describe(ch chan<- *prometheus.Desc) {
+	ch <- burnRateDesc
+	ch <- alertDesc
+}
+
+// Collect reports burn rates as of the scrape and alerts as of the last
+// evaluation.
+func (t *SLOTracker) Collect(ch chan<- prometheus.Metric) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	for route, rt := range t.routes {
+		for sli, rates := range t.burnRates(rt, now) {
+			for window, rate := range rates {
+				ch <- prometheus.MustNewConstMetric(burnRateDesc, prometheus.GaugeValue, rate, route, sli, windowLabel(window))
+			}
+			for _, alert := range burnAlerts {
+				firing := 0.0
+				if t.firing[[3]string{route, sli, alert.Severity}] {
+					firing = 1
+				}
+				ch <- prometheus.MustNewConstMetric(alertDesc, prometheus.GaugeValue, firing, route, sli, alert.Severity)
+			}
+		}
+	}
+}
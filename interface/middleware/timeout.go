@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/interface/apierror"
+)
+
+// writeGrace is how long past its deadline a request may still write, so the
+// 504 itself can be delivered.
+const writeGrace = 5 * time.Second
+
+// TimeoutConfig bounds each request's context by the timeout of its route (a
+// path, or a prefix ending in "/"), falling back to Default. Zero means no
+// timeout, leaving the server-wide WriteTimeout in force.
+type TimeoutConfig struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+func (c TimeoutConfig) timeout(path string) time.Duration {
+	if d, ok := matchRoute(c.Routes, path); ok {
+		return d
+	}
+	return c.Default
+}
+
+// Timeout cancels the request context when the route's timeout expires and
+// answers 504 unless the handler had already started its response. The
+// connection's write deadline is moved to match, overriding the server-wide
+// WriteTimeout.
+func Timeout(cfg TimeoutConfig) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			d := cfg.timeout(r.URL.Path)
+			if d <= 0 {
+				next.ServeHTTP(w, r)
+				return
+			}
+			http.NewResponseController(w).SetWriteDeadline(time.Now().Add(d + writeGrace))
+
+			ctx, cancel := context.WithTimeout(r.Context(), d)
+			defer cancel()
+			tw := &timeoutWriter{ResponseWriter: w, ctx: ctx}
+			next.ServeHTTP(tw, r.WithContext(ctx))
+			if !tw.wroteHeader && !tw.timedOut && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				tw.writeTimeout()
+			}
+		})
+	}
+}
+
+// timeoutWriter replaces whatever a handler writes after the deadline, such as
+// the error from its cancelled upstream call, with the 504.
+type timeoutWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (t *timeoutWriter) WriteHeader(status int) {
+	if t.timedOut || t.wroteHeader {
+		return
+	}
+	if status < http.StatusOK {
+		t.ResponseWriter.WriteHeader(status)
+		return
+	}
+	if errors.Is(t.ctx.Err(), context.DeadlineExceeded) {
+		t.writeTimeout()
+		return
+	}
+	t.wroteHeader = true
+	t.ResponseWriter.WriteHeader(status)
+}
+
+func (t *timeoutWriter) Write(b []byte) (int, error) {
+	if !t.wroteHeader {
+		t.WriteHeader(http.StatusOK)
+	}
+	if t.timedOut {
+		return 0, http.ErrHandlerTimeout
+	}
+	return t.ResponseWriter.Write(b)
+}
+
+func (t *timeoutWriter) Flush() {
+	if t.timedOut {
+		return
+	}
+	if !t.wroteHeader {
+		t.WriteHeader(http.StatusOK)
+	}
+	if f, ok := t.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (t *timeoutWriter) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
+func (t *timeoutWriter) writeTimeout() {
+	t.timedOut = true
+	h := t.Header()
+	h.Del("Content-Length")
+	h.Del("Content-Encoding")
+	apierror.Write(t.ResponseWriter, apierror.Timeout, "Request timed out")
+}
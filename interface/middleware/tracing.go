@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+)
+
+// Tracing records a server span for each request, continuing the caller's
+// trace from its traceparent header. Spans are named after the pattern routes
+// matches the request with, like the request metrics.
+func Tracing(routes Router) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, route := routes.Handler(r)
+			if route == "" {
+				route = "unmatched"
+			}
+			ctx := tracing.Extract(r.Context(), r.Header.Get(tracing.Header))
+			ctx, span := tracing.Start(ctx, metricMethod(r.Method)+" "+route, tracing.WithKind(tracing.KindServer),
+				tracing.WithAttributes(
+					tracing.String("http.request.method", r.Method),
+					tracing.String("http.route", route),
+					tracing.String("url.path", r.URL.Path),
+				))
+			defer span.End()
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r.WithContext(ctx))
+			if rec.status == 0 {
+				rec.status = http.StatusOK
+			}
+			span.SetAttributes(tracing.Int("http.response.status_code", rec.status))
+			if rec.status >= http.StatusInternalServerError {
+				span.SetError(fmt.Errorf("status %d", rec.status))
+			}
+		})
+	}
+}
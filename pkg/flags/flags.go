@@ -0,0 +1,118 @@
+// Package flags gates risky features behind switches that can be rolled out
+// per environment or to a percentage of callers, and changed while serving.
+package flags
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Flag is the rollout of one feature.
+type Flag struct {
+	// Percent of subjects the feature is on for, from 0 to 100.
+	Percent float64
+	// Environments limits the feature to these environments; empty means all.
+	Environments []string
+}
+
+// Parse reads a flag written as on, off or a percentage such as 25%,
+// optionally followed by @ and the |-separated environments it applies in.
+// A bare @staging|production means on in those environments.
+func Parse(s string) (Flag, error) {
+	rollout, envs, hasEnvs := strings.Cut(strings.TrimSpace(s), "@")
+	var f Flag
+	switch rollout = strings.ToLower(strings.TrimSpace(rollout)); {
+	case rollout == "on" || rollout == "true" || rollout == "" && hasEnvs:
+		f.Percent = 100
+	case rollout == "off" || rollout == "false":
+	case strings.HasSuffix(rollout, "%"):
+		p, err := strconv.ParseFloat(strings.TrimSuffix(rollout, "%"), 64)
+		if err != nil || p < 0 || p > 100 {
+			return Flag{}, fmt.Errorf("invalid percentage %q", rollout)
+		}
+		f.Percent = p
+	default:
+		return Flag{}, fmt.Errorf("invalid rollout %q, want on, off or a percentage", rollout)
+	}
+	for _, env := range strings.Split(envs, "|") {
+		if env = strings.TrimSpace(env); env != "" {
+			f.Environments = append(f.Environments, env)
+		}
+	}
+	return f, nil
+}
+
+// String writes f the way Parse reads it.
+func (f Flag) String() string {
+	var s string
+	switch f.Percent {
+	case 100:
+		s = "on"
+	case 0:
+		s = "off"
+	default:
+		s = strconv.FormatFloat(f.Percent, 'f', -1, 64) + "%"
+	}
+	if len(f.Environments) > 0 {
+		s += "@" + strings.Join(f.Environments, "|")
+	}
+	return s
+}
+
+type state struct {
+	environment string
+	flags       map[string]Flag
+	subject     func(context.Context) string
+}
+
+var current atomic.Pointer[state]
+
+// Configure sets the flags evaluated by Enabled for the environment the
+// process runs in. subject names who a request is made by, so a percentage
+// rollout keeps each caller on the same side; it may be nil. Configure can be
+// called again to change the flags while serving.
+func Configure(environment string, flags map[string]Flag, subject func(context.Context) string) {
+	current.Store(&state{environment: environment, flags: flags, subject: subject})
+}
+
+// Enabled reports whether the feature name is on for the caller of ctx.
+// Unknown flags are off. Without a subject only fully rolled out flags are on.
+func Enabled(ctx context.Context, name string) bool {
+	s := current.Load()
+	if s == nil {
+		return false
+	}
+	f, ok := s.flags[name]
+	if !ok || f.Percent <= 0 {
+		return false
+	}
+	if len(f.Environments) > 0 && !slices.Contains(f.Environments, s.environment) {
+		return false
+	}
+	if f.Percent >= 100 {
+		return true
+	}
+	var subject string
+	if s.subject != nil {
+		subject = s.subject(ctx)
+	}
+	if subject == "" {
+		return false
+	}
+	return bucket(name, subject) < f.Percent
+}
+
+// bucket places subject at a stable point from 0 to 100 for the flag name,
+// so raising a flag's percentage only ever adds subjects.
+func bucket(name, subject string) float64 {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(subject))
+	return float64(h.Sum32()%10000) / 100
+}
@@ -0,0 +1,168 @@
+package htmltext
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+)
+
+var blockElements = map[string]bool{
+	"p": true, "div": true, "br": true, "li": true, "tr": true, "table": true,
+	"h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"pre": true, "blockquote": true, "ul": true, "ol": true, "hr": true,
+}
+
+var skipElements = map[string]bool{
+	"script": true, "style": true, "head": true,
+}
+
+var spaceRun = regexp.MustCompile(`[ \t\f\r\x{00a0}]+`)
+
+// Extract converts HTML or Confluence storage-format XHTML into plain text,
+// keeping block boundaries as line breaks so chunking can split on paragraphs.
+func Extract(r io.Reader) (string, error) {
+	var b strings.Builder
+	if err := ExtractTo(&b, r); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// ExtractTo is Extract writing the text to w as the markup is decoded, so a
+// large page is never held as text more than once. Markup cut short ends the
+// text, and from where markup stops parsing the rest is passed on as raw text:
+// a stream has no whole page to fall back on.
+func ExtractTo(w io.Writer, r io.Reader) error {
+	// The decoder reads an io.ByteReader a byte at a time, so what it hasn't
+	// parsed is still in src.
+	src, ok := r.(byteReader)
+	if !ok {
+		src = bufio.NewReader(r)
+	}
+	dec := xml.NewDecoder(src)
+	dec.Strict = false
+	dec.AutoClose = xml.HTMLAutoClose
+	dec.Entity = xml.HTMLEntity
+
+	n := &normalizer{w: w}
+	skip := 0
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		var syntax *xml.SyntaxError
+		if errors.As(err, &syntax) {
+			if _, err := io.Copy(n, src); err != nil {
+				return err
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			name := strings.ToLower(t.Name.Local)
+			if skipElements[name] {
+				skip++
+			}
+			if blockElements[name] {
+				n.Write([]byte{'\n'})
+			}
+			if name == "li" {
+				n.Write([]byte("- "))
+			}
+		case xml.EndElement:
+			name := strings.ToLower(t.Name.Local)
+			if skipElements[name] && skip > 0 {
+				skip--
+			}
+			if blockElements[name] {
+				n.Write([]byte{'\n'})
+			}
+			if name == "td" || name == "th" {
+				n.Write([]byte(" | "))
+			}
+		case xml.CharData:
+			if skip == 0 {
+				n.Write(t)
+			}
+		}
+		if n.err != nil {
+			return n.err
+		}
+	}
+	return n.Close()
+}
+
+// ExtractString is Extract for in-memory markup.
+func ExtractString(s string) string {
+	text, _ := Extract(strings.NewReader(s))
+	return text
+}
+
+type byteReader interface {
+	io.Reader
+	io.ByteReader
+}
+
+// normalizer collapses the spaces of each line written to it and trims it,
+// keeps at most one blank line between lines of text and drops blank lines
+// at either end, passing the text on to w a line at a time.
+type normalizer struct {
+	w    io.Writer
+	line []byte
+	// blank is set when blank lines came since the last line of text.
+	blank bool
+	wrote bool
+	err   error
+}
+
+func (n *normalizer) Write(p []byte) (int, error) {
+	written := len(p)
+	for len(p) > 0 && n.err == nil {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			n.line = append(n.line, p...)
+			break
+		}
+		n.line = append(n.line, p[:i]...)
+		n.endLine()
+		p = p[i+1:]
+	}
+	return written, n.err
+}
+
+func (n *normalizer) endLine() {
+	line := bytes.TrimSpace(spaceRun.ReplaceAll(n.line, []byte(" ")))
+	n.line = n.line[:0]
+	if len(line) == 0 {
+		n.blank = true
+		return
+	}
+	if n.wrote {
+		sep := "\n"
+		if n.blank {
+			sep = "\n\n"
+		}
+		if _, n.err = io.WriteString(n.w, sep); n.err != nil {
+			return
+		}
+	}
+	_, n.err = n.w.Write(line)
+	n.wrote, n.blank = true, false
+}
+
+// Close writes the last line.
+func (n *normalizer) Close() error {
+	if n.err == nil && len(n.line) > 0 {
+		n.endLine()
+	}
+	return n.err
+}
@@ -0,0 +1,121 @@
+package access
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+var ErrNoReadableSpaces = errors.New("no readable spaces")
+
+type Config struct {
+	Enabled bool
+	// SpaceCacheTTL is how long space permissions are reused before refetching.
+	SpaceCacheTTL time.Duration
+}
+
+// Service limits questions to the pages the caller can read in Confluence:
+// spaces they have read permission on, and restricted pages that list them.
+// Only SSO users are filtered; API keys belong to trusted integrations and
+// admins see everything.
+type Service struct {
+	spaces domain.SpacePermissions
+	cfg    Config
+
+	mu        sync.Mutex
+	readers   map[string][]string
+	fetchedAt time.Time
+}
+
+func NewService(spaces domain.SpacePermissions, cfg Config) *Service {
+	return &Service{spaces: spaces, cfg: cfg}
+}
+
+// Restrict narrows q to what p may read.
+func (s *Service) Restrict(ctx context.Context, p *domain.Principal, q *domain.Question) error {
+	if p == nil || p.Method != "oidc" || p.HasScope(domain.ScopeAdmin) {
+		return nil
+	}
+	return s.restrictTo(ctx, Readers(p), q)
+}
+
+// RestrictAnonymous narrows q to what everyone may read, for callers with no
+// Confluence identity, such as chat users: spaces and pages granted to anyone.
+func (s *Service) RestrictAnonymous(ctx context.Context, q *domain.Question) error {
+	return s.restrictTo(ctx, []string{domain.ReaderAnyone}, q)
+}
+
+func (s *Service) restrictTo(ctx context.Context, readers []string, q *domain.Question) error {
+	spaceReaders, err := s.spaceReaders(ctx)
+	if err != nil {
+		return err
+	}
+	var readable []string
+	for space, granted := range spaceReaders {
+		if slices.Contains(granted, domain.ReaderAnyone) || slices.ContainsFunc(readers, func(r string) bool { return slices.Contains(granted, r) }) {
+			readable = append(readable, space)
+		}
+	}
+	if len(q.SpaceKeys) > 0 {
+		readable = slices.DeleteFunc(readable, func(space string) bool { return !slices.Contains(q.SpaceKeys, space) })
+	}
+	if len(readable) == 0 {
+		return ErrNoReadableSpaces
+	}
+	slices.Sort(readable)
+	q.SpaceKeys = readable
+	q.Readers = readers
+	return nil
+}
+
+// Readers returns the identities p can be matched by in page and space
+// permissions. The email only counts once the identity provider has verified
+// it, or anyone able to set their own email there could read as its owner.
+func Readers(p *domain.Principal) []string {
+	var readers []string
+	ids := []string{p.ID, p.Username}
+	if p.EmailVerified {
+		ids = append(ids, p.Email)
+	}
+	for _, id := range ids {
+		if id != "" && !slices.Contains(readers, domain.UserReader(id)) {
+			readers = append(readers, domain.UserReader(id))
+		}
+	}
+	for _, g := range p.Groups {
+		readers = append(readers, domain.GroupReader(g))
+	}
+	return readers
+}
+
+// spaceReaders returns cached space permissions, serving stale ones if a refresh fails.
+func (s *Service) spaceReaders(ctx context.Context) (map[string][]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.readers != nil && time.Since(s.fetchedAt) < s.cfg.SpaceCacheTTL {
+		return s.readers, nil
+	}
+	readers, err := s.spaces.SpaceReaders(ctx)
+	if err != nil {
+		if s.readers != nil {
+			log.Printf("Refreshing space permissions failed, using cached ones: %v\n", err)
+			return s.readers, nil
+		}
+		return nil, fmt.Errorf("load space permissions: %w", err)
+	}
+	s.readers, s.fetchedAt = readers, time.Now()
+	return readers, nil
+}
+
+// Flush drops the cached space permissions so the next question refetches them.
+func (s *Service) Flush() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.readers = nil
+}
@@ -0,0 +1,174 @@
+// Package archive keeps raw inputs, such as webhook payloads, downloaded
+// attachments and dead-lettered events, in blob storage so they can be
+// inspected or replayed later, and deletes them once their retention ends.
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+// Kind is what a blob holds; it is also the first segment of its key, so
+// bucket lifecycle rules can target one kind by prefix.
+type Kind string
+
+const (
+	Webhooks    Kind = "webhooks"
+	Attachments Kind = "attachments"
+	DeadLetters Kind = "dead-letters"
+)
+
+// Policy says whether a kind is archived and for how long. A zero Retention
+// keeps blobs until something else, e.g. a bucket lifecycle rule, removes them.
+type Policy struct {
+	Enabled   bool
+	Retention time.Duration
+}
+
+type Config struct {
+	Webhooks    Policy
+	Attachments Policy
+	DeadLetters Policy
+	// SweepInterval is how often expired blobs are deleted; zero never sweeps.
+	SweepInterval time.Duration
+}
+
+type Service struct {
+	store domain.BlobStore
+	cfg   Config
+}
+
+func NewService(store domain.BlobStore, cfg Config) *Service {
+	return &Service{store: store, cfg: cfg}
+}
+
+func (s *Service) policy(kind Kind) Policy {
+	switch kind {
+	case Webhooks:
+		return s.cfg.Webhooks
+	case Attachments:
+		return s.cfg.Attachments
+	case DeadLetters:
+		return s.cfg.DeadLetters
+	}
+	return Policy{}
+}
+
+// Enabled reports whether blobs of kind are kept.
+func (s *Service) Enabled(kind Kind) bool {
+	return s != nil && s.policy(kind).Enabled
+}
+
+// Save stores data as kind/YYYY/MM/DD/name, dated the day it arrived, and
+// returns the key. Nothing is stored for a kind that isn't enabled.
+func (s *Service) Save(ctx context.Context, kind Kind, name string, data []byte, contentType string) (string, error) {
+	if !s.Enabled(kind) {
+		return "", nil
+	}
+	key := path.Join(string(kind), time.Now().UTC().Format("2006/01/02"), safeName(name))
+	if err := s.store.Put(ctx, key, data, contentType); err != nil {
+		return "", fmt.Errorf("archive %s: %w", kind, err)
+	}
+	return key, nil
+}
+
+// SaveJSON stores v encoded as JSON under a fresh name.
+func (s *Service) SaveJSON(ctx context.Context, kind Kind, v any) (string, error) {
+	if !s.Enabled(kind) {
+		return "", nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+	return s.Save(ctx, kind, id.New()+".json", data, "application/json")
+}
+
+// Start deletes expired blobs every SweepInterval until ctx ends.
+func (s *Service) Start(ctx context.Context) {
+	if s.cfg.SweepInterval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.SweepInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if n, err := s.Sweep(ctx); err != nil {
+					log.Printf("Sweeping archived blobs failed after deleting %d: %v\n", n, err)
+				}
+			}
+		}
+	}()
+}
+
+// Sweep deletes the blobs older than their kind's retention and returns how
+// many it deleted. Several instances may sweep at once; deletes are idempotent.
+func (s *Service) Sweep(ctx context.Context) (int, error) {
+	deleted := 0
+	var errs []error
+	for _, kind := range []Kind{Webhooks, Attachments, DeadLetters} {
+		retention := s.policy(kind).Retention
+		if retention <= 0 {
+			continue
+		}
+		blobs, err := s.store.List(ctx, string(kind)+"/")
+		if err != nil {
+			errs = append(errs, fmt.Errorf("list %s: %w", kind, err))
+			continue
+		}
+		cutoff := time.Now().Add(-retention)
+		for _, blob := range blobs {
+			if !archivedAt(blob).Before(cutoff) {
+				continue
+			}
+			if err := s.store.Delete(ctx, blob.Key); err != nil {
+				errs = append(errs, fmt.Errorf("delete %s: %w", blob.Key, err))
+				continue
+			}
+			deleted++
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
+
+// archivedAt is when blob was archived. Blobs rewritten since, e.g. when
+// encryption keys are rotated, look newer than they are, so their age is
+// capped by the day in their key.
+func archivedAt(blob domain.Blob) time.Time {
+	parts := strings.SplitN(blob.Key, "/", 5)
+	if len(parts) < 5 {
+		return blob.Modified
+	}
+	day, err := time.Parse("2006/01/02", strings.Join(parts[1:4], "/"))
+	if err != nil {
+		return blob.Modified
+	}
+	if end := day.AddDate(0, 0, 1); end.Before(blob.Modified) {
+		return end
+	}
+	return blob.Modified
+}
+
+// safeName keeps name within its key segment and readable in a bucket browser.
+func safeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '-', r == '_', r == '.', r == '/':
+			return r
+		}
+		return '_'
+	}, strings.Trim(strings.ReplaceAll(name, "..", "_"), "/"))
+}
@@ -0,0 +1,84 @@
+package archive
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log"
+	"mime"
+	"path"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// ImageSource matches diagrams.ImageSource.
+type ImageSource interface {
+	FetchImage(ctx context.Context, documentID string, img domain.DocumentImage, maxBytes int64) ([]byte, string, error)
+}
+
+// Images archives every image source downloads under the page it belongs to.
+// A failed save is logged and doesn't fail the download.
+func (s *Service) Images(source ImageSource) ImageSource {
+	if !s.Enabled(Attachments) {
+		return source
+	}
+	return &images{source: source, archive: s}
+}
+
+type images struct {
+	source  ImageSource
+	archive *Service
+}
+
+func (i *images) FetchImage(ctx context.Context, documentID string, img domain.DocumentImage, maxBytes int64) ([]byte, string, error) {
+	data, contentType, err := i.source.FetchImage(ctx, documentID, img, maxBytes)
+	if err != nil {
+		return nil, "", err
+	}
+	ext := path.Ext(img.Name())
+	if exts, _ := mime.ExtensionsByType(contentType); ext == "" && len(exts) > 0 {
+		ext = exts[0]
+	}
+	sum := sha256.Sum256(data)
+	name := documentID + "/" + hex.EncodeToString(sum[:8]) + ext
+	if _, err := i.archive.Save(ctx, Attachments, name, data, contentType); err != nil {
+		log.Printf("Archiving image %s of %s failed: %v\n", img.Name(), documentID, err)
+	}
+	return data, contentType, nil
+}
+
+// maxArchivedOnPurge bounds how many dead letters are read into memory to be
+// archived before a purge.
+const maxArchivedOnPurge = 10000
+
+// DeadLetters archives the dead letters of queue before purging them, so
+// dropping them from the bus doesn't lose them.
+func (s *Service) DeadLetters(queue domain.DeadLetterQueue) domain.DeadLetterQueue {
+	if queue == nil || !s.Enabled(DeadLetters) {
+		return queue
+	}
+	return &deadLetters{DeadLetterQueue: queue, archive: s}
+}
+
+type deadLetters struct {
+	domain.DeadLetterQueue
+	archive *Service
+}
+
+func (d *deadLetters) PurgeDeadLetters(ctx context.Context) (int, error) {
+	letters, err := d.PeekDeadLetters(ctx, maxArchivedOnPurge)
+	if err != nil {
+		return 0, err
+	}
+	if len(letters) > 0 {
+		key, err := d.archive.SaveJSON(ctx, DeadLetters, letters)
+		if err != nil {
+			return 0, err
+		}
+		log.Printf("Archived %d dead letters to %s\n", len(letters), key)
+		if len(letters) == maxArchivedOnPurge {
+			log.Printf("Only the oldest %d dead letters were archived before purging\n", maxArchivedOnPurge)
+		}
+	}
+	return d.DeadLetterQueue.PurgeDeadLetters(ctx)
+}
@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+	"github.com/shubhamgptln/sarama-ai/usecase/auth"
+	"github.com/shubhamgptln/sarama-ai/usecase/moderation"
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+)
+
+// Question policies for audit events.
+const (
+	QuestionFull   = "full"
+	QuestionRedact = "redact"
+	QuestionHash   = "hash"
+	QuestionOmit   = "omit"
+)
+
+type Config struct {
+	QuestionMode string
+	// HashKey keys the question HMAC so hashes can't be reversed by guessing questions.
+	HashKey string
+}
+
+// Recorder turns query outcomes into audit events with PII controls applied.
+type Recorder struct {
+	publisher domain.AuditPublisher
+	log       domain.AuditLog
+	cfg       Config
+	redactor  domain.Moderator
+}
+
+type Option func(*Recorder)
+
+// WithAuditLog also records who asked what in the security audit log.
+func WithAuditLog(l domain.AuditLog) Option {
+	return func(r *Recorder) { r.log = l }
+}
+
+// NewRecorder publishes query audit events to publisher, which may be nil when
+// they only go to the audit log.
+func NewRecorder(publisher domain.AuditPublisher, cfg Config, opts ...Option) (*Recorder, error) {
+	r := &Recorder{publisher: publisher, cfg: cfg}
+	for _, opt := range opts {
+		opt(r)
+	}
+	switch cfg.QuestionMode {
+	case QuestionFull, QuestionHash, QuestionOmit:
+	case "", QuestionRedact:
+		r.cfg.QuestionMode = QuestionRedact
+		rules := append(append([]moderation.Rule{}, moderation.DefaultRules...), moderation.PIIRules...)
+		redactor, err := moderation.NewRuleModerator(rules)
+		if err != nil {
+			return nil, err
+		}
+		r.redactor = redactor
+	default:
+		return nil, fmt.Errorf("unknown audit question mode %q", cfg.QuestionMode)
+	}
+	return r, nil
+}
+
+// Record publishes the outcome of a question; failures are logged, never returned.
+func (r *Recorder) Record(ctx context.Context, q domain.Question, answer *domain.Answer, variants map[string]string, latency time.Duration, err error) {
+	event := domain.QueryAudit{
+		ID:          id.New(),
+		At:          time.Now().UTC(),
+		SessionID:   q.SessionID,
+		Mode:        q.Mode,
+		Language:    q.Language,
+		DocumentIDs: []string{},
+		LatencyMS:   latency.Milliseconds(),
+		Variants:    variants,
+		Outcome:     outcome(err),
+	}
+	if event.Mode == "" {
+		event.Mode = domain.ModeStandard
+	}
+	if answer != nil {
+		event.DocumentIDs = answer.DocumentIDs()
+		event.Model = answer.Model
+		event.Usage = answer.Usage
+		event.Moderation = answer.Moderation
+		event.Language = answer.Language
+	}
+	r.applyQuestionPolicy(ctx, &event, q.Text)
+
+	if r.publisher != nil {
+		if err := r.publisher.PublishQueryAudit(ctx, event); err != nil {
+			log.Printf("Publishing audit event %s failed: %v\n", event.ID, err)
+		}
+	}
+	if r.log != nil {
+		r.append(ctx, event)
+	}
+}
+
+// append records the question in the audit log, under the same PII policy.
+func (r *Recorder) append(ctx context.Context, event domain.QueryAudit) {
+	details := map[string]any{
+		"audit_id":     event.ID,
+		"mode":         event.Mode,
+		"document_ids": event.DocumentIDs,
+		"latency_ms":   event.LatencyMS,
+	}
+	if event.Question != "" {
+		details["question"] = event.Question
+	}
+	if event.QuestionHash != "" {
+		details["question_hash"] = event.QuestionHash
+	}
+	if event.Model != "" {
+		details["model"] = event.Model
+	}
+	if event.SessionID != "" {
+		details["session_id"] = event.SessionID
+	}
+	entry := domain.AuditEvent{At: event.At, Action: domain.AuditQuery, Outcome: event.Outcome, Details: details}
+	if p := auth.PrincipalFromContext(ctx); p != nil {
+		entry.Actor = p.ID
+	}
+	if err := r.log.Append(ctx, entry); err != nil {
+		log.Printf("Writing audit log failed: %v\n", err)
+	}
+}
+
+func (r *Recorder) applyQuestionPolicy(ctx context.Context, event *domain.QueryAudit, question string) {
+	switch r.cfg.QuestionMode {
+	case QuestionFull:
+		event.Question = question
+	case QuestionRedact:
+		verdict, err := r.redactor.Moderate(ctx, question)
+		if err != nil {
+			log.Printf("Redacting audit question failed: %v\n", err)
+			return
+		}
+		event.Question = verdict.Text
+	case QuestionHash:
+		mac := hmac.New(sha256.New, []byte(r.cfg.HashKey))
+		mac.Write([]byte(question))
+		event.QuestionHash = hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+func outcome(err error) string {
+	switch {
+	case err == nil:
+		return "answered"
+	case errors.Is(err, query.ErrQuestionBlocked):
+		return "blocked"
+	case errors.Is(err, query.ErrEmptyQuestion), errors.Is(err, query.ErrInvalidLanguage):
+		return "rejected"
+	default:
+		return "failed"
+	}
+}
@@ -0,0 +1,131 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+const keyPrefix = "srm_"
+
+// parseStaticKey parses name:scopes:sha256, optionally followed by :tenant.
+func parseStaticKey(spec string) (domain.APIKey, error) {
+	parts := strings.Split(spec, ":")
+	if len(parts) < 3 || len(parts) > 4 || parts[0] == "" {
+		return domain.APIKey{}, fmt.Errorf("static api key %q: want name:scopes:sha256[:tenant]", spec)
+	}
+	hash := strings.ToLower(parts[2])
+	if b, err := hex.DecodeString(hash); err != nil || len(b) != sha256.Size {
+		return domain.APIKey{}, fmt.Errorf("static api key %q: hash must be hex SHA-256", parts[0])
+	}
+	scopes, err := ParseScopes(strings.Split(parts[1], "+"))
+	if err != nil {
+		return domain.APIKey{}, fmt.Errorf("static api key %q: %w", parts[0], err)
+	}
+	key := domain.APIKey{ID: parts[0], Name: parts[0], Hash: hash, Scopes: scopes, Static: true}
+	if len(parts) == 4 {
+		key.Tenant = parts[3]
+		if err := checkTenantScopes(key.Tenant, scopes); err != nil {
+			return domain.APIKey{}, fmt.Errorf("static api key %q: %w", parts[0], err)
+		}
+	}
+	return key, nil
+}
+
+// checkTenantScopes refuses the admin scope to a tenant's key, since admin
+// routes reach every tenant's data.
+func checkTenantScopes(tenant string, scopes []domain.Scope) error {
+	if tenant == "" {
+		return nil
+	}
+	if slices.Contains(scopes, domain.ScopeAdmin) {
+		return ErrTenantAdmin
+	}
+	return nil
+}
+
+func ParseScopes(names []string) ([]domain.Scope, error) {
+	scopes := make([]domain.Scope, 0, len(names))
+	for _, name := range names {
+		switch scope := domain.Scope(strings.TrimSpace(name)); scope {
+		case domain.ScopeQuery, domain.ScopeIngest, domain.ScopeAdmin:
+			scopes = append(scopes, scope)
+		default:
+			return nil, fmt.Errorf("unknown scope %q", name)
+		}
+	}
+	if len(scopes) == 0 {
+		return nil, fmt.Errorf("at least one scope is required")
+	}
+	return scopes, nil
+}
+
+func HashKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+func (s *Service) authenticateKey(ctx context.Context, raw string) (*domain.Principal, error) {
+	hash := HashKey(raw)
+	key, ok := s.static[hash]
+	if !ok {
+		stored, err := s.repo.FindAPIKeyByHash(ctx, hash)
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, ErrInvalidCredentials
+		}
+		if err != nil {
+			return nil, err
+		}
+		key = *stored
+	}
+	return &domain.Principal{ID: key.ID, Name: key.Name, Method: "api_key", Scopes: key.Scopes, Tenant: key.Tenant}, nil
+}
+
+// CreateKey stores a new key, limited to tenant when set, and returns its
+// secret, which is not retrievable later.
+func (s *Service) CreateKey(ctx context.Context, name string, scopes []domain.Scope, tenant string) (string, *domain.APIKey, error) {
+	if err := checkTenantScopes(tenant, scopes); err != nil {
+		return "", nil, err
+	}
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	raw := keyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	key := domain.APIKey{
+		ID:        id.New(),
+		Name:      name,
+		Prefix:    raw[:len(keyPrefix)+6],
+		Hash:      HashKey(raw),
+		Scopes:    scopes,
+		Tenant:    tenant,
+		CreatedAt: time.Now().UTC(),
+	}
+	if err := s.repo.SaveAPIKey(ctx, key); err != nil {
+		return "", nil, err
+	}
+	return raw, &key, nil
+}
+
+// ListKeys returns static keys followed by stored keys.
+func (s *Service) ListKeys(ctx context.Context) ([]domain.APIKey, error) {
+	stored, err := s.repo.ListAPIKeys(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]domain.APIKey(nil), s.staticKeys...), stored...), nil
+}
+
+func (s *Service) RevokeKey(ctx context.Context, id string) error {
+	return s.repo.DeleteAPIKey(ctx, id)
+}
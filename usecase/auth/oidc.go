@@ -0,0 +1,94 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+func (s *Service) authenticateToken(ctx context.Context, token string) (*domain.Principal, error) {
+	claims, err := s.tokens.VerifyToken(ctx, token)
+	if errors.Is(err, domain.ErrInvalidToken) {
+		log.Printf("Rejected bearer token: %v\n", err)
+		return nil, ErrInvalidCredentials
+	}
+	if err != nil {
+		return nil, err
+	}
+	sub, _ := claims["sub"].(string)
+	if sub == "" {
+		return nil, ErrInvalidCredentials
+	}
+
+	p := &domain.Principal{ID: sub, Method: "oidc", Roles: s.roles(claims)}
+	p.Email, _ = claims["email"].(string)
+	// Some providers send the claim as a string.
+	switch verified := claims["email_verified"].(type) {
+	case bool:
+		p.EmailVerified = verified
+	case string:
+		p.EmailVerified = strings.EqualFold(verified, "true")
+	}
+	p.Username, _ = claims["preferred_username"].(string)
+	if p.Name, _ = claims["name"].(string); p.Name == "" {
+		p.Name = p.Username
+	}
+	p.Groups = claimValues(claims, s.cfg.GroupsClaim)
+	if tenants := claimValues(claims, s.cfg.TenantClaim); len(tenants) > 0 {
+		p.Tenant = tenants[0]
+	}
+	for _, role := range p.Roles {
+		p.Scopes = append(p.Scopes, role.Scopes()...)
+	}
+	return p, nil
+}
+
+// roles maps the values of the roles claim to known roles, plus the default roles.
+func (s *Service) roles(claims map[string]any) []domain.AccessRole {
+	var roles []domain.AccessRole
+	for _, name := range s.cfg.DefaultRoles {
+		roles = append(roles, domain.AccessRole(name))
+	}
+	for _, value := range claimValues(claims, s.cfg.RolesClaim) {
+		name, ok := s.cfg.RoleMapping[value]
+		if !ok {
+			name = value
+		}
+		if role := domain.AccessRole(name); role.Valid() && !slices.Contains(roles, role) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
+
+// claimValues returns the string values at a dotted claim path; a space-separated
+// string (like the standard "scope" claim) yields one value per word.
+func claimValues(claims map[string]any, path string) []string {
+	if path == "" {
+		return nil
+	}
+	var v any = claims
+	for _, name := range strings.Split(path, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[name]
+	}
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			values = append(values, fmt.Sprint(item))
+		}
+		return values
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+var (
+	ErrMissingCredentials = errors.New("missing credentials")
+	ErrInvalidCredentials = errors.New("invalid credentials")
+	ErrTenantAdmin        = errors.New("a tenant's key can't have the admin scope")
+)
+
+type Config struct {
+	Enabled bool
+	// StaticKeys are "name:scope+scope:sha256hex" entries, optionally followed
+	// by ":tenant"; the hash is the hex SHA-256 of the key, e.g. from
+	// `printf %s "$KEY" | sha256sum`.
+	StaticKeys []string
+	// RolesClaim is the dotted path of the token claim listing the caller's
+	// groups or roles, e.g. "groups" or "realm_access.roles".
+	RolesClaim string
+	// RoleMapping maps claim values to roles; values that already name a role map to themselves.
+	RoleMapping  map[string]string
+	DefaultRoles []string
+	// GroupsClaim holds the caller's groups, matched against Confluence group permissions.
+	GroupsClaim string
+	// TenantClaim is the dotted path of the token claim naming the caller's
+	// tenant; callers without one aren't limited to a tenant.
+	TenantClaim string
+}
+
+// Service authenticates API keys and, when a token verifier is configured,
+// OIDC bearer tokens.
+type Service struct {
+	repo       domain.APIKeyRepository
+	tokens     domain.TokenVerifier
+	cfg        Config
+	static     map[string]domain.APIKey
+	staticKeys []domain.APIKey
+}
+
+func NewService(repo domain.APIKeyRepository, tokens domain.TokenVerifier, cfg Config) (*Service, error) {
+	s := &Service{repo: repo, tokens: tokens, cfg: cfg, static: make(map[string]domain.APIKey, len(cfg.StaticKeys))}
+	for _, spec := range cfg.StaticKeys {
+		key, err := parseStaticKey(spec)
+		if err != nil {
+			return nil, err
+		}
+		s.static[key.Hash] = key
+		s.staticKeys = append(s.staticKeys, key)
+	}
+	for value, role := range cfg.RoleMapping {
+		if !domain.AccessRole(role).Valid() {
+			return nil, fmt.Errorf("role mapping %q: unknown role %q", value, role)
+		}
+	}
+	for _, role := range cfg.DefaultRoles {
+		if !domain.AccessRole(role).Valid() {
+			return nil, fmt.Errorf("unknown default role %q", role)
+		}
+	}
+	return s, nil
+}
+
+// Authenticate resolves a credential from the request: a JWT when OIDC is
+// configured and the credential looks like one, otherwise an API key.
+func (s *Service) Authenticate(ctx context.Context, credential string) (*domain.Principal, error) {
+	if credential == "" {
+		return nil, ErrMissingCredentials
+	}
+	if s.tokens != nil && strings.Count(credential, ".") == 2 {
+		return s.authenticateToken(ctx, credential)
+	}
+	return s.authenticateKey(ctx, credential)
+}
+
+type contextKey struct{}
+
+func WithPrincipal(ctx context.Context, p *domain.Principal) context.Context {
+	return context.WithValue(ctx, contextKey{}, p)
+}
+
+// PrincipalFromContext returns the caller that authenticated the request, if any.
+func PrincipalFromContext(ctx context.Context) *domain.Principal {
+	p, _ := ctx.Value(contextKey{}).(*domain.Principal)
+	return p
+}
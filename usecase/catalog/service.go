@@ -0,0 +1,164 @@
+package catalog
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// Document is an indexed page, summarised from its chunks.
+type Document struct {
+	ID        string    `json:"id"`
+	Title     string    `json:"title"`
+	SpaceKey  string    `json:"space_key"`
+	URL       string    `json:"url"`
+	Labels    []string  `json:"labels,omitempty"`
+	Chunks    int       `json:"chunks"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Space is a space with indexed documents.
+type Space struct {
+	Key       string    `json:"key"`
+	Documents int       `json:"documents"`
+	Chunks    int       `json:"chunks"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Service lists what is in the index, limited by a search filter the same way
+// search results are.
+type Service struct {
+	store domain.VectorStore
+}
+
+func NewService(store domain.VectorStore) *Service {
+	return &Service{store: store}
+}
+
+// Documents returns the readable documents sorted by space, then title.
+func (s *Service) Documents(ctx context.Context, filter domain.SearchFilter) ([]Document, error) {
+	byID := map[string]*Document{}
+	err := s.store.ScanChunks(ctx, func(c domain.Chunk) error {
+		if !readable(c, filter) {
+			return nil
+		}
+		d, ok := byID[c.DocumentID]
+		if !ok {
+			d = &Document{ID: c.DocumentID, Title: c.Title, SpaceKey: c.SpaceKey, URL: c.URL, Labels: c.Labels}
+			byID[c.DocumentID] = d
+		}
+		d.Chunks++
+		if c.UpdatedAt.After(d.UpdatedAt) {
+			d.UpdatedAt = c.UpdatedAt
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan index: %w", err)
+	}
+	docs := make([]Document, 0, len(byID))
+	for _, d := range byID {
+		docs = append(docs, *d)
+	}
+	slices.SortFunc(docs, func(a, b Document) int {
+		if c := strings.Compare(a.SpaceKey, b.SpaceKey); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Title, b.Title); c != 0 {
+			return c
+		}
+		return strings.Compare(a.ID, b.ID)
+	})
+	return docs, nil
+}
+
+// Query narrows a document search; empty fields match every document.
+type Query struct {
+	// Title matches titles containing it, ignoring case.
+	Title    string
+	SpaceKey string
+	Label    string
+}
+
+func (q Query) matches(d Document) bool {
+	if q.SpaceKey != "" && d.SpaceKey != q.SpaceKey {
+		return false
+	}
+	if q.Label != "" && !slices.ContainsFunc(d.Labels, func(l string) bool { return strings.EqualFold(l, q.Label) }) {
+		return false
+	}
+	return q.Title == "" || strings.Contains(strings.ToLower(d.Title), strings.ToLower(q.Title))
+}
+
+// Search returns the readable documents matching q, sorted like Documents.
+func (s *Service) Search(ctx context.Context, filter domain.SearchFilter, q Query) ([]Document, error) {
+	if q.SpaceKey != "" {
+		filter.SpaceKeys = append(filter.SpaceKeys, q.SpaceKey)
+	}
+	docs, err := s.Documents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(docs, func(d Document) bool { return !q.matches(d) }), nil
+}
+
+// Document returns an indexed document with its chunks in order, or
+// domain.ErrNotFound.
+func (s *Service) Document(ctx context.Context, documentID string) (*Document, []domain.Chunk, error) {
+	chunks, err := s.store.DocumentChunks(ctx, documentID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("read chunks of %s: %w", documentID, err)
+	}
+	if len(chunks) == 0 {
+		return nil, nil, domain.ErrNotFound
+	}
+	d := &Document{ID: documentID, Title: chunks[0].Title, SpaceKey: chunks[0].SpaceKey, URL: chunks[0].URL, Labels: chunks[0].Labels, Chunks: len(chunks)}
+	for _, c := range chunks {
+		if c.UpdatedAt.After(d.UpdatedAt) {
+			d.UpdatedAt = c.UpdatedAt
+		}
+	}
+	return d, chunks, nil
+}
+
+// Spaces returns the spaces with readable documents sorted by key.
+func (s *Service) Spaces(ctx context.Context, filter domain.SearchFilter) ([]Space, error) {
+	docs, err := s.Documents(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	var spaces []Space
+	for _, d := range docs {
+		if len(spaces) == 0 || spaces[len(spaces)-1].Key != d.SpaceKey {
+			spaces = append(spaces, Space{Key: d.SpaceKey})
+		}
+		sp := &spaces[len(spaces)-1]
+		sp.Documents++
+		sp.Chunks += d.Chunks
+		if d.UpdatedAt.After(sp.UpdatedAt) {
+			sp.UpdatedAt = d.UpdatedAt
+		}
+	}
+	return spaces, nil
+}
+
+// readable applies filter as the vector stores do when searching.
+func readable(c domain.Chunk, filter domain.SearchFilter) bool {
+	if len(filter.SpaceKeys) > 0 && !slices.Contains(filter.SpaceKeys, c.SpaceKey) {
+		return false
+	}
+	if len(filter.DocumentIDs) > 0 && !slices.Contains(filter.DocumentIDs, c.DocumentID) {
+		return false
+	}
+	if slices.Contains(filter.ExcludeDocumentIDs, c.DocumentID) {
+		return false
+	}
+	if len(filter.Readers) > 0 && len(c.Readers) > 0 && !slices.ContainsFunc(filter.Readers, func(r string) bool { return slices.Contains(c.Readers, r) }) {
+		return false
+	}
+	return true
+}
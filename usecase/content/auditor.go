@@ -0,0 +1,256 @@
+package content
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Config struct {
+	Interval           time.Duration
+	DuplicateThreshold float64
+	StaleAfter         time.Duration
+	StaleMinRetrievals int
+	RetrievalLookback  time.Duration
+}
+
+type PageRef struct {
+	DocumentID string    `json:"document_id"`
+	SpaceKey   string    `json:"space_key"`
+	Title      string    `json:"title"`
+	URL        string    `json:"url"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+type DuplicatePair struct {
+	A          PageRef `json:"a"`
+	B          PageRef `json:"b"`
+	Similarity float64 `json:"similarity"`
+}
+
+type StalePage struct {
+	PageRef
+	Retrievals int `json:"retrievals"`
+	AgeDays    int `json:"age_days"`
+}
+
+type Report struct {
+	GeneratedAt  time.Time       `json:"generated_at"`
+	Duration     string          `json:"duration"`
+	PagesScanned int             `json:"pages_scanned"`
+	Duplicates   []DuplicatePair `json:"duplicates"`
+	StalePages   []StalePage     `json:"stale_pages"`
+}
+
+var ErrRunning = errors.New("content audit already running")
+
+// Auditor scans the index for near-duplicate pages and stale pages that are still
+// frequently served as answer context, so doc owners know what to clean up.
+type Auditor struct {
+	store domain.VectorStore
+	stats domain.RetrievalStats
+	cfg   Config
+
+	mu      sync.Mutex
+	running bool
+	last    *Report
+}
+
+func NewAuditor(store domain.VectorStore, stats domain.RetrievalStats, cfg Config) *Auditor {
+	return &Auditor{store: store, stats: stats, cfg: cfg}
+}
+
+func (a *Auditor) LastReport() *Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.last
+}
+
+// Start runs the audit on the configured interval until ctx is cancelled.
+func (a *Auditor) Start(ctx context.Context) {
+	if a.cfg.Interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(a.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := a.Run(ctx); err != nil && !errors.Is(err, ErrRunning) {
+					log.Printf("Content audit failed: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+func (a *Auditor) Run(ctx context.Context) (*Report, error) {
+	if !a.begin() {
+		return nil, ErrRunning
+	}
+	return a.finish(a.run(ctx))
+}
+
+// Trigger starts an audit in the background; callers poll LastReport for the result.
+func (a *Auditor) Trigger(ctx context.Context) error {
+	if !a.begin() {
+		return ErrRunning
+	}
+	go func() {
+		if _, err := a.finish(a.run(ctx)); err != nil {
+			log.Printf("Content audit failed: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+func (a *Auditor) begin() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.running {
+		return false
+	}
+	a.running = true
+	return true
+}
+
+func (a *Auditor) finish(report *Report, err error) (*Report, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.running = false
+	if err != nil {
+		return nil, err
+	}
+	a.last = report
+	return report, nil
+}
+
+type pageVector struct {
+	ref    PageRef
+	sum    []float32
+	chunks int
+}
+
+func (a *Auditor) run(ctx context.Context) (*Report, error) {
+	start := time.Now()
+
+	pages := make(map[string]*pageVector)
+	err := a.store.ScanChunks(ctx, func(c domain.Chunk) error {
+		p, ok := pages[c.DocumentID]
+		if !ok {
+			p = &pageVector{
+				ref: PageRef{DocumentID: c.DocumentID, SpaceKey: c.SpaceKey, Title: c.Title, URL: c.URL, UpdatedAt: c.UpdatedAt},
+				sum: make([]float32, len(c.Embedding)),
+			}
+			pages[c.DocumentID] = p
+		}
+		if len(c.Embedding) != len(p.sum) {
+			return nil
+		}
+		for i, v := range c.Embedding {
+			p.sum[i] += v
+		}
+		p.chunks++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan index: %w", err)
+	}
+
+	duplicates, err := a.findDuplicates(ctx, pages)
+	if err != nil {
+		return nil, err
+	}
+	stale, err := a.findStale(ctx, pages, start)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Report{
+		GeneratedAt:  start.UTC(),
+		Duration:     time.Since(start).Round(time.Millisecond).String(),
+		PagesScanned: len(pages),
+		Duplicates:   duplicates,
+		StalePages:   stale,
+	}, nil
+}
+
+func (a *Auditor) findDuplicates(ctx context.Context, pages map[string]*pageVector) ([]DuplicatePair, error) {
+	seen := make(map[[2]string]bool)
+	duplicates := []DuplicatePair{}
+
+	for id, p := range pages {
+		if p.chunks == 0 {
+			continue
+		}
+		centroid := make([]float32, len(p.sum))
+		for i, v := range p.sum {
+			centroid[i] = v / float32(p.chunks)
+		}
+
+		hits, err := a.store.Search(ctx, centroid, 10, domain.SearchFilter{ExcludeDocumentIDs: []string{id}})
+		if err != nil {
+			return nil, fmt.Errorf("search neighbours of %s: %w", id, err)
+		}
+		for _, h := range hits {
+			if h.Score < a.cfg.DuplicateThreshold {
+				break
+			}
+			key := [2]string{id, h.DocumentID}
+			if key[0] > key[1] {
+				key[0], key[1] = key[1], key[0]
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			other := PageRef{DocumentID: h.DocumentID, SpaceKey: h.SpaceKey, Title: h.Title, URL: h.URL, UpdatedAt: h.UpdatedAt}
+			if o, ok := pages[h.DocumentID]; ok {
+				other = o.ref
+			}
+			duplicates = append(duplicates, DuplicatePair{A: p.ref, B: other, Similarity: h.Score})
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool { return duplicates[i].Similarity > duplicates[j].Similarity })
+	return duplicates, nil
+}
+
+func (a *Auditor) findStale(ctx context.Context, pages map[string]*pageVector, now time.Time) ([]StalePage, error) {
+	stale := []StalePage{}
+	if a.stats == nil {
+		return stale, nil
+	}
+
+	counts, err := a.stats.RetrievalCounts(ctx, now.Add(-a.cfg.RetrievalLookback))
+	if err != nil {
+		return nil, fmt.Errorf("load retrieval counts: %w", err)
+	}
+
+	cutoff := now.Add(-a.cfg.StaleAfter)
+	for id, p := range pages {
+		if p.ref.UpdatedAt.IsZero() || p.ref.UpdatedAt.After(cutoff) {
+			continue
+		}
+		if n := counts[id]; n >= a.cfg.StaleMinRetrievals {
+			stale = append(stale, StalePage{
+				PageRef:    p.ref,
+				Retrievals: n,
+				AgeDays:    int(now.Sub(p.ref.UpdatedAt).Hours() / 24),
+			})
+		}
+	}
+
+	sort.Slice(stale, func(i, j int) bool { return stale[i].Retrievals > stale[j].Retrievals })
+	return stale, nil
+}
@@ -0,0 +1,140 @@
+package diagrams
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const defaultPrompt = `This image is embedded in an internal documentation page titled %q.
+If it is a diagram (architecture, sequence, flow, data model), describe it for someone who cannot see it:
+name every component, what connects to what and in which direction, and any labels on the connections.
+If it is a screenshot or photo, summarize the information it shows. Answer in plain prose, without preamble.`
+
+// cacheLimit bounds the description cache; it is cleared when full.
+const cacheLimit = 1000
+
+var supportedTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+type Config struct {
+	Model         string
+	MaxImages     int
+	MaxImageBytes int64
+	MaxTokens     int
+}
+
+type ImageSource interface {
+	FetchImage(ctx context.Context, documentID string, img domain.DocumentImage, maxBytes int64) ([]byte, string, error)
+}
+
+// Describer adds vision-model descriptions of a page's images to its body so
+// diagrams are searchable alongside the text.
+type Describer struct {
+	source ImageSource
+	model  domain.VisionModel
+	cfg    Config
+
+	mu    sync.Mutex
+	cache map[[sha256.Size]byte]string
+}
+
+func NewDescriber(source ImageSource, model domain.VisionModel, cfg Config) *Describer {
+	if cfg.MaxImages <= 0 {
+		cfg.MaxImages = 5
+	}
+	if cfg.MaxImageBytes <= 0 {
+		cfg.MaxImageBytes = 5 << 20
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = 500
+	}
+	return &Describer{source: source, model: model, cfg: cfg, cache: map[[sha256.Size]byte]string{}}
+}
+
+func (d *Describer) Preprocess(ctx context.Context, doc *domain.Document) error {
+	images := doc.Images
+	if len(images) > d.cfg.MaxImages {
+		images = images[:d.cfg.MaxImages]
+	}
+
+	var b strings.Builder
+	var failed int
+	for _, img := range images {
+		description, err := d.describe(ctx, doc, img)
+		if err != nil {
+			log.Printf("Describing image %s of %s failed: %v\n", img.Name(), doc.ID, err)
+			failed++
+			continue
+		}
+		if description == "" {
+			continue
+		}
+		label := img.Alt
+		if label == "" {
+			label = img.Name()
+		}
+		fmt.Fprintf(&b, "\n\nDiagram %s: %s", label, description)
+	}
+	doc.Body += b.String()
+
+	if failed > 0 && failed == len(images) {
+		return fmt.Errorf("none of %d images could be described", failed)
+	}
+	return nil
+}
+
+func (d *Describer) describe(ctx context.Context, doc *domain.Document, img domain.DocumentImage) (string, error) {
+	data, contentType, err := d.source.FetchImage(ctx, doc.ID, img, d.cfg.MaxImageBytes)
+	if err != nil {
+		return "", err
+	}
+	if !supportedTypes[contentType] {
+		return "", nil
+	}
+
+	key := sha256.Sum256(data)
+	d.mu.Lock()
+	cached, ok := d.cache[key]
+	d.mu.Unlock()
+	if ok {
+		return cached, nil
+	}
+
+	completion, err := d.model.DescribeImage(ctx, domain.ImageInput{
+		Model:       d.cfg.Model,
+		Prompt:      fmt.Sprintf(defaultPrompt, doc.Title),
+		Data:        data,
+		ContentType: contentType,
+		MaxTokens:   d.cfg.MaxTokens,
+	})
+	if err != nil {
+		return "", err
+	}
+	description := strings.TrimSpace(completion.Content)
+
+	d.mu.Lock()
+	if len(d.cache) >= cacheLimit {
+		clear(d.cache)
+	}
+	d.cache[key] = description
+	d.mu.Unlock()
+	return description, nil
+}
+
+// Flush forgets every cached description, so images are described again the
+// next time their page is indexed.
+func (d *Describer) Flush() {
+	d.mu.Lock()
+	clear(d.cache)
+	d.mu.Unlock()
+}
@@ -0,0 +1,329 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/usecase/gaps"
+)
+
+// summaryLength is the length of the opening text quoted for each page.
+const summaryLength = 280
+
+var ErrRunning = errors.New("digest already running")
+
+//go:embed templates
+var templates embed.FS
+
+type Config struct {
+	Interval time.Duration
+	// Recipients get the pages of their spaces, or of every space when they
+	// have none. Unanswered questions aren't tied to spaces; everyone gets them.
+	Recipients []Recipient
+	Subject    string
+	MaxPages   int
+	MaxTopics  int
+	// HTMLTemplate and TextTemplate replace the built-in templates when set.
+	HTMLTemplate string
+	TextTemplate string
+}
+
+type Recipient struct {
+	Email     string
+	SpaceKeys []string
+}
+
+// Page is a page changed during the digest period.
+type Page struct {
+	Title     string
+	URL       string
+	SpaceKey  string
+	UpdatedAt time.Time
+	Summary   string
+}
+
+// Digest is the data the templates are executed with.
+type Digest struct {
+	Recipient Recipient
+	SpaceKeys []string
+	Since     time.Time
+	Until     time.Time
+	Pages     []Page
+	// MorePages counts changed pages beyond MaxPages.
+	MorePages int
+	Questions []gaps.Topic
+}
+
+// Service periodically emails each recipient the pages changed since the last
+// digest and the questions the documentation answered poorly.
+type Service struct {
+	store  domain.VectorStore
+	gaps   *gaps.Tracker
+	mailer domain.Mailer
+	cfg    Config
+
+	subject *texttemplate.Template
+	html    *htmltemplate.Template
+	text    *texttemplate.Template
+
+	mu      sync.Mutex
+	running bool
+	lastRun time.Time
+}
+
+func NewService(store domain.VectorStore, tracker *gaps.Tracker, mailer domain.Mailer, cfg Config) (*Service, error) {
+	funcs := map[string]any{
+		"join": strings.Join,
+		"inc":  func(i int) int { return i + 1 },
+	}
+	s := &Service{store: store, gaps: tracker, mailer: mailer, cfg: cfg}
+	var err error
+	if s.subject, err = texttemplate.New("subject").Funcs(funcs).Parse(cfg.Subject); err != nil {
+		return nil, fmt.Errorf("digest subject: %w", err)
+	}
+	html, err := load(cfg.HTMLTemplate, "templates/digest.html.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if s.html, err = htmltemplate.New("html").Funcs(funcs).Parse(html); err != nil {
+		return nil, fmt.Errorf("digest html template: %w", err)
+	}
+	text, err := load(cfg.TextTemplate, "templates/digest.txt.tmpl")
+	if err != nil {
+		return nil, err
+	}
+	if s.text, err = texttemplate.New("text").Funcs(funcs).Parse(text); err != nil {
+		return nil, fmt.Errorf("digest text template: %w", err)
+	}
+	return s, nil
+}
+
+func load(path, builtin string) (string, error) {
+	var b []byte
+	var err error
+	if path != "" {
+		b, err = os.ReadFile(path)
+	} else {
+		b, err = templates.ReadFile(builtin)
+	}
+	if err != nil {
+		return "", fmt.Errorf("digest template: %w", err)
+	}
+	return string(b), nil
+}
+
+// Start sends digests on the configured interval until ctx is cancelled. The
+// first digest covers the interval before it.
+func (s *Service) Start(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Run(ctx); err != nil && !errors.Is(err, ErrRunning) {
+					log.Printf("Sending digests failed: %v\n", err)
+				}
+			}
+		}
+	}()
+}
+
+// Run sends a digest to every recipient with something to report and returns
+// how many were sent. A recipient who can't be sent to doesn't stop the rest.
+func (s *Service) Run(ctx context.Context) (int, error) {
+	if !s.begin() {
+		return 0, ErrRunning
+	}
+	return s.run(ctx)
+}
+
+// Trigger sends digests in the background.
+func (s *Service) Trigger(ctx context.Context) error {
+	if !s.begin() {
+		return ErrRunning
+	}
+	go func() {
+		if _, err := s.run(ctx); err != nil {
+			log.Printf("Sending digests failed: %v\n", err)
+		}
+	}()
+	return nil
+}
+
+func (s *Service) run(ctx context.Context) (int, error) {
+	until := time.Now().UTC()
+	since := s.since(until)
+	pages, questions, err := s.collect(ctx, since)
+	if err != nil {
+		s.finish(time.Time{})
+		return 0, err
+	}
+	// The period counts as covered even if some digests failed, so the
+	// others don't get the same pages again.
+	defer s.finish(until)
+	return s.send(ctx, Digest{Since: since, Until: until, Questions: questions}, pages)
+}
+
+func (s *Service) collect(ctx context.Context, since time.Time) ([]Page, []gaps.Topic, error) {
+	pages, err := s.changedPages(ctx, since)
+	if err != nil {
+		return nil, nil, err
+	}
+	var questions []gaps.Topic
+	if s.gaps != nil && s.cfg.MaxTopics > 0 {
+		if questions, err = s.gaps.Topics(ctx, since, s.cfg.MaxTopics); err != nil {
+			return nil, nil, fmt.Errorf("load unanswered questions: %w", err)
+		}
+	}
+	return pages, questions, nil
+}
+
+// send renders base with each recipient's pages and mails it.
+func (s *Service) send(ctx context.Context, base Digest, pages []Page) (int, error) {
+	sent := 0
+	var errs []error
+	for _, r := range s.cfg.Recipients {
+		d := base
+		d.Recipient, d.SpaceKeys = r, r.SpaceKeys
+		for _, p := range pages {
+			if len(r.SpaceKeys) == 0 || slices.Contains(r.SpaceKeys, p.SpaceKey) {
+				d.Pages = append(d.Pages, p)
+			}
+		}
+		if s.cfg.MaxPages > 0 && len(d.Pages) > s.cfg.MaxPages {
+			d.MorePages = len(d.Pages) - s.cfg.MaxPages
+			d.Pages = d.Pages[:s.cfg.MaxPages]
+		}
+		if len(d.Pages) == 0 && len(d.Questions) == 0 {
+			continue
+		}
+		email, err := s.render(d)
+		if err == nil {
+			err = s.mailer.Send(ctx, email)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Email, err))
+			continue
+		}
+		sent++
+	}
+	log.Printf("Sent %d digests covering %d changed pages\n", sent, len(pages))
+	return sent, errors.Join(errs...)
+}
+
+func (s *Service) render(d Digest) (domain.Email, error) {
+	var subject, text, html bytes.Buffer
+	if err := s.subject.Execute(&subject, d); err != nil {
+		return domain.Email{}, fmt.Errorf("render subject: %w", err)
+	}
+	if err := s.text.Execute(&text, d); err != nil {
+		return domain.Email{}, fmt.Errorf("render text: %w", err)
+	}
+	if err := s.html.Execute(&html, d); err != nil {
+		return domain.Email{}, fmt.Errorf("render html: %w", err)
+	}
+	return domain.Email{
+		To:      []string{d.Recipient.Email},
+		Subject: strings.TrimSpace(subject.String()),
+		Text:    text.String(),
+		HTML:    html.String(),
+	}, nil
+}
+
+// changedPages lists pages updated since, newest first, summarised by the
+// opening text of their first chunk.
+func (s *Service) changedPages(ctx context.Context, since time.Time) ([]Page, error) {
+	byID := map[string]*Page{}
+	first := map[string]int{}
+	err := s.store.ScanChunks(ctx, func(c domain.Chunk) error {
+		if !c.UpdatedAt.After(since) {
+			return nil
+		}
+		p, ok := byID[c.DocumentID]
+		if !ok {
+			p = &Page{Title: c.Title, URL: c.URL, SpaceKey: c.SpaceKey}
+			byID[c.DocumentID] = p
+			first[c.DocumentID] = c.Index + 1
+		}
+		if c.UpdatedAt.After(p.UpdatedAt) {
+			p.UpdatedAt = c.UpdatedAt
+		}
+		if c.Index < first[c.DocumentID] {
+			first[c.DocumentID] = c.Index
+			p.Summary = summarize(c.Text)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan index: %w", err)
+	}
+	pages := make([]Page, 0, len(byID))
+	for _, p := range byID {
+		pages = append(pages, *p)
+	}
+	slices.SortFunc(pages, func(a, b Page) int { return b.UpdatedAt.Compare(a.UpdatedAt) })
+	return pages, nil
+}
+
+func summarize(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	if utf8.RuneCountInString(text) <= summaryLength {
+		return text
+	}
+	runes := []rune(text)[:summaryLength]
+	if i := strings.LastIndexByte(string(runes), ' '); i > 0 {
+		return string(runes)[:i] + "…"
+	}
+	return string(runes) + "…"
+}
+
+func (s *Service) since(until time.Time) time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.lastRun.IsZero() {
+		return s.lastRun
+	}
+	if s.cfg.Interval > 0 {
+		return until.Add(-s.cfg.Interval)
+	}
+	return until.Add(-24 * time.Hour)
+}
+
+func (s *Service) begin() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return false
+	}
+	s.running = true
+	return true
+}
+
+// finish ends a run, moving the next period's start to covered when set.
+func (s *Service) finish(covered time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.running = false
+	if !covered.IsZero() {
+		s.lastRun = covered
+	}
+}
@@ -0,0 +1,113 @@
+// Package erasure deletes what is stored about a user when they ask for it,
+// e.g. under the GDPR right to erasure, and reports what was deleted.
+package erasure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+var ErrInvalidSubject = errors.New("subject must be a principal ID")
+
+type Status string
+
+const (
+	StatusCompleted Status = "completed"
+	// StatusPartial means some stores failed; erasing again retries them.
+	StatusPartial Status = "partial"
+)
+
+// Report is what one erasure deleted.
+type Report struct {
+	ID      string `json:"id"`
+	Subject string `json:"subject"`
+	Status  Status `json:"status"`
+	// ConversationMessages, Feedback and AuditEntries count the records
+	// deleted from each store.
+	ConversationMessages int `json:"conversation_messages"`
+	Feedback             int `json:"feedback"`
+	AuditEntries         int `json:"audit_entries"`
+	// Errors are the stores that failed.
+	Errors []string `json:"errors,omitempty"`
+	// Retained are the stores that may still hold the subject's data but
+	// can't be erased from here, with when it leaves them.
+	Retained    []string  `json:"retained,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+}
+
+// Service erases a subject, the principal ID their records are kept under:
+// the conversations keyed by it, the feedback they gave and their entries in
+// the audit log. Feedback given before principals were recorded with it can't
+// be traced back to them and is left to retention.
+type Service struct {
+	conversations domain.ConversationRepository
+	feedback      domain.FeedbackRepository
+	audit         domain.AuditEraser
+	retained      []string
+}
+
+type Option func(*Service)
+
+// WithAuditLog also removes the subject's entries from the audit log.
+func WithAuditLog(a domain.AuditEraser) Option {
+	return func(s *Service) { s.audit = a }
+}
+
+// WithRetained lists a store the subject's data can't be erased from in each
+// report, e.g. a stream whose records only expire.
+func WithRetained(note string) Option {
+	return func(s *Service) { s.retained = append(s.retained, note) }
+}
+
+func NewService(conversations domain.ConversationRepository, feedback domain.FeedbackRepository, opts ...Option) *Service {
+	s := &Service{conversations: conversations, feedback: feedback}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Erase deletes the subject's records from every store, carrying on past
+// failures, which the report lists.
+func (s *Service) Erase(ctx context.Context, subject string) (Report, error) {
+	// Conversation keys are the principal ID and the session ID joined by a
+	// slash, so a subject with one would match someone else's.
+	if subject == "" || strings.Contains(subject, "/") {
+		return Report{}, ErrInvalidSubject
+	}
+	report := Report{ID: id.New(), Subject: subject, Retained: s.retained, StartedAt: time.Now().UTC()}
+	fail := func(store string, err error) {
+		log.Printf("Erasure %s failed on %s: %v\n", report.ID, store, err)
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", store, err))
+	}
+	scope := domain.RetentionScope{Tenant: subject}
+	var err error
+	if report.ConversationMessages, err = s.conversations.PurgeMessages(ctx, time.Now(), scope); err != nil {
+		fail("conversations", err)
+	}
+	if report.Feedback, err = s.feedback.Purge(ctx, time.Now(), scope); err != nil {
+		fail("feedback", err)
+	}
+	if s.audit != nil {
+		if report.AuditEntries, err = s.audit.Erase(ctx, subject); err != nil {
+			fail("audit log", err)
+		}
+	}
+	report.Status = StatusCompleted
+	if len(report.Errors) > 0 {
+		report.Status = StatusPartial
+	}
+	report.CompletedAt = time.Now().UTC()
+	// The subject isn't logged, so the erasure leaves no trace of them here.
+	log.Printf("Erasure %s %s: %d conversation messages, %d feedback, %d audit entries\n",
+		report.ID, report.Status, report.ConversationMessages, report.Feedback, report.AuditEntries)
+	return report, nil
+}
@@ -0,0 +1,63 @@
+package eval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const judgePrompt = `You grade answers produced by a documentation assistant.
+Compare the candidate answer with the reference answer and score it from 1 to 5:
+5 = fully correct and complete, 3 = partially correct, 1 = wrong or unsupported.
+Respond with JSON only: {"score": <1-5>, "reasoning": "<one sentence>"}`
+
+type Verdict struct {
+	Score     float64 `json:"score"`
+	Reasoning string  `json:"reasoning"`
+}
+
+// Judge scores a generated answer against a reference using a language model.
+type Judge struct {
+	model     domain.ChatModel
+	modelName string
+}
+
+func NewJudge(model domain.ChatModel, modelName string) *Judge {
+	return &Judge{model: model, modelName: modelName}
+}
+
+func (j *Judge) Grade(ctx context.Context, question, expected, actual string) (*Verdict, error) {
+	completion, err := j.model.Complete(ctx, domain.CompletionRequest{
+		Model: j.modelName,
+		Messages: []domain.Message{
+			{Role: domain.RoleSystem, Content: judgePrompt},
+			{Role: domain.RoleUser, Content: fmt.Sprintf(
+				"Question: %s\n\nReference answer: %s\n\nCandidate answer: %s", question, expected, actual)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var v Verdict
+	if err := json.Unmarshal([]byte(extractJSON(completion.Content)), &v); err != nil {
+		return nil, fmt.Errorf("parse judge verdict %q: %w", completion.Content, err)
+	}
+	if v.Score < 1 || v.Score > 5 {
+		return nil, fmt.Errorf("judge score %v out of range", v.Score)
+	}
+	return &v, nil
+}
+
+// extractJSON trims any prose or code fences the model wraps around the object.
+func extractJSON(s string) string {
+	start := strings.Index(s, "{")
+	end := strings.LastIndex(s, "}")
+	if start < 0 || end < start {
+		return s
+	}
+	return s[start : end+1]
+}
@@ -0,0 +1,81 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+type Summary struct {
+	Cases          int           `json:"cases"`
+	Failures       int           `json:"failures"`
+	MeanRecall     float64       `json:"mean_recall"`
+	MRR            float64       `json:"mrr"`
+	MeanJudgeScore float64       `json:"mean_judge_score"`
+	MeanLatency    time.Duration `json:"mean_latency_ns"`
+}
+
+type Report struct {
+	StartedAt  time.Time `json:"started_at"`
+	FinishedAt time.Time `json:"finished_at"`
+	Summary    Summary   `json:"summary"`
+	Results    []Result  `json:"results"`
+}
+
+func (r *Report) summarize() {
+	s := Summary{Cases: len(r.Results)}
+	var answered, judged int
+	var latency time.Duration
+	for _, res := range r.Results {
+		// Failed cases count as zero recall so errors can't inflate the scores.
+		s.MeanRecall += res.Recall
+		s.MRR += res.ReciprocalRank
+		if res.Error != "" {
+			s.Failures++
+		}
+		if res.Answer != "" {
+			latency += res.Latency
+			answered++
+		}
+		if res.JudgeScore > 0 {
+			s.MeanJudgeScore += res.JudgeScore
+			judged++
+		}
+	}
+	if s.Cases > 0 {
+		s.MeanRecall /= float64(s.Cases)
+		s.MRR /= float64(s.Cases)
+	}
+	if answered > 0 {
+		s.MeanLatency = latency / time.Duration(answered)
+	}
+	if judged > 0 {
+		s.MeanJudgeScore /= float64(judged)
+	}
+	r.Summary = s
+}
+
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "CASE\tRECALL\tRR\tJUDGE\tLATENCY\tERROR")
+	for _, res := range r.Results {
+		fmt.Fprintf(tw, "%s\t%.2f\t%.2f\t%.1f\t%s\t%s\n",
+			res.CaseID, res.Recall, res.ReciprocalRank, res.JudgeScore, res.Latency.Round(time.Millisecond), res.Error)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	s := r.Summary
+	_, err := fmt.Fprintf(w, "\ncases=%d failures=%d recall=%.3f mrr=%.3f judge=%.2f latency=%s\n",
+		s.Cases, s.Failures, s.MeanRecall, s.MRR, s.MeanJudgeScore, s.MeanLatency.Round(time.Millisecond))
+	return err
+}
@@ -0,0 +1,91 @@
+package eval
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Pipeline interface {
+	Ask(ctx context.Context, q domain.Question) (*domain.Answer, error)
+}
+
+type Result struct {
+	CaseID         string        `json:"case_id"`
+	Question       string        `json:"question"`
+	Answer         string        `json:"answer,omitempty"`
+	RetrievedDocs  []string      `json:"retrieved_documents"`
+	Recall         float64       `json:"recall"`
+	ReciprocalRank float64       `json:"reciprocal_rank"`
+	JudgeScore     float64       `json:"judge_score"`
+	JudgeReason    string        `json:"judge_reasoning,omitempty"`
+	Latency        time.Duration `json:"latency_ns"`
+	Error          string        `json:"error,omitempty"`
+}
+
+type Runner struct {
+	pipeline Pipeline
+	judge    *Judge
+	topK     int
+}
+
+func NewRunner(pipeline Pipeline, judge *Judge, topK int) *Runner {
+	return &Runner{pipeline: pipeline, judge: judge, topK: topK}
+}
+
+func (r *Runner) Run(ctx context.Context, cases []Case) *Report {
+	report := &Report{StartedAt: time.Now()}
+	for _, c := range cases {
+		if ctx.Err() != nil {
+			break
+		}
+		report.Results = append(report.Results, r.runCase(ctx, c))
+	}
+	report.FinishedAt = time.Now()
+	report.summarize()
+	return report
+}
+
+func (r *Runner) runCase(ctx context.Context, c Case) Result {
+	res := Result{CaseID: c.ID, Question: c.Question}
+
+	answer, err := r.pipeline.Ask(ctx, domain.Question{Text: c.Question, TopK: r.topK, SpaceKeys: c.SpaceKeys})
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.Answer = answer.Text
+	res.Latency = answer.Latency
+	res.RetrievedDocs = answer.DocumentIDs()
+	res.Recall, res.ReciprocalRank = retrievalScores(c.RelevantDocuments, res.RetrievedDocs)
+
+	if r.judge != nil && c.ExpectedAnswer != "" {
+		verdict, err := r.judge.Grade(ctx, c.Question, c.ExpectedAnswer, answer.Text)
+		if err != nil {
+			res.Error = "judge: " + err.Error()
+			return res
+		}
+		res.JudgeScore = verdict.Score
+		res.JudgeReason = verdict.Reasoning
+	}
+	return res
+}
+
+func retrievalScores(relevant, retrieved []string) (recall, reciprocalRank float64) {
+	if len(relevant) == 0 {
+		return 0, 0
+	}
+	hits := 0
+	for rank, id := range retrieved {
+		if !slices.Contains(relevant, id) {
+			continue
+		}
+		hits++
+		if reciprocalRank == 0 {
+			reciprocalRank = 1 / float64(rank+1)
+		}
+	}
+	return float64(hits) / float64(len(relevant)), reciprocalRank
+}
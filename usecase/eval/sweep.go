@@ -0,0 +1,42 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// Variant is the report of one configuration in a sweep, e.g. a chunk size.
+type Variant struct {
+	Name   string  `json:"name"`
+	Report *Report `json:"report"`
+}
+
+// Sweep compares the same cases run against several configurations.
+type Sweep struct {
+	Variants []Variant `json:"variants"`
+}
+
+func (s *Sweep) Add(name string, report *Report) {
+	s.Variants = append(s.Variants, Variant{Name: name, Report: report})
+}
+
+func (s *Sweep) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
+
+// WriteText writes one summary line per variant, in the order they were added.
+func (s *Sweep) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "VARIANT\tCASES\tFAILURES\tRECALL\tMRR\tJUDGE\tLATENCY")
+	for _, v := range s.Variants {
+		sum := v.Report.Summary
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%.3f\t%.3f\t%.2f\t%s\n",
+			v.Name, sum.Cases, sum.Failures, sum.MeanRecall, sum.MRR, sum.MeanJudgeScore, sum.MeanLatency.Round(time.Millisecond))
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,104 @@
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+)
+
+// Variant overrides parts of the query configuration for a share of sessions.
+// Zero values leave the base configuration untouched.
+type Variant struct {
+	Name        string   `json:"name"`
+	Weight      int      `json:"weight"`
+	TopK        int      `json:"top_k,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Prompt      string   `json:"prompt,omitempty"`
+	Temperature *float64 `json:"temperature,omitempty"`
+}
+
+type Experiment struct {
+	Name     string    `json:"name"`
+	Enabled  bool      `json:"enabled"`
+	Variants []Variant `json:"variants"`
+}
+
+func (e Experiment) Validate() error {
+	if e.Name == "" {
+		return fmt.Errorf("experiment name is required")
+	}
+	if len(e.Variants) == 0 {
+		return fmt.Errorf("experiment %s: at least one variant is required", e.Name)
+	}
+	names := make(map[string]bool, len(e.Variants))
+	for _, v := range e.Variants {
+		if v.Name == "" || names[v.Name] {
+			return fmt.Errorf("experiment %s: variant names must be unique and non-empty", e.Name)
+		}
+		names[v.Name] = true
+		if v.Weight < 0 {
+			return fmt.Errorf("experiment %s: variant %s has negative weight", e.Name, v.Name)
+		}
+		if v.Prompt != "" && !query.HasPrompt(v.Prompt) {
+			return fmt.Errorf("experiment %s: variant %s references unknown prompt %q", e.Name, v.Name, v.Prompt)
+		}
+	}
+	if e.totalWeight() == 0 {
+		return fmt.Errorf("experiment %s: variant weights sum to zero", e.Name)
+	}
+	return nil
+}
+
+// Assign deterministically buckets a session into a variant, so the same session
+// always sees the same variant and feedback can be attributed after the fact.
+func (e Experiment) Assign(sessionID string) Variant {
+	h := fnv.New32a()
+	h.Write([]byte(e.Name + ":" + sessionID))
+	bucket := int(h.Sum32() % uint32(e.totalWeight()))
+	for _, v := range e.Variants {
+		if bucket < v.Weight {
+			return v
+		}
+		bucket -= v.Weight
+	}
+	return e.Variants[len(e.Variants)-1]
+}
+
+func (e Experiment) totalWeight() int {
+	total := 0
+	for _, v := range e.Variants {
+		total += v.Weight
+	}
+	return total
+}
+
+func (v Variant) apply(cfg query.Config) query.Config {
+	if v.TopK > 0 {
+		cfg.TopK = v.TopK
+	}
+	if v.Model != "" {
+		cfg.Model = v.Model
+	}
+	if v.Prompt != "" {
+		cfg.Prompt = v.Prompt
+	}
+	if v.Temperature != nil {
+		cfg.Temperature = *v.Temperature
+	}
+	return cfg
+}
+
+func LoadFile(path string) ([]Experiment, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var experiments []Experiment
+	if err := json.Unmarshal(data, &experiments); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return experiments, nil
+}
@@ -0,0 +1,84 @@
+package experiment
+
+import (
+	"context"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+)
+
+type Manager struct {
+	experiments []Experiment
+}
+
+func NewManager(experiments []Experiment) (*Manager, error) {
+	active := make([]Experiment, 0, len(experiments))
+	for _, e := range experiments {
+		if err := e.Validate(); err != nil {
+			return nil, err
+		}
+		if e.Enabled {
+			active = append(active, e)
+		}
+	}
+	return &Manager{experiments: active}, nil
+}
+
+func (m *Manager) Experiments() []Experiment {
+	return m.experiments
+}
+
+// Assign returns the variant name per active experiment for a session.
+func (m *Manager) Assign(sessionID string) map[string]string {
+	if len(m.experiments) == 0 {
+		return nil
+	}
+	assignments := make(map[string]string, len(m.experiments))
+	for _, e := range m.experiments {
+		assignments[e.Name] = e.Assign(sessionID).Name
+	}
+	return assignments
+}
+
+// Apply layers every active experiment's variant over base, in definition order.
+func (m *Manager) Apply(base query.Config, sessionID string) (query.Config, map[string]string) {
+	cfg := base
+	for _, e := range m.experiments {
+		cfg = e.Assign(sessionID).apply(cfg)
+	}
+	return cfg, m.Assign(sessionID)
+}
+
+type VariantResult struct {
+	Experiment    string  `json:"experiment"`
+	Variant       string  `json:"variant"`
+	FeedbackCount int     `json:"feedback_count"`
+	MeanRating    float64 `json:"mean_rating"`
+}
+
+// Results aggregates recorded feedback per experiment variant.
+func (m *Manager) Results(ctx context.Context, repo domain.FeedbackRepository) ([]VariantResult, error) {
+	feedback, err := repo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []VariantResult
+	for _, e := range m.experiments {
+		for _, v := range e.Variants {
+			r := VariantResult{Experiment: e.Name, Variant: v.Name}
+			sum := 0
+			for _, f := range feedback {
+				if f.Variants[e.Name] == v.Name {
+					r.FeedbackCount++
+					sum += f.Rating
+				}
+			}
+			if r.FeedbackCount > 0 {
+				r.MeanRating = float64(sum) / float64(r.FeedbackCount)
+			}
+			results = append(results, r)
+		}
+	}
+	return results, nil
+}
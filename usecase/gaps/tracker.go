@@ -0,0 +1,181 @@
+package gaps
+
+import (
+	"context"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Config struct {
+	ScoreThreshold    float64
+	ClusterSimilarity float64
+}
+
+// noAnswerPhrases match the refusals the system prompt asks the model to give.
+var noAnswerPhrases = []string{
+	"i don't know",
+	"i do not know",
+	"i don't have enough information",
+	"i couldn't find",
+	"i could not find",
+	"context does not contain",
+	"context doesn't contain",
+	"no information about",
+}
+
+type Topic struct {
+	Label         string    `json:"label"`
+	Count         int       `json:"count"`
+	NoAnswerCount int       `json:"no_answer_count"`
+	MeanTopScore  float64   `json:"mean_top_score"`
+	LastSeen      time.Time `json:"last_seen"`
+	Examples      []string  `json:"examples"`
+
+	centroid []float32
+}
+
+// Tracker records questions the index answered poorly and groups them into topics.
+type Tracker struct {
+	repo domain.GapRepository
+	cfg  Config
+}
+
+func NewTracker(repo domain.GapRepository, cfg Config) *Tracker {
+	return &Tracker{repo: repo, cfg: cfg}
+}
+
+func (t *Tracker) Observe(ctx context.Context, question string, vector []float32, answer *domain.Answer) {
+	if answer.Moderation == domain.ModerationBlock {
+		return
+	}
+
+	topScore := 0.0
+	if len(answer.Chunks) > 0 {
+		topScore = answer.Chunks[0].Score
+	}
+
+	var reason domain.GapReason
+	switch {
+	case IsNoAnswer(answer.Text):
+		reason = domain.GapNoAnswer
+	case topScore < t.cfg.ScoreThreshold:
+		reason = domain.GapLowRetrievalScore
+	default:
+		return
+	}
+
+	err := t.repo.SaveGap(ctx, domain.KnowledgeGap{
+		Question:  question,
+		Reason:    reason,
+		TopScore:  topScore,
+		Embedding: vector,
+		At:        time.Now().UTC(),
+	})
+	if err != nil {
+		log.Printf("Recording knowledge gap failed: %v\n", err)
+	}
+}
+
+func IsNoAnswer(text string) bool {
+	lower := strings.ToLower(text)
+	for _, p := range noAnswerPhrases {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Topics clusters recorded gaps by question similarity and returns the largest clusters.
+func (t *Tracker) Topics(ctx context.Context, since time.Time, limit int) ([]Topic, error) {
+	gaps, err := t.repo.ListGaps(ctx, since)
+	if err != nil {
+		return nil, err
+	}
+
+	var topics []*Topic
+	scoreSums := make(map[*Topic]float64)
+	for _, g := range gaps {
+		topic := t.nearest(topics, g.Embedding)
+		if topic == nil {
+			topic = &Topic{Label: g.Question, centroid: append([]float32(nil), g.Embedding...)}
+			topics = append(topics, topic)
+		} else {
+			for i := range topic.centroid {
+				topic.centroid[i] += (g.Embedding[i] - topic.centroid[i]) / float32(topic.Count+1)
+			}
+		}
+
+		topic.Count++
+		if g.Reason == domain.GapNoAnswer {
+			topic.NoAnswerCount++
+		}
+		scoreSums[topic] += g.TopScore
+		if g.At.After(topic.LastSeen) {
+			topic.LastSeen = g.At
+		}
+		if len(topic.Examples) < 5 && !containsFold(topic.Examples, g.Question) {
+			topic.Examples = append(topic.Examples, g.Question)
+		}
+	}
+
+	out := make([]Topic, len(topics))
+	for i, topic := range topics {
+		topic.MeanTopScore = scoreSums[topic] / float64(topic.Count)
+		out[i] = *topic
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].LastSeen.After(out[j].LastSeen)
+	})
+	if limit > 0 && len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (t *Tracker) nearest(topics []*Topic, vector []float32) *Topic {
+	var best *Topic
+	bestScore := t.cfg.ClusterSimilarity
+	for _, topic := range topics {
+		if len(topic.centroid) != len(vector) {
+			continue
+		}
+		if s := cosine(topic.centroid, vector); s >= bestScore {
+			best, bestScore = topic, s
+		}
+	}
+	return best
+}
+
+func containsFold(list []string, s string) bool {
+	for _, v := range list {
+		if strings.EqualFold(v, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
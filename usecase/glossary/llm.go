@@ -0,0 +1,56 @@
+package glossary
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const extractionPrompt = `Extract internal jargon from the documentation page below.
+Return a JSON array only. Each item: {"term": "...", "definition": "...", "kind": "acronym" | "entity" | "term"}.
+Use "entity" for named systems, services, teams and products; "acronym" for abbreviations.
+Only include items whose meaning is stated or clearly implied by the page. Keep definitions under 30 words.`
+
+// maxExtractionInput bounds the page text sent to the model per document.
+const maxExtractionInput = 12000
+
+type LLMExtractor struct {
+	model     domain.ChatModel
+	modelName string
+}
+
+func NewLLMExtractor(model domain.ChatModel, modelName string) *LLMExtractor {
+	return &LLMExtractor{model: model, modelName: modelName}
+}
+
+func (e *LLMExtractor) Extract(ctx context.Context, doc *domain.Document) ([]domain.GlossaryEntry, error) {
+	body := doc.Body
+	if len(body) > maxExtractionInput {
+		body = body[:maxExtractionInput]
+	}
+
+	completion, err := e.model.Complete(ctx, domain.CompletionRequest{
+		Model: e.modelName,
+		Messages: []domain.Message{
+			{Role: domain.RoleSystem, Content: extractionPrompt},
+			{Role: domain.RoleUser, Content: "Title: " + doc.Title + "\n\n" + body},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content := completion.Content
+	start, end := strings.Index(content, "["), strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("glossary extraction returned no JSON array")
+	}
+	var entries []domain.GlossaryEntry
+	if err := json.Unmarshal([]byte(content[start:end+1]), &entries); err != nil {
+		return nil, fmt.Errorf("parse glossary extraction: %w", err)
+	}
+	return entries, nil
+}
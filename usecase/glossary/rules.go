@@ -0,0 +1,96 @@
+package glossary
+
+import (
+	"context"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Extractor interface {
+	Extract(ctx context.Context, doc *domain.Document) ([]domain.GlossaryEntry, error)
+}
+
+var (
+	// "Service Level Objective (SLO)"
+	longThenShort = regexp.MustCompile(`((?:[A-Z][\w-]*\s+(?:(?:of|for|and|the|to|in|on)\s+)?){1,7})\(([A-Z][A-Za-z0-9&]{1,9})\)`)
+	// "SLO (Service Level Objective)"
+	shortThenLong = regexp.MustCompile(`\b([A-Z][A-Z0-9&]{1,9})\s+\(([A-Z][\w-]*(?:\s+[\w-]+){1,7})\)`)
+	// "Term: definition" or "Term | definition |" rows on glossary pages
+	definitionLine = regexp.MustCompile(`^([^:|]{2,60}?)\s*(?::|\||\s-\s)\s*(.{10,400}?)\s*\|?$`)
+)
+
+// RuleExtractor finds acronym expansions anywhere and term definitions on pages
+// that are labelled or titled as glossaries.
+type RuleExtractor struct{}
+
+func (RuleExtractor) Extract(ctx context.Context, doc *domain.Document) ([]domain.GlossaryEntry, error) {
+	var entries []domain.GlossaryEntry
+
+	for _, m := range longThenShort.FindAllStringSubmatch(doc.Body, -1) {
+		if long, ok := matchInitials(strings.TrimSpace(m[1]), m[2]); ok {
+			entries = append(entries, domain.GlossaryEntry{Term: m[2], Definition: long, Kind: domain.GlossaryAcronym})
+		}
+	}
+	for _, m := range shortThenLong.FindAllStringSubmatch(doc.Body, -1) {
+		if long, ok := matchInitials(strings.TrimSpace(m[2]), m[1]); ok {
+			entries = append(entries, domain.GlossaryEntry{Term: m[1], Definition: long, Kind: domain.GlossaryAcronym})
+		}
+	}
+
+	if isGlossaryPage(doc) {
+		for _, line := range strings.Split(doc.Body, "\n") {
+			m := definitionLine.FindStringSubmatch(strings.TrimPrefix(strings.TrimSpace(line), "- "))
+			if m == nil || len(strings.Fields(m[1])) > 5 {
+				continue
+			}
+			kind := domain.GlossaryTerm
+			if isAcronym(m[1]) {
+				kind = domain.GlossaryAcronym
+			}
+			entries = append(entries, domain.GlossaryEntry{Term: strings.TrimSpace(m[1]), Definition: m[2], Kind: kind})
+		}
+	}
+	return entries, nil
+}
+
+func isGlossaryPage(doc *domain.Document) bool {
+	if strings.Contains(strings.ToLower(doc.Title), "glossary") {
+		return true
+	}
+	return slices.ContainsFunc(doc.Labels, func(l string) bool { return strings.EqualFold(l, "glossary") })
+}
+
+// matchInitials trims leading words off phrase until its initials spell the acronym.
+func matchInitials(phrase, acronym string) (string, bool) {
+	words := strings.Fields(phrase)
+	letters := strings.ToUpper(acronym)
+	for start := 0; start < len(words); start++ {
+		var initials strings.Builder
+		for _, w := range words[start:] {
+			r := []rune(w)[0]
+			if unicode.IsUpper(r) || unicode.IsDigit(r) {
+				initials.WriteRune(unicode.ToUpper(r))
+			}
+		}
+		if initials.String() == letters {
+			return strings.Join(words[start:], " "), true
+		}
+	}
+	return "", false
+}
+
+func isAcronym(s string) bool {
+	if len(s) < 2 || len(s) > 10 {
+		return false
+	}
+	for _, r := range s {
+		if !unicode.IsUpper(r) && !unicode.IsDigit(r) && r != '&' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,120 @@
+package glossary
+
+import (
+	"context"
+	"log"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// maxPromptEntries caps how many glossary entries are injected into one prompt.
+const maxPromptEntries = 10
+
+// Service maintains the glossary during ingestion and looks up entries for questions.
+type Service struct {
+	repo       domain.GlossaryRepository
+	extractors []Extractor
+}
+
+func NewService(repo domain.GlossaryRepository, extractors ...Extractor) *Service {
+	return &Service{repo: repo, extractors: extractors}
+}
+
+func (s *Service) Enrich(ctx context.Context, doc *domain.Document, chunks []domain.Chunk) error {
+	if doc.Body == "" && len(chunks) > 0 {
+		// Streamed documents come without their body; the chunks hold the
+		// text, overlaps repeated.
+		texts := make([]string, len(chunks))
+		for i, c := range chunks {
+			texts[i] = c.Text
+		}
+		withBody := *doc
+		withBody.Body = strings.Join(texts, "\n\n")
+		doc = &withBody
+	}
+	seen := make(map[string]bool)
+	var entries []domain.GlossaryEntry
+	for _, x := range s.extractors {
+		found, err := x.Extract(ctx, doc)
+		if err != nil {
+			log.Printf("Glossary extraction for %s failed: %v\n", doc.ID, err)
+			continue
+		}
+		for _, e := range found {
+			e.Term = strings.TrimSpace(e.Term)
+			e.Definition = strings.TrimSpace(e.Definition)
+			key := strings.ToLower(e.Term)
+			if e.Term == "" || e.Definition == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			if e.Kind == "" {
+				e.Kind = domain.GlossaryTerm
+			}
+			e.UpdatedAt = doc.UpdatedAt
+			if e.UpdatedAt.IsZero() {
+				e.UpdatedAt = time.Now().UTC()
+			}
+			entries = append(entries, e)
+		}
+	}
+	return s.repo.ReplaceDocumentEntries(ctx, doc.ID, entries)
+}
+
+func (s *Service) Forget(ctx context.Context, documentID string) error {
+	return s.repo.RemoveDocumentEntries(ctx, documentID)
+}
+
+func (s *Service) List(ctx context.Context) ([]domain.GlossaryEntry, error) {
+	return s.repo.ListGlossary(ctx)
+}
+
+// Lookup returns glossary entries for terms mentioned in the question. Acronyms
+// must match case exactly so ordinary words like "it" don't pull in "IT".
+func (s *Service) Lookup(ctx context.Context, question string) []domain.GlossaryEntry {
+	entries, err := s.repo.LookupTerms(ctx, candidateTerms(question))
+	if err != nil {
+		log.Printf("Glossary lookup failed: %v\n", err)
+		return nil
+	}
+
+	var out []domain.GlossaryEntry
+	for _, e := range entries {
+		if e.Kind == domain.GlossaryAcronym && !containsWord(question, e.Term) {
+			continue
+		}
+		out = append(out, e)
+		if len(out) == maxPromptEntries {
+			break
+		}
+	}
+	return out
+}
+
+// candidateTerms returns every 1-4 word n-gram of the question.
+func candidateTerms(question string) []string {
+	words := strings.FieldsFunc(question, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '&'
+	})
+	var terms []string
+	for n := 1; n <= 4; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			terms = append(terms, strings.Join(words[i:i+n], " "))
+		}
+	}
+	return terms
+}
+
+func containsWord(text, word string) bool {
+	for _, w := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '&'
+	}) {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const extractionPrompt = `Extract a knowledge graph from the documentation excerpt below.
+Return a JSON array only. Each item: {"subject": "...", "subject_type": "...", "predicate": "...", "object": "...", "object_type": "..."}.
+Entities are named systems, services, teams, people, datastores, environments or processes.
+Predicates are short lowercase verb phrases such as "depends on", "owned by", "writes to", "deployed in".
+Only include relations explicitly stated in the excerpt.`
+
+type triple struct {
+	Subject     string `json:"subject"`
+	SubjectType string `json:"subject_type"`
+	Predicate   string `json:"predicate"`
+	Object      string `json:"object"`
+	ObjectType  string `json:"object_type"`
+}
+
+type LLMExtractor struct {
+	model     domain.ChatModel
+	modelName string
+}
+
+func NewLLMExtractor(model domain.ChatModel, modelName string) *LLMExtractor {
+	return &LLMExtractor{model: model, modelName: modelName}
+}
+
+func (e *LLMExtractor) ExtractRelations(ctx context.Context, doc *domain.Document, chunk domain.Chunk) ([]domain.Relation, error) {
+	completion, err := e.model.Complete(ctx, domain.CompletionRequest{
+		Model: e.modelName,
+		Messages: []domain.Message{
+			{Role: domain.RoleSystem, Content: extractionPrompt},
+			{Role: domain.RoleUser, Content: "Page: " + doc.Title + "\n\n" + chunk.Text},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content := completion.Content
+	start, end := strings.Index(content, "["), strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("graph extraction returned no JSON array")
+	}
+	var triples []triple
+	if err := json.Unmarshal([]byte(content[start:end+1]), &triples); err != nil {
+		return nil, fmt.Errorf("parse graph extraction: %w", err)
+	}
+
+	relations := make([]domain.Relation, 0, len(triples))
+	for _, t := range triples {
+		subject, object := NewEntity(t.Subject, t.SubjectType), NewEntity(t.Object, t.ObjectType)
+		predicate := strings.ToLower(strings.TrimSpace(t.Predicate))
+		if subject.ID == "" || object.ID == "" || predicate == "" || subject.ID == object.ID {
+			continue
+		}
+		relations = append(relations, domain.Relation{
+			Subject:    subject,
+			Predicate:  predicate,
+			Object:     object,
+			DocumentID: doc.ID,
+			ChunkID:    chunk.ID,
+		})
+	}
+	return relations, nil
+}
+
+func NewEntity(name, typ string) domain.Entity {
+	name = strings.TrimSpace(name)
+	return domain.Entity{ID: EntityID(name), Name: name, Type: strings.ToLower(strings.TrimSpace(typ))}
+}
+
+// EntityID normalizes a name so "Payments API" and "payments-api" resolve to one node.
+func EntityID(name string) string {
+	fields := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return strings.Join(fields, "-")
+}
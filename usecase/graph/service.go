@@ -0,0 +1,151 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Config struct {
+	MaxChunksPerDocument int
+	MaxRelations         int
+}
+
+type Extractor interface {
+	ExtractRelations(ctx context.Context, doc *domain.Document, chunk domain.Chunk) ([]domain.Relation, error)
+}
+
+// Service builds the knowledge graph during ingestion and expands retrieval with
+// relations and source chunks of entities the question mentions.
+type Service struct {
+	repo      domain.GraphRepository
+	store     domain.VectorStore
+	extractor Extractor
+	cfg       Config
+}
+
+func NewService(repo domain.GraphRepository, store domain.VectorStore, extractor Extractor, cfg Config) *Service {
+	if cfg.MaxRelations <= 0 {
+		cfg.MaxRelations = 20
+	}
+	return &Service{repo: repo, store: store, extractor: extractor, cfg: cfg}
+}
+
+func (s *Service) Enrich(ctx context.Context, doc *domain.Document, chunks []domain.Chunk) error {
+	if s.cfg.MaxChunksPerDocument > 0 && len(chunks) > s.cfg.MaxChunksPerDocument {
+		chunks = chunks[:s.cfg.MaxChunksPerDocument]
+	}
+
+	var relations []domain.Relation
+	for _, c := range chunks {
+		found, err := s.extractor.ExtractRelations(ctx, doc, c)
+		if err != nil {
+			log.Printf("Graph extraction for chunk %s failed: %v\n", c.ID, err)
+			continue
+		}
+		relations = append(relations, found...)
+	}
+	return s.repo.ReplaceDocumentGraph(ctx, doc.ID, relations)
+}
+
+func (s *Service) Forget(ctx context.Context, documentID string) error {
+	return s.repo.RemoveDocumentGraph(ctx, documentID)
+}
+
+// Neighborhood returns an entity and the relations touching it.
+func (s *Service) Neighborhood(ctx context.Context, name string) (*domain.Entity, []domain.Relation, error) {
+	id := EntityID(name)
+	entities, err := s.repo.FindEntities(ctx, []string{id})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(entities) == 0 {
+		return nil, nil, domain.ErrNotFound
+	}
+	relations, err := s.repo.Neighbors(ctx, []string{id}, s.cfg.MaxRelations)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &entities[0], relations, nil
+}
+
+// Expand finds graph entities named in the question and returns their relations
+// plus the source chunks behind those relations that retrieval did not already return.
+// Relations drawn from chunks filter rejects are left out with their chunks, so
+// the graph reveals nothing retrieval wouldn't.
+func (s *Service) Expand(ctx context.Context, question string, retrieved []domain.ScoredChunk, filter domain.SearchFilter) ([]domain.ScoredChunk, []domain.Relation) {
+	entities, err := s.repo.FindEntities(ctx, candidateIDs(question))
+	if err != nil || len(entities) == 0 {
+		if err != nil {
+			log.Printf("Graph entity lookup failed: %v\n", err)
+		}
+		return nil, nil
+	}
+
+	ids := make([]string, len(entities))
+	for i, e := range entities {
+		ids[i] = e.ID
+	}
+	relations, err := s.repo.Neighbors(ctx, ids, s.cfg.MaxRelations)
+	if err != nil {
+		log.Printf("Graph neighbor lookup failed: %v\n", err)
+		return nil, nil
+	}
+
+	have := make(map[string]bool, len(retrieved))
+	for _, c := range retrieved {
+		have[c.ID] = true
+	}
+	extra, err := s.sourceChunks(ctx, relations, have, filter)
+	if err != nil {
+		log.Printf("Loading graph source chunks failed: %v\n", err)
+	}
+	for _, c := range extra {
+		have[c.ID] = true
+	}
+	relations = slices.DeleteFunc(relations, func(r domain.Relation) bool { return !have[r.ChunkID] })
+	return extra, relations
+}
+
+func (s *Service) sourceChunks(ctx context.Context, relations []domain.Relation, have map[string]bool, filter domain.SearchFilter) ([]domain.ScoredChunk, error) {
+	wanted := make(map[string][]string)
+	for _, r := range relations {
+		if !have[r.ChunkID] && !slices.Contains(wanted[r.DocumentID], r.ChunkID) {
+			wanted[r.DocumentID] = append(wanted[r.DocumentID], r.ChunkID)
+		}
+	}
+
+	var extra []domain.ScoredChunk
+	for docID, chunkIDs := range wanted {
+		chunks, err := s.store.DocumentChunks(ctx, docID)
+		if err != nil {
+			return extra, fmt.Errorf("document %s: %w", docID, err)
+		}
+		for _, c := range chunks {
+			if slices.Contains(chunkIDs, c.ID) && filter.Matches(c) {
+				c.Embedding = nil
+				extra = append(extra, domain.ScoredChunk{Chunk: c})
+			}
+		}
+	}
+	return extra, nil
+}
+
+// candidateIDs returns normalized 1-4 word n-grams of the question.
+func candidateIDs(question string) []string {
+	words := strings.FieldsFunc(strings.ToLower(question), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	var ids []string
+	for n := 1; n <= 4; n++ {
+		for i := 0; i+n <= len(words); i++ {
+			ids = append(ids, strings.Join(words[i:i+n], "-"))
+		}
+	}
+	return ids
+}
@@ -0,0 +1,69 @@
+package graph
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/storage/memory"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/vectorstore"
+)
+
+func TestExpandHidesChunksTheFilterRejects(t *testing.T) {
+	ctx := context.Background()
+	store := vectorstore.NewMemory()
+	chunks := []domain.Chunk{
+		{ID: "open-0", DocumentID: "open", SpaceKey: "ENG", Text: "Billing runs on Kafka."},
+		{ID: "secret-0", DocumentID: "secret", SpaceKey: "ENG", Text: "Billing keys live in vault-7.", Readers: []string{"user:alice@example.com"}},
+		{ID: "hr-0", DocumentID: "hr", SpaceKey: "HR", Text: "Billing disputes go to payroll."},
+	}
+	if err := store.Upsert(ctx, chunks); err != nil {
+		t.Fatal(err)
+	}
+	billing := domain.Entity{ID: EntityID("Billing"), Name: "Billing"}
+	repo := memory.NewGraphRepository()
+	for _, c := range chunks {
+		relation := domain.Relation{Subject: billing, Predicate: "mentioned in", Object: domain.Entity{ID: c.ID, Name: c.ID}, DocumentID: c.DocumentID, ChunkID: c.ID}
+		if err := repo.ReplaceDocumentGraph(ctx, c.DocumentID, []domain.Relation{relation}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewService(repo, store, nil, Config{})
+
+	tests := []struct {
+		name   string
+		filter domain.SearchFilter
+		want   []string
+	}{
+		{"unrestricted", domain.SearchFilter{}, []string{"hr-0", "open-0", "secret-0"}},
+		{"other reader", domain.SearchFilter{Readers: []string{"user:bob@example.com"}}, []string{"hr-0", "open-0"}},
+		{"allowed reader", domain.SearchFilter{Readers: []string{"user:alice@example.com"}}, []string{"hr-0", "open-0", "secret-0"}},
+		{"space", domain.SearchFilter{SpaceKeys: []string{"ENG"}, Readers: []string{"user:bob@example.com"}}, []string{"open-0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			extra, relations := s.Expand(ctx, "Where is billing?", nil, tt.filter)
+			if got := chunkIDs(extra); !slices.Equal(got, tt.want) {
+				t.Errorf("expanded chunks = %v, want %v", got, tt.want)
+			}
+			var fromChunks []string
+			for _, r := range relations {
+				fromChunks = append(fromChunks, r.ChunkID)
+			}
+			slices.Sort(fromChunks)
+			if !slices.Equal(fromChunks, tt.want) {
+				t.Errorf("relations drawn from %v, want %v", fromChunks, tt.want)
+			}
+		})
+	}
+}
+
+func chunkIDs(chunks []domain.ScoredChunk) []string {
+	var ids []string
+	for _, c := range chunks {
+		ids = append(ids, c.ID)
+	}
+	slices.Sort(ids)
+	return ids
+}
@@ -0,0 +1,63 @@
+package indexversion
+
+import (
+	"context"
+	"sync"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// Router is the vector store and embedder the rest of the server uses: it
+// reads and writes the active version's store and embeds with its model, so
+// queries are compared to chunks embedded the same way.
+type Router struct {
+	mu       sync.RWMutex
+	store    domain.VectorStore
+	embedder domain.Embedder
+}
+
+func NewRouter(store domain.VectorStore, embedder domain.Embedder) *Router {
+	return &Router{store: store, embedder: embedder}
+}
+
+func (r *Router) active() (domain.VectorStore, domain.Embedder) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.store, r.embedder
+}
+
+func (r *Router) set(store domain.VectorStore, embedder domain.Embedder) {
+	r.mu.Lock()
+	r.store, r.embedder = store, embedder
+	r.mu.Unlock()
+}
+
+func (r *Router) Upsert(ctx context.Context, chunks []domain.Chunk) error {
+	store, _ := r.active()
+	return store.Upsert(ctx, chunks)
+}
+
+func (r *Router) Search(ctx context.Context, vector []float32, topK int, filter domain.SearchFilter) ([]domain.ScoredChunk, error) {
+	store, _ := r.active()
+	return store.Search(ctx, vector, topK, filter)
+}
+
+func (r *Router) DeleteDocument(ctx context.Context, documentID string) error {
+	store, _ := r.active()
+	return store.DeleteDocument(ctx, documentID)
+}
+
+func (r *Router) DocumentChunks(ctx context.Context, documentID string) ([]domain.Chunk, error) {
+	store, _ := r.active()
+	return store.DocumentChunks(ctx, documentID)
+}
+
+func (r *Router) ScanChunks(ctx context.Context, fn func(domain.Chunk) error) error {
+	store, _ := r.active()
+	return store.ScanChunks(ctx, fn)
+}
+
+func (r *Router) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	_, embedder := r.active()
+	return embedder.Embed(ctx, texts)
+}
@@ -0,0 +1,402 @@
+// Package indexversion builds a new version of the index, e.g. after changing
+// the chunk size or embedding model, while the current one keeps serving, then
+// switches to it atomically and can switch back.
+//
+// Which version serves is kept in memory: a restart serves the store the
+// instance is configured with again, so a switch is made permanent by
+// configuring the new version's collection, chunking and embedding model.
+package indexversion
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+	"github.com/shubhamgptln/sarama-ai/usecase/ingest"
+)
+
+var (
+	ErrBuilding = errors.New("a version is already being built")
+	ErrExists   = errors.New("version already exists")
+	ErrNotReady = errors.New("version is not ready to serve")
+	ErrActive   = errors.New("version is serving")
+	ErrInvalid  = errors.New("invalid version")
+)
+
+// Initial names the version in the store the instance was started with.
+const Initial = "initial"
+
+var validName = regexp.MustCompile(`^[a-z0-9][a-z0-9_-]{0,62}$`)
+
+// Settings are what a version is built with.
+type Settings struct {
+	ChunkSize      int    `json:"chunk_size"`
+	ChunkOverlap   int    `json:"chunk_overlap"`
+	EmbeddingModel string `json:"embedding_model"`
+}
+
+type Status string
+
+const (
+	StatusBuilding Status = "building"
+	StatusReady    Status = "ready"
+	StatusFailed   Status = "failed"
+)
+
+// Version is a build of the index and its progress.
+type Version struct {
+	Name     string   `json:"name"`
+	Settings Settings `json:"settings"`
+	Status   Status   `json:"status"`
+	// Documents, Processed and Failed count the documents copied into the
+	// version while it is built.
+	Documents   int        `json:"documents"`
+	Processed   int        `json:"processed"`
+	Failed      int        `json:"failed"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	ActivatedAt *time.Time `json:"activated_at,omitempty"`
+}
+
+// Pipeline embeds and indexes into one version.
+type Pipeline struct {
+	Embedder domain.Embedder
+	Ingester *ingest.Service
+}
+
+// Factory builds the pipeline of a version's store. The serving version's is
+// primary and records applied events, enriches documents and notifies; the
+// others only keep their chunks up to date.
+type Factory func(store domain.VectorStore, settings Settings, primary bool) Pipeline
+
+type version struct {
+	info     Version
+	store    domain.VectorStore
+	pipeline Pipeline
+}
+
+// Service serves the index from its active version. Changes are indexed into
+// the active version, the version being built and the previously active one,
+// so a switch in either direction loses no edits.
+type Service struct {
+	router    *Router
+	versioner domain.IndexVersioner
+	factory   Factory
+
+	mu       sync.RWMutex
+	versions map[string]*version
+	active   string
+	previous string
+	building string
+}
+
+// NewService serves the store router was created with, built with settings,
+// as the Initial version, and switches router when another is activated.
+func NewService(router *Router, versioner domain.IndexVersioner, settings Settings, factory Factory) *Service {
+	now := time.Now().UTC()
+	store, _ := router.active()
+	initial := &version{
+		info:     Version{Name: Initial, Settings: settings, Status: StatusReady, CreatedAt: now, ActivatedAt: &now},
+		store:    store,
+		pipeline: factory(store, settings, true),
+	}
+	router.set(store, initial.pipeline.Embedder)
+	return &Service{
+		router:    router,
+		versioner: versioner,
+		factory:   factory,
+		versions:  map[string]*version{Initial: initial},
+		active:    Initial,
+	}
+}
+
+// Versions returns every version, oldest first, and the names of the active
+// and previously active ones.
+func (s *Service) Versions() (versions []Version, active, previous string) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, v := range s.versions {
+		versions = append(versions, v.info)
+	}
+	slices.SortFunc(versions, func(a, b Version) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return versions, s.active, s.previous
+}
+
+// Build starts copying every indexed document into a new version in the
+// background. Zero settings are taken from the active version. Documents from
+// a source are fetched and indexed again; documents added through the API
+// have no source, so their chunks are kept and only embedded again.
+func (s *Service) Build(ctx context.Context, name string, settings Settings) (Version, error) {
+	if !validName.MatchString(name) {
+		return Version{}, fmt.Errorf("%w: name must be lowercase letters, digits, - and _", ErrInvalid)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.building != "" {
+		return Version{}, ErrBuilding
+	}
+	if _, ok := s.versions[name]; ok {
+		return Version{}, fmt.Errorf("%w: %s", ErrExists, name)
+	}
+	base := s.versions[s.active]
+	if settings.ChunkSize == 0 {
+		settings.ChunkSize = base.info.Settings.ChunkSize
+	}
+	if settings.ChunkOverlap == 0 {
+		settings.ChunkOverlap = base.info.Settings.ChunkOverlap
+	}
+	if settings.EmbeddingModel == "" {
+		settings.EmbeddingModel = base.info.Settings.EmbeddingModel
+	}
+	if settings.ChunkSize < 0 || settings.ChunkOverlap < 0 || settings.ChunkOverlap >= settings.ChunkSize {
+		return Version{}, fmt.Errorf("%w: chunk overlap must be below the chunk size", ErrInvalid)
+	}
+
+	store := s.versioner.OpenVersion(name)
+	v := &version{
+		info:     Version{Name: name, Settings: settings, Status: StatusBuilding, CreatedAt: time.Now().UTC()},
+		store:    store,
+		pipeline: s.factory(store, settings, false),
+	}
+	s.versions[name] = v
+	s.building = name
+	go s.build(ctx, v, base.store)
+	return v.info, nil
+}
+
+func (s *Service) build(ctx context.Context, v *version, from domain.VectorStore) {
+	var ids []string
+	seen := map[string]bool{}
+	manual := map[string][]domain.Chunk{}
+	err := from.ScanChunks(ctx, func(c domain.Chunk) error {
+		if domain.IsManualDocument(c.DocumentID) {
+			manual[c.DocumentID] = append(manual[c.DocumentID], c)
+		} else if !seen[c.DocumentID] {
+			seen[c.DocumentID] = true
+			ids = append(ids, c.DocumentID)
+		}
+		return nil
+	})
+	s.mu.Lock()
+	v.info.Documents = len(ids) + len(manual)
+	s.mu.Unlock()
+
+	failed := 0
+	progress := func(ok bool) {
+		if !ok {
+			failed++
+		}
+		s.mu.Lock()
+		v.info.Processed++
+		v.info.Failed = failed
+		s.mu.Unlock()
+	}
+	for _, documentID := range ids {
+		if err != nil || ctx.Err() != nil {
+			break
+		}
+		herr := v.pipeline.Ingester.Handle(ctx, domain.IngestEvent{
+			ID:         id.New(),
+			Source:     "confluence",
+			Action:     domain.IngestUpsert,
+			DocumentID: documentID,
+			RawType:    "index-version",
+			ReceivedAt: time.Now().UTC(),
+		})
+		if herr != nil {
+			log.Printf("Copying %s into index version %s failed: %v\n", documentID, v.info.Name, herr)
+		}
+		progress(herr == nil)
+	}
+	for documentID, chunks := range manual {
+		if err != nil || ctx.Err() != nil {
+			break
+		}
+		cerr := s.copyChunks(ctx, v, chunks)
+		if cerr != nil {
+			log.Printf("Copying %s into index version %s failed: %v\n", documentID, v.info.Name, cerr)
+		}
+		progress(cerr == nil)
+	}
+	if err == nil {
+		err = ctx.Err()
+	}
+
+	finished := time.Now().UTC()
+	s.mu.Lock()
+	v.info.Status, v.info.FinishedAt = StatusReady, &finished
+	if err != nil {
+		v.info.Status, v.info.Error = StatusFailed, err.Error()
+	}
+	s.building = ""
+	s.mu.Unlock()
+	if err != nil {
+		log.Printf("Building index version %s failed: %v\n", v.info.Name, err)
+		return
+	}
+	log.Printf("Index version %s built: %d documents, %d failed\n", v.info.Name, len(ids)+len(manual), failed)
+}
+
+// copyChunks embeds chunks again with v's model and stores them in v.
+func (s *Service) copyChunks(ctx context.Context, v *version, chunks []domain.Chunk) error {
+	inputs := make([]string, len(chunks))
+	for i, c := range chunks {
+		inputs[i] = c.Title + "\n\n" + c.Text
+	}
+	vectors, err := v.pipeline.Embedder.Embed(ctx, inputs)
+	if err != nil {
+		return fmt.Errorf("embed: %w", err)
+	}
+	copied := make([]domain.Chunk, len(chunks))
+	for i, c := range chunks {
+		c.Embedding = vectors[i]
+		copied[i] = c
+	}
+	if err := v.store.DeleteDocument(ctx, chunks[0].DocumentID); err != nil {
+		return err
+	}
+	return v.store.Upsert(ctx, copied)
+}
+
+// Activate serves the named version from now on. The version served until
+// now keeps being updated so Rollback can return to it.
+func (s *Service) Activate(name string) (Version, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	v, ok := s.versions[name]
+	if !ok {
+		return Version{}, fmt.Errorf("%w: version %s", domain.ErrNotFound, name)
+	}
+	if name == s.active {
+		return Version{}, fmt.Errorf("%w: %s", ErrActive, name)
+	}
+	if v.info.Status != StatusReady {
+		return Version{}, fmt.Errorf("%w: %s is %s", ErrNotReady, name, v.info.Status)
+	}
+	old := s.versions[s.active]
+	old.pipeline = s.factory(old.store, old.info.Settings, false)
+	v.pipeline = s.factory(v.store, v.info.Settings, true)
+	now := time.Now().UTC()
+	v.info.ActivatedAt = &now
+	s.previous, s.active = s.active, name
+	s.router.set(v.store, v.pipeline.Embedder)
+	log.Printf("Index version %s is serving, replacing %s\n", name, s.previous)
+	return v.info, nil
+}
+
+// Rollback serves the previously active version again.
+func (s *Service) Rollback() (Version, error) {
+	s.mu.RLock()
+	previous := s.previous
+	s.mu.RUnlock()
+	if previous == "" {
+		return Version{}, fmt.Errorf("%w: no version to roll back to", domain.ErrNotFound)
+	}
+	return s.Activate(previous)
+}
+
+// Drop deletes a version that isn't serving or being built.
+func (s *Service) Drop(ctx context.Context, name string) error {
+	s.mu.Lock()
+	v, ok := s.versions[name]
+	switch {
+	case !ok:
+		s.mu.Unlock()
+		return fmt.Errorf("%w: version %s", domain.ErrNotFound, name)
+	case name == s.active:
+		s.mu.Unlock()
+		return fmt.Errorf("%w: %s", ErrActive, name)
+	case name == s.building:
+		s.mu.Unlock()
+		return ErrBuilding
+	}
+	delete(s.versions, name)
+	if s.previous == name {
+		s.previous = ""
+	}
+	s.mu.Unlock()
+
+	if name != Initial {
+		return s.versioner.DropVersion(ctx, name)
+	}
+	// The initial version is the configured store itself; empty it.
+	ids := map[string]bool{}
+	err := v.store.ScanChunks(ctx, func(c domain.Chunk) error {
+		ids[c.DocumentID] = true
+		return nil
+	})
+	for documentID := range ids {
+		err = errors.Join(err, v.store.DeleteDocument(ctx, documentID))
+	}
+	return err
+}
+
+// target is where a change goes, copied so Activate can swap pipelines.
+type target struct {
+	name     string
+	store    domain.VectorStore
+	pipeline Pipeline
+}
+
+// current returns the active version and the others kept up to date.
+func (s *Service) current() (target, []target) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var others []target
+	for _, name := range []string{s.building, s.previous} {
+		if v, ok := s.versions[name]; ok && name != s.active {
+			others = append(others, target{name: name, store: v.store, pipeline: v.pipeline})
+		}
+	}
+	active := s.versions[s.active]
+	return target{name: s.active, store: active.store, pipeline: active.pipeline}, others
+}
+
+// Handle indexes event into the active version, then into the others; only
+// the active version's errors are returned.
+func (s *Service) Handle(ctx context.Context, event domain.IngestEvent) error {
+	active, others := s.current()
+	err := active.pipeline.Ingester.Handle(ctx, event)
+	for _, v := range others {
+		if verr := v.pipeline.Ingester.Handle(ctx, event); verr != nil {
+			log.Printf("Indexing %s into index version %s failed: %v\n", event.DocumentID, v.name, verr)
+		}
+	}
+	return err
+}
+
+// AddManual adds a document through the active version and under the same ID
+// to the others.
+func (s *Service) AddManual(ctx context.Context, m ingest.Manual) (string, error) {
+	active, others := s.current()
+	docID, err := active.pipeline.Ingester.AddManual(ctx, m)
+	if err != nil {
+		return "", err
+	}
+	m.ID = docID
+	for _, v := range others {
+		if _, verr := v.pipeline.Ingester.AddManual(ctx, m); verr != nil {
+			log.Printf("Adding %s to index version %s failed: %v\n", docID, v.name, verr)
+		}
+	}
+	return docID, nil
+}
+
+func (s *Service) Delete(ctx context.Context, documentID string) error {
+	active, others := s.current()
+	err := active.pipeline.Ingester.Delete(ctx, documentID)
+	for _, v := range others {
+		if verr := v.pipeline.Ingester.Delete(ctx, documentID); verr != nil {
+			log.Printf("Deleting %s from index version %s failed: %v\n", documentID, v.name, verr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,141 @@
+package ingest
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Chunker splits text into overlapping chunks of roughly Size characters,
+// preferring paragraph, then line, then word boundaries.
+type Chunker struct {
+	Size    int
+	Overlap int
+}
+
+func (c Chunker) Split(text string) []string {
+	var chunks []string
+	c.Stream(strings.NewReader(text), func(chunk string) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	return chunks
+}
+
+// Stream reads text from r a line at a time and calls emit with each chunk
+// once it is complete, so a large document is never held whole. An error
+// reading r or from emit stops it.
+func (c Chunker) Stream(r io.Reader, emit func(chunk string) error) error {
+	size := c.Size
+	if size <= 0 {
+		size = 1000
+	}
+	overlap := c.Overlap
+	if overlap < 0 || overlap >= size {
+		overlap = 0
+	}
+
+	var current strings.Builder
+	// fresh is set once current holds text beyond the overlap carried from the previous chunk.
+	fresh := false
+	flush := func() error {
+		chunk := strings.TrimSpace(current.String())
+		current.Reset()
+		current.WriteString(tail(chunk, overlap))
+		fresh = false
+		return emit(chunk)
+	}
+	add := func(piece string) error {
+		if fresh && utf8.RuneCountInString(current.String())+utf8.RuneCountInString(piece)+1 > size {
+			if err := flush(); err != nil {
+				return err
+			}
+		}
+		if current.Len() > 0 {
+			current.WriteByte('\n')
+		}
+		current.WriteString(piece)
+		fresh = true
+		return nil
+	}
+
+	lines := bufio.NewReader(r)
+	for {
+		line, err := lines.ReadString('\n')
+		if err != nil && err != io.EOF {
+			return err
+		}
+		if perr := pieces(line, size-overlap, add); perr != nil {
+			return perr
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	if fresh {
+		return flush()
+	}
+	return nil
+}
+
+// pieces breaks a paragraph into pieces no longer than max runes and calls
+// fn with each.
+func pieces(para string, max int, fn func(piece string) error) error {
+	para = strings.TrimSpace(para)
+	for longer(para, max) {
+		cut := splitPoint(para, max)
+		if err := fn(strings.TrimSpace(para[:cut])); err != nil {
+			return err
+		}
+		para = strings.TrimSpace(para[cut:])
+	}
+	if para == "" {
+		return nil
+	}
+	return fn(para)
+}
+
+// longer reports whether s has more than max runes, without counting past them.
+func longer(s string, max int) bool {
+	runes := 0
+	for range s {
+		if runes == max {
+			return true
+		}
+		runes++
+	}
+	return false
+}
+
+// splitPoint returns a byte offset at or before max runes, on a space when possible.
+func splitPoint(s string, max int) int {
+	end := len(s)
+	runes := 0
+	for i := range s {
+		if runes == max {
+			end = i
+			break
+		}
+		runes++
+	}
+	if sp := strings.LastIndexByte(s[:end], ' '); sp > end/2 {
+		return sp
+	}
+	return end
+}
+
+func tail(s string, n int) string {
+	if n <= 0 {
+		return ""
+	}
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	t := string(runes[len(runes)-n:])
+	if sp := strings.IndexByte(t, ' '); sp >= 0 && sp < len(t)/2 {
+		t = t[sp+1:]
+	}
+	return t
+}
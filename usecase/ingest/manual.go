@@ -0,0 +1,79 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/htmltext"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+// Formats of manually added content. Markdown is indexed as written, since
+// its markup reads as plain text; HTML is reduced to its text.
+const (
+	FormatText     = "text"
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+var ErrInvalidDocument = errors.New("invalid document")
+
+// Manual is content added through the API rather than fetched from a source,
+// such as a runbook kept outside Confluence.
+type Manual struct {
+	// ID names the document so adding it again replaces it; one is generated
+	// when empty.
+	ID       string
+	Title    string
+	SpaceKey string
+	URL      string
+	Labels   []string
+	Readers  []string
+	Format   string
+	Content  string
+}
+
+// AddManual indexes m and returns its document ID, which starts with
+// domain.ManualDocumentPrefix. Remove it like any other document.
+func (s *Service) AddManual(ctx context.Context, m Manual) (string, error) {
+	if strings.TrimSpace(m.Title) == "" {
+		return "", fmt.Errorf("%w: title is required", ErrInvalidDocument)
+	}
+	body := m.Content
+	switch m.Format {
+	case "", FormatText, FormatMarkdown:
+	case FormatHTML:
+		body = htmltext.ExtractString(m.Content)
+	default:
+		return "", fmt.Errorf("%w: format must be %s, %s or %s", ErrInvalidDocument, FormatText, FormatMarkdown, FormatHTML)
+	}
+	if strings.TrimSpace(body) == "" {
+		return "", fmt.Errorf("%w: content is empty", ErrInvalidDocument)
+	}
+
+	docID := m.ID
+	if docID == "" {
+		docID = id.New()
+	}
+	if !domain.IsManualDocument(docID) {
+		docID = domain.ManualDocumentPrefix + docID
+	}
+	doc := &domain.Document{
+		ID:        docID,
+		SpaceKey:  m.SpaceKey,
+		Title:     m.Title,
+		URL:       m.URL,
+		Body:      body,
+		Labels:    m.Labels,
+		Readers:   m.Readers,
+		UpdatedAt: time.Now().UTC(),
+	}
+	if err := s.Index(ctx, doc); err != nil {
+		return "", err
+	}
+	return docID, nil
+}
@@ -0,0 +1,424 @@
+package ingest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/logger"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+)
+
+type Config struct {
+	ChunkSize    int
+	ChunkOverlap int
+	// EmbedBatchSize is how many chunks are handed to the embedder at a time
+	// while a document is chunked; a large page would otherwise be held and
+	// sent whole.
+	EmbedBatchSize int
+}
+
+// Enricher derives extra knowledge from a freshly indexed document and drops it
+// again when the document is removed.
+type Enricher interface {
+	Enrich(ctx context.Context, doc *domain.Document, chunks []domain.Chunk) error
+	Forget(ctx context.Context, documentID string) error
+}
+
+// Preprocessor adjusts a fetched document before it is chunked, e.g. to add
+// text derived from embedded media. A streamed document is preprocessed once
+// its body has been read, with Body empty, and what is added to Body is
+// chunked after the body.
+type Preprocessor interface {
+	Preprocess(ctx context.Context, doc *domain.Document) error
+}
+
+// Service turns ingest events into indexed chunks.
+type Service struct {
+	source   domain.DocumentSource
+	embedder domain.Embedder
+	store    domain.VectorStore
+	chunker  Chunker
+	batch    int
+
+	preprocessors []Preprocessor
+	enrichers     []Enricher
+	ledger        domain.IngestLedger
+	notifier      domain.Notifier
+	log           *logger.Logger
+	units         domain.UnitOfWork
+}
+
+type Option func(*Service)
+
+func WithEnricher(e Enricher) Option {
+	return func(s *Service) { s.enrichers = append(s.enrichers, e) }
+}
+
+func WithPreprocessor(p Preprocessor) Option {
+	return func(s *Service) { s.preprocessors = append(s.preprocessors, p) }
+}
+
+// WithLedger makes Handle effectively-once: redelivered events and events older
+// than the document's last applied change are skipped. Indexing itself is
+// idempotent (deterministic chunk IDs, replace-all writes), so a crash between
+// indexing and recording the event only repeats the same write.
+func WithLedger(l domain.IngestLedger) Option {
+	return func(s *Service) { s.ledger = l }
+}
+
+// WithUnitOfWork writes a document's chunks, and with a ledger the event that
+// produced them, through the stores of u so they are committed together.
+func WithUnitOfWork(u domain.UnitOfWork) Option {
+	return func(s *Service) { s.units = u }
+}
+
+// WithNotifier reports documents that were indexed or removed.
+func WithNotifier(n domain.Notifier) Option {
+	return func(s *Service) { s.notifier = n }
+}
+
+// WithLogger logs through l, e.g. a logger named for ingestion so its level
+// can be raised alone; the default is the process-wide logger.
+func WithLogger(l *logger.Logger) Option {
+	return func(s *Service) { s.log = l }
+}
+
+func NewService(source domain.DocumentSource, embedder domain.Embedder, store domain.VectorStore, cfg Config, opts ...Option) *Service {
+	s := &Service{
+		source:   source,
+		embedder: embedder,
+		store:    store,
+		chunker:  Chunker{Size: cfg.ChunkSize, Overlap: cfg.ChunkOverlap},
+		batch:    cfg.EmbedBatchSize,
+		log:      logger.Default(),
+	}
+	if s.batch <= 0 {
+		s.batch = 64
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Service) Handle(ctx context.Context, event domain.IngestEvent) (err error) {
+	ctx, span := tracing.Start(ctx, "ingest", tracing.WithAttributes(
+		tracing.String("ingest.event_id", event.ID),
+		tracing.String("ingest.action", string(event.Action)),
+		tracing.String("document.id", event.DocumentID),
+	))
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	if s.ledger == nil {
+		return s.apply(ctx, event)
+	}
+
+	applied, err := s.ledger.Applied(ctx, event.ID)
+	if err != nil {
+		return fmt.Errorf("check ingest ledger: %w", err)
+	}
+	if applied {
+		s.log.Debug("Skipping already applied ingest event", logger.String("event_id", event.ID))
+		return nil
+	}
+	watermark, err := s.ledger.Watermark(ctx, event.DocumentID)
+	if err != nil {
+		return fmt.Errorf("check ingest ledger: %w", err)
+	}
+	if event.ReceivedAt.Before(watermark) {
+		s.log.Debug("Skipping stale ingest event",
+			logger.String("event_id", event.ID), logger.String("document_id", event.DocumentID))
+		return nil
+	}
+
+	return s.apply(ctx, event)
+}
+
+func (s *Service) apply(ctx context.Context, event domain.IngestEvent) error {
+	var c change
+	switch event.Action {
+	case domain.IngestDelete:
+		if ok, err := s.mayDelete(ctx, event); !ok {
+			return err
+		}
+		c = s.deletion(event.DocumentID)
+	case domain.IngestUpsert:
+		if domain.IsManualDocument(event.DocumentID) {
+			return fmt.Errorf("document %s was added through the API and can't be fetched", event.DocumentID)
+		}
+		fetchCtx, span := tracing.Start(ctx, "ingest.fetch")
+		doc, body, err := s.fetch(fetchCtx, event.DocumentID)
+		if !errors.Is(err, domain.ErrNotFound) {
+			span.SetError(err)
+		}
+		span.End()
+		if errors.Is(err, domain.ErrNotFound) {
+			// The page disappeared between the event and the fetch.
+			if ok, err := s.mayDelete(ctx, event); !ok {
+				return err
+			}
+			c = s.deletion(event.DocumentID)
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("fetch document %s: %w", event.DocumentID, err)
+		}
+		if body != nil {
+			defer body.Close()
+		}
+		if len(event.Spaces) > 0 && !slices.Contains(event.Spaces, doc.SpaceKey) {
+			s.log.Warn("Skipping ingest event for a document outside its spaces",
+				logger.String("event_id", event.ID), logger.String("document_id", event.DocumentID))
+			return nil
+		}
+		if c, err = s.indexing(ctx, doc, body); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported ingest action %q", event.Action)
+	}
+	return s.commit(ctx, c, &event)
+}
+
+// fetch gets the document, streaming its text when the source can; body is
+// nil otherwise.
+func (s *Service) fetch(ctx context.Context, id string) (doc *domain.Document, body io.ReadCloser, err error) {
+	if streamer, ok := s.source.(domain.DocumentStreamer); ok {
+		return streamer.StreamDocument(ctx, id)
+	}
+	doc, err = s.source.GetDocument(ctx, id)
+	return doc, nil, err
+}
+
+// mayDelete reports whether event may remove its document: always, unless
+// the event is limited to spaces and the document is indexed in another.
+func (s *Service) mayDelete(ctx context.Context, event domain.IngestEvent) (bool, error) {
+	if len(event.Spaces) == 0 {
+		return true, nil
+	}
+	chunks, err := s.store.DocumentChunks(ctx, event.DocumentID)
+	if err != nil {
+		return false, fmt.Errorf("look up document %s: %w", event.DocumentID, err)
+	}
+	if len(chunks) > 0 && !slices.Contains(event.Spaces, chunks[0].SpaceKey) {
+		s.log.Warn("Skipping ingest event for a document outside its spaces",
+			logger.String("event_id", event.ID), logger.String("document_id", event.DocumentID))
+		return false, nil
+	}
+	return true, nil
+}
+
+// change is a prepared update of the index: write makes it, in one unit of
+// work, and done follows up once it is committed.
+type change struct {
+	write func(ctx context.Context, stores domain.Stores) error
+	done  func(ctx context.Context)
+}
+
+// commit writes c and, when event is set and a ledger is kept, records the
+// event in the same unit of work.
+func (s *Service) commit(ctx context.Context, c change, event *domain.IngestEvent) error {
+	storeCtx, span := tracing.Start(ctx, "ingest.store")
+	err := s.do(storeCtx, func(ctx context.Context, stores domain.Stores) error {
+		if err := c.write(ctx, stores); err != nil {
+			return err
+		}
+		if event == nil || s.ledger == nil {
+			return nil
+		}
+		if err := stores.Ledger.MarkApplied(ctx, *event); err != nil {
+			return fmt.Errorf("record ingest event %s: %w", event.ID, err)
+		}
+		return nil
+	})
+	span.SetError(err)
+	span.End()
+	if err != nil {
+		return err
+	}
+	c.done(ctx)
+	return nil
+}
+
+func (s *Service) do(ctx context.Context, fn func(ctx context.Context, stores domain.Stores) error) error {
+	if s.units != nil {
+		return s.units.Do(ctx, fn)
+	}
+	return fn(ctx, domain.Stores{Chunks: s.store, Ledger: s.ledger})
+}
+
+func (s *Service) Index(ctx context.Context, doc *domain.Document) error {
+	c, err := s.indexing(ctx, doc, nil)
+	if err != nil {
+		return err
+	}
+	return s.commit(ctx, c, nil)
+}
+
+// indexing chunks and embeds doc, ready to replace its stored chunks. The
+// text is read from body when doc was streamed, and from doc.Body otherwise.
+func (s *Service) indexing(ctx context.Context, doc *domain.Document, body io.Reader) (change, error) {
+	var src io.Reader
+	if body == nil {
+		s.preprocess(ctx, doc)
+		src = strings.NewReader(doc.Body)
+	} else {
+		src = io.MultiReader(body, &lazyReader{open: func() io.Reader {
+			s.preprocess(ctx, doc)
+			return strings.NewReader(doc.Body)
+		}})
+	}
+
+	// Chunks are embedded a batch at a time as the body is chunked, so only
+	// one batch of embedding inputs is held besides the chunks themselves.
+	chunkCtx, span := tracing.Start(ctx, "ingest.chunk")
+	var chunks []domain.Chunk
+	var texts []string
+	embed := func() error {
+		if len(texts) == 0 {
+			return nil
+		}
+		inputs := make([]string, len(texts))
+		for i, t := range texts {
+			inputs[i] = doc.Title + "\n\n" + t
+		}
+		embedCtx, span := tracing.Start(chunkCtx, "ingest.embed", tracing.WithAttributes(tracing.Int("chunks", len(inputs))))
+		vectors, err := s.embedder.Embed(embedCtx, inputs)
+		span.SetError(err)
+		span.End()
+		if err != nil {
+			return err
+		}
+		for i, t := range texts {
+			chunks = append(chunks, domain.Chunk{
+				ID:         fmt.Sprintf("%s-%d", doc.ID, len(chunks)),
+				DocumentID: doc.ID,
+				SpaceKey:   doc.SpaceKey,
+				Title:      doc.Title,
+				URL:        doc.URL,
+				Index:      len(chunks),
+				Text:       t,
+				Readers:    doc.Readers,
+				Labels:     doc.Labels,
+				Embedding:  vectors[i],
+				UpdatedAt:  doc.UpdatedAt,
+			})
+		}
+		texts = texts[:0]
+		return nil
+	}
+	err := s.chunker.Stream(src, func(text string) error {
+		texts = append(texts, text)
+		if len(texts) < s.batch {
+			return nil
+		}
+		return embed()
+	})
+	if err == nil {
+		err = embed()
+	}
+	span.SetAttributes(tracing.Int("chunks", len(chunks)))
+	span.SetError(err)
+	span.End()
+	if err != nil {
+		return change{}, fmt.Errorf("embed document %s: %w", doc.ID, err)
+	}
+	if body != nil {
+		// Enrichers read a streamed document's text from its chunks; Body
+		// only holds what preprocessors added.
+		doc.Body = ""
+	}
+	if len(chunks) == 0 {
+		return s.deletion(doc.ID), nil
+	}
+
+	write := func(ctx context.Context, stores domain.Stores) error {
+		// Drop chunks from the previous version first; a shorter page would otherwise leave stale tails.
+		if err := stores.Chunks.DeleteDocument(ctx, doc.ID); err != nil {
+			return fmt.Errorf("delete previous chunks of %s: %w", doc.ID, err)
+		}
+		if err := stores.Chunks.Upsert(ctx, chunks); err != nil {
+			return fmt.Errorf("store chunks of %s: %w", doc.ID, err)
+		}
+		return nil
+	}
+	return change{write: write, done: func(ctx context.Context) { s.indexed(ctx, doc, chunks) }}, nil
+}
+
+func (s *Service) preprocess(ctx context.Context, doc *domain.Document) {
+	for _, p := range s.preprocessors {
+		if err := p.Preprocess(ctx, doc); err != nil {
+			s.log.Warn("Preprocessing document failed",
+				logger.String("document_id", doc.ID), logger.Err(err))
+		}
+	}
+}
+
+// lazyReader reads from the reader open returns, called on the first Read.
+type lazyReader struct {
+	open func() io.Reader
+	r    io.Reader
+}
+
+func (l *lazyReader) Read(p []byte) (int, error) {
+	if l.r == nil {
+		l.r = l.open()
+	}
+	return l.r.Read(p)
+}
+
+func (s *Service) indexed(ctx context.Context, doc *domain.Document, chunks []domain.Chunk) {
+	for _, e := range s.enrichers {
+		if err := e.Enrich(ctx, doc, chunks); err != nil {
+			s.log.Warn("Enriching document failed",
+				logger.String("document_id", doc.ID), logger.Err(err))
+		}
+	}
+	s.log.Debug("Indexed document",
+		logger.String("document_id", doc.ID), logger.Int("version", doc.Version), logger.Int("chunks", len(chunks)))
+	if s.notifier != nil {
+		s.notifier.Notify(ctx, domain.EventDocumentIndexed, domain.DocumentEvent{
+			DocumentID: doc.ID,
+			Title:      doc.Title,
+			SpaceKey:   doc.SpaceKey,
+			URL:        doc.URL,
+			Version:    doc.Version,
+			Chunks:     len(chunks),
+		})
+	}
+}
+
+func (s *Service) Delete(ctx context.Context, documentID string) error {
+	return s.commit(ctx, s.deletion(documentID), nil)
+}
+
+// deletion removes a document's chunks.
+func (s *Service) deletion(documentID string) change {
+	write := func(ctx context.Context, stores domain.Stores) error {
+		if err := stores.Chunks.DeleteDocument(ctx, documentID); err != nil {
+			return fmt.Errorf("delete document %s: %w", documentID, err)
+		}
+		return nil
+	}
+	return change{write: write, done: func(ctx context.Context) { s.deleted(ctx, documentID) }}
+}
+
+func (s *Service) deleted(ctx context.Context, documentID string) {
+	for _, e := range s.enrichers {
+		if err := e.Forget(ctx, documentID); err != nil {
+			s.log.Warn("Removing enrichments failed",
+				logger.String("document_id", documentID), logger.Err(err))
+		}
+	}
+	s.log.Debug("Deleted document", logger.String("document_id", documentID))
+	if s.notifier != nil {
+		s.notifier.Notify(ctx, domain.EventDocumentDeleted, domain.DocumentEvent{DocumentID: documentID})
+	}
+}
@@ -0,0 +1,105 @@
+package ingest
+
+import (
+	"context"
+	"io"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/htmltext"
+)
+
+const largePageBytes = 50 << 20
+
+// pageMarkup writes about size bytes of storage format, a paragraph at a time.
+func pageMarkup(w io.Writer, size int) error {
+	paragraph := "<p>" + strings.Repeat("The deploy pipeline promotes a build once its checks pass. ", 8) + "</p>\n"
+	for written := 0; written < size; written += len(paragraph) {
+		if _, err := io.WriteString(w, paragraph); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// largePage serves one page of pageMarkup, held whole by GetDocument as a
+// source without streaming would, or streamed through StreamDocument.
+type largePage struct{}
+
+func (largePage) GetDocument(ctx context.Context, id string) (*domain.Document, error) {
+	var markup strings.Builder
+	pageMarkup(&markup, largePageBytes)
+	return &domain.Document{ID: id, SpaceKey: "ENG", Title: "Large page", Body: htmltext.ExtractString(markup.String())}, nil
+}
+
+type streamedPage struct{ largePage }
+
+func (streamedPage) StreamDocument(ctx context.Context, id string) (*domain.Document, io.ReadCloser, error) {
+	markup, mw := io.Pipe()
+	go func() { mw.CloseWithError(pageMarkup(mw, largePageBytes)) }()
+	text, tw := io.Pipe()
+	go func() {
+		err := htmltext.ExtractTo(tw, markup)
+		markup.CloseWithError(io.ErrClosedPipe)
+		tw.CloseWithError(err)
+	}()
+	return &domain.Document{ID: id, SpaceKey: "ENG", Title: "Large page"}, text, nil
+}
+
+// peakEmbedder returns tiny vectors and samples the live heap on every call,
+// as the chunks of the page pile up.
+type peakEmbedder struct {
+	peak uint64
+}
+
+func (e *peakEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+	e.peak = max(e.peak, m.HeapAlloc)
+	vectors := make([][]float32, len(texts))
+	for i := range vectors {
+		vectors[i] = []float32{1, 0, 0, 0}
+	}
+	return vectors, nil
+}
+
+// nopStore drops what it is given.
+type nopStore struct{}
+
+func (nopStore) Upsert(ctx context.Context, chunks []domain.Chunk) error { return nil }
+func (nopStore) Search(ctx context.Context, vector []float32, topK int, filter domain.SearchFilter) ([]domain.ScoredChunk, error) {
+	return nil, nil
+}
+func (nopStore) DeleteDocument(ctx context.Context, documentID string) error { return nil }
+func (nopStore) DocumentChunks(ctx context.Context, documentID string) ([]domain.Chunk, error) {
+	return nil, nil
+}
+func (nopStore) ScanChunks(ctx context.Context, fn func(domain.Chunk) error) error { return nil }
+
+func benchmarkLargePage(b *testing.B, source domain.DocumentSource) {
+	embedder := &peakEmbedder{}
+	s := NewService(source, embedder, nopStore{}, Config{ChunkSize: 1000, ChunkOverlap: 150, EmbedBatchSize: 256})
+	event := domain.IngestEvent{ID: "evt", Action: domain.IngestUpsert, DocumentID: "large"}
+	b.SetBytes(largePageBytes)
+	b.ReportAllocs()
+	for b.Loop() {
+		runtime.GC()
+		if err := s.Handle(context.Background(), event); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(embedder.peak)/(1<<20), "peak-heap-MB")
+}
+
+// BenchmarkIngestLargePage indexes a 50MB page held in memory.
+func BenchmarkIngestLargePage(b *testing.B) {
+	benchmarkLargePage(b, largePage{})
+}
+
+// BenchmarkIngestLargePageStreamed indexes a 50MB page streamed from the
+// source's reader through text extraction into the chunker.
+func BenchmarkIngestLargePageStreamed(b *testing.B) {
+	benchmarkLargePage(b, streamedPage{})
+}
@@ -0,0 +1,30 @@
+package moderation
+
+import (
+	"context"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// Chain runs moderators in order, feeding redacted text forward and stopping at the first block.
+type Chain []domain.Moderator
+
+func (c Chain) Moderate(ctx context.Context, text string) (domain.ModerationResult, error) {
+	result := domain.ModerationResult{Action: domain.ModerationAllow, Text: text}
+	for _, m := range c {
+		r, err := m.Moderate(ctx, result.Text)
+		if err != nil {
+			return result, err
+		}
+		result.Categories = append(result.Categories, r.Categories...)
+		switch r.Action {
+		case domain.ModerationBlock:
+			result.Action = domain.ModerationBlock
+			return result, nil
+		case domain.ModerationRedact:
+			result.Action = domain.ModerationRedact
+			result.Text = r.Text
+		}
+	}
+	return result, nil
+}
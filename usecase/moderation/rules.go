@@ -0,0 +1,111 @@
+package moderation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Rule struct {
+	Name        string                  `json:"name"`
+	Action      domain.ModerationAction `json:"action"`
+	Pattern     string                  `json:"pattern,omitempty"`
+	Keywords    []string                `json:"keywords,omitempty"`
+	Replacement string                  `json:"replacement,omitempty"`
+}
+
+// DefaultRules redact credentials that occasionally leak into wiki pages and questions.
+var DefaultRules = []Rule{
+	{Name: "aws-access-key", Action: domain.ModerationRedact, Pattern: `\b(AKIA|ASIA)[0-9A-Z]{16}\b`},
+	{Name: "private-key", Action: domain.ModerationRedact, Pattern: `-----BEGIN [A-Z ]*PRIVATE KEY-----[\s\S]*?-----END [A-Z ]*PRIVATE KEY-----`},
+	{Name: "bearer-token", Action: domain.ModerationRedact, Pattern: `(?i)\bbearer\s+[a-z0-9\-._~+/]{20,}=*`},
+	{Name: "slack-token", Action: domain.ModerationRedact, Pattern: `\bxox[abprs]-[0-9A-Za-z-]{10,}\b`},
+}
+
+// PIIRules redact personal contact details; they are used where data leaves the
+// service, e.g. for audit events.
+var PIIRules = []Rule{
+	{Name: "email", Action: domain.ModerationRedact, Pattern: `(?i)\b[a-z0-9._%+\-]+@[a-z0-9.\-]+\.[a-z]{2,}\b`, Replacement: "[EMAIL]"},
+	{Name: "phone", Action: domain.ModerationRedact, Pattern: `\+?\d[\d\s().\-]{7,}\d`, Replacement: "[PHONE]"},
+	{Name: "ipv4", Action: domain.ModerationRedact, Pattern: `\b(?:\d{1,3}\.){3}\d{1,3}\b`, Replacement: "[IP]"},
+}
+
+type compiledRule struct {
+	Rule
+	re *regexp.Regexp
+}
+
+// RuleModerator applies local regex and keyword rules without calling any provider.
+type RuleModerator struct {
+	rules []compiledRule
+}
+
+func NewRuleModerator(rules []Rule) (*RuleModerator, error) {
+	compiled := make([]compiledRule, 0, len(rules))
+	for _, r := range rules {
+		if r.Action != domain.ModerationRedact && r.Action != domain.ModerationBlock {
+			return nil, fmt.Errorf("rule %s: action must be %q or %q", r.Name, domain.ModerationRedact, domain.ModerationBlock)
+		}
+
+		pattern := r.Pattern
+		if len(r.Keywords) > 0 {
+			quoted := make([]string, len(r.Keywords))
+			for i, k := range r.Keywords {
+				quoted[i] = regexp.QuoteMeta(k)
+			}
+			keywords := `(?i)\b(` + strings.Join(quoted, "|") + `)\b`
+			if pattern != "" {
+				pattern = "(" + pattern + ")|" + keywords
+			} else {
+				pattern = keywords
+			}
+		}
+		if pattern == "" {
+			return nil, fmt.Errorf("rule %s: pattern or keywords required", r.Name)
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", r.Name, err)
+		}
+		if r.Replacement == "" {
+			r.Replacement = "[REDACTED]"
+		}
+		compiled = append(compiled, compiledRule{Rule: r, re: re})
+	}
+	return &RuleModerator{rules: compiled}, nil
+}
+
+func (m *RuleModerator) Moderate(ctx context.Context, text string) (domain.ModerationResult, error) {
+	result := domain.ModerationResult{Action: domain.ModerationAllow, Text: text}
+	for _, r := range m.rules {
+		if !r.re.MatchString(result.Text) {
+			continue
+		}
+		result.Categories = append(result.Categories, r.Name)
+		if r.Action == domain.ModerationBlock {
+			result.Action = domain.ModerationBlock
+			return result, nil
+		}
+		result.Action = domain.ModerationRedact
+		result.Text = r.re.ReplaceAllLiteralString(result.Text, r.Replacement)
+	}
+	return result, nil
+}
+
+func LoadRules(path string) ([]Rule, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return rules, nil
+}
@@ -0,0 +1,238 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+// Headers sent with every delivery. Receivers verify SignatureHeader, which is
+// "sha256=" followed by the hex HMAC-SHA256 of TimestampHeader + "." + body
+// keyed with the webhook's secret, and should reject stale timestamps.
+const (
+	DeliveryHeader  = "X-Webhook-Delivery"
+	EventHeader     = "X-Webhook-Event"
+	TimestampHeader = "X-Webhook-Timestamp"
+	SignatureHeader = "X-Webhook-Signature"
+)
+
+var ErrInvalidWebhook = errors.New("invalid webhook")
+
+type Config struct {
+	Timeout     time.Duration
+	MaxAttempts int
+	// RetryDelay is the wait before the first retry; it doubles after each failure.
+	RetryDelay time.Duration
+	QueueSize  int
+	Workers    int
+}
+
+// Payload is the JSON body of a delivery.
+type Payload struct {
+	ID         string           `json:"id"`
+	Type       domain.EventType `json:"type"`
+	OccurredAt time.Time        `json:"occurred_at"`
+	Data       any              `json:"data"`
+}
+
+type delivery struct {
+	hook    domain.Webhook
+	payload Payload
+	body    []byte
+	attempt int
+}
+
+// Service manages outbound webhooks and delivers events to them in the
+// background, retrying failed deliveries with exponential backoff.
+type Service struct {
+	repo   domain.WebhookRepository
+	client *http.Client
+	cfg    Config
+	queue  chan delivery
+	busy   atomic.Int64
+}
+
+func NewService(repo domain.WebhookRepository, cfg Config) *Service {
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 1
+	}
+	if cfg.Workers <= 0 {
+		cfg.Workers = 1
+	}
+	return &Service{
+		repo:   repo,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+		queue:  make(chan delivery, cfg.QueueSize),
+	}
+}
+
+// Subscribe registers a webhook and returns its signing secret, which is
+// generated when none is given and not retrievable later.
+func (s *Service) Subscribe(ctx context.Context, rawURL, secret string, events []domain.EventType) (string, *domain.Webhook, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return "", nil, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+	for _, e := range events {
+		if !e.Valid() {
+			return "", nil, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, e)
+		}
+	}
+	if secret == "" {
+		b := make([]byte, 32)
+		if _, err := rand.Read(b); err != nil {
+			return "", nil, err
+		}
+		secret = hex.EncodeToString(b)
+	}
+	hook := domain.Webhook{ID: id.New(), URL: u.String(), Secret: secret, Events: events, CreatedAt: time.Now().UTC()}
+	if err := s.repo.SaveWebhook(ctx, hook); err != nil {
+		return "", nil, err
+	}
+	return secret, &hook, nil
+}
+
+func (s *Service) Webhooks(ctx context.Context) ([]domain.Webhook, error) {
+	return s.repo.ListWebhooks(ctx)
+}
+
+func (s *Service) Unsubscribe(ctx context.Context, id string) error {
+	return s.repo.DeleteWebhook(ctx, id)
+}
+
+// Start runs the delivery workers until ctx is cancelled. Deliveries still
+// queued or waiting to be retried at that point are dropped.
+func (s *Service) Start(ctx context.Context) {
+	for range s.cfg.Workers {
+		go func() {
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-s.queue:
+					s.deliver(ctx, d)
+				}
+			}
+		}()
+	}
+}
+
+// QueueDepth counts the deliveries waiting for a worker.
+func (s *Service) QueueDepth() int {
+	return len(s.queue)
+}
+
+// Workers reports how many deliveries can be sent at once and how many are
+// being sent.
+func (s *Service) Workers() (total, busy int) {
+	return s.cfg.Workers, int(s.busy.Load())
+}
+
+// Notify queues event for every subscribed webhook without waiting for delivery.
+func (s *Service) Notify(ctx context.Context, event domain.EventType, data any) {
+	hooks, err := s.repo.ListWebhooks(ctx)
+	if err != nil {
+		log.Printf("Loading webhooks for %s failed: %v\n", event, err)
+		return
+	}
+	payload := Payload{ID: id.New(), Type: event, OccurredAt: time.Now().UTC(), Data: data}
+	var body []byte
+	for _, hook := range hooks {
+		if !hook.Subscribed(event) {
+			continue
+		}
+		if body == nil {
+			if body, err = json.Marshal(payload); err != nil {
+				log.Printf("Encoding %s webhook payload failed: %v\n", event, err)
+				return
+			}
+		}
+		s.enqueue(delivery{hook: hook, payload: payload, body: body, attempt: 1})
+	}
+}
+
+func (s *Service) enqueue(d delivery) {
+	select {
+	case s.queue <- d:
+	default:
+		log.Printf("Webhook queue full, dropping %s delivery %s to %s\n", d.payload.Type, d.payload.ID, d.hook.URL)
+	}
+}
+
+func (s *Service) deliver(ctx context.Context, d delivery) {
+	s.busy.Add(1)
+	retry, err := s.send(ctx, d)
+	s.busy.Add(-1)
+	if err == nil {
+		return
+	}
+	if !retry || d.attempt >= s.cfg.MaxAttempts {
+		log.Printf("Webhook delivery %s to %s failed after %d attempts: %v\n", d.payload.ID, d.hook.URL, d.attempt, err)
+		return
+	}
+	delay := s.cfg.RetryDelay << (d.attempt - 1)
+	log.Printf("Webhook delivery %s to %s failed, retrying in %s: %v\n", d.payload.ID, d.hook.URL, delay, err)
+	d.attempt++
+	// Wait off the worker so one slow endpoint doesn't hold up the others.
+	time.AfterFunc(delay, func() {
+		if ctx.Err() == nil {
+			s.enqueue(d)
+		}
+	})
+}
+
+// send posts one delivery and reports whether a failure is worth retrying.
+func (s *Service) send(ctx context.Context, d delivery) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.hook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return false, err
+	}
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sarama-ai-webhooks")
+	req.Header.Set(DeliveryHeader, d.payload.ID)
+	req.Header.Set(EventHeader, string(d.payload.Type))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(d.hook.Secret, timestamp, d.body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return false, nil
+	case resp.StatusCode == http.StatusRequestTimeout, resp.StatusCode == http.StatusTooManyRequests, resp.StatusCode >= 500:
+		return true, fmt.Errorf("status %d", resp.StatusCode)
+	default:
+		return false, fmt.Errorf("status %d", resp.StatusCode)
+	}
+}
+
+// Sign computes the SignatureHeader value for a delivery body.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,147 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const (
+	// messageOverhead approximates the tokens a chat API spends on each message's framing.
+	messageOverhead = 4
+	// minChunkTokens is the smallest truncated passage still worth including.
+	minChunkTokens = 64
+)
+
+// EstimateTokens approximates the token count of s at four bytes per token,
+// which slightly overestimates English and stays safe for other scripts.
+func EstimateTokens(s string) int {
+	return (len(s) + 3) / 4
+}
+
+// Budget packs a prompt into the model's context window. Parts are admitted by
+// priority: system prompt and question, the best passage, recent history,
+// glossary, the remaining passages in rank order, then graph relations.
+// Passages and history turns that don't fit are truncated or dropped.
+type Budget struct {
+	ContextWindow int
+	MaxOutput     int
+	MaxHistory    int
+}
+
+// Fit returns in trimmed to the budget; a zero ContextWindow disables packing.
+func (b Budget) Fit(in PromptInput) PromptInput {
+	if b.ContextWindow <= 0 {
+		return in
+	}
+
+	out := in
+	out.Chunks, out.History, out.Glossary, out.Relations = nil, nil, nil, nil
+
+	remaining := b.ContextWindow - b.MaxOutput -
+		EstimateTokens(systemPrompt(in.Version)+LanguageInstruction(in.Language)) -
+		EstimateTokens("Context:\n\nQuestion: "+in.Question) - 2*messageOverhead
+
+	take := func(cost int) bool {
+		if cost > remaining {
+			return false
+		}
+		remaining -= cost
+		return true
+	}
+	addChunk := func(c domain.ScoredChunk) bool {
+		n := len(out.Chunks) + 1
+		if take(chunkTokens(n, c)) {
+			out.Chunks = append(out.Chunks, c)
+			return true
+		}
+		room := remaining - chunkTokens(n, domain.ScoredChunk{Chunk: domain.Chunk{Title: c.Title, URL: c.URL}})
+		if room < minChunkTokens {
+			return false
+		}
+		c.Text = truncateText(c.Text, room)
+		remaining -= chunkTokens(n, c)
+		out.Chunks = append(out.Chunks, c)
+		return false
+	}
+
+	rest := in.Chunks
+	if len(rest) > 0 {
+		if addChunk(rest[0]) {
+			rest = rest[1:]
+		} else {
+			rest = nil
+		}
+	}
+
+	historyLeft := b.MaxHistory
+	if historyLeft <= 0 {
+		historyLeft = remaining
+	}
+	var history []domain.Message
+	for i := len(in.History) - 1; i >= 0; i-- {
+		cost := EstimateTokens(in.History[i].Content) + messageOverhead
+		if cost > historyLeft || !take(cost) {
+			break
+		}
+		historyLeft -= cost
+		history = append(history, in.History[i])
+	}
+	for i := len(history) - 1; i >= 0; i-- {
+		out.History = append(out.History, history[i])
+	}
+
+	if len(in.Glossary) > 0 && take(EstimateTokens("Glossary of internal terms:\n\n")) {
+		for _, e := range in.Glossary {
+			if !take(EstimateTokens(FormatGlossary([]domain.GlossaryEntry{e}))) {
+				break
+			}
+			out.Glossary = append(out.Glossary, e)
+		}
+	}
+
+	for _, c := range rest {
+		if !addChunk(c) {
+			break
+		}
+	}
+
+	if len(in.Relations) > 0 && take(EstimateTokens("Known relationships between entities:\n\n")) {
+		for _, r := range in.Relations {
+			if !take(EstimateTokens(FormatRelations([]domain.Relation{r}))) {
+				break
+			}
+			out.Relations = append(out.Relations, r)
+		}
+	}
+	return out
+}
+
+func chunkTokens(n int, c domain.ScoredChunk) int {
+	return EstimateTokens(fmt.Sprintf("[%d] %s (%s)\n%s\n\n", n, c.Title, c.URL, c.Text))
+}
+
+// truncateText shortens s to about tokens, preferring to cut at a paragraph,
+// sentence or word boundary in the second half of the allowance.
+func truncateText(s string, tokens int) string {
+	limit := tokens*4 - len(" …")
+	if limit >= len(s) {
+		return s
+	}
+	if limit <= 0 {
+		return ""
+	}
+	for limit > 0 && !utf8.RuneStart(s[limit]) {
+		limit--
+	}
+	cut := s[:limit]
+	for _, sep := range []string{"\n\n", "\n", ". ", " "} {
+		if i := strings.LastIndex(cut, sep); i >= limit/2 {
+			cut = cut[:i+len(strings.TrimRight(sep, " "))]
+			break
+		}
+	}
+	return strings.TrimSpace(cut) + " …"
+}
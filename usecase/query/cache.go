@@ -0,0 +1,85 @@
+package query
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// WithAnswerCache answers a question asked again within ttl from cache,
+// without retrieval or generation, when the caller may read the same pages
+// and the service runs with the same settings. Questions with conversation
+// history aren't cached since their answers depend on it, and cached answers
+// report no usage and record no retrievals or gaps.
+func WithAnswerCache(cache domain.Cache, ttl time.Duration) Option {
+	return func(s *Service) {
+		s.answers = cache
+		s.answerTTL = ttl
+	}
+}
+
+// cachedAnswer keeps the chunks an answer was grounded on, which Answer's
+// JSON leaves out.
+type cachedAnswer struct {
+	Answer domain.Answer        `json:"answer"`
+	Chunks []domain.ScoredChunk `json:"chunks"`
+}
+
+// answerKey identifies what an answer to q depends on, or is empty when q
+// shouldn't be cached.
+func (s *Service) answerKey(ctx context.Context, q domain.Question) string {
+	if s.answers == nil || len(q.History) > 0 {
+		return ""
+	}
+	spaces, readers := slices.Sorted(slices.Values(q.SpaceKeys)), slices.Sorted(slices.Values(q.Readers))
+	b, err := json.Marshal(struct {
+		Config   Config
+		Prompt   string
+		Question string
+		TopK     int
+		Spaces   []string
+		Language string
+		Readers  []string
+		Tenant   string
+	}{s.Config(), s.promptVersion(ctx), strings.TrimSpace(q.Text), q.TopK, spaces, q.Language, readers, q.Tenant})
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(b)
+	return "answer:" + hex.EncodeToString(sum[:])
+}
+
+func (s *Service) cachedAnswer(ctx context.Context, key string) *domain.Answer {
+	b, ok, err := s.answers.Get(ctx, key)
+	if err != nil {
+		log.Printf("Reading answer cache failed: %v\n", err)
+	}
+	if !ok {
+		return nil
+	}
+	var cached cachedAnswer
+	if err := json.Unmarshal(b, &cached); err != nil {
+		return nil
+	}
+	answer := cached.Answer
+	answer.Chunks = cached.Chunks
+	answer.Usage = domain.Usage{}
+	return &answer
+}
+
+func (s *Service) cacheAnswer(ctx context.Context, key string, answer *domain.Answer) {
+	b, err := json.Marshal(cachedAnswer{Answer: *answer, Chunks: answer.Chunks})
+	if err != nil {
+		return
+	}
+	if err := s.answers.Set(ctx, key, b, s.answerTTL); err != nil {
+		log.Printf("Writing answer cache failed: %v\n", err)
+	}
+}
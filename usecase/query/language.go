@@ -0,0 +1,105 @@
+package query
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const (
+	// LanguageAuto answers in whatever language the question was asked in.
+	LanguageAuto = "auto"
+
+	StrategyInstruct  = "instruct"
+	StrategyTranslate = "translate"
+)
+
+var ErrInvalidLanguage = errors.New("language must be a BCP 47 tag such as \"de\" or \"pt-BR\", or \"auto\"")
+
+var languageTag = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+var languageNames = map[string]string{
+	"ar": "Arabic", "cs": "Czech", "da": "Danish", "de": "German", "el": "Greek",
+	"en": "English", "es": "Spanish", "fi": "Finnish", "fr": "French", "he": "Hebrew",
+	"hi": "Hindi", "hu": "Hungarian", "id": "Indonesian", "it": "Italian", "ja": "Japanese",
+	"ko": "Korean", "nl": "Dutch", "no": "Norwegian", "pl": "Polish", "pt": "Portuguese",
+	"ro": "Romanian", "ru": "Russian", "sv": "Swedish", "th": "Thai", "tr": "Turkish",
+	"uk": "Ukrainian", "vi": "Vietnamese", "zh": "Chinese",
+}
+
+func ValidLanguage(lang string) bool {
+	return lang == "" || lang == LanguageAuto || languageTag.MatchString(lang)
+}
+
+// LanguageName renders a tag like "pt-BR" as "Portuguese (pt-BR)" for prompts.
+func LanguageName(tag string) string {
+	base := strings.ToLower(strings.SplitN(tag, "-", 2)[0])
+	if name, ok := languageNames[base]; ok {
+		if base == strings.ToLower(tag) {
+			return name
+		}
+		return name + " (" + tag + ")"
+	}
+	return tag
+}
+
+// LanguageInstruction is appended to a system prompt to have the answer
+// written in lang; it is empty when lang is.
+func LanguageInstruction(lang string) string {
+	switch {
+	case lang == "":
+		return ""
+	case lang == LanguageAuto:
+		return "\nWrite the answer in the same language as the question."
+	default:
+		return "\nWrite the answer in " + LanguageName(lang) + ", even if the context is in another language. Keep product names, code and citations unchanged."
+	}
+}
+
+const translatePrompt = `Translate each passage in the JSON array into %s.
+Keep product names, code, identifiers and URLs unchanged.
+Return a JSON array of the translated strings only, in the same order.`
+
+// translateChunks translates retrieved passages into the answer language in one call.
+// On any failure the original passages are kept; the language instruction still applies.
+func (s *Service) translateChunks(ctx context.Context, lang string, chunks []domain.ScoredChunk) ([]domain.ScoredChunk, domain.Usage, error) {
+	if len(chunks) == 0 || lang == "" || lang == LanguageAuto {
+		return chunks, domain.Usage{}, nil
+	}
+
+	texts := make([]string, len(chunks))
+	for i, c := range chunks {
+		texts[i] = c.Text
+	}
+	payload, _ := json.Marshal(texts)
+
+	completion, err := s.model.Complete(ctx, domain.CompletionRequest{
+		Model: *s.modelName.Load(),
+		Messages: []domain.Message{
+			{Role: domain.RoleSystem, Content: fmt.Sprintf(translatePrompt, LanguageName(lang))},
+			{Role: domain.RoleUser, Content: string(payload)},
+		},
+	})
+	if err != nil {
+		return chunks, domain.Usage{}, err
+	}
+
+	content := completion.Content
+	start, end := strings.Index(content, "["), strings.LastIndex(content, "]")
+	var translated []string
+	if start < 0 || end < start || json.Unmarshal([]byte(content[start:end+1]), &translated) != nil || len(translated) != len(chunks) {
+		return chunks, completion.Usage, fmt.Errorf("translation returned an unexpected shape")
+	}
+
+	out := make([]domain.ScoredChunk, len(chunks))
+	for i, c := range chunks {
+		c.Text = translated[i]
+		out[i] = c
+	}
+	return out, completion.Usage, nil
+}
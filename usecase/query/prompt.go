@@ -0,0 +1,96 @@
+package query
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const DefaultPrompt = "v1"
+
+// NewPrompt is rolled out behind the FlagNewPrompt feature flag to answers
+// that don't ask for a prompt version.
+const (
+	NewPrompt     = "v2"
+	FlagNewPrompt = "new_prompt"
+)
+
+var systemPrompts = map[string]string{
+	"v1": `You are Sarama, an assistant that answers questions about internal documentation.
+Answer only from the numbered context passages. Cite passages inline as [n].
+If the context does not contain the answer, say you don't know.`,
+
+	"v2": `You are Sarama, an assistant that explains internal documentation to engineers.
+Using only the numbered context passages, answer in three parts where applicable:
+what it is, why it exists, and how to use it. Cite passages inline as [n].
+If the context does not contain the answer, say you don't know instead of guessing.`,
+}
+
+func HasPrompt(version string) bool {
+	_, ok := systemPrompts[version]
+	return ok
+}
+
+type PromptInput struct {
+	Version   string
+	Question  string
+	Language  string
+	History   []domain.Message
+	Chunks    []domain.ScoredChunk
+	Glossary  []domain.GlossaryEntry
+	Relations []domain.Relation
+}
+
+func systemPrompt(version string) string {
+	if prompt, ok := systemPrompts[version]; ok {
+		return prompt
+	}
+	return systemPrompts[DefaultPrompt]
+}
+
+func BuildMessages(in PromptInput) []domain.Message {
+	messages := make([]domain.Message, 0, len(in.History)+2)
+	messages = append(messages, domain.Message{Role: domain.RoleSystem, Content: systemPrompt(in.Version) + LanguageInstruction(in.Language)})
+	messages = append(messages, in.History...)
+	return append(messages, domain.Message{
+		Role:    domain.RoleUser,
+		Content: FormatGlossary(in.Glossary) + FormatRelations(in.Relations) + FormatContext(in.Chunks) + "\nQuestion: " + in.Question,
+	})
+}
+
+// FormatGlossary explains internal jargon used in the question; it is not citable context.
+func FormatGlossary(entries []domain.GlossaryEntry) string {
+	if len(entries) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Glossary of internal terms:\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "- %s: %s\n", e.Term, e.Definition)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
+
+func FormatContext(chunks []domain.ScoredChunk) string {
+	var b strings.Builder
+	b.WriteString("Context:\n")
+	for i, c := range chunks {
+		fmt.Fprintf(&b, "[%d] %s (%s)\n%s\n\n", i+1, c.Title, c.URL, c.Text)
+	}
+	return b.String()
+}
+
+func FormatRelations(relations []domain.Relation) string {
+	if len(relations) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Known relationships between entities:\n")
+	for _, r := range relations {
+		fmt.Fprintf(&b, "- %s %s %s\n", r.Subject.Name, r.Predicate, r.Object.Name)
+	}
+	b.WriteString("\n")
+	return b.String()
+}
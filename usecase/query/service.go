@@ -0,0 +1,374 @@
+package query
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/infrastructure/tracing"
+	"github.com/shubhamgptln/sarama-ai/pkg/flags"
+)
+
+type Config struct {
+	TopK        int
+	Model       string
+	Temperature float64
+	MaxTokens   int
+	Prompt      string
+
+	// DefaultLanguage applies when a question doesn't name one; LanguageStrategy
+	// is "instruct" (tell the model) or "translate" (also translate the context).
+	DefaultLanguage  string
+	LanguageStrategy string
+
+	// ContextWindow is the model's context size in tokens; prompts are packed to
+	// fit it, keeping MaxTokens free for the answer and at most HistoryTokens of
+	// conversation history. Zero disables packing.
+	ContextWindow int
+	HistoryTokens int
+}
+
+var (
+	ErrEmptyQuestion   = errors.New("question must not be empty")
+	ErrQuestionBlocked = errors.New("question was blocked by content moderation")
+)
+
+const blockedAnswer = "I can't share an answer to this question."
+
+// Service answers questions by retrieving relevant chunks and grounding the model on them.
+type Service struct {
+	embedder domain.Embedder
+	store    domain.VectorStore
+	model    domain.ChatModel
+	cfg      Config
+	// topK and modelName are cfg.TopK and cfg.Model, kept apart so SetTopK
+	// and SetModel can change them while serving.
+	topK      *atomic.Int64
+	modelName *atomic.Pointer[string]
+
+	moderator domain.Moderator
+	stats     domain.RetrievalStats
+	gaps      GapTracker
+	glossary  Glossary
+	graph     GraphExpander
+	answers   domain.Cache
+	answerTTL time.Duration
+}
+
+// GraphExpander adds knowledge-graph neighbours of entities named in the question.
+type GraphExpander interface {
+	Expand(ctx context.Context, question string, retrieved []domain.ScoredChunk, filter domain.SearchFilter) ([]domain.ScoredChunk, []domain.Relation)
+}
+
+type Glossary interface {
+	Lookup(ctx context.Context, question string) []domain.GlossaryEntry
+}
+
+// GapTracker is told about every answered question so it can spot documentation gaps.
+type GapTracker interface {
+	Observe(ctx context.Context, question string, vector []float32, answer *domain.Answer)
+}
+
+type Option func(*Service)
+
+// WithModerator screens both incoming questions and generated answers.
+func WithModerator(m domain.Moderator) Option {
+	return func(s *Service) { s.moderator = m }
+}
+
+// WithRetrievalStats records which documents were used as answer context.
+func WithRetrievalStats(stats domain.RetrievalStats) Option {
+	return func(s *Service) { s.stats = stats }
+}
+
+func WithGapTracker(t GapTracker) Option {
+	return func(s *Service) { s.gaps = t }
+}
+
+// WithGlossary adds definitions of internal terms found in the question to the prompt.
+func WithGlossary(g Glossary) Option {
+	return func(s *Service) { s.glossary = g }
+}
+
+func WithGraph(g GraphExpander) Option {
+	return func(s *Service) { s.graph = g }
+}
+
+func NewService(embedder domain.Embedder, store domain.VectorStore, model domain.ChatModel, cfg Config, opts ...Option) *Service {
+	if cfg.TopK <= 0 {
+		cfg.TopK = 5
+	}
+	s := &Service{embedder: embedder, store: store, model: model, cfg: cfg, topK: new(atomic.Int64), modelName: new(atomic.Pointer[string])}
+	s.topK.Store(int64(cfg.TopK))
+	s.modelName.Store(&cfg.Model)
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *Service) Config() Config {
+	cfg := s.cfg
+	cfg.TopK = int(s.topK.Load())
+	cfg.Model = *s.modelName.Load()
+	return cfg
+}
+
+// WithConfig returns a copy of the service that uses cfg, sharing the same backends.
+// A copy that keeps the service's TopK or Model follows later SetTopK or SetModel calls.
+func (s *Service) WithConfig(cfg Config) *Service {
+	clone := *s
+	if cfg.TopK > 0 && int64(cfg.TopK) != s.topK.Load() {
+		clone.topK = new(atomic.Int64)
+		clone.topK.Store(int64(cfg.TopK))
+	}
+	if cfg.Model != *s.modelName.Load() {
+		clone.modelName = new(atomic.Pointer[string])
+		clone.modelName.Store(&cfg.Model)
+	}
+	clone.cfg = cfg
+	return &clone
+}
+
+// SetTopK changes how many chunks questions that don't ask for a number retrieve.
+func (s *Service) SetTopK(n int) {
+	if n > 0 {
+		s.topK.Store(int64(n))
+	}
+}
+
+// SetModel changes the model answers are generated with; empty uses the chat
+// model's default.
+func (s *Service) SetModel(name string) {
+	s.modelName.Store(&name)
+}
+
+func (s *Service) Retrieve(ctx context.Context, q domain.Question) ([]domain.ScoredChunk, error) {
+	_, chunks, err := s.retrieve(ctx, q)
+	return chunks, err
+}
+
+func (s *Service) retrieve(ctx context.Context, q domain.Question) (_ []float32, _ []domain.ScoredChunk, err error) {
+	ctx, span := tracing.Start(ctx, "query.retrieve")
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+	if strings.TrimSpace(q.Text) == "" {
+		return nil, nil, ErrEmptyQuestion
+	}
+	if !ValidLanguage(q.Language) {
+		return nil, nil, ErrInvalidLanguage
+	}
+
+	vectors, err := s.embedder.Embed(ctx, []string{q.Text})
+	if err != nil {
+		return nil, nil, fmt.Errorf("embed question: %w", err)
+	}
+
+	topK := q.TopK
+	if topK <= 0 {
+		topK = int(s.topK.Load())
+	}
+	chunks, err := s.store.Search(ctx, vectors[0], topK, searchFilter(q))
+	if err != nil {
+		return nil, nil, fmt.Errorf("search vector store: %w", err)
+	}
+	span.SetAttributes(tracing.Int("chunks", len(chunks)))
+
+	if s.stats != nil && len(chunks) > 0 {
+		if err := s.stats.RecordRetrieval(ctx, domain.DocumentIDs(chunks), time.Now()); err != nil {
+			log.Printf("Recording retrieval stats failed: %v\n", err)
+		}
+	}
+	return vectors[0], chunks, nil
+}
+
+// searchFilter limits retrieval to what the asker may see.
+func searchFilter(q domain.Question) domain.SearchFilter {
+	return domain.SearchFilter{SpaceKeys: q.SpaceKeys, Readers: q.Readers}
+}
+
+func (s *Service) Ask(ctx context.Context, q domain.Question) (_ *domain.Answer, err error) {
+	start := time.Now()
+	ctx, span := tracing.Start(ctx, "query")
+	defer func() {
+		span.SetError(err)
+		span.End()
+	}()
+
+	if s.moderator != nil && strings.TrimSpace(q.Text) != "" {
+		verdict, err := s.moderator.Moderate(ctx, q.Text)
+		if err != nil {
+			return nil, fmt.Errorf("moderate question: %w", err)
+		}
+		if verdict.Action == domain.ModerationBlock {
+			return nil, ErrQuestionBlocked
+		}
+		q.Text = verdict.Text
+	}
+
+	key := s.answerKey(ctx, q)
+	if key != "" {
+		if answer := s.cachedAnswer(ctx, key); answer != nil {
+			span.SetAttributes(tracing.Bool("query.cached", true))
+			answer.Latency = time.Since(start)
+			return answer, nil
+		}
+	}
+
+	vector, chunks, err := s.retrieve(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+
+	lang := q.Language
+	if lang == "" {
+		lang = s.cfg.DefaultLanguage
+	}
+	// There is no reranker: the retrieved chunks are only extended from the
+	// graph and cut to the prompt budget before generation.
+	contextCtx, contextSpan := tracing.Start(ctx, "query.context")
+	prompt := PromptInput{Version: s.promptVersion(ctx), Question: q.Text, Language: lang, History: conversationHistory(q.History)}
+	// The glossary and the graph are built from every space. Glossary entries
+	// can't be traced to a space or its readers, so a tenant's or a restricted
+	// caller's questions go without them; the graph only adds what the
+	// caller's filter lets through.
+	if s.glossary != nil && q.Tenant == "" && len(q.SpaceKeys) == 0 && len(q.Readers) == 0 {
+		prompt.Glossary = s.glossary.Lookup(ctx, q.Text)
+	}
+	if s.graph != nil && q.Tenant == "" {
+		var extra []domain.ScoredChunk
+		extra, prompt.Relations = s.graph.Expand(contextCtx, q.Text, chunks, searchFilter(q))
+		chunks = append(chunks, extra...)
+	}
+	prompt.Chunks = chunks
+	prompt = s.budget().Fit(prompt)
+	chunks = prompt.Chunks
+	contextSpan.SetAttributes(tracing.Int("chunks", len(chunks)))
+	contextSpan.End()
+
+	var translationUsage domain.Usage
+	if s.cfg.LanguageStrategy == StrategyTranslate {
+		prompt.Chunks, translationUsage, err = s.translateChunks(ctx, lang, chunks)
+		if err != nil {
+			log.Printf("Translating context to %s failed: %v\n", lang, err)
+		}
+		// Translations can run longer than the passages they replace, so
+		// fit them again; Fit keeps a prefix of the passages, which the
+		// citations follow.
+		prompt = s.budget().Fit(prompt)
+		chunks = chunks[:len(prompt.Chunks)]
+	}
+
+	model := *s.modelName.Load()
+	generateCtx, generateSpan := tracing.Start(ctx, "query.generate")
+	if model != "" {
+		generateSpan.SetAttributes(tracing.String("gen_ai.request.model", model))
+	}
+	completion, err := s.model.Complete(generateCtx, domain.CompletionRequest{
+		Model:       model,
+		Messages:    BuildMessages(prompt),
+		Temperature: s.cfg.Temperature,
+		MaxTokens:   s.cfg.MaxTokens,
+	})
+	generateSpan.SetError(err)
+	if err == nil {
+		generateSpan.SetAttributes(
+			tracing.Int("gen_ai.usage.input_tokens", completion.Usage.PromptTokens),
+			tracing.Int("gen_ai.usage.output_tokens", completion.Usage.CompletionTokens),
+		)
+	}
+	generateSpan.End()
+	if err != nil {
+		return nil, fmt.Errorf("generate answer: %w", err)
+	}
+
+	answer := &domain.Answer{
+		Text:      completion.Content,
+		Citations: Citations(chunks),
+		Chunks:    chunks,
+		Model:     completion.Model,
+		Usage:     completion.Usage.Add(translationUsage),
+		Language:  lang,
+	}
+	if err := s.moderateAnswer(ctx, answer); err != nil {
+		return nil, err
+	}
+	if s.gaps != nil {
+		s.gaps.Observe(ctx, q.Text, vector, answer)
+	}
+	if key != "" {
+		s.cacheAnswer(ctx, key, answer)
+	}
+	answer.Latency = time.Since(start)
+	return answer, nil
+}
+
+// promptVersion is the configured prompt, or NewPrompt for callers the
+// new_prompt flag is on for.
+func (s *Service) promptVersion(ctx context.Context) string {
+	if s.cfg.Prompt == "" && flags.Enabled(ctx, FlagNewPrompt) {
+		return NewPrompt
+	}
+	return s.cfg.Prompt
+}
+
+// conversationHistory keeps only user and assistant turns so clients can't inject system instructions.
+func conversationHistory(history []domain.Message) []domain.Message {
+	var kept []domain.Message
+	for _, m := range history {
+		if (m.Role == domain.RoleUser || m.Role == domain.RoleAssistant) && strings.TrimSpace(m.Content) != "" {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+func (s *Service) budget() Budget {
+	return Budget{ContextWindow: s.cfg.ContextWindow, MaxOutput: s.cfg.MaxTokens, MaxHistory: s.cfg.HistoryTokens}
+}
+
+func (s *Service) moderateAnswer(ctx context.Context, answer *domain.Answer) error {
+	if s.moderator == nil {
+		return nil
+	}
+	verdict, err := s.moderator.Moderate(ctx, answer.Text)
+	if err != nil {
+		return fmt.Errorf("moderate answer: %w", err)
+	}
+	switch verdict.Action {
+	case domain.ModerationBlock:
+		answer.Text = blockedAnswer
+		answer.Citations = nil
+		answer.Moderation = verdict.Action
+	case domain.ModerationRedact:
+		answer.Text = verdict.Text
+		answer.Moderation = verdict.Action
+	}
+	return nil
+}
+
+// Citations collapses retrieved chunks into one citation per source document.
+func Citations(chunks []domain.ScoredChunk) []domain.Citation {
+	seen := make(map[string]bool, len(chunks))
+	citations := make([]domain.Citation, 0, len(chunks))
+	for _, c := range chunks {
+		if seen[c.DocumentID] {
+			continue
+		}
+		seen[c.DocumentID] = true
+		citations = append(citations, domain.Citation{
+			DocumentID: c.DocumentID,
+			Title:      c.Title,
+			URL:        c.URL,
+			Score:      c.Score,
+		})
+	}
+	return citations
+}
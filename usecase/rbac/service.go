@@ -0,0 +1,109 @@
+package rbac
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+var (
+	ErrInvalidBinding = errors.New("invalid role binding")
+	ErrSpaceForbidden = errors.New("no access to the requested spaces")
+)
+
+// Service manages persisted role bindings and applies them to authenticated principals.
+type Service struct {
+	repo domain.RoleBindingRepository
+}
+
+func NewService(repo domain.RoleBindingRepository) *Service {
+	return &Service{repo: repo}
+}
+
+func (s *Service) Bind(ctx context.Context, subject string, role domain.AccessRole, spaces []string) (*domain.RoleBinding, error) {
+	if subject == "" {
+		return nil, fmt.Errorf("%w: subject is required", ErrInvalidBinding)
+	}
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: unknown role %q", ErrInvalidBinding, role)
+	}
+	if len(spaces) > 0 && role != domain.AccessReader {
+		return nil, fmt.Errorf("%w: only %s bindings can be limited to spaces", ErrInvalidBinding, domain.AccessReader)
+	}
+	b := domain.RoleBinding{ID: id.New(), Subject: subject, Role: role, Spaces: spaces, CreatedAt: time.Now().UTC()}
+	if err := s.repo.SaveRoleBinding(ctx, b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (s *Service) Bindings(ctx context.Context, subject string) ([]domain.RoleBinding, error) {
+	if subject != "" {
+		return s.repo.RoleBindingsFor(ctx, subject)
+	}
+	return s.repo.ListRoleBindings(ctx)
+}
+
+func (s *Service) Unbind(ctx context.Context, id string) error {
+	return s.repo.DeleteRoleBinding(ctx, id)
+}
+
+// Resolve adds the principal's bound roles and their scopes. Space-limited
+// reader bindings only restrict queries when nothing else grants query access.
+func (s *Service) Resolve(ctx context.Context, p *domain.Principal) error {
+	bindings, err := s.repo.RoleBindingsFor(ctx, p.ID)
+	if err != nil {
+		return err
+	}
+	unrestricted := p.HasScope(domain.ScopeQuery)
+	var spaces []string
+	for _, b := range bindings {
+		if len(b.Spaces) > 0 {
+			for _, space := range b.Spaces {
+				if !slices.Contains(spaces, space) {
+					spaces = append(spaces, space)
+				}
+			}
+		} else if b.Role.Valid() {
+			unrestricted = unrestricted || slices.Contains(b.Role.Scopes(), domain.ScopeQuery) || b.Role == domain.AccessAdmin
+		}
+		if !slices.Contains(p.Roles, b.Role) {
+			p.Roles = append(p.Roles, b.Role)
+		}
+		for _, scope := range b.Role.Scopes() {
+			if !slices.Contains(p.Scopes, scope) {
+				p.Scopes = append(p.Scopes, scope)
+			}
+		}
+	}
+	if !unrestricted {
+		p.Spaces = spaces
+	}
+	return nil
+}
+
+// AllowedSpaces narrows the requested spaces to those the principal may read.
+// A nil result with no error means no restriction applies.
+func AllowedSpaces(p *domain.Principal, requested []string) ([]string, error) {
+	if p == nil || len(p.Spaces) == 0 {
+		return requested, nil
+	}
+	if len(requested) == 0 {
+		return p.Spaces, nil
+	}
+	var allowed []string
+	for _, space := range requested {
+		if slices.Contains(p.Spaces, space) {
+			allowed = append(allowed, space)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, ErrSpaceForbidden
+	}
+	return allowed, nil
+}
@@ -0,0 +1,348 @@
+package reindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+var (
+	ErrRunning     = errors.New("reindex already running")
+	ErrJobNotFound = errors.New("reindex job not found")
+	// ErrJobState is returned for a job that can't be paused, resumed or
+	// cancelled in its current state.
+	ErrJobState = errors.New("wrong reindex job state")
+)
+
+// maxJobs is how many finished jobs are remembered.
+const maxJobs = 50
+
+// lockName and lockTTL are the lock a job holds, so only one instance
+// reindexes at a time; a crashed holder's lock lapses after lockTTL.
+const (
+	lockName = "reindex"
+	lockTTL  = time.Minute
+)
+
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobPaused    JobStatus = "paused"
+	JobCompleted JobStatus = "completed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+// Job is the progress of one reindex run.
+type Job struct {
+	ID         string     `json:"id"`
+	SpaceKey   string     `json:"space_key,omitempty"`
+	Status     JobStatus  `json:"status"`
+	Documents  int        `json:"documents"`
+	Processed  int        `json:"processed"`
+	Failed     int        `json:"failed"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// ETA is when a running job should finish at its pace so far.
+	ETA *time.Time `json:"eta,omitempty"`
+
+	pausedAt time.Time
+	// pausedFor is how long the job has been paused, left out of its pace.
+	pausedFor time.Duration
+}
+
+// withETA returns a copy of j with its ETA.
+func (j *Job) withETA() Job {
+	out := *j
+	if j.Status == JobRunning && j.Processed > 0 {
+		active := time.Since(j.StartedAt) - j.pausedFor
+		eta := time.Now().Add(active / time.Duration(j.Processed) * time.Duration(j.Documents-j.Processed)).UTC()
+		out.ETA = &eta
+	}
+	return out
+}
+
+// Request selects the documents to reindex: the listed documents, else every
+// indexed document in the space, else every indexed document.
+type Request struct {
+	DocumentIDs []string `json:"document_ids,omitempty"`
+	SpaceKey    string   `json:"space_key,omitempty"`
+}
+
+// Service re-fetches and re-embeds documents by handing upsert events to
+// ingestion one at a time, the same way a page edit would.
+type Service struct {
+	store    domain.VectorStore
+	handle   domain.IngestHandler
+	notifier domain.Notifier
+	locker   domain.Locker
+
+	mu      sync.Mutex
+	running bool
+	// unlock releases the lock the running job holds.
+	unlock func()
+	// cancel stops the running job. resume is set while it is paused and
+	// closed to let it go on.
+	cancel context.CancelFunc
+	resume chan struct{}
+	// jobs are the recent jobs, newest first.
+	jobs []*Job
+}
+
+type Option func(*Service)
+
+// WithNotifier reports each finished job as sync.completed.
+func WithNotifier(n domain.Notifier) Option {
+	return func(s *Service) { s.notifier = n }
+}
+
+// WithLocker takes a lock of l for each job, so instances sharing l don't
+// reindex at the same time.
+func WithLocker(l domain.Locker) Option {
+	return func(s *Service) { s.locker = l }
+}
+
+func NewService(store domain.VectorStore, handle domain.IngestHandler, opts ...Option) *Service {
+	s := &Service{store: store, handle: handle}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Reindex selects the documents and starts handing them to ingestion in the
+// background. It returns the job ID and the number of documents selected.
+func (s *Service) Reindex(ctx context.Context, req Request) (string, int, error) {
+	if !s.begin() {
+		return "", 0, ErrRunning
+	}
+	if s.locker != nil {
+		unlock, err := s.locker.TryLock(ctx, lockName, lockTTL)
+		if errors.Is(err, domain.ErrLocked) {
+			s.finish()
+			return "", 0, fmt.Errorf("%w on another instance", ErrRunning)
+		}
+		if err != nil {
+			s.finish()
+			return "", 0, fmt.Errorf("lock reindex: %w", err)
+		}
+		s.mu.Lock()
+		s.unlock = unlock
+		s.mu.Unlock()
+	}
+	ids := req.DocumentIDs
+	if len(ids) == 0 {
+		var err error
+		if ids, err = s.indexed(ctx, req.SpaceKey); err != nil {
+			s.finish()
+			return "", 0, err
+		}
+	}
+	// Documents added through the API have no source to re-fetch them from.
+	ids = slices.DeleteFunc(slices.Clone(ids), domain.IsManualDocument)
+	job := &Job{ID: id.New(), SpaceKey: req.SpaceKey, Status: JobRunning, Documents: len(ids), StartedAt: time.Now().UTC()}
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.jobs = append([]*Job{job}, s.jobs...)
+	if len(s.jobs) > maxJobs {
+		s.jobs = s.jobs[:maxJobs]
+	}
+	s.cancel = cancel
+	s.mu.Unlock()
+	go s.run(ctx, job, ids)
+	return job.ID, len(ids), nil
+}
+
+// Jobs returns the recent jobs, newest first.
+func (s *Service) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, len(s.jobs))
+	for i, j := range s.jobs {
+		jobs[i] = j.withETA()
+	}
+	return jobs
+}
+
+func (s *Service) Job(jobID string) (Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if j := s.find(jobID); j != nil {
+		return j.withETA(), true
+	}
+	return Job{}, false
+}
+
+// find returns the job with jobID; s.mu must be held.
+func (s *Service) find(jobID string) *Job {
+	for _, j := range s.jobs {
+		if j.ID == jobID {
+			return j
+		}
+	}
+	return nil
+}
+
+// Pause holds the running job before its next document until Resume.
+func (s *Service) Pause(jobID string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.find(jobID)
+	if j == nil {
+		return Job{}, ErrJobNotFound
+	}
+	if j.Status != JobRunning {
+		return Job{}, fmt.Errorf("%w: can't pause a %s job", ErrJobState, j.Status)
+	}
+	j.Status, j.pausedAt = JobPaused, time.Now()
+	s.resume = make(chan struct{})
+	return j.withETA(), nil
+}
+
+func (s *Service) Resume(jobID string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.find(jobID)
+	if j == nil {
+		return Job{}, ErrJobNotFound
+	}
+	if j.Status != JobPaused {
+		return Job{}, fmt.Errorf("%w: can't resume a %s job", ErrJobState, j.Status)
+	}
+	j.Status = JobRunning
+	j.pausedFor += time.Since(j.pausedAt)
+	close(s.resume)
+	s.resume = nil
+	return j.withETA(), nil
+}
+
+// Cancel stops a running or paused job. The document being reindexed is
+// abandoned, and the job turns cancelled once its goroutine has stopped.
+func (s *Service) Cancel(jobID string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	j := s.find(jobID)
+	if j == nil {
+		return Job{}, ErrJobNotFound
+	}
+	if j.Status != JobRunning && j.Status != JobPaused {
+		return Job{}, fmt.Errorf("%w: can't cancel a %s job", ErrJobState, j.Status)
+	}
+	s.cancel()
+	return j.withETA(), nil
+}
+
+func (s *Service) run(ctx context.Context, job *Job, ids []string) {
+	jobID, spaceKey := job.ID, job.SpaceKey
+	status := JobCompleted
+	start := time.Now()
+	failed := 0
+	defer func() {
+		finished := time.Now().UTC()
+		s.mu.Lock()
+		job.Status, job.Failed, job.FinishedAt = status, failed, &finished
+		s.mu.Unlock()
+		s.finish()
+	}()
+	for i, documentID := range ids {
+		s.waitResumed(ctx)
+		if ctx.Err() != nil {
+			// Cancelled: count the rest as failed.
+			failed += len(ids) - i
+			status = JobCancelled
+			break
+		}
+		err := s.handle(ctx, domain.IngestEvent{
+			ID:         id.New(),
+			Source:     "confluence",
+			Action:     domain.IngestUpsert,
+			DocumentID: documentID,
+			SpaceKey:   spaceKey,
+			RawType:    "reindex",
+			ReceivedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			log.Printf("Reindexing %s failed: %v\n", documentID, err)
+			failed++
+		}
+		s.mu.Lock()
+		job.Processed, job.Failed = i+1, failed
+		s.mu.Unlock()
+	}
+	log.Printf("Reindex %s finished: %d documents, %d failed\n", jobID, len(ids), failed)
+	if s.notifier != nil {
+		// Cancelling the job doesn't cancel reporting it.
+		s.notifier.Notify(context.WithoutCancel(ctx), domain.EventSyncCompleted, domain.SyncEvent{
+			JobID:      jobID,
+			SpaceKey:   spaceKey,
+			Documents:  len(ids),
+			Failed:     failed,
+			DurationMS: time.Since(start).Milliseconds(),
+		})
+	}
+}
+
+// waitResumed blocks while the job is paused, or until ctx is done.
+func (s *Service) waitResumed(ctx context.Context) {
+	s.mu.Lock()
+	resume := s.resume
+	s.mu.Unlock()
+	if resume == nil {
+		return
+	}
+	select {
+	case <-resume:
+	case <-ctx.Done():
+	}
+}
+
+func (s *Service) begin() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return false
+	}
+	s.running = true
+	return true
+}
+
+func (s *Service) finish() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.unlock != nil {
+		s.unlock()
+		s.unlock = nil
+	}
+	if s.cancel != nil {
+		s.cancel()
+		s.cancel = nil
+	}
+	s.resume = nil
+	s.running = false
+}
+
+// indexed lists the documents with chunks in the index, optionally in one space.
+func (s *Service) indexed(ctx context.Context, spaceKey string) ([]string, error) {
+	seen := map[string]bool{}
+	var ids []string
+	err := s.store.ScanChunks(ctx, func(c domain.Chunk) error {
+		if seen[c.DocumentID] || (spaceKey != "" && c.SpaceKey != spaceKey) {
+			return nil
+		}
+		seen[c.DocumentID] = true
+		ids = append(ids, c.DocumentID)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("scan index: %w", err)
+	}
+	slices.Sort(ids)
+	return ids, nil
+}
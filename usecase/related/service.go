@@ -0,0 +1,104 @@
+package related
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+type Page struct {
+	DocumentID string  `json:"document_id"`
+	SpaceKey   string  `json:"space_key"`
+	Title      string  `json:"title"`
+	URL        string  `json:"url"`
+	Score      float64 `json:"score"`
+}
+
+// Service finds pages whose content is semantically close to a given page.
+type Service struct {
+	store domain.VectorStore
+}
+
+func NewService(store domain.VectorStore) *Service {
+	return &Service{store: store}
+}
+
+// Related returns the pages most like pageID among those filter lets through;
+// pageID itself must be one of them.
+func (s *Service) Related(ctx context.Context, pageID string, limit int, filter domain.SearchFilter) ([]Page, error) {
+	chunks, err := s.store.DocumentChunks(ctx, pageID)
+	if err != nil {
+		return nil, fmt.Errorf("load page chunks: %w", err)
+	}
+	if len(chunks) == 0 || !visible(chunks[0], filter) {
+		return nil, domain.ErrNotFound
+	}
+
+	// Several chunks of the same page usually match, so over-fetch before collapsing per page.
+	filter.ExcludeDocumentIDs = []string{pageID}
+	hits, err := s.store.Search(ctx, centroid(chunks), limit*5, filter)
+	if err != nil {
+		return nil, fmt.Errorf("search similar chunks: %w", err)
+	}
+
+	best := make(map[string]Page)
+	for _, h := range hits {
+		if p, ok := best[h.DocumentID]; ok && p.Score >= h.Score {
+			continue
+		}
+		best[h.DocumentID] = Page{
+			DocumentID: h.DocumentID,
+			SpaceKey:   h.SpaceKey,
+			Title:      h.Title,
+			URL:        h.URL,
+			Score:      h.Score,
+		}
+	}
+
+	pages := make([]Page, 0, len(best))
+	for _, p := range best {
+		pages = append(pages, p)
+	}
+	sort.Slice(pages, func(i, j int) bool { return pages[i].Score > pages[j].Score })
+	if len(pages) > limit {
+		pages = pages[:limit]
+	}
+	return pages, nil
+}
+
+// visible reports whether filter lets c through, the way a search would.
+func visible(c domain.Chunk, filter domain.SearchFilter) bool {
+	if len(filter.SpaceKeys) > 0 && !slices.Contains(filter.SpaceKeys, c.SpaceKey) {
+		return false
+	}
+	return len(filter.Readers) == 0 || len(c.Readers) == 0 ||
+		slices.ContainsFunc(filter.Readers, func(r string) bool { return slices.Contains(c.Readers, r) })
+}
+
+func centroid(chunks []domain.Chunk) []float32 {
+	var dim int
+	for _, c := range chunks {
+		if len(c.Embedding) > dim {
+			dim = len(c.Embedding)
+		}
+	}
+
+	sum := make([]float32, dim)
+	n := 0
+	for _, c := range chunks {
+		if len(c.Embedding) != dim {
+			continue
+		}
+		for i, v := range c.Embedding {
+			sum[i] += v
+		}
+		n++
+	}
+	for i := range sum {
+		sum[i] /= float32(n)
+	}
+	return sum
+}
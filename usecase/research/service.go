@@ -0,0 +1,232 @@
+package research
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+)
+
+type Config struct {
+	MaxSubQuestions int
+	Parallelism     int
+	Model           string
+	MaxTokens       int
+}
+
+const decomposePrompt = `Break the user's question into at most %d focused sub-questions that can each be
+answered from internal documentation on their own. Cover every aspect of the original question.
+Return a JSON array of strings only.`
+
+const synthesizePrompt = `You are Sarama, writing a thorough answer for engineers from researched findings.
+Write one section per finding, each starting with a "## " heading, then a short overall summary.
+Use only the findings. Keep their citation markers such as [3] exactly as given and do not invent new ones.
+If a finding says the documentation does not cover something, say so in that section.`
+
+type Asker interface {
+	Ask(ctx context.Context, q domain.Question) (*domain.Answer, error)
+}
+
+// Service implements deep-research mode: decompose, answer each sub-question, synthesize.
+type Service struct {
+	asker Asker
+	model domain.ChatModel
+	cfg   Config
+}
+
+func NewService(asker Asker, model domain.ChatModel, cfg Config) *Service {
+	if cfg.MaxSubQuestions <= 0 {
+		cfg.MaxSubQuestions = 4
+	}
+	if cfg.Parallelism <= 0 {
+		cfg.Parallelism = 2
+	}
+	return &Service{asker: asker, model: model, cfg: cfg}
+}
+
+// WithAsker returns a copy that answers sub-questions with a, e.g. an experiment variant.
+func (s *Service) WithAsker(a Asker) *Service {
+	clone := *s
+	clone.asker = a
+	return &clone
+}
+
+func (s *Service) Research(ctx context.Context, q domain.Question) (*domain.Answer, error) {
+	start := time.Now()
+
+	subQuestions, usage, err := s.decompose(ctx, q.Text)
+	if err != nil {
+		return nil, err
+	}
+
+	answers, err := s.answerAll(ctx, q, subQuestions)
+	if err != nil {
+		return nil, err
+	}
+
+	sources := newSourceIndex()
+	sections := make([]domain.AnswerSection, len(answers))
+	for i, a := range answers {
+		usage = usage.Add(a.Usage)
+		text, cited := sources.renumber(a)
+		sections[i] = domain.AnswerSection{Question: subQuestions[i], Answer: text, Citations: cited}
+	}
+
+	completion, err := s.model.Complete(ctx, domain.CompletionRequest{
+		Model:     s.cfg.Model,
+		MaxTokens: s.cfg.MaxTokens,
+		Messages: []domain.Message{
+			{Role: domain.RoleSystem, Content: synthesizePrompt + synthesisLanguage(q.Language)},
+			{Role: domain.RoleUser, Content: formatFindings(q.Text, sections)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("synthesize answer: %w", err)
+	}
+
+	var chunks []domain.ScoredChunk
+	for _, a := range answers {
+		chunks = append(chunks, a.Chunks...)
+	}
+	return &domain.Answer{
+		Text:      completion.Content,
+		Citations: sources.citations,
+		Sections:  sections,
+		Chunks:    chunks,
+		Model:     completion.Model,
+		Usage:     usage.Add(completion.Usage),
+		Latency:   time.Since(start),
+	}, nil
+}
+
+// synthesisLanguage asks for the answer in the question's language. Without
+// one, the findings come back in the asker's default language, if it has
+// one, so the synthesis follows them.
+func synthesisLanguage(lang string) string {
+	if lang == "" {
+		return "\nWrite the answer in the same language as the findings."
+	}
+	return query.LanguageInstruction(lang)
+}
+
+func (s *Service) decompose(ctx context.Context, question string) ([]string, domain.Usage, error) {
+	completion, err := s.model.Complete(ctx, domain.CompletionRequest{
+		Model: s.cfg.Model,
+		Messages: []domain.Message{
+			{Role: domain.RoleSystem, Content: fmt.Sprintf(decomposePrompt, s.cfg.MaxSubQuestions)},
+			{Role: domain.RoleUser, Content: question},
+		},
+	})
+	if err != nil {
+		return nil, domain.Usage{}, fmt.Errorf("decompose question: %w", err)
+	}
+
+	var subQuestions []string
+	content := completion.Content
+	start, end := strings.Index(content, "["), strings.LastIndex(content, "]")
+	if start >= 0 && end > start {
+		_ = json.Unmarshal([]byte(content[start:end+1]), &subQuestions)
+	}
+
+	cleaned := subQuestions[:0]
+	for _, sq := range subQuestions {
+		if sq = strings.TrimSpace(sq); sq != "" {
+			cleaned = append(cleaned, sq)
+		}
+	}
+	// Fall back to the original question rather than failing the whole request.
+	if len(cleaned) == 0 {
+		cleaned = []string{question}
+	}
+	if len(cleaned) > s.cfg.MaxSubQuestions {
+		cleaned = cleaned[:s.cfg.MaxSubQuestions]
+	}
+	return cleaned, completion.Usage, nil
+}
+
+func (s *Service) answerAll(ctx context.Context, q domain.Question, subQuestions []string) ([]*domain.Answer, error) {
+	answers := make([]*domain.Answer, len(subQuestions))
+	errs := make([]error, len(subQuestions))
+
+	sem := make(chan struct{}, s.cfg.Parallelism)
+	var wg sync.WaitGroup
+	for i, sq := range subQuestions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			sub := q
+			sub.Text = sq
+			answers[i], errs[i] = s.asker.Ask(ctx, sub)
+		}()
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("answer sub-question %q: %w", subQuestions[i], err)
+		}
+	}
+	return answers, nil
+}
+
+var citationMarker = regexp.MustCompile(`\[(\d+)\]`)
+
+// sourceIndex assigns one global citation number per document across all sub-answers.
+type sourceIndex struct {
+	numbers   map[string]int
+	citations []domain.Citation
+}
+
+func newSourceIndex() *sourceIndex {
+	return &sourceIndex{numbers: make(map[string]int)}
+}
+
+func (s *sourceIndex) number(c domain.ScoredChunk) int {
+	if n, ok := s.numbers[c.DocumentID]; ok {
+		return n
+	}
+	s.citations = append(s.citations, domain.Citation{DocumentID: c.DocumentID, Title: c.Title, URL: c.URL, Score: c.Score})
+	n := len(s.citations)
+	s.numbers[c.DocumentID] = n
+	return n
+}
+
+// renumber rewrites the passage markers of a sub-answer, which refer to its own
+// context passages, into global per-document citation numbers.
+func (s *sourceIndex) renumber(a *domain.Answer) (string, []int) {
+	var cited []int
+	seen := make(map[int]bool)
+	text := citationMarker.ReplaceAllStringFunc(a.Text, func(m string) string {
+		local, _ := strconv.Atoi(m[1 : len(m)-1])
+		if local < 1 || local > len(a.Chunks) {
+			// A marker without a passage would collide with the global numbering.
+			return ""
+		}
+		n := s.number(a.Chunks[local-1])
+		if !seen[n] {
+			seen[n] = true
+			cited = append(cited, n)
+		}
+		return "[" + strconv.Itoa(n) + "]"
+	})
+	return text, cited
+}
+
+func formatFindings(question string, sections []domain.AnswerSection) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Original question: %s\n\n", question)
+	for i, s := range sections {
+		fmt.Fprintf(&b, "Finding %d - %s\n%s\n\n", i+1, s.Question, s.Answer)
+	}
+	return b.String()
+}
@@ -0,0 +1,123 @@
+// Package retention deletes conversations and feedback once they are older
+// than their retention, which tenants may override.
+package retention
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+// Policy is how long records are kept: Default for every tenant without an
+// entry in Tenants, keyed by principal ID. Zero keeps records forever.
+type Policy struct {
+	Default time.Duration
+	Tenants map[string]time.Duration
+}
+
+type Config struct {
+	Conversations Policy
+	Feedback      Policy
+	// Interval is how often expired records are purged; zero never purges.
+	Interval time.Duration
+}
+
+// Result counts the records one purge deleted.
+type Result struct {
+	Conversations int `json:"conversations"`
+	Feedback      int `json:"feedback"`
+}
+
+type Service struct {
+	conversations domain.ConversationRepository
+	feedback      domain.FeedbackRepository
+	cfg           Config
+}
+
+func NewService(conversations domain.ConversationRepository, feedback domain.FeedbackRepository, cfg Config) *Service {
+	return &Service{conversations: conversations, feedback: feedback, cfg: cfg}
+}
+
+// Start purges expired records every Interval until ctx ends.
+func (s *Service) Start(ctx context.Context) {
+	if s.cfg.Interval <= 0 {
+		return
+	}
+	go func() {
+		ticker := time.NewTicker(s.cfg.Interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				res, err := s.Purge(ctx)
+				if err != nil {
+					log.Printf("Purging expired records failed: %v\n", err)
+				}
+				if res.Conversations > 0 || res.Feedback > 0 {
+					log.Printf("Purged %d conversation messages and %d feedback entries\n", res.Conversations, res.Feedback)
+				}
+			}
+		}
+	}()
+}
+
+// Purge deletes the records older than their tenant's retention. Several
+// instances may purge at once; deletes are idempotent.
+func (s *Service) Purge(ctx context.Context) (Result, error) {
+	var res Result
+	var errs []error
+	if s.conversations != nil {
+		n, err := purge(ctx, s.cfg.Conversations, s.conversations.PurgeMessages)
+		res.Conversations = n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("purge conversations: %w", err))
+		}
+	}
+	if s.feedback != nil {
+		n, err := purge(ctx, s.cfg.Feedback, s.feedback.Purge)
+		res.Feedback = n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("purge feedback: %w", err))
+		}
+	}
+	return res, errors.Join(errs...)
+}
+
+// purge applies each tenant's override, then the default to every other
+// tenant.
+func purge(ctx context.Context, p Policy, fn func(context.Context, time.Time, domain.RetentionScope) (int, error)) (int, error) {
+	now := time.Now()
+	deleted := 0
+	var errs []error
+	tenants := make([]string, 0, len(p.Tenants))
+	for tenant := range p.Tenants {
+		tenants = append(tenants, tenant)
+	}
+	slices.Sort(tenants)
+	for _, tenant := range tenants {
+		retention := p.Tenants[tenant]
+		if tenant == "" || retention <= 0 {
+			continue
+		}
+		n, err := fn(ctx, now.Add(-retention), domain.RetentionScope{Tenant: tenant})
+		deleted += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("tenant %s: %w", tenant, err))
+		}
+	}
+	if p.Default > 0 {
+		n, err := fn(ctx, now.Add(-p.Default), domain.RetentionScope{Except: tenants})
+		deleted += n
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return deleted, errors.Join(errs...)
+}
@@ -0,0 +1,303 @@
+// Package snapshot exports the whole index, embeddings included, to blob
+// storage and restores it, for backups and for cloning an environment into a
+// fresh instance.
+package snapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+	"github.com/shubhamgptln/sarama-ai/pkg/id"
+)
+
+var (
+	ErrRunning = errors.New("a snapshot or restore is already running")
+	// ErrIncompatible is returned for a snapshot in another format or whose
+	// embeddings come from another model than this instance's.
+	ErrIncompatible = errors.New("snapshot is incompatible")
+)
+
+// prefix starts every snapshot key.
+const prefix = "snapshots/"
+
+// version is the snapshot format written by Export.
+const version = 1
+
+// maxJobs is how many finished jobs are remembered.
+const maxJobs = 50
+
+type JobKind string
+
+const (
+	JobExport  JobKind = "export"
+	JobRestore JobKind = "restore"
+)
+
+type JobStatus string
+
+const (
+	JobRunning   JobStatus = "running"
+	JobCompleted JobStatus = "completed"
+	JobFailed    JobStatus = "failed"
+)
+
+// Job is the progress of one export or restore.
+type Job struct {
+	ID         string     `json:"id"`
+	Kind       JobKind    `json:"kind"`
+	Key        string     `json:"key"`
+	Status     JobStatus  `json:"status"`
+	Documents  int        `json:"documents"`
+	Chunks     int        `json:"chunks"`
+	Error      string     `json:"error,omitempty"`
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
+
+// Snapshot is a stored snapshot.
+type Snapshot struct {
+	Key       string    `json:"key"`
+	Size      int64     `json:"size"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// header is the first line of a snapshot; each following line is a record.
+type header struct {
+	Version        int       `json:"version"`
+	EmbeddingModel string    `json:"embedding_model"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// record is a chunk with its embedding, which domain.Chunk leaves out of JSON.
+type record struct {
+	domain.Chunk
+	Embedding []float32 `json:"embedding"`
+}
+
+// Service writes snapshots to blobs as gzipped JSON lines, one job at a time.
+type Service struct {
+	store domain.VectorStore
+	blobs domain.BlobStore
+	// model is the embedding model of this instance, recorded in snapshots
+	// and checked on restore.
+	model string
+
+	mu      sync.Mutex
+	running bool
+	// jobs are the recent jobs, newest first.
+	jobs []*Job
+}
+
+func NewService(store domain.VectorStore, blobs domain.BlobStore, model string) *Service {
+	return &Service{store: store, blobs: blobs, model: model}
+}
+
+// Export starts writing every chunk in the index to a new snapshot in the
+// background and returns the job, whose Key names the snapshot.
+func (s *Service) Export(ctx context.Context) (Job, error) {
+	if !s.begin() {
+		return Job{}, ErrRunning
+	}
+	now := time.Now().UTC()
+	job := s.track(JobExport, prefix+now.Format("20060102T150405Z")+"-"+id.New()[:8]+".jsonl.gz")
+	go s.run(job, func() error { return s.export(ctx, job, now) })
+	return *job, nil
+}
+
+// Restore checks that the snapshot at key can be restored and starts putting
+// its documents back in the background: each replaces the indexed document
+// with the same ID, and documents indexed since the snapshot are kept. Unless
+// force is set, a snapshot embedded with another model is refused, as its
+// chunks couldn't be compared to the queries this instance embeds.
+func (s *Service) Restore(ctx context.Context, key string, force bool) (Job, error) {
+	if !strings.HasPrefix(key, prefix) {
+		return Job{}, fmt.Errorf("%w: %s is not a snapshot", domain.ErrNotFound, key)
+	}
+	if !s.begin() {
+		return Job{}, ErrRunning
+	}
+	data, _, err := s.blobs.Get(ctx, key)
+	if err != nil {
+		s.finish()
+		return Job{}, fmt.Errorf("read snapshot %s: %w", key, err)
+	}
+	dec, err := s.open(data, force)
+	if err != nil {
+		s.finish()
+		return Job{}, fmt.Errorf("read snapshot %s: %w", key, err)
+	}
+	job := s.track(JobRestore, key)
+	go s.run(job, func() error { return s.restore(ctx, job, dec) })
+	return *job, nil
+}
+
+// List returns the stored snapshots, newest first.
+func (s *Service) List(ctx context.Context) ([]Snapshot, error) {
+	blobs, err := s.blobs.List(ctx, prefix)
+	if err != nil {
+		return nil, err
+	}
+	snapshots := make([]Snapshot, 0, len(blobs))
+	for _, b := range blobs {
+		snapshots = append(snapshots, Snapshot{Key: b.Key, Size: b.Size, CreatedAt: b.Modified})
+	}
+	// Keys start with the time they were taken.
+	slices.SortFunc(snapshots, func(a, b Snapshot) int { return strings.Compare(b.Key, a.Key) })
+	return snapshots, nil
+}
+
+// Jobs returns the recent jobs, newest first.
+func (s *Service) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	jobs := make([]Job, len(s.jobs))
+	for i, j := range s.jobs {
+		jobs[i] = *j
+	}
+	return jobs
+}
+
+func (s *Service) export(ctx context.Context, job *Job, createdAt time.Time) error {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	enc := json.NewEncoder(zw)
+	if err := enc.Encode(header{Version: version, EmbeddingModel: s.model, CreatedAt: createdAt}); err != nil {
+		return err
+	}
+	documents := map[string]bool{}
+	err := s.store.ScanChunks(ctx, func(c domain.Chunk) error {
+		if err := enc.Encode(record{Chunk: c, Embedding: c.Embedding}); err != nil {
+			return err
+		}
+		documents[c.DocumentID] = true
+		s.progress(job, len(documents), 1)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("scan index: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	if err := s.blobs.Put(ctx, job.Key, buf.Bytes(), "application/gzip"); err != nil {
+		return fmt.Errorf("store snapshot: %w", err)
+	}
+	return nil
+}
+
+// open reads the header of a snapshot and returns a decoder positioned at its
+// first record.
+func (s *Service) open(data []byte, force bool) (*json.Decoder, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(zr)
+	var h header
+	if err := dec.Decode(&h); err != nil {
+		return nil, err
+	}
+	if h.Version != version {
+		return nil, fmt.Errorf("%w: format version %d, want %d", ErrIncompatible, h.Version, version)
+	}
+	if !force && h.EmbeddingModel != s.model {
+		return nil, fmt.Errorf("%w: embedded with %s, this instance uses %s", ErrIncompatible, h.EmbeddingModel, s.model)
+	}
+	return dec, nil
+}
+
+// restore reads every record of dec, then replaces each document in turn.
+// Stores don't scan in document order, so chunks are grouped first.
+func (s *Service) restore(ctx context.Context, job *Job, dec *json.Decoder) error {
+	byDocument := map[string][]domain.Chunk{}
+	var order []string
+	for {
+		var r record
+		err := dec.Decode(&r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("read snapshot: %w", err)
+		}
+		r.Chunk.Embedding = r.Embedding
+		if _, ok := byDocument[r.DocumentID]; !ok {
+			order = append(order, r.DocumentID)
+		}
+		byDocument[r.DocumentID] = append(byDocument[r.DocumentID], r.Chunk)
+	}
+	for i, documentID := range order {
+		chunks := byDocument[documentID]
+		if err := s.store.DeleteDocument(ctx, documentID); err != nil {
+			return fmt.Errorf("replace %s: %w", documentID, err)
+		}
+		if err := s.store.Upsert(ctx, chunks); err != nil {
+			return fmt.Errorf("restore %s: %w", documentID, err)
+		}
+		s.progress(job, i+1, len(chunks))
+	}
+	return nil
+}
+
+func (s *Service) begin() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.running {
+		return false
+	}
+	s.running = true
+	return true
+}
+
+func (s *Service) finish() {
+	s.mu.Lock()
+	s.running = false
+	s.mu.Unlock()
+}
+
+func (s *Service) track(kind JobKind, key string) *Job {
+	job := &Job{ID: id.New(), Kind: kind, Key: key, Status: JobRunning, StartedAt: time.Now().UTC()}
+	s.mu.Lock()
+	s.jobs = append([]*Job{job}, s.jobs...)
+	if len(s.jobs) > maxJobs {
+		s.jobs = s.jobs[:maxJobs]
+	}
+	s.mu.Unlock()
+	return job
+}
+
+func (s *Service) progress(job *Job, documents, chunks int) {
+	s.mu.Lock()
+	job.Documents = documents
+	job.Chunks += chunks
+	s.mu.Unlock()
+}
+
+func (s *Service) run(job *Job, work func() error) {
+	err := work()
+	finished := time.Now().UTC()
+	s.mu.Lock()
+	job.Status, job.FinishedAt = JobCompleted, &finished
+	if err != nil {
+		job.Status, job.Error = JobFailed, err.Error()
+	}
+	documents, chunks := job.Documents, job.Chunks
+	s.mu.Unlock()
+	s.finish()
+	if err != nil {
+		log.Printf("Snapshot %s of %s failed: %v\n", job.Kind, job.Key, err)
+		return
+	}
+	log.Printf("Snapshot %s of %s finished: %d documents, %d chunks\n", job.Kind, job.Key, documents, chunks)
+}
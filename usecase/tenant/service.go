@@ -0,0 +1,193 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+)
+
+var (
+	ErrUnknownTenant  = errors.New("unknown tenant")
+	ErrSpaceForbidden = errors.New("the space isn't one of the tenant's")
+	ErrQuotaExceeded  = errors.New("tenant query quota exceeded")
+)
+
+// Counters count quota use outside the process, e.g. in Redis so every
+// instance draws on the same quota.
+type Counters interface {
+	// Incr adds one to key, kept for ttl after it is created, and returns the new count.
+	Incr(ctx context.Context, key string, ttl time.Duration) (int64, error)
+	Count(ctx context.Context, key string) (int64, error)
+}
+
+// Service resolves tenants and enforces their isolation and quotas.
+type Service struct {
+	tenants  []Tenant
+	byID     map[string]Tenant
+	owners   map[string]string
+	counters Counters
+
+	mu    sync.Mutex
+	day   string
+	local map[string]int64
+	// lastFailure is when counter failures were last logged.
+	lastFailure time.Time
+}
+
+type Option func(*Service)
+
+// WithCounters keeps quota counts in c. While c fails, each instance counts
+// on its own.
+func WithCounters(c Counters) Option {
+	return func(s *Service) { s.counters = c }
+}
+
+// NewService checks that tenant IDs are unique and that no space is owned by
+// two tenants.
+func NewService(tenants []Tenant, opts ...Option) (*Service, error) {
+	s := &Service{tenants: tenants, byID: map[string]Tenant{}, owners: map[string]string{}, local: map[string]int64{}}
+	for _, t := range tenants {
+		if err := t.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := s.byID[t.ID]; ok {
+			return nil, fmt.Errorf("tenant %s is defined twice", t.ID)
+		}
+		s.byID[t.ID] = t
+		for _, space := range t.Spaces {
+			if owner, ok := s.owners[space]; ok && owner != t.ID {
+				return nil, fmt.Errorf("space %s is owned by tenants %s and %s", space, owner, t.ID)
+			}
+			s.owners[space] = t.ID
+		}
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+func (s *Service) Tenants() []Tenant {
+	return s.tenants
+}
+
+func (s *Service) Tenant(id string) (Tenant, error) {
+	t, ok := s.byID[id]
+	if !ok {
+		return Tenant{}, fmt.Errorf("%w %q", ErrUnknownTenant, id)
+	}
+	return t, nil
+}
+
+// Owner returns the tenant owning space, or "" when no tenant does.
+func (s *Service) Owner(space string) string {
+	return s.owners[space]
+}
+
+// AllowedSpaces narrows the requested spaces to the tenant's; all of them
+// when none are requested.
+func (s *Service) AllowedSpaces(id string, requested []string) ([]string, error) {
+	t, err := s.Tenant(id)
+	if err != nil {
+		return nil, err
+	}
+	if len(requested) == 0 {
+		return t.Spaces, nil
+	}
+	var allowed []string
+	for _, space := range requested {
+		if slices.Contains(t.Spaces, space) {
+			allowed = append(allowed, space)
+		}
+	}
+	if len(allowed) == 0 {
+		return nil, ErrSpaceForbidden
+	}
+	return allowed, nil
+}
+
+// Apply layers the tenant's overrides over base.
+func (s *Service) Apply(base query.Config, id string) query.Config {
+	return s.byID[id].Overrides.apply(base)
+}
+
+// TakeQuery counts a question against the tenant's daily quota, which resets
+// at midnight UTC, or fails with ErrQuotaExceeded once it is used up.
+func (s *Service) TakeQuery(ctx context.Context, id string) error {
+	t, err := s.Tenant(id)
+	if err != nil {
+		return err
+	}
+	if t.Quota.QueriesPerDay <= 0 {
+		return nil
+	}
+	if s.incr(ctx, queriesKey(id, time.Now())) > int64(t.Quota.QueriesPerDay) {
+		return ErrQuotaExceeded
+	}
+	return nil
+}
+
+// QueriesToday counts the questions the tenant asked since midnight UTC,
+// those refused over its quota included.
+func (s *Service) QueriesToday(ctx context.Context, id string) int64 {
+	return s.count(ctx, queriesKey(id, time.Now()))
+}
+
+func queriesKey(id string, now time.Time) string {
+	return "tenant:" + id + ":queries:" + now.UTC().Format(time.DateOnly)
+}
+
+// incr counts key in the counters, falling back to local counts when there
+// are none or they fail.
+func (s *Service) incr(ctx context.Context, key string) int64 {
+	if s.counters != nil {
+		// The key outlives its day a little, for clocks that disagree.
+		n, err := s.counters.Incr(ctx, key, 25*time.Hour)
+		if err == nil {
+			return n
+		}
+		s.failed(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollover()
+	s.local[key]++
+	return s.local[key]
+}
+
+func (s *Service) count(ctx context.Context, key string) int64 {
+	if s.counters != nil {
+		n, err := s.counters.Count(ctx, key)
+		if err == nil {
+			return n
+		}
+		s.failed(err)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.rollover()
+	return s.local[key]
+}
+
+// rollover drops yesterday's local counts; s.mu must be held.
+func (s *Service) rollover() {
+	if day := time.Now().UTC().Format(time.DateOnly); day != s.day {
+		clear(s.local)
+		s.day = day
+	}
+}
+
+func (s *Service) failed(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if time.Since(s.lastFailure) > time.Minute {
+		s.lastFailure = time.Now()
+		log.Printf("Tenant quota counters failed, counting locally: %v\n", err)
+	}
+}
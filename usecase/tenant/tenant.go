@@ -0,0 +1,95 @@
+// Package tenant lets one deployment serve several teams or customers. Each
+// tenant owns Confluence spaces, which no other tenant may own: the spaces
+// are its namespace of the index, and its callers neither see nor change
+// documents outside them.
+package tenant
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/shubhamgptln/sarama-ai/usecase/query"
+)
+
+// Quota bounds what a tenant's callers may do; zero means unlimited.
+type Quota struct {
+	QueriesPerDay int `json:"queries_per_day,omitempty"`
+}
+
+// Overrides replace parts of the query configuration for a tenant's
+// questions. Zero values leave the deployment's configuration untouched.
+type Overrides struct {
+	TopK            int      `json:"top_k,omitempty"`
+	Model           string   `json:"model,omitempty"`
+	Prompt          string   `json:"prompt,omitempty"`
+	Temperature     *float64 `json:"temperature,omitempty"`
+	DefaultLanguage string   `json:"default_language,omitempty"`
+}
+
+type Tenant struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+	// Spaces are the Confluence spaces the tenant owns. Its Confluence pages
+	// are indexed from them and documents it adds go into them.
+	Spaces    []string  `json:"spaces"`
+	Quota     Quota     `json:"quota,omitzero"`
+	Overrides Overrides `json:"overrides,omitzero"`
+}
+
+func (t Tenant) Validate() error {
+	// Tenant IDs namespace the IDs of documents tenants add.
+	if t.ID == "" || strings.ContainsAny(t.ID, "/:") {
+		return fmt.Errorf("tenant ID %q must be non-empty without / or :", t.ID)
+	}
+	if len(t.Spaces) == 0 {
+		return fmt.Errorf("tenant %s: at least one space is required", t.ID)
+	}
+	for _, space := range t.Spaces {
+		if space == "" {
+			return fmt.Errorf("tenant %s: space keys must be non-empty", t.ID)
+		}
+	}
+	if t.Quota.QueriesPerDay < 0 {
+		return fmt.Errorf("tenant %s: queries_per_day must not be negative", t.ID)
+	}
+	if t.Overrides.TopK < 0 {
+		return fmt.Errorf("tenant %s: top_k must not be negative", t.ID)
+	}
+	if t.Overrides.Prompt != "" && !query.HasPrompt(t.Overrides.Prompt) {
+		return fmt.Errorf("tenant %s: unknown prompt %q", t.ID, t.Overrides.Prompt)
+	}
+	return nil
+}
+
+func (o Overrides) apply(cfg query.Config) query.Config {
+	if o.TopK > 0 {
+		cfg.TopK = o.TopK
+	}
+	if o.Model != "" {
+		cfg.Model = o.Model
+	}
+	if o.Prompt != "" {
+		cfg.Prompt = o.Prompt
+	}
+	if o.Temperature != nil {
+		cfg.Temperature = *o.Temperature
+	}
+	if o.DefaultLanguage != "" {
+		cfg.DefaultLanguage = o.DefaultLanguage
+	}
+	return cfg
+}
+
+func LoadFile(path string) ([]Tenant, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var tenants []Tenant
+	if err := json.Unmarshal(data, &tenants); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return tenants, nil
+}
@@ -0,0 +1,245 @@
+package writeback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/shubhamgptln/sarama-ai/domain"
+)
+
+const (
+	TargetComment  = "comment"
+	TargetProperty = "property"
+)
+
+// maxSourceRunes bounds how much of a page is sent to the model to summarize.
+const maxSourceRunes = 24000
+
+const summaryPrompt = `Summarize the internal documentation page below for a colleague skimming it.
+Write two to four sentences covering what the page is for and its key facts or steps.
+Use only the page content, and answer in plain prose without preamble.`
+
+var (
+	ErrSpaceNotAllowed = errors.New("write-back is not enabled for this space")
+	ErrInvalidTarget   = errors.New("target must be comment or property")
+)
+
+type Config struct {
+	// Target is where results are posted: a page comment or a content property.
+	Target      string
+	PropertyKey string
+	// DryRun renders what would be posted without writing to Confluence.
+	DryRun bool
+	// SpaceKeys limits write-back to pages of these spaces; all when empty.
+	SpaceKeys []string
+	Model     string
+	MaxTokens int
+}
+
+type Asker interface {
+	Ask(ctx context.Context, q domain.Question) (*domain.Answer, error)
+}
+
+type Publisher interface {
+	AddComment(ctx context.Context, pageID, storage string) (string, error)
+	SetProperty(ctx context.Context, pageID, key string, value any) error
+}
+
+type Request struct {
+	DocumentID string `json:"document_id"`
+	// Question, when set, posts its answer with links to the sources instead
+	// of a summary of the page.
+	Question string `json:"question,omitempty"`
+	// DryRun previews the write-back even when it is live.
+	DryRun bool `json:"dry_run,omitempty"`
+}
+
+// Property is the value written to the page's content property.
+type Property struct {
+	Summary     string    `json:"summary,omitempty"`
+	Question    string    `json:"question,omitempty"`
+	Answer      string    `json:"answer,omitempty"`
+	Sources     []Source  `json:"sources,omitempty"`
+	Model       string    `json:"model"`
+	GeneratedAt time.Time `json:"generated_at"`
+}
+
+type Source struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+type Result struct {
+	DocumentID string `json:"document_id"`
+	Target     string `json:"target"`
+	DryRun     bool   `json:"dry_run"`
+	// Comment is the comment in Confluence storage format; Property the
+	// property value. Only the one matching Target is set.
+	Comment  string    `json:"comment,omitempty"`
+	Property *Property `json:"property,omitempty"`
+	// URL links to the posted comment.
+	URL string `json:"url,omitempty"`
+}
+
+// Service posts generated page summaries and answers back to Confluence pages.
+type Service struct {
+	store     domain.VectorStore
+	asker     Asker
+	model     domain.ChatModel
+	publisher Publisher
+	cfg       Config
+}
+
+func NewService(store domain.VectorStore, asker Asker, model domain.ChatModel, publisher Publisher, cfg Config) (*Service, error) {
+	if cfg.Target != TargetComment && cfg.Target != TargetProperty {
+		return nil, fmt.Errorf("write-back: %w", ErrInvalidTarget)
+	}
+	if cfg.PropertyKey == "" {
+		cfg.PropertyKey = "sarama-summary"
+	}
+	if cfg.MaxTokens <= 0 {
+		cfg.MaxTokens = 400
+	}
+	return &Service{store: store, asker: asker, model: model, publisher: publisher, cfg: cfg}, nil
+}
+
+// Post summarizes the page, or answers req.Question from its space, and posts
+// the result to the page.
+func (s *Service) Post(ctx context.Context, req Request) (*Result, error) {
+	chunks, err := s.store.DocumentChunks(ctx, req.DocumentID)
+	if err != nil {
+		return nil, err
+	}
+	if len(chunks) == 0 {
+		return nil, domain.ErrNotFound
+	}
+	slices.SortFunc(chunks, func(a, b domain.Chunk) int { return a.Index - b.Index })
+	page := chunks[0]
+	if len(s.cfg.SpaceKeys) > 0 && !slices.Contains(s.cfg.SpaceKeys, page.SpaceKey) {
+		return nil, ErrSpaceNotAllowed
+	}
+
+	var prop *Property
+	if req.Question == "" {
+		prop, err = s.summarize(ctx, chunks)
+	} else {
+		prop, err = s.answer(ctx, page, req.Question)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{DocumentID: req.DocumentID, Target: s.cfg.Target, DryRun: s.cfg.DryRun || req.DryRun}
+	if s.cfg.Target == TargetProperty {
+		result.Property = prop
+	} else {
+		result.Comment = comment(prop)
+	}
+	if result.DryRun {
+		log.Printf("Dry run: would post a %s to page %s\n", s.cfg.Target, req.DocumentID)
+		return result, nil
+	}
+	if s.cfg.Target == TargetProperty {
+		err = s.publisher.SetProperty(ctx, req.DocumentID, s.cfg.PropertyKey, prop)
+	} else {
+		result.URL, err = s.publisher.AddComment(ctx, req.DocumentID, result.Comment)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("post %s to page %s: %w", s.cfg.Target, req.DocumentID, err)
+	}
+	log.Printf("Posted a %s to page %s\n", s.cfg.Target, req.DocumentID)
+	return result, nil
+}
+
+func (s *Service) summarize(ctx context.Context, chunks []domain.Chunk) (*Property, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Title: %s\n\n", chunks[0].Title)
+	for _, c := range chunks {
+		b.WriteString(c.Text)
+		b.WriteString("\n\n")
+	}
+	source := []rune(b.String())
+	if len(source) > maxSourceRunes {
+		source = source[:maxSourceRunes]
+	}
+	completion, err := s.model.Complete(ctx, domain.CompletionRequest{
+		Model:     s.cfg.Model,
+		MaxTokens: s.cfg.MaxTokens,
+		Messages: []domain.Message{
+			{Role: domain.RoleSystem, Content: summaryPrompt},
+			{Role: domain.RoleUser, Content: string(source)},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("summarize: %w", err)
+	}
+	return &Property{
+		Summary:     strings.TrimSpace(completion.Content),
+		Model:       completion.Model,
+		GeneratedAt: time.Now().UTC(),
+	}, nil
+}
+
+// answer answers question from the page's space. Everyone who can read the
+// page sees the result, so it only draws on pages they can read too: pages
+// with the same restrictions, or none when the page itself has none.
+func (s *Service) answer(ctx context.Context, page domain.Chunk, question string) (*Property, error) {
+	readers := page.Readers
+	if len(readers) == 0 {
+		readers = []string{domain.ReaderAnyone}
+	}
+	answer, err := s.asker.Ask(ctx, domain.Question{
+		Text:      question,
+		SpaceKeys: []string{page.SpaceKey},
+		Readers:   readers,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("answer: %w", err)
+	}
+	prop := &Property{
+		Question:    question,
+		Answer:      answer.Text,
+		Model:       answer.Model,
+		GeneratedAt: time.Now().UTC(),
+	}
+	for _, c := range answer.Citations {
+		prop.Sources = append(prop.Sources, Source{Title: c.Title, URL: c.URL})
+	}
+	return prop, nil
+}
+
+// comment renders p in Confluence storage format.
+func comment(p *Property) string {
+	var b strings.Builder
+	if p.Question != "" {
+		fmt.Fprintf(&b, "<p><strong>Q: %s</strong></p>", html.EscapeString(p.Question))
+		writeParagraphs(&b, p.Answer)
+	} else {
+		b.WriteString("<p><strong>Summary</strong></p>")
+		writeParagraphs(&b, p.Summary)
+	}
+	if len(p.Sources) > 0 {
+		b.WriteString("<p><strong>Sources</strong></p><ul>")
+		for _, src := range p.Sources {
+			fmt.Fprintf(&b, `<li><a href="%s">%s</a></li>`, html.EscapeString(src.URL), html.EscapeString(src.Title))
+		}
+		b.WriteString("</ul>")
+	}
+	fmt.Fprintf(&b, "<p><em>Generated by Sarama (%s)</em></p>", html.EscapeString(p.Model))
+	return b.String()
+}
+
+func writeParagraphs(b *strings.Builder, text string) {
+	for _, para := range strings.Split(strings.TrimSpace(text), "\n\n") {
+		if para = strings.TrimSpace(para); para != "" {
+			lines := strings.Split(html.EscapeString(para), "\n")
+			fmt.Fprintf(b, "<p>%s</p>", strings.Join(lines, "<br/>"))
+		}
+	}
+}